@@ -5,12 +5,17 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
+	"Backend/internal/mailer"
+	"Backend/internal/pdf"
 	"Backend/internal/store"
 	"Backend/internal/store/models"
+	"Backend/internal/worker"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -20,7 +25,7 @@ import (
 func (app *Application) MountAdmin() {
 	r := chi.NewRouter()
 	r.Use(app.AuthTokenMiddleware())
-	r.Use(app.RoleMiddleware("admin"))
+	r.Use(app.StaffMiddleware)
 
 	// Profile
 	r.Get("/profile", app.adminProfileHandler)
@@ -28,128 +33,179 @@ func (app *Application) MountAdmin() {
 	r.Post("/profile/change-password", app.adminChangePasswordHandler)
 
 	// Events (admin view - all events, not just own)
-	r.Get("/events", app.adminListEventsHandler)
-	r.Post("/events", app.adminCreateEventHandler)
-	r.Get("/events/stats", app.adminEventsStatsHandler)
-	r.Get("/events/stats/today", app.adminEventsTodayHandler)
-	r.Get("/events/stats/week", app.adminEventsWeekHandler)
-	r.Get("/events/{id}", app.adminGetEventHandler)
-	r.Patch("/events/{id}", app.adminUpdateEventHandler)
-	r.Delete("/events/{id}", app.adminDeleteEventHandler)
-	r.Post("/events/{id}/send-quote", app.adminSendQuoteHandler)
-
-	// Quotes
-	r.Post("/quotes", app.adminCreateQuoteHandler)
-	r.Get("/quotes", app.adminListQuotesHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermEventsRead))
+		r.Get("/events", app.adminListEventsHandler)
+		r.Get("/events/stats", app.adminEventsStatsHandler)
+		r.Get("/events/stats/today", app.adminEventsTodayHandler)
+		r.Get("/events/stats/week", app.adminEventsWeekHandler)
+		r.Get("/events/{id}", app.adminGetEventHandler)
+		r.Get("/events/{id}/return", app.adminGetEventReturnHandler)
+		r.Get("/quotes", app.adminListQuotesHandler)
+		r.Get("/quotes/history", app.adminQuoteHistoryHandler)
+		r.Get("/recurring", app.adminListRecurringEventsHandler)
+		r.Get("/recurring/{id}/events", app.adminRecurringEventsHandler)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermEventsWrite))
+		r.Post("/events", app.adminCreateEventHandler)
+		r.Patch("/events/{id}", app.adminUpdateEventHandler)
+		r.Delete("/events/{id}", app.adminDeleteEventHandler)
+		r.Put("/events/{id}/payment-plan", app.adminSetEventPaymentPlanHandler)
+		r.Post("/events/{id}/send-quote", app.adminSendQuoteHandler)
+		r.Post("/quotes", app.adminCreateQuoteHandler)
+		r.Post("/recurring", app.adminCreateRecurringEventHandler)
+		r.Patch("/recurring/{id}", app.adminUpdateRecurringEventHandler)
+		r.Delete("/recurring/{id}", app.adminDeleteRecurringEventHandler)
+		r.Post("/recurring/{id}/generate", app.adminGenerateRecurringEventHandler)
+	})
 
 	// Clients / Users
-	r.Get("/clients", app.adminListClientsHandler)
-	r.Get("/users/{id}", app.adminGetClientHandler)
-	r.Patch("/users/{id}", app.adminUpdateClientHandler)
-	r.Post("/users/{id}/block", app.adminBlockClientHandler)
-	r.Post("/users/{id}/force-logout", app.adminForceLogoutHandler)
-	r.Post("/users/lead", app.adminCreateLeadHandler)
-
-	// Articles / Products
-	r.Get("/articles", app.adminListArticlesHandler)
-	r.Post("/articles", app.adminCreateArticleHandler)
-	r.Get("/articles/{id}", app.adminGetArticleHandler)
-	r.Patch("/articles/{id}", app.adminUpdateArticleHandler)
-	r.Delete("/articles/{id}", app.adminDeleteArticleHandler)
-	r.Patch("/articles/{id}/toggle-active", app.adminToggleArticleActiveHandler)
-
-	// Article Variants
-	r.Get("/articles/{id}/variants", app.adminListArticleVariantsHandler)
-	r.Post("/articles/{id}/variants", app.adminCreateArticleVariantHandler)
-	r.Patch("/variants/{variantId}", app.adminUpdateArticleVariantHandler)
-	r.Delete("/variants/{variantId}", app.adminDeleteArticleVariantHandler)
-
-	// Categories
-	r.Get("/categories", app.adminListCategoriesHandler)
-	r.Post("/categories", app.adminCreateCategoryHandler)
-	r.Patch("/categories/{id}", app.adminUpdateCategoryHandler)
-	r.Delete("/categories/{id}", app.adminDeleteCategoryHandler)
-
-	// Bundles
-	r.Get("/bundles", app.adminListBundlesHandler)
-	r.Post("/bundles", app.adminCreateBundleHandler)
-	r.Patch("/bundles/{id}", app.adminUpdateBundleHandler)
-	r.Delete("/bundles/{id}", app.adminDeleteBundleHandler)
-	r.Post("/bundles/{id}/items", app.adminAddBundleItemHandler)
-	r.Delete("/bundles/{id}/items/{articleId}", app.adminRemoveBundleItemHandler)
-
-	// AI Config
-	r.Get("/ai/config", app.adminGetAIConfigHandler)
-	r.Patch("/ai/config", app.adminUpdateAIConfigHandler)
-	r.Get("/ai/history", app.adminGetAIHistoryHandler)
-
-	// Notifications
-	r.Get("/notifications/email-templates", app.adminListEmailTemplatesHandler)
-	r.Patch("/notifications/email-templates/{id}", app.adminUpdateEmailTemplateHandler)
-	r.Post("/notifications/test-email", app.adminSendTestEmailHandler)
-	r.Get("/notifications/whatsapp-templates", app.adminListWhatsAppTemplatesHandler)
-	r.Patch("/notifications/whatsapp-templates/{id}", app.adminUpdateWhatsAppTemplateHandler)
-	r.Get("/notifications/triggers", app.adminGetNotificationTriggersHandler)
-	r.Patch("/notifications/triggers/{id}", app.adminUpdateNotificationTriggerHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermClientsRead))
+		r.Get("/clients", app.adminListClientsHandler)
+		r.Get("/users/{id}", app.adminGetClientHandler)
+		r.Get("/search/clients", app.adminSearchClientsHandler)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermClientsWrite))
+		r.Patch("/users/{id}", app.adminUpdateClientHandler)
+		r.Post("/users/lead", app.adminCreateLeadHandler)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermUsersBlock))
+		r.Post("/users/{id}/block", app.adminBlockClientHandler)
+		r.Post("/users/{id}/unblock", app.adminUnblockClientHandler)
+		r.Post("/users/{id}/force-logout", app.adminForceLogoutHandler)
+	})
+
+	// Roles and staff
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermRolesManage))
+		r.Get("/permissions", app.adminListPermissionsHandler)
+		r.Get("/roles", app.adminListRolesHandler)
+		r.Post("/roles", app.adminCreateRoleHandler)
+		r.Patch("/roles/{id}", app.adminUpdateRoleHandler)
+		r.Delete("/roles/{id}", app.adminDeleteRoleHandler)
+		r.Get("/staff", app.adminListStaffHandler)
+		r.Put("/users/{id}/role", app.adminAssignRoleHandler)
+	})
+
+	// Articles, variants, categories, bundles and event types
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermInventoryRead))
+		r.Get("/articles", app.adminListArticlesHandler)
+		r.Get("/articles/{id}", app.adminGetArticleHandler)
+		r.Get("/articles/{id}/variants", app.adminListArticleVariantsHandler)
+		r.Get("/categories", app.adminListCategoriesHandler)
+		r.Get("/bundles", app.adminListBundlesHandler)
+		r.Get("/event-types", app.adminListEventTypesHandler)
+		r.Get("/search/articles", app.adminSearchArticlesHandler)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermInventoryWrite))
+		r.Post("/articles", app.adminCreateArticleHandler)
+		r.Patch("/articles/{id}", app.adminUpdateArticleHandler)
+		r.Delete("/articles/{id}", app.adminDeleteArticleHandler)
+		r.Patch("/articles/{id}/toggle-active", app.adminToggleArticleActiveHandler)
+		r.Post("/articles/bulk-deactivate", app.adminBulkDeactivateArticlesHandler)
+		r.Post("/articles/{id}/variants", app.adminCreateArticleVariantHandler)
+		r.Patch("/variants/{variantId}", app.adminUpdateArticleVariantHandler)
+		r.Delete("/variants/{variantId}", app.adminDeleteArticleVariantHandler)
+		r.Post("/categories", app.adminCreateCategoryHandler)
+		r.Patch("/categories/{id}", app.adminUpdateCategoryHandler)
+		r.Delete("/categories/{id}", app.adminDeleteCategoryHandler)
+		r.Post("/bundles", app.adminCreateBundleHandler)
+		r.Patch("/bundles/{id}", app.adminUpdateBundleHandler)
+		r.Delete("/bundles/{id}", app.adminDeleteBundleHandler)
+		r.Post("/bundles/{id}/items", app.adminAddBundleItemHandler)
+		r.Delete("/bundles/{id}/items/{articleId}", app.adminRemoveBundleItemHandler)
+		r.Post("/event-types", app.adminCreateEventTypeHandler)
+		r.Patch("/event-types/{id}", app.adminUpdateEventTypeHandler)
+		r.Delete("/event-types/{id}", app.adminDeleteEventTypeHandler)
+		r.Put("/event-types/{id}/payment-plan", app.adminSetEventTypePaymentPlanHandler)
+	})
+
+	// Maintenance and returns
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermInventoryAdjust))
+		r.Get("/maintenance", app.adminListMaintenanceLogsHandler)
+		r.Post("/maintenance", app.adminCreateMaintenanceLogHandler)
+		r.Patch("/maintenance/{id}", app.adminUpdateMaintenanceLogHandler)
+		r.Get("/maintenance/overdue", app.adminMaintenanceOverdueHandler)
+		r.Get("/articles/{id}/maintenance", app.adminArticleMaintenanceHandler)
+		r.Post("/events/{id}/return", app.adminRecordEventReturnHandler)
+	})
 
 	// Analytics
-	r.Get("/analytics/monthly", app.adminMonthlyStatsHandler)
-	r.Get("/analytics/revenue", app.adminRevenueChartHandler)
-	r.Get("/analytics/top-products", app.adminTopProductsHandler)
-	r.Get("/analytics/conversion-rate", app.adminConversionRateHandler)
-	r.Get("/analytics/pending-payments", app.adminPendingPaymentsHandler)
-	r.Get("/analytics/export/{type}", app.adminExportHandler)
-	r.Get("/analytics/report", app.adminReportPDFHandler)
-
-	// Quote history
-	r.Get("/quotes/history", app.adminQuoteHistoryHandler)
-
-	// Bulk operations
-	r.Post("/articles/bulk-deactivate", app.adminBulkDeactivateArticlesHandler)
-
-	// Health
-	r.Get("/health/redis", app.adminRedisHealthHandler)
-	r.Get("/analytics/monthly", app.adminMonthlyStatsHandler)
-	r.Get("/analytics/revenue", app.adminRevenueChartHandler)
-	r.Get("/analytics/top-products", app.adminTopProductsHandler)
-	r.Get("/analytics/conversion-rate", app.adminConversionRateHandler)
-	r.Get("/analytics/pending-payments", app.adminPendingPaymentsHandler)
-	r.Get("/analytics/export/{type}", app.adminExportHandler)
-	r.Get("/analytics/report", app.adminReportPDFHandler)
-
-	// Config
-	r.Get("/config/delivery-zones", app.adminGetDeliveryZonesHandler)
-	r.Patch("/config/delivery-zones", app.adminUpdateDeliveryZonesHandler)
-	r.Get("/config/payment-methods", app.adminGetPaymentMethodsHandler)
-	r.Patch("/config/payment-methods", app.adminUpdatePaymentMethodsHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermDashboardRead))
+		r.Get("/analytics/monthly", app.adminMonthlyStatsHandler)
+		r.Get("/analytics/revenue", app.adminRevenueChartHandler)
+		r.Get("/analytics/top-products", app.adminTopProductsHandler)
+		r.Get("/analytics/conversion-rate", app.adminConversionRateHandler)
+		r.Get("/analytics/export/{type}", app.adminExportHandler)
+		r.Get("/analytics/report", app.adminReportPDFHandler)
+	})
+
+	// Payments and profitability
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermFinancialRead))
+		r.Get("/analytics/pending-payments", app.adminPendingPaymentsHandler)
+		r.Get("/analytics/event-profit", app.adminEventProfitsHandler)
+		r.Get("/events/{id}/profit", app.adminEventProfitHandler)
+		r.Get("/payments/pending", app.adminListPaymentVerificationsHandler)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermFinancialWrite))
+		r.Post("/payments/{id}/confirm", app.adminConfirmPaymentHandler)
+		r.Post("/payments/{id}/reject", app.adminRejectPaymentHandler)
+	})
+
+	// WhatsApp inbox
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermMessagesWrite))
+		r.Get("/whatsapp/conversations", app.adminListWhatsAppConversationsHandler)
+		r.Get("/whatsapp/conversations/{phone}", app.adminGetWhatsAppThreadHandler)
+		r.Post("/whatsapp/conversations/{phone}/reply", app.adminReplyWhatsAppHandler)
+	})
 
 	// Audit logs
-	r.Get("/events/audit", app.adminGetAuditLogsHandler)
-
-	// Search
-	r.Get("/search/clients", app.adminSearchClientsHandler)
-	r.Get("/search/articles", app.adminSearchArticlesHandler)
-
-	// Event Types
-	r.Get("/event-types", app.adminListEventTypesHandler)
-	r.Post("/event-types", app.adminCreateEventTypeHandler)
-	r.Patch("/event-types/{id}", app.adminUpdateEventTypeHandler)
-	r.Delete("/event-types/{id}", app.adminDeleteEventTypeHandler)
-
-	// Maintenance Logs
-	r.Get("/maintenance", app.adminListMaintenanceLogsHandler)
-	r.Post("/maintenance", app.adminCreateMaintenanceLogHandler)
-	r.Patch("/maintenance/{id}", app.adminUpdateMaintenanceLogHandler)
-	r.Get("/maintenance/overdue", app.adminMaintenanceOverdueHandler)
-	r.Get("/articles/{id}/maintenance", app.adminArticleMaintenanceHandler)
-
-	// Recurring Events
-	r.Get("/recurring", app.adminListRecurringEventsHandler)
-	r.Post("/recurring", app.adminCreateRecurringEventHandler)
-	r.Patch("/recurring/{id}", app.adminUpdateRecurringEventHandler)
-	r.Delete("/recurring/{id}", app.adminDeleteRecurringEventHandler)
-	r.Get("/recurring/{id}/events", app.adminRecurringEventsHandler)
-	r.Post("/recurring/{id}/generate", app.adminGenerateRecurringEventHandler)
+	r.With(app.RequirePermission(models.PermAuditRead)).Get("/events/audit", app.adminGetAuditLogsHandler)
+
+	// Settings: AI, notifications, jobs and configuration
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermSettingsWrite))
+		r.Get("/ai/config", app.adminGetAIConfigHandler)
+		r.Patch("/ai/config", app.adminUpdateAIConfigHandler)
+		r.Get("/ai/history", app.adminGetAIHistoryHandler)
+
+		r.Get("/notifications/email-templates", app.adminListEmailTemplatesHandler)
+		r.Patch("/notifications/email-templates/{id}", app.adminUpdateEmailTemplateHandler)
+		r.Get("/notifications/email-templates/{id}/versions", app.adminListEmailTemplateVersionsHandler)
+		r.Post("/notifications/email-templates/{id}/versions/{version}/activate", app.adminActivateEmailTemplateVersionHandler)
+		r.Post("/notifications/email-templates/{id}/preview", app.adminPreviewEmailTemplateHandler)
+		r.Post("/notifications/test-email", app.adminSendTestEmailHandler)
+		r.Get("/notifications/whatsapp-templates", app.adminListWhatsAppTemplatesHandler)
+		r.Patch("/notifications/whatsapp-templates/{id}", app.adminUpdateWhatsAppTemplateHandler)
+		r.Get("/notifications/triggers", app.adminGetNotificationTriggersHandler)
+		r.Post("/notifications/triggers", app.adminCreateNotificationTriggerHandler)
+		r.Patch("/notifications/triggers/{id}", app.adminUpdateNotificationTriggerHandler)
+		r.Delete("/notifications/triggers/{id}", app.adminDeleteNotificationTriggerHandler)
+
+		r.Get("/health/redis", app.adminRedisHealthHandler)
+		r.Get("/jobs", app.adminListJobsHandler)
+		r.Post("/jobs/{id}/retry", app.adminRetryJobHandler)
+
+		r.Get("/config/delivery-zones", app.adminGetDeliveryZonesHandler)
+		r.Patch("/config/delivery-zones", app.adminUpdateDeliveryZonesHandler)
+		r.Get("/config/payment-methods", app.adminGetPaymentMethodsHandler)
+		r.Patch("/config/payment-methods", app.adminUpdatePaymentMethodsHandler)
+		r.Get("/config/payment-plans", app.adminListPaymentPlansHandler)
+		r.Post("/config/payment-plans", app.adminCreatePaymentPlanHandler)
+		r.Patch("/config/payment-plans/{id}", app.adminUpdatePaymentPlanHandler)
+		r.Delete("/config/payment-plans/{id}", app.adminDeletePaymentPlanHandler)
+	})
 
 	app.Mux.Mount("/v1/admin", r)
 }
@@ -160,12 +216,18 @@ func (app *Application) MountAdmin() {
 
 func (app *Application) adminProfileHandler(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromCtx(r)
+	permissions, _, err := app.userPermissions(r)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
 		"data": map[string]interface{}{
-			"id":    user.ID,
-			"name":  user.FirstName + " " + user.LastName,
-			"email": user.Email,
-			"role":  user.Role.Name,
+			"id":          user.ID,
+			"name":        user.FirstName + " " + user.LastName,
+			"email":       user.Email,
+			"role":        user.Role.Name,
+			"permissions": permissions,
 		},
 	})
 }
@@ -373,6 +435,7 @@ func (app *Application) adminGetEventHandler(w http.ResponseWriter, r *http.Requ
 		"client_name":  "",
 		"client_phone": "",
 		"total_quote":   event.TotalQuote,
+		"delivery_fee":  event.DeliveryFee,
 		"deposit_paid":  event.DepositAmount,
 		"items":        items,
 	}
@@ -408,29 +471,62 @@ func (app *Application) adminUpdateEventHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	prevStatus := event.Status
 	if v, ok := payload["status"].(string); ok {
+		if !app.checkEventTransition(w, r, event, v) {
+			return
+		}
 		event.Status = v
 	}
+	adminUser := GetUserFromCtx(r)
+	event.StatusChange = &models.EventStatusChange{ActorID: adminUser.ID}
+	if v, ok := payload["status_reason"].(string); ok && v != "" {
+		event.StatusChange.Reason = &v
+	}
 	if v, ok := payload["location"].(string); ok {
 		event.Location = v
 	}
 	if v, ok := payload["total_quote"].(float64); ok {
 		event.TotalQuote = int(v)
 	}
+	var pickupAt, returnAt *time.Time
+	if v, ok := payload["pickup_at"].(string); ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			app.badRequest(w, r, errors.New("invalid pickup_at, expected RFC3339"))
+			return
+		}
+		pickupAt = &t
+	}
+	if v, ok := payload["return_at"].(string); ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			app.badRequest(w, r, errors.New("invalid return_at, expected RFC3339"))
+			return
+		}
+		returnAt = &t
+	}
+	if err := setRentalWindow(event, pickupAt, returnAt); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
 
 	if err := app.Store.Events.Update(r.Context(), event); err != nil {
-		app.internalServerError(w, r, err)
+		app.handleError(w, r, err)
 		return
 	}
 
-	adminUser := GetUserFromCtx(r)
-	_ = app.Store.AuditLogs.Log(r.Context(), &models.AuditLog{
-		UserID:     &adminUser.ID,
-		EventID:   &event.ID,
-		Action:    models.AuditActionEventAdjust,
-		EntityType: "event",
-		EntityID:  &event.ID,
-	})
+	// A change of status was recorded by the store; other edits are
+	// recorded here.
+	if event.Status == prevStatus {
+		_ = app.Store.AuditLogs.Log(r.Context(), &models.AuditLog{
+			UserID:     &adminUser.ID,
+			EventID:    &event.ID,
+			Action:     models.AuditActionEventAdjust,
+			EntityType: "event",
+			EntityID:   &event.ID,
+		})
+	}
 
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": event})
 }
@@ -463,9 +559,13 @@ func (app *Application) adminSendQuoteHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	event.Status = "requested"
+	if !app.checkEventTransition(w, r, event, models.EventStatusRequested) {
+		return
+	}
+	event.Status = models.EventStatusRequested
+	event.StatusChange = &models.EventStatusChange{ActorID: GetUserFromCtx(r).ID}
 	if err := app.Store.Events.Update(r.Context(), event); err != nil {
-		app.internalServerError(w, r, err)
+		app.handleError(w, r, err)
 		return
 	}
 
@@ -675,12 +775,113 @@ func (app *Application) adminUpdateClientHandler(w http.ResponseWriter, r *http.
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "ok"})
 }
 
+// adminBlockClientHandler blocks a user, or suspends them until a date,
+// and signs them out everywhere at once.
 func (app *Application) adminBlockClientHandler(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-	_ = idParam
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	var payload models.BlockUserPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if payload.Until != nil && !payload.Until.After(time.Now()) {
+		app.badRequest(w, r, errors.New("until must be in the future"))
+		return
+	}
+
+	admin := GetUserFromCtx(r)
+	if admin.ID == userID {
+		app.badRequest(w, r, errors.New("you can't block yourself"))
+		return
+	}
+
+	ctx := r.Context()
+	if err := app.Store.Users.Block(ctx, userID, admin.ID, payload.Until, payload.Reason); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if err := app.evictUser(ctx, userID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var until *string
+	if payload.Until != nil {
+		v := payload.Until.Format(time.RFC3339)
+		until = &v
+	}
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionUserBlock,
+		EntityType: "user",
+		EntityID:   &userID,
+		NewValue:   until,
+		Reason:     &payload.Reason,
+	})
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "ok"})
 }
 
+func (app *Application) adminUnblockClientHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	reason, ok := app.readAdminReason(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	if err := app.Store.Users.Unblock(ctx, userID); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if err := app.evictUser(ctx, userID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	admin := GetUserFromCtx(r)
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionUserUnblock,
+		EntityType: "user",
+		EntityID:   &userID,
+		Reason:     reason,
+	})
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "ok"})
+}
+
+// readAdminReason reads the optional reason an admin gave for an action on
+// a user. The body may be empty.
+func (app *Application) readAdminReason(w http.ResponseWriter, r *http.Request) (*string, bool) {
+	var payload struct {
+		Reason string `json:"reason" validate:"max=500"`
+	}
+	if err := readJson(w, r, &payload); err != nil && !errors.Is(err, io.EOF) {
+		app.badRequest(w, r, err)
+		return nil, false
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return nil, false
+	}
+	if payload.Reason == "" {
+		return nil, true
+	}
+	return &payload.Reason, true
+}
+
 func (app *Application) adminCreateLeadHandler(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
 		Name  string `json:"name"`
@@ -959,17 +1160,25 @@ func (app *Application) adminListCategoriesHandler(w http.ResponseWriter, r *htt
 
 func (app *Application) adminCreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Icon        string `json:"icon"`
+		Name                string `json:"name"`
+		Description         string `json:"description"`
+		Icon                string `json:"icon"`
+		SetupBufferHours    int    `json:"setup_buffer_hours"`
+		CleaningBufferHours int    `json:"cleaning_buffer_hours"`
 	}
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
 		return
 	}
+	if payload.SetupBufferHours < 0 || payload.CleaningBufferHours < 0 {
+		app.badRequest(w, r, errors.New("buffer hours cannot be negative"))
+		return
+	}
 
 	cat := &models.Category{
-		Name: payload.Name,
+		Name:                payload.Name,
+		SetupBufferHours:    payload.SetupBufferHours,
+		CleaningBufferHours: payload.CleaningBufferHours,
 	}
 	if payload.Description != "" {
 		cat.Description = &payload.Description
@@ -1002,6 +1211,13 @@ func (app *Application) adminUpdateCategoryHandler(w http.ResponseWriter, r *htt
 	if v, ok := payload["description"].(string); ok {
 		cat.Description = &v
 	}
+	// JSON numbers decode as float64 into the generic payload.
+	if v, ok := payload["setup_buffer_hours"].(float64); ok && v >= 0 {
+		cat.SetupBufferHours = int(v)
+	}
+	if v, ok := payload["cleaning_buffer_hours"].(float64); ok && v >= 0 {
+		cat.CleaningBufferHours = int(v)
+	}
 
 	if err := app.Store.Categories.Update(r.Context(), cat); err != nil {
 		app.internalServerError(w, r, err)
@@ -1105,39 +1321,298 @@ func (app *Application) adminGetAIHistoryHandler(w http.ResponseWriter, r *http.
 // Notifications
 // ============================================================
 
+// emailTemplateView is what the admin panel edits: the active stored
+// version of a template, or the embedded file when it was never edited.
+type emailTemplateView struct {
+	Key       string     `json:"key"`
+	Name      string     `json:"name"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Version   int        `json:"version"`
+	Source    string     `json:"source"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
 func (app *Application) adminListEmailTemplatesHandler(w http.ResponseWriter, r *http.Request) {
-	templates := []map[string]interface{}{
-		{"id": "user_invitation", "name": "Invitacion de registro", "subject": "Bienvenido a RosaFiesta!", "body": "Haz click para registrarte..."},
-		{"id": "reminder_7d", "name": "Recordatorio 7 dias", "subject": "Tu evento se acerca!", "body": "Recuerda que tu evento es en 7 dias..."},
-		{"id": "reminder_24h", "name": "Recordatorio 24h", "subject": "Mañana es tu evento!", "body": "Todo listo para mañana..."},
-		{"id": "thank_you", "name": "Agradecimiento post-evento", "subject": "Gracias por elegirnos!", "body": "Esperamos que hayas disfrutado..."},
-		{"id": "reset_password", "name": "Reset password", "subject": "Recupera tu contrasena", "body": "haz click en el enlace..."},
-		{"id": "contract_confirmed", "name": "Contrato confirmado", "subject": "Contrato confirmado!", "body": "Tu contrato ha sido confirmado..."},
+	stored, err := app.Store.EmailTemplates.ListActive(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	active := make(map[string]models.EmailTemplate, len(stored))
+	for _, t := range stored {
+		active[t.Key] = t
+	}
+
+	templates := make([]emailTemplateView, 0, len(mailer.Templates))
+	for _, info := range mailer.Templates {
+		view, err := newEmailTemplateView(info, active)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		templates = append(templates, view)
 	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": templates})
 }
 
+func newEmailTemplateView(info mailer.TemplateInfo, active map[string]models.EmailTemplate) (emailTemplateView, error) {
+	key := mailer.TemplateKey(info.File)
+	if t, ok := active[key]; ok {
+		return emailTemplateView{
+			Key:       key,
+			Name:      info.Name,
+			Subject:   t.Subject,
+			Body:      t.Body,
+			Version:   t.Version,
+			Source:    "database",
+			UpdatedAt: &t.CreatedAt,
+		}, nil
+	}
+
+	subject, body, err := mailer.EmbeddedSource(info.File)
+	if err != nil {
+		return emailTemplateView{}, err
+	}
+	return emailTemplateView{Key: key, Name: info.Name, Subject: subject, Body: body, Source: "default"}, nil
+}
+
+type updateEmailTemplatePayload struct {
+	Subject string `json:"subject" validate:"required,max=998"`
+	Body    string `json:"body" validate:"required"`
+}
+
+// adminUpdateEmailTemplateHandler saves an edit as a new version of the
+// template and makes it the one mails are sent with.
 func (app *Application) adminUpdateEmailTemplateHandler(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-	_ = idParam
-	var payload map[string]interface{}
+	key := chi.URLParam(r, "id")
+	if _, ok := mailer.LookupTemplate(key); !ok {
+		app.notFoundResponse(w, r, fmt.Errorf("email template %q not found", key))
+		return
+	}
+
+	var payload updateEmailTemplatePayload
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
 		return
 	}
-	_ = payload
-	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "ok"})
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if _, err := mailer.Compile(payload.Subject, payload.Body); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	adminUser := GetUserFromCtx(r)
+	t := &models.EmailTemplate{
+		Key:       key,
+		Subject:   payload.Subject,
+		Body:      payload.Body,
+		CreatedBy: &adminUser.ID,
+	}
+	if err := app.Store.EmailTemplates.CreateVersion(r.Context(), t); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": t})
+}
+
+func (app *Application) adminListEmailTemplateVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "id")
+	if _, ok := mailer.LookupTemplate(key); !ok {
+		app.notFoundResponse(w, r, fmt.Errorf("email template %q not found", key))
+		return
+	}
+
+	versions, err := app.Store.EmailTemplates.ListVersions(r.Context(), key)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if versions == nil {
+		versions = []models.EmailTemplate{}
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": versions})
+}
+
+// adminActivateEmailTemplateVersionHandler rolls a template back to an
+// earlier saved version.
+func (app *Application) adminActivateEmailTemplateVersionHandler(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "id")
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	t, err := app.Store.EmailTemplates.Activate(r.Context(), key, version)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": t})
+}
+
+type previewEmailTemplatePayload struct {
+	EventID *uuid.UUID `json:"event_id"`
+	Subject *string    `json:"subject"`
+	Body    *string    `json:"body"`
+}
+
+// adminPreviewEmailTemplateHandler renders a template against an event, or a
+// sample event when none is given. A subject and body in the payload preview
+// an unsaved draft; otherwise the version mails are currently sent with is
+// rendered.
+func (app *Application) adminPreviewEmailTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "id")
+	info, ok := mailer.LookupTemplate(key)
+	if !ok {
+		app.notFoundResponse(w, r, fmt.Errorf("email template %q not found", key))
+		return
+	}
+
+	// The payload is optional: an empty body previews the active version
+	// against the sample event.
+	var payload previewEmailTemplatePayload
+	if err := readJson(w, r, &payload); err != nil && !errors.Is(err, io.EOF) {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	subject, body := payload.Subject, payload.Body
+	if subject == nil || body == nil {
+		view, err := app.activeEmailTemplate(ctx, info)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if subject == nil {
+			subject = &view.Subject
+		}
+		if body == nil {
+			body = &view.Body
+		}
+	}
+
+	tmpl, err := mailer.Compile(*subject, *body)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	event, userName := sampleEmailEvent(), "Cliente Demo"
+	if payload.EventID != nil {
+		event, err = app.Store.Events.GetByID(ctx, *payload.EventID)
+		if err != nil {
+			app.handleError(w, r, err)
+			return
+		}
+		if owner, err := app.Store.Users.RetrieveById(ctx, event.UserID); err == nil {
+			userName = owner.FirstName
+		}
+	}
+
+	renderedSubject, renderedBody, err := mailer.Render(tmpl, app.emailTemplateData(event, userName))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]string{
+		"subject": renderedSubject,
+		"html":    renderedBody,
+	}})
+}
+
+func (app *Application) activeEmailTemplate(ctx context.Context, info mailer.TemplateInfo) (emailTemplateView, error) {
+	active := map[string]models.EmailTemplate{}
+	t, err := app.Store.EmailTemplates.GetActive(ctx, mailer.TemplateKey(info.File))
+	switch {
+	case err == nil:
+		active[t.Key] = *t
+	case !errors.Is(err, store.ErrNotFound):
+		return emailTemplateView{}, err
+	}
+	return newEmailTemplateView(info, active)
+}
+
+// sampleEmailEvent is the event templates are previewed and tested with when
+// the admin doesn't pick a real one.
+func sampleEmailEvent() *models.Event {
+	date := time.Now().AddDate(0, 0, 7)
+	return &models.Event{
+		ID:       uuid.New(),
+		Name:     "Boda de Ana y Luis",
+		Date:     &date,
+		Location: "Santo Domingo",
+	}
+}
+
+// emailTemplateData holds every variable the templates use, so any of them
+// can be rendered against the same event.
+func (app *Application) emailTemplateData(event *models.Event, userName string) map[string]any {
+	data := map[string]any{
+		"UserName":      userName,
+		"Username":      userName,
+		"EventName":     event.Name,
+		"EventDate":     "",
+		"EventTime":     "",
+		"EventLocation": event.Location,
+		"ReviewURL":     app.Config.FrontendURL + "/reviews",
+		"ChecklistURL":  fmt.Sprintf("%s/event/%s/checklist", app.Config.FrontendURL, event.ID),
+		"ActivationURL": app.Config.FrontendURL + "/confirm/sample-token",
+		"ResetURL":      app.Config.FrontendURL + "/reset-password/sample-token",
+	}
+	if event.Date != nil {
+		data["EventDate"] = event.Date.Format("02 Jan 2006")
+		data["EventTime"] = event.Date.Format("15:04")
+	}
+	return data
 }
 
 func (app *Application) adminSendTestEmailHandler(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		TemplateID string `json:"template_id"`
+		TemplateID string     `json:"template_id" validate:"required"`
+		EventID    *uuid.UUID `json:"event_id"`
 	}
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
 		return
 	}
-	_ = payload.TemplateID
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	info, ok := mailer.LookupTemplate(payload.TemplateID)
+	if !ok {
+		app.notFoundResponse(w, r, fmt.Errorf("email template %q not found", payload.TemplateID))
+		return
+	}
+
+	event := sampleEmailEvent()
+	if payload.EventID != nil {
+		var err error
+		event, err = app.Store.Events.GetByID(r.Context(), *payload.EventID)
+		if err != nil {
+			app.handleError(w, r, err)
+			return
+		}
+	}
+
+	// The test goes to the admin who asked for it, through the same Send
+	// path real mails take.
+	adminUser := GetUserFromCtx(r)
+	isProdEnv := app.Config.Env == "production"
+	data := app.emailTemplateData(event, adminUser.FirstName)
+	if _, err := app.Mailer.Send(info.File, adminUser.UserName, adminUser.Email, data, !isProdEnv); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "Test email sent"})
 }
 
@@ -1165,79 +1640,340 @@ func (app *Application) adminUpdateWhatsAppTemplateHandler(w http.ResponseWriter
 }
 
 func (app *Application) adminGetNotificationTriggersHandler(w http.ResponseWriter, r *http.Request) {
-	triggers := []map[string]interface{}{
-		{"id": "1", "name": "Recordatorio 7 dias", "enabled": true, "type": "email"},
-		{"id": "2", "name": "Recordatorio 24h", "enabled": true, "type": "email"},
-		{"id": "3", "name": "Confirmacion de contrato", "enabled": true, "type": "whatsapp"},
+	triggers, err := app.Store.NotificationTriggers.GetAll(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if triggers == nil {
+		triggers = []models.NotificationTrigger{}
 	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": triggers})
 }
 
+func (app *Application) adminCreateNotificationTriggerHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.CreateNotificationTriggerPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	trigger := &models.NotificationTrigger{
+		Name:       payload.Name,
+		EventType:  payload.EventType,
+		Channel:    payload.Channel,
+		TemplateID: payload.TemplateID,
+		DaysOffset: payload.DaysOffset,
+		Enabled:    true,
+	}
+	if payload.Enabled != nil {
+		trigger.Enabled = *payload.Enabled
+	}
+	if err := validateNotificationTrigger(trigger); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	if err := app.Store.NotificationTriggers.Create(r.Context(), trigger); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusCreated, map[string]interface{}{"data": trigger})
+}
+
 func (app *Application) adminUpdateNotificationTriggerHandler(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-	_ = idParam
-	var payload struct {
-		Enabled bool `json:"enabled"`
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
 	}
+
+	var payload models.UpdateNotificationTriggerPayload
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
 		return
 	}
-	_ = payload.Enabled
-	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "ok"})
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	trigger, err := app.Store.NotificationTriggers.GetByID(r.Context(), id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	if payload.Name != nil {
+		trigger.Name = *payload.Name
+	}
+	if payload.EventType != nil {
+		trigger.EventType = *payload.EventType
+	}
+	if payload.Channel != nil {
+		trigger.Channel = *payload.Channel
+	}
+	if payload.TemplateID != nil {
+		trigger.TemplateID = *payload.TemplateID
+	}
+	if payload.DaysOffset != nil {
+		trigger.DaysOffset = *payload.DaysOffset
+	}
+	if payload.Enabled != nil {
+		trigger.Enabled = *payload.Enabled
+	}
+	if err := validateNotificationTrigger(trigger); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	if err := app.Store.NotificationTriggers.Update(r.Context(), trigger); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": trigger})
+}
+
+func (app *Application) adminDeleteNotificationTriggerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	if err := app.Store.NotificationTriggers.Delete(r.Context(), id); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateNotificationTrigger checks that the template a trigger names
+// exists for its channel. WhatsApp templates live in Meta's dashboard, so
+// their names can't be checked here.
+func validateNotificationTrigger(t *models.NotificationTrigger) error {
+	switch t.Channel {
+	case models.ChannelEmail:
+		if _, ok := mailer.LookupTemplate(t.TemplateID); !ok {
+			return fmt.Errorf("unknown email template %q", t.TemplateID)
+		}
+	case models.ChannelPush:
+		if _, ok := worker.PushMessages[t.TemplateID]; !ok {
+			return fmt.Errorf("unknown push message %q", t.TemplateID)
+		}
+	}
+	// The scheduler only sees installments due within the next week.
+	if t.EventType == models.TriggerInstallmentDue && t.DaysOffset > 7 {
+		return errors.New("installment_due triggers fire at most 7 days before the due date")
+	}
+	return nil
 }
 
 // ============================================================
 // Analytics
 // ============================================================
 
+// analyticsMaxPeriods caps how many buckets a series may have, e.g. a
+// little over a year of days.
+const analyticsMaxPeriods = 400
+
+// parseAnalyticsFilter reads the dashboard filters from the query string:
+// from and to as inclusive YYYY-MM-DD dates, granularity (day, week or
+// month) and event_type_id. Series need a closed range, so when series is
+// set a missing range defaults to the last twelve months.
+func parseAnalyticsFilter(r *http.Request, series bool) (models.AnalyticsFilter, error) {
+	q := r.URL.Query()
+	f := models.AnalyticsFilter{Granularity: q.Get("granularity")}
+
+	switch f.Granularity {
+	case "":
+		f.Granularity = models.GranularityMonth
+	case models.GranularityDay, models.GranularityWeek, models.GranularityMonth:
+	default:
+		return f, errors.New("granularity must be day, week or month")
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return f, fmt.Errorf("invalid from date: %w", err)
+		}
+		f.From = from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return f, fmt.Errorf("invalid to date: %w", err)
+		}
+		f.To = to.AddDate(0, 0, 1)
+	}
+	if v := q.Get("event_type_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid event_type_id: %w", err)
+		}
+		f.EventTypeID = &id
+	}
+
+	if series {
+		if f.To.IsZero() {
+			now := time.Now().UTC()
+			f.To = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		}
+		if f.From.IsZero() {
+			f.From = time.Date(f.To.Year(), f.To.Month()-11, 1, 0, 0, 0, 0, time.UTC)
+		}
+		days := f.To.Sub(f.From).Hours() / 24
+		periods := days
+		switch f.Granularity {
+		case models.GranularityWeek:
+			periods = days / 7
+		case models.GranularityMonth:
+			periods = days / 28
+		}
+		if periods > analyticsMaxPeriods {
+			return f, fmt.Errorf("range is too long for %s granularity", f.Granularity)
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return f, errors.New("from must not be after to")
+	}
+	return f, nil
+}
+
+// adminMonthlyStatsHandler returns how many events are held per period and
+// the quoted revenue of those that were booked. Despite the route's name,
+// the period follows the granularity parameter.
 func (app *Application) adminMonthlyStatsHandler(w http.ResponseWriter, r *http.Request) {
-	stats := []map[string]interface{}{
-		{"month": "Ene", "events": 12, "revenue": 450000},
-		{"month": "Feb", "events": 8, "revenue": 320000},
-		{"month": "Mar", "events": 15, "revenue": 580000},
+	filter, err := parseAnalyticsFilter(r, true)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	stats, err := app.Store.Analytics.EventVolume(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": stats})
 }
 
+// adminRevenueChartHandler returns the money collected per period.
 func (app *Application) adminRevenueChartHandler(w http.ResponseWriter, r *http.Request) {
-	data := []map[string]interface{}{
-		{"month": "Ene", "revenue": 450000},
-		{"month": "Feb", "revenue": 320000},
-		{"month": "Mar", "revenue": 580000},
-		{"month": "Abr", "revenue": 720000},
-		{"month": "May", "revenue": 610000},
-		{"month": "Jun", "revenue": 890000},
+	filter, err := parseAnalyticsFilter(r, true)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	data, err := app.Store.Analytics.Revenue(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": data})
 }
 
 func (app *Application) adminTopProductsHandler(w http.ResponseWriter, r *http.Request) {
-	products := []map[string]interface{}{
-		{"id": uuid.New(), "name": "Sillas de madera", "rentals": 45},
-		{"id": uuid.New(), "name": "Manteles premium", "rentals": 38},
-		{"id": uuid.New(), "name": "Arco floral", "rentals": 32},
+	filter, err := parseAnalyticsFilter(r, false)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	products, err := app.Store.Analytics.TopArticles(r.Context(), filter, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": products})
 }
 
 func (app *Application) adminConversionRateHandler(w http.ResponseWriter, r *http.Request) {
-	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"data": map[string]interface{}{
-			"rate": 0.68,
-			"quotes_sent": 50,
-			"approved":    34,
-		},
-	})
+	filter, err := parseAnalyticsFilter(r, false)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	stats, err := app.Store.Analytics.Conversion(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": stats})
 }
 
+// adminPendingPaymentsHandler lists unpaid installments. Without a range it
+// lists all of them.
 func (app *Application) adminPendingPaymentsHandler(w http.ResponseWriter, r *http.Request) {
-	pending := []map[string]interface{}{
-		{"event_id": uuid.New(), "client": "Maria Garcia", "amount": 25000, "due_date": "2026-04-20"},
+	filter, err := parseAnalyticsFilter(r, false)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	pending, err := app.Store.Analytics.PendingPayments(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": pending})
 }
 
+// adminEventProfitsHandler ranks booked events by margin, best first, or
+// worst first with order=asc.
+func (app *Application) adminEventProfitsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r, false)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	order := r.URL.Query().Get("order")
+	if order != "" && order != "asc" && order != "desc" {
+		app.badRequest(w, r, errors.New("order must be asc or desc"))
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	profits, err := app.Store.Analytics.EventProfits(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if order == "asc" {
+		slices.Reverse(profits)
+	}
+	if limit > 0 && limit < len(profits) {
+		profits = profits[:limit]
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": profits})
+}
+
+func (app *Application) adminEventProfitHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	profit, err := app.Store.Analytics.EventProfit(r.Context(), id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": profit})
+}
+
 func (app *Application) adminExportHandler(w http.ResponseWriter, r *http.Request) {
 	exportType := chi.URLParam(r, "type")
 	format := r.URL.Query().Get("format")
@@ -1394,11 +2130,118 @@ func generateXlsx(w http.ResponseWriter, records [][]string) {
 	csvWriter.Flush()
 }
 
+// adminReportPDFHandler renders the business report for a month (?month=
+// YYYY-MM, the current month by default) or for an explicit from/to range.
+// Short ranges are charted by week, longer ones by month.
 func (app *Application) adminReportPDFHandler(w http.ResponseWriter, r *http.Request) {
-	pdfData := []byte("%PDF-1.4\nFake PDF for demo purposes\n")
+	filter, err := parseAnalyticsFilter(r, false)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	name := "reporte"
+	if filter.From.IsZero() || filter.To.IsZero() {
+		if !filter.From.IsZero() || !filter.To.IsZero() {
+			app.badRequest(w, r, errors.New("from and to must be given together"))
+			return
+		}
+		month := time.Now().UTC()
+		if v := r.URL.Query().Get("month"); v != "" {
+			if month, err = time.Parse("2006-01", v); err != nil {
+				app.badRequest(w, r, fmt.Errorf("invalid month: %w", err))
+				return
+			}
+		}
+		filter.From = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+		filter.To = filter.From.AddDate(0, 1, 0)
+		name += "-" + filter.From.Format("2006-01")
+	} else {
+		name += "-" + filter.From.Format("2006-01-02") + "-" + filter.To.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	filter.Granularity = models.GranularityMonth
+	if filter.To.Sub(filter.From) <= 62*24*time.Hour {
+		filter.Granularity = models.GranularityWeek
+	}
+
+	data, err := app.reportData(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	pdfBytes, err := pdf.GenerateReportPDF(*data)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", "inline; filename=report.pdf")
-	w.Write(pdfData)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%s.pdf", name))
+	w.Write(pdfBytes)
+}
+
+// reportData gathers the business report for the filter's range.
+func (app *Application) reportData(ctx context.Context, filter models.AnalyticsFilter) (*pdf.ReportData, error) {
+	// The financial store takes inclusive date strings.
+	start, end := filter.From.Format("2006-01-02"), filter.To.AddDate(0, 0, -1).Format("2006-01-02")
+
+	data := &pdf.ReportData{From: filter.From, To: filter.To, GeneratedAt: time.Now()}
+
+	totals, err := app.Store.Financial.GetTotalsByPeriod(ctx, filter.Granularity, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range totals {
+		label := t.Period.Format("01/2006")
+		if filter.Granularity == models.GranularityWeek {
+			label = t.Period.Format("02/01")
+		}
+		data.Periods = append(data.Periods, pdf.ReportPeriod{Label: label, Income: t.Income, Expenses: t.Expenses})
+	}
+	if data.IncomeByCategory, err = app.Store.Financial.GetIncomeByCategory(ctx, start, end); err != nil {
+		return nil, err
+	}
+	if data.ExpensesByCategory, err = app.Store.Financial.GetExpensesByCategory(ctx, start, end); err != nil {
+		return nil, err
+	}
+
+	articles, err := app.Store.Analytics.TopArticles(ctx, filter, 10)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range articles {
+		data.TopArticles = append(data.TopArticles, pdf.ReportArticle{Name: a.Name, Rentals: a.Rentals, Events: a.Events, Revenue: a.Revenue})
+	}
+
+	funnel, err := app.Store.Analytics.LeadFunnel(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	data.LeadsTotal, data.LeadsLost = funnel.Total, funnel.Lost
+	for _, s := range funnel.Stages {
+		data.Funnel = append(data.Funnel, pdf.ReportFunnelStage{Stage: s.Stage, Count: s.Count})
+	}
+
+	// Everything still owed at the end of the period, however old.
+	pending, err := app.Store.Analytics.PendingPayments(ctx, models.AnalyticsFilter{To: filter.To, EventTypeID: filter.EventTypeID})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pending {
+		data.Outstanding = append(data.Outstanding, pdf.ReportInstallment{
+			EventName: p.EventName, Client: p.Client, Amount: float64(p.Amount), DueDate: p.DueDate, Overdue: p.Overdue,
+		})
+	}
+
+	maintenance, err := app.Store.Analytics.MaintenanceCosts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range maintenance {
+		data.Maintenance = append(data.Maintenance, pdf.ReportMaintenance{Type: m.Type, Count: m.Count, Total: m.Total})
+	}
+
+	return data, nil
 }
 
 // ============================================================
@@ -1414,37 +2257,61 @@ func (app *Application) adminGetDeliveryZonesHandler(w http.ResponseWriter, r *h
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": zones})
 }
 
+// adminUpdateDeliveryZonesHandler replaces the delivery zones with the
+// given list. Zones with an id are updated, the others are created, and
+// zones missing from the list are deleted.
 func (app *Application) adminUpdateDeliveryZonesHandler(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		Zones []map[string]interface{} `json:"zones"`
+		Zones []models.DeliveryZonePayload `json:"zones" validate:"required,dive"`
 	}
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
 		return
 	}
-	_ = payload.Zones
-	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "ok"})
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	zones, err := app.Store.DeliveryZones.ReplaceAll(r.Context(), payload.Zones)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": zones})
 }
 
 func (app *Application) adminGetPaymentMethodsHandler(w http.ResponseWriter, r *http.Request) {
-	methods := []map[string]interface{}{
-		{"id": "1", "name": "Transferencia bancaria", "enabled": true},
-		{"id": "2", "name": "Efectivo", "enabled": true},
-		{"id": "3", "name": "Tarjeta (mock)", "enabled": false},
+	methods, err := app.Store.PaymentMethods.List(r.Context(), false)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": methods})
 }
 
+// adminUpdatePaymentMethodsHandler replaces the payment method settings.
+// Methods left out of the list are disabled rather than deleted, since past
+// payments still refer to them.
 func (app *Application) adminUpdatePaymentMethodsHandler(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		Methods []map[string]interface{} `json:"methods"`
+		Methods []models.PaymentMethodPayload `json:"methods" validate:"required,dive"`
 	}
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
 		return
 	}
-	_ = payload.Methods
-	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": "ok"})
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	methods, err := app.Store.PaymentMethods.ReplaceAll(r.Context(), payload.Methods)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": methods})
 }
 
 // ============================================================
@@ -1687,6 +2554,7 @@ func (app *Application) adminQuoteHistoryHandler(w http.ResponseWriter, r *http.
 				"action":     l.Action,
 				"old_value":  l.OldValue,
 				"new_value":  l.NewValue,
+				"reason":     l.Reason,
 				"admin_name": l.UserName,
 				"created_at": l.CreatedAt,
 			})
@@ -1758,10 +2626,64 @@ func (app *Application) adminRedisHealthHandler(w http.ResponseWriter, r *http.R
 	})
 }
 
+// ============================================================
+// Background Jobs
+// ============================================================
+
+// adminListJobsHandler lists jobs by status, dead jobs by default, so failed
+// background work can be inspected and retried.
+func (app *Application) adminListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.JobStatusDead
+	}
+	switch status {
+	case models.JobStatusPending, models.JobStatusRunning, models.JobStatusSucceeded, models.JobStatusDead:
+	default:
+		app.badRequest(w, r, fmt.Errorf("unknown job status %q", status))
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	jobs, err := app.Store.Jobs.List(r.Context(), status, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []models.Job{}
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": jobs})
+}
+
+func (app *Application) adminRetryJobHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	job, err := app.Store.Jobs.Retry(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("no dead job with that id"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": job})
+}
+
 // ============================================================
 // Force Logout
 // ============================================================
 
+// adminForceLogoutHandler signs a user out everywhere: their refresh
+// tokens are deleted and their access tokens stop working immediately.
 func (app *Application) adminForceLogoutHandler(w http.ResponseWriter, r *http.Request) {
 	userIDParam := chi.URLParam(r, "id")
 	userID, err := uuid.Parse(userIDParam)
@@ -1769,10 +2691,29 @@ func (app *Application) adminForceLogoutHandler(w http.ResponseWriter, r *http.R
 		app.badRequest(w, r, err)
 		return
 	}
-	if err := app.Store.RefreshTokens.DeleteAllForUser(r.Context(), userID); err != nil {
+	reason, ok := app.readAdminReason(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	if err := app.Store.Users.RevokeTokens(ctx, userID); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if err := app.evictUser(ctx, userID); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+
+	admin := GetUserFromCtx(r)
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionUserLogout,
+		EntityType: "user",
+		EntityID:   &userID,
+		Reason:     reason,
+	})
 	writeJson(w, http.StatusOK, map[string]interface{}{"message": "ok"})
 }
 
@@ -1970,6 +2911,7 @@ func (app *Application) adminCreateMaintenanceLogHandler(w http.ResponseWriter,
 	var payload struct {
 		ArticleID          string    `json:"article_id"`
 		VariantID          string    `json:"variant_id"`
+		EventID            string    `json:"event_id"`
 		MaintenanceType    string    `json:"maintenance_type"`
 		Description        string    `json:"description"`
 		PerformedBy        string    `json:"performed_by"`
@@ -1997,6 +2939,14 @@ func (app *Application) adminCreateMaintenanceLogHandler(w http.ResponseWriter,
 		variantID, _ := uuid.Parse(payload.VariantID)
 		log.VariantID = &variantID
 	}
+	if payload.EventID != "" {
+		eventID, err := uuid.Parse(payload.EventID)
+		if err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+		log.EventID = &eventID
+	}
 
 	if err := app.Store.MaintenanceLogs.Create(r.Context(), log); err != nil {
 		app.internalServerError(w, r, err)
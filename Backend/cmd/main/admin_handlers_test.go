@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/store"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseAnalyticsFilter(t *testing.T) {
+	parse := func(query string, series bool) (models.AnalyticsFilter, error) {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/admin/analytics/revenue?"+query, nil)
+		return parseAnalyticsFilter(req, series)
+	}
+
+	t.Run("to is inclusive", func(t *testing.T) {
+		f, err := parse("from=2026-01-01&to=2026-03-31&granularity=week", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !f.From.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !f.To.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected range %v - %v", f.From, f.To)
+		}
+		if f.Granularity != models.GranularityWeek {
+			t.Errorf("expected week granularity, got %s", f.Granularity)
+		}
+	})
+
+	t.Run("series default to the last twelve months", func(t *testing.T) {
+		f, err := parse("", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Granularity != models.GranularityMonth || f.From.Day() != 1 || f.To.Sub(f.From) < 330*24*time.Hour {
+			t.Errorf("unexpected default filter %+v", f)
+		}
+	})
+
+	t.Run("other endpoints keep the range open", func(t *testing.T) {
+		f, err := parse("event_type_id=6f1c2b1e-8a5e-4a43-9f7e-2f4d1c9e0b11", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !f.From.IsZero() || !f.To.IsZero() || f.EventTypeID == nil {
+			t.Errorf("unexpected filter %+v", f)
+		}
+	})
+
+	for _, query := range []string{
+		"granularity=year",
+		"from=01/02/2026",
+		"event_type_id=abc",
+		"from=2026-03-01&to=2026-01-01",
+		"from=2020-01-01&to=2026-01-01&granularity=day",
+	} {
+		if _, err := parse(query, true); err == nil {
+			t.Errorf("expected %q to be rejected", query)
+		}
+	}
+}
+
+func TestEventProfitHandlers(t *testing.T) {
+	adminID := uuid.New()
+	best, middle, worst := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name         string
+		url          string
+		setupMocks   func(*Application)
+		assertBody   func(*testing.T, []byte)
+		expectedCode int
+	}{
+		{
+			name: "should rank events worst first",
+			url:  "/v1/admin/analytics/event-profit?from=2026-01-01&order=asc&limit=2",
+			setupMocks: func(app *Application) {
+				app.Store.Analytics.(*storeMocks.AnalyticsStore).On("EventProfits", mock.Anything, mock.MatchedBy(func(f models.AnalyticsFilter) bool {
+					return f.From.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+				})).Return([]models.EventProfit{
+					{EventID: best, Margin: 0.6}, {EventID: middle, Margin: 0.3}, {EventID: worst, Margin: -0.1},
+				}, nil).Once()
+			},
+			assertBody: func(t *testing.T, body []byte) {
+				var resp struct {
+					Data struct {
+						Data []models.EventProfit `json:"data"`
+					} `json:"data"`
+				}
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatal(err)
+				}
+				if got := resp.Data.Data; len(got) != 2 || got[0].EventID != worst || got[1].EventID != middle {
+					t.Errorf("unexpected ranking %+v", got)
+				}
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should reject an unknown order",
+			url:          "/v1/admin/analytics/event-profit?order=margin",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return 404 for an unknown event",
+			url:  "/v1/admin/events/" + uuid.NewString() + "/profit",
+			setupMocks: func(app *Application) {
+				app.Store.Analytics.(*storeMocks.AnalyticsStore).On("EventProfit", mock.Anything, mock.Anything).Return(nil, store.ErrNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateAdmin(app, adminID)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.assertBody != nil {
+				tc.assertBody(t, rr.Body.Bytes())
+			}
+			if tc.setupMocks == nil {
+				app.Store.Analytics.(*storeMocks.AnalyticsStore).AssertNotCalled(t, "EventProfits", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"Backend/docs"
+	"Backend/internal/store/models"
 
 	"github.com/go-chi/cors"
 
@@ -45,6 +46,8 @@ func (app *Application) Mount() http.Handler {
 
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	r.Get("/.well-known/jwks.json", app.jwksHandler)
+
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/health", app.healthCheckHandler)
 		r.With(app.BasicAuthMiddleware()).Get("/debug/vars", expvar.Handler().ServeHTTP)
@@ -59,8 +62,8 @@ func (app *Application) Mount() http.Handler {
 				r.Use(app.postsContextMiddleware)
 				r.Get("/", app.getPostHandler)
 				r.Post("/{postId}/comments", app.createPostCommentHandler)
-				r.Put("/", app.CheckPostOwnerShip("moderator", app.updatePostHandler))
-				r.Delete("/", app.CheckPostOwnerShip("admin", app.deletePostHandler))
+				r.Put("/", app.CheckPostOwnerShip(models.PermContentModerate, app.updatePostHandler))
+				r.Delete("/", app.CheckPostOwnerShip(models.PermContentModerate, app.deletePostHandler))
 			})
 		})
 
@@ -75,7 +78,7 @@ func (app *Application) Mount() http.Handler {
 				r.Get("/", app.getArticleHandler)
 
 				r.Group(func(r chi.Router) {
-					r.Use(app.RoleMiddleware("moderator"))
+					r.Use(app.RequirePermission(models.PermInventoryWrite))
 					r.Put("/", app.updateArticleHandler)
 					r.Delete("/", app.deleteArticleHandler)
 				})
@@ -107,10 +110,10 @@ func (app *Application) Mount() http.Handler {
 			// Protected/Admin endpoints (JWT)
 			r.Group(func(r chi.Router) {
 				r.Use(app.AuthTokenMiddleware())
-				r.Post("/", app.CheckRole("moderator", app.createCategoryHandler))
+				r.With(app.RequirePermission(models.PermInventoryWrite)).Post("/", app.createCategoryHandler)
 
-				r.With(app.categoriesContextMiddleware, app.RoleMiddleware("moderator")).Put("/{categoryId}", app.updateCategoryHandler)
-				r.With(app.categoriesContextMiddleware, app.RoleMiddleware("moderator")).Delete("/{categoryId}", app.deleteCategoryHandler)
+				r.With(app.categoriesContextMiddleware, app.RequirePermission(models.PermInventoryWrite)).Put("/{categoryId}", app.updateCategoryHandler)
+				r.With(app.categoriesContextMiddleware, app.RequirePermission(models.PermInventoryWrite)).Delete("/{categoryId}", app.deleteCategoryHandler)
 			})
 		})
 
@@ -136,12 +139,23 @@ func (app *Application) Mount() http.Handler {
 				r.Use(app.AuthTokenMiddleware())
 				r.Get("/feed", app.getUserFeedHandler)
 				r.Put("/fcm-token", app.updateFCMTokenHandler)
+				r.Get("/sessions", app.listSessionsHandler)
+				r.Delete("/sessions/{sessionId}", app.deleteSessionHandler)
+
+				r.Route("/2fa", func(r chi.Router) {
+					r.Get("/", app.twoFactorStatusHandler)
+					r.Delete("/", app.disableTwoFactorHandler)
+					r.Post("/enroll", app.enrollTwoFactorHandler)
+					r.Post("/enable", app.enableTwoFactorHandler)
+					r.Post("/recovery-codes", app.regenerateRecoveryCodesHandler)
+				})
 			})
 		})
 
 		r.Route("/authentication", func(r chi.Router) {
 			r.Post("/register", app.registerUserHandler)
 			r.Post("/token", app.createTokenHandler)
+			r.Post("/token/2fa", app.verifyTwoFactorLoginHandler)
 			r.Post("/refresh", app.refreshTokenHandler)
 			r.Post("/forgot-password", app.forgotPasswordHandler)
 			r.Post("/reset-password", app.resetPasswordHandler)
@@ -154,6 +168,8 @@ func (app *Application) Mount() http.Handler {
 			r.Delete("/{articleId}", app.removeFavoriteHandler)
 		})
 
+		r.With(app.AuthTokenMiddleware()).Get("/payment-methods", app.listPaymentMethodsHandler)
+
 		r.Route("/events", func(r chi.Router) {
 			r.Use(app.AuthTokenMiddleware())
 			r.Post("/", app.createEventHandler)
@@ -222,16 +238,17 @@ func (app *Application) Mount() http.Handler {
 			})
 
 			r.Post("/{id}/calculate-delivery", app.calculateDeliveryHandler)
+			r.Post("/{id}/payments/{paymentId}/receipt", app.uploadPaymentReceiptHandler)
 
 			// The r.Group for admin-only adjustQuoteHandler was moved to /v1/admin
 		})
 
 		r.Route("/admin", func(r chi.Router) {
 			r.Use(app.AuthTokenMiddleware())
-			r.Use(app.RoleMiddleware("admin"))
-			r.Patch("/events/{id}/adjust", app.adjustQuoteHandler)
-			r.Get("/stats", app.getStatsHandler)
-			r.Get("/events/{id}/audit", app.getEventAuditLogHandler)
+			r.Use(app.StaffMiddleware)
+			r.With(app.RequirePermission(models.PermEventsWrite)).Patch("/events/{id}/adjust", app.adjustQuoteHandler)
+			r.With(app.RequirePermission(models.PermDashboardRead)).Get("/stats", app.getStatsHandler)
+			r.With(app.RequirePermission(models.PermAuditRead)).Get("/events/{id}/audit", app.getEventAuditLogHandler)
 		})
 
 		// Mount all admin-specific routes (new admin app endpoints)
@@ -272,6 +289,7 @@ func (app *Application) Mount() http.Handler {
 		r.Mount("/leads", app.MountLeadsRoutes())
 		r.Mount("/availability", app.MountAvailabilityRoutes())
 		r.Mount("/chatbot", app.MountChatbotRoutes())
+		r.Mount("/whatsapp", app.MountWhatsAppRoutes())
 		r.Mount("/reviews", app.MountReviewsRoutes())
 	})
 
@@ -293,6 +311,15 @@ func (app *Application) run(mux http.Handler) error {
 
 	shutdown := make(chan error)
 
+	queueCtx, stopQueue := context.WithCancel(context.Background())
+	queueDone := make(chan struct{})
+	go func() {
+		defer close(queueDone)
+		if app.Queue != nil {
+			app.Queue.Run(queueCtx)
+		}
+	}()
+
 	go func() {
 		quit := make(chan os.Signal, 1)
 
@@ -306,13 +333,25 @@ func (app *Application) run(mux http.Handler) error {
 
 		app.Logger.Infow("shutting down server", "signal", s.String())
 
-		shutdown <- srv.Shutdown(ctx)
+		// Stop claiming jobs while the server drains; jobs already running
+		// get until the shutdown deadline to finish.
+		stopQueue()
+		err := srv.Shutdown(ctx)
+
+		select {
+		case <-queueDone:
+		case <-ctx.Done():
+			app.Logger.Warn("job queue did not stop before the shutdown deadline")
+		}
+
+		shutdown <- err
 	}()
 
 	app.Logger.Infow("server has started at", " addr", app.Config.Addr, "env", app.Config.Env)
 
 	err := srv.ListenAndServe()
 	if !errors.Is(err, http.ErrServerClosed) {
+		stopQueue()
 		return err
 	}
 	err = <-shutdown
@@ -322,5 +361,5 @@ func (app *Application) run(mux http.Handler) error {
 
 	app.Logger.Info("server has stopped", "addr", app.Config.Addr, "env", app.Config.Env)
 
-	return srv.ListenAndServe()
+	return nil
 }
@@ -6,9 +6,11 @@ import (
 	"Backend/internal/cache"
 	"Backend/internal/mailer"
 	"Backend/internal/notifications"
+	"Backend/internal/paypal"
 	"Backend/internal/ratelimiter"
 	"Backend/internal/store"
 	"Backend/internal/whatsapp"
+	"Backend/internal/worker"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-redis/redis/v8"
@@ -27,6 +29,8 @@ type Application struct {
 	ChatHub       *Hub
 	R2            *store.R2Client
 	WhatsApp      *whatsapp.Client
+	PayPal        *paypal.Client
 	Mux           *chi.Mux
 	Redis         *redis.Client
+	Queue         *worker.Queue
 }
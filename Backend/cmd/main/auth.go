@@ -142,21 +142,33 @@ func (app *Application) refreshTokenHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Get refresh token from database
-	refreshToken, err := app.Store.RefreshTokens.GetByToken(r.Context(), payload.RefreshToken)
-	if err != nil {
-		app.unauthorized(w, r, errors.New("invalid refresh token"))
+	// Rotate the refresh token within its session
+	newRefreshToken := &models.RefreshToken{
+		Token:     uuid.New().String(),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+	session := requestDevice(r)
+	if err := app.Store.RefreshTokens.Rotate(r.Context(), payload.RefreshToken, newRefreshToken, session); err != nil {
+		switch {
+		case errors.Is(err, store.ErrRefreshTokenReused):
+			app.Logger.Warnw("refresh token reused, session revoked", "user", newRefreshToken.UserID, "session", newRefreshToken.SessionID, "ip", session.IP)
+			app.unauthorized(w, r, errors.New("invalid refresh token"))
+		case errors.Is(err, store.ErrNotFound):
+			app.unauthorized(w, r, errors.New("invalid refresh token"))
+		default:
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 
-	user, err := app.Store.Users.RetrieveById(r.Context(), refreshToken.UserID)
+	user, err := app.Store.Users.RetrieveById(r.Context(), newRefreshToken.UserID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.Store.RefreshTokens.Delete(r.Context(), refreshToken.Token); err != nil {
-		app.internalServerError(w, r, err)
+	if user.Blocked(time.Now()) {
+		app.forbidden(w, r, errAccountBlocked)
 		return
 	}
 
@@ -168,6 +180,8 @@ func (app *Application) refreshTokenHandler(w http.ResponseWriter, r *http.Reque
 		"iss": app.Config.Auth.Token.Iss,
 		"nbf": time.Now().Unix(),
 		"aud": app.Config.Auth.Token.Aud,
+		"ver": user.TokenVersion,
+		"sid": session.ID,
 	}
 
 	accessToken, err := app.Auth.GenerateToken(claims)
@@ -176,27 +190,11 @@ func (app *Application) refreshTokenHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Generate new refresh token
-	newRefreshTokenStr := uuid.New().String()
-	refreshTokenExpiration := time.Now().Add(30 * 24 * time.Hour) // 30 days
-
-	newRefreshToken := &models.RefreshToken{
-		UserID:    user.ID,
-		Token:     newRefreshTokenStr,
-		ExpiresAt: refreshTokenExpiration,
-	}
-
-	// Store new refresh token in database
-	if err := app.Store.RefreshTokens.Create(r.Context(), newRefreshToken); err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-
 	response := users.LoginResponse{
 		AccessToken:                    accessToken,
 		UserId:                         user.ID,
 		AccessTokenExpirationTimestamp: accessTokenExpiration.Unix(),
-		RefreshToken:                   newRefreshTokenStr,
+		RefreshToken:                   newRefreshToken.Token,
 	}
 
 	if err := app.jsonResponse(w, http.StatusOK, response); err != nil {
@@ -207,7 +205,7 @@ func (app *Application) refreshTokenHandler(w http.ResponseWriter, r *http.Reque
 // createTokenHandler godoc
 //
 //	@Summary		Create a new token
-//	@Description	Create a new token
+//	@Description	Create a new token. Users with two-factor authentication enabled get a users.TwoFactorChallengeResponse instead, to finish signing in at /authentication/token/2fa
 //	@Tags			authentication
 //	@Accept			json
 //	@Produce		json
@@ -250,6 +248,36 @@ func (app *Application) createTokenHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if user.Blocked(time.Now()) {
+		app.forbidden(w, r, errAccountBlocked)
+		return
+	}
+
+	if user.TwoFactorEnabled {
+		app.issueTwoFactorChallenge(w, r, user)
+		return
+	}
+
+	app.issueLoginTokens(w, r, user)
+}
+
+// issueLoginTokens signs the user in on the request's device.
+func (app *Application) issueLoginTokens(w http.ResponseWriter, r *http.Request, user *models.User) {
+	// Start a session on the device with its first refresh token
+	refreshTokenStr := uuid.New().String()
+	refreshToken := &models.RefreshToken{
+		UserID:    user.ID,
+		Token:     refreshTokenStr,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+	session := requestDevice(r)
+
+	// Store refresh token in database
+	if err := app.Store.RefreshTokens.Create(r.Context(), refreshToken, session); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	// Generate access token
 	accessTokenExpiration := time.Now().Add(app.Config.Auth.Token.Exp)
 	claims := jwt.MapClaims{
@@ -259,6 +287,8 @@ func (app *Application) createTokenHandler(w http.ResponseWriter, r *http.Reques
 		"iss": app.Config.Auth.Token.Iss,
 		"nbf": time.Now().Unix(),
 		"aud": app.Config.Auth.Token.Aud,
+		"ver": user.TokenVersion,
+		"sid": session.ID,
 	}
 
 	accessToken, err := app.Auth.GenerateToken(claims)
@@ -267,22 +297,6 @@ func (app *Application) createTokenHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Generate refresh token
-	refreshTokenStr := uuid.New().String()
-	refreshTokenExpiration := time.Now().Add(30 * 24 * time.Hour) // 30 days
-
-	refreshToken := &models.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshTokenStr,
-		ExpiresAt: refreshTokenExpiration,
-	}
-
-	// Store refresh token in database
-	if err := app.Store.RefreshTokens.Create(r.Context(), refreshToken); err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-
 	response := users.LoginResponse{
 		AccessToken:                    accessToken,
 		UserId:                         user.ID,
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"net/http"
 	"testing"
-	"time"
 
 	"Backend/cmd/main/configModels"
 	"Backend/cmd/main/view_models/users"
@@ -15,6 +14,7 @@ import (
 	storeMocks "Backend/internal/store/mocks"
 	"Backend/internal/store/models"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -73,22 +73,23 @@ func TestRefreshToken(t *testing.T) {
 		req, _ := http.NewRequest(http.MethodPost, "/v1/authentication/refresh", bytes.NewBuffer(body))
 
 		userID := uuid.New()
-		refreshToken := &models.RefreshToken{
-			UserID:    userID,
-			Token:     "valid-refresh-token",
-			ExpiresAt: time.Now().Add(time.Hour),
-		}
+		sessionID := uuid.New()
 
 		user := &models.User{
 			ID: userID,
 		}
 
 		// Mocks
-		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("GetByToken", mock.Anything, "valid-refresh-token").Return(refreshToken, nil).Once()
+		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Rotate", mock.Anything, "valid-refresh-token", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				next := args.Get(2).(*models.RefreshToken)
+				next.UserID, next.SessionID = userID, sessionID
+				args.Get(3).(*models.Session).ID = sessionID
+			}).Return(nil).Once()
 		app.Store.Users.(*storeMocks.UserStore).On("RetrieveById", mock.Anything, userID).Return(user, nil).Once()
-		app.Auth.(*authMocks.Authenticator).On("GenerateToken", mock.Anything).Return("new-access-token", nil).Once()
-		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Delete", mock.Anything, "valid-refresh-token").Return(nil).Once()
-		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		app.Auth.(*authMocks.Authenticator).On("GenerateToken", mock.MatchedBy(func(claims jwt.MapClaims) bool {
+			return claims["sid"] == sessionID
+		})).Return("new-access-token", nil).Once()
 
 		rr := executeRequest(req, mux)
 		checkResponseCode(t, http.StatusOK, rr)
@@ -112,10 +113,24 @@ func TestRefreshToken(t *testing.T) {
 		body, _ := json.Marshal(payload)
 		req, _ := http.NewRequest(http.MethodPost, "/v1/authentication/refresh", bytes.NewBuffer(body))
 
-		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("GetByToken", mock.Anything, "invalid-token").Return(nil, store.ErrNotFound).Once()
+		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Rotate", mock.Anything, "invalid-token", mock.Anything, mock.Anything).Return(store.ErrNotFound).Once()
+
+		rr := executeRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr)
+	})
+
+	t.Run("should reject a reused refresh token", func(t *testing.T) {
+		app := newTestApplication(t, configModels.Config{})
+		mux := app.Mount()
+
+		body, _ := json.Marshal(users.RefreshTokenRequest{RefreshToken: "rotated-token"})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/authentication/refresh", bytes.NewBuffer(body))
+
+		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Rotate", mock.Anything, "rotated-token", mock.Anything, mock.Anything).Return(store.ErrRefreshTokenReused).Once()
 
 		rr := executeRequest(req, mux)
 		checkResponseCode(t, http.StatusUnauthorized, rr)
+		app.Auth.(*authMocks.Authenticator).AssertNotCalled(t, "GenerateToken", mock.Anything)
 	})
 }
 
@@ -143,7 +158,7 @@ func TestCreateToken(t *testing.T) {
 		// Mocks
 		app.Store.Users.(*storeMocks.UserStore).On("GetByEmail", mock.Anything, "test@example.com").Return(user, nil).Once()
 		app.Auth.(*authMocks.Authenticator).On("GenerateToken", mock.Anything).Return("access-token", nil).Once()
-		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 		app.Store.Events.(*storeMocks.EventStore).On("GetPendingByUserID", mock.Anything, userID).Return([]models.Event{}, nil).Once()
 
 		rr := executeRequest(req, mux)
@@ -35,7 +35,7 @@ func TestGetEventCalendar(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				eventsM := app.Store.Events.(*storeMocks.EventStore)
 				futureDate := time.Now().Add(7 * 24 * time.Hour)
@@ -59,7 +59,7 @@ func TestGetEventCalendar(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -71,7 +71,7 @@ func TestGetEventCalendar(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				eventsM := app.Store.Events.(*storeMocks.EventStore)
 				eventsM.On("GetByID", mock.Anything, mock.Anything).Return(nil, store.ErrNotFound).Maybe()
@@ -86,7 +86,7 @@ func TestGetEventCalendar(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				eventsM := app.Store.Events.(*storeMocks.EventStore)
 				eventsM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -50,6 +50,12 @@ func (app *Application) createCategoryHandler(w http.ResponseWriter, r *http.Req
 		ImageURL:    payload.ImageURL,
 		ParentID:    payload.ParentID,
 	}
+	if payload.SetupBufferHours != nil {
+		category.SetupBufferHours = *payload.SetupBufferHours
+	}
+	if payload.CleaningBufferHours != nil {
+		category.CleaningBufferHours = *payload.CleaningBufferHours
+	}
 
 	ctx := r.Context()
 
@@ -95,6 +101,12 @@ func (app *Application) updateCategoryHandler(w http.ResponseWriter, r *http.Req
 	category.Description = payload.Description
 	category.ImageURL = payload.ImageURL
 	category.ParentID = payload.ParentID
+	if payload.SetupBufferHours != nil {
+		category.SetupBufferHours = *payload.SetupBufferHours
+	}
+	if payload.CleaningBufferHours != nil {
+		category.CleaningBufferHours = *payload.CleaningBufferHours
+	}
 	category.UpdatedBy = &user.UserName
 
 	if err := app.Store.Categories.Update(r.Context(), category); err != nil {
@@ -46,10 +46,10 @@ func TestCreateCategory(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "moderator", Level: 3}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "moderator"}}, nil).Once()
 
 				roleM := app.Store.Roles.(*storeMocks.RoleStore)
-				roleM.On("RetrieveByName", mock.Anything, "moderator").Return(&models.Role{Name: "moderator", Level: 3}, nil).Once()
+				roleM.On("Permissions", mock.Anything, mock.Anything).Return(models.PermissionNames(), nil).Once()
 
 				catM := app.Store.Categories.(*storeMocks.CategoryStore)
 				catM.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
@@ -69,10 +69,10 @@ func TestCreateCategory(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "moderator", Level: 3}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "moderator"}}, nil).Once()
 
 				roleM := app.Store.Roles.(*storeMocks.RoleStore)
-				roleM.On("RetrieveByName", mock.Anything, "moderator").Return(&models.Role{Name: "moderator", Level: 3}, nil).Maybe()
+				roleM.On("Permissions", mock.Anything, mock.Anything).Return(models.PermissionNames(), nil).Maybe()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -208,10 +208,10 @@ func TestDeleteCategory(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "moderator", Level: 3}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "moderator"}}, nil).Maybe()
 
 				roleM := app.Store.Roles.(*storeMocks.RoleStore)
-				roleM.On("RetrieveByName", mock.Anything, "moderator").Return(&models.Role{Name: "moderator", Level: 3}, nil).Maybe()
+				roleM.On("Permissions", mock.Anything, mock.Anything).Return(models.PermissionNames(), nil).Maybe()
 
 				catM := app.Store.Categories.(*storeMocks.CategoryStore)
 				catM.On("GetById", mock.Anything, categoryID).Return(&models.Category{BaseModel: models.BaseModel{ID: categoryID}}, nil).Once()
@@ -230,10 +230,10 @@ func TestDeleteCategory(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "moderator", Level: 3}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "moderator"}}, nil).Maybe()
 
 				roleM := app.Store.Roles.(*storeMocks.RoleStore)
-				roleM.On("RetrieveByName", mock.Anything, "moderator").Return(&models.Role{Name: "moderator", Level: 3}, nil).Maybe()
+				roleM.On("Permissions", mock.Anything, mock.Anything).Return(models.PermissionNames(), nil).Maybe()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
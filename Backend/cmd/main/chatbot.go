@@ -0,0 +1,471 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Backend/internal/chatbot"
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (app *Application) MountChatbotRoutes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Group(func(r chi.Router) {
+		r.Use(app.AuthTokenMiddleware())
+		r.Use(app.RequirePermission(models.PermMessagesWrite))
+		r.Post("/faqs", app.createFAQHandler)
+		r.Put("/faqs/{id}", app.updateFAQHandler)
+		r.Delete("/faqs/{id}", app.deleteFAQHandler)
+
+		r.Get("/intents", app.getChatbotIntentsHandler)
+		r.Post("/intents", app.createChatbotIntentHandler)
+		r.Put("/intents/{id}", app.updateChatbotIntentHandler)
+		r.Delete("/intents/{id}", app.deleteChatbotIntentHandler)
+
+		r.Get("/synonyms", app.getChatbotSynonymsHandler)
+		r.Put("/synonyms", app.upsertChatbotSynonymHandler)
+		r.Delete("/synonyms/{term}", app.deleteChatbotSynonymHandler)
+
+		r.Get("/conversations", app.getChatbotConversationsHandler)
+	})
+
+	r.Get("/faqs", app.getFAQsHandler)
+	r.Get("/faqs/category/{category}", app.getFAQsByCategoryHandler)
+	r.Post("/message", app.handleChatbotMessageHandler)
+	r.Patch("/conversations/{id}/feedback", app.provideChatbotFeedbackHandler)
+
+	return r
+}
+
+func (app *Application) getFAQsHandler(w http.ResponseWriter, r *http.Request) {
+	app.listFAQs(w, r, "")
+}
+
+func (app *Application) getFAQsByCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	app.listFAQs(w, r, chi.URLParam(r, "category"))
+}
+
+func (app *Application) listFAQs(w http.ResponseWriter, r *http.Request, category string) {
+	faqs, err := app.Store.Chatbot.ListFAQs(r.Context(), category, true)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if faqs == nil {
+		faqs = []models.FAQ{}
+	}
+	app.jsonResponse(w, http.StatusOK, faqs)
+}
+
+// handleChatbotMessageHandler answers a client's message and keeps the
+// conversation's state between calls. Clients send back the session_id of
+// the first response to continue the same conversation; once the bot has
+// collected the event details, a lead is created for the sales team.
+func (app *Application) handleChatbotMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.ChatbotMessagePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	key := payload.SessionID
+	if key == "" {
+		key = uuid.New().String()
+	}
+	session, err := app.Store.Chatbot.GetOrCreateSession(ctx, key, payload.Phone, strings.TrimSpace(payload.ClientName))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	bot, err := app.loadChatbot(r)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	reply := bot.Reply(session, payload.Message, time.Now())
+	if reply.LeadReady {
+		lead := &models.Lead{
+			Source:      "chatbot",
+			Status:      "new",
+			Priority:    "medium",
+			ClientName:  session.ClientName,
+			ClientPhone: session.ClientPhone,
+			EventType:   session.Slots.EventType,
+			EventDate:   session.Slots.EventDate,
+			GuestCount:  session.Slots.GuestCount,
+			Notes:       fmt.Sprintf("Chatbot session %s", session.SessionKey),
+		}
+		if err := app.Store.Leads.CreateLead(ctx, lead); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		app.Store.Leads.LogLeadActivity(ctx, lead.ID, "created", "Lead created from "+lead.Source)
+		session.LeadID = &lead.ID
+	}
+
+	turn := &models.ChatbotTurn{
+		Message:  payload.Message,
+		Response: reply.Text,
+		FAQID:    reply.FAQID,
+		IntentID: reply.IntentID,
+	}
+	if err := app.Store.Chatbot.SaveTurn(ctx, session, turn); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"id":           turn.ID,
+		"session_id":   session.SessionKey,
+		"response":     turn.Response,
+		"faq_id":       turn.FAQID,
+		"intent_id":    turn.IntentID,
+		"lead_id":      session.LeadID,
+		"state":        session.State,
+		"pending_slot": session.PendingSlot,
+	})
+}
+
+// loadChatbot builds a bot from the active intents and FAQs as they are
+// now, so edits in the admin panel apply to the next message.
+func (app *Application) loadChatbot(r *http.Request) (*chatbot.Bot, error) {
+	ctx := r.Context()
+
+	intents, err := app.Store.Chatbot.ListIntents(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	faqs, err := app.Store.Chatbot.ListFAQs(ctx, "", true)
+	if err != nil {
+		return nil, err
+	}
+	synonyms, err := app.Store.Chatbot.ListSynonyms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	eventTypes, err := app.Store.EventTypes.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(eventTypes))
+	for _, et := range eventTypes {
+		names = append(names, et.Name)
+	}
+	return chatbot.New(chatbot.Knowledge{
+		Intents:    intents,
+		FAQs:       faqs,
+		Synonyms:   synonyms,
+		EventTypes: names,
+	}), nil
+}
+
+func (app *Application) getChatbotConversationsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	turns, err := app.Store.Chatbot.ListTurns(r.Context(), r.URL.Query().Get("session_id"), limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if turns == nil {
+		turns = []models.ChatbotTurn{}
+	}
+	app.jsonResponse(w, http.StatusOK, turns)
+}
+
+func (app *Application) provideChatbotFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid conversation id"))
+		return
+	}
+
+	var payload models.ChatbotFeedbackPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	turn, err := app.Store.Chatbot.RecordFeedback(r.Context(), id, *payload.WasHelpful)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, turn)
+}
+
+func (app *Application) createFAQHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.CreateFAQPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	faq := &models.FAQ{
+		Keyword:  payload.Keyword,
+		Question: payload.Question,
+		Answer:   payload.Answer,
+		Category: payload.Category,
+		Priority: payload.Priority,
+		IsActive: payload.IsActive == nil || *payload.IsActive,
+		Language: payload.Language,
+	}
+	if faq.Category == "" {
+		faq.Category = "general"
+	}
+	if faq.Language == "" {
+		faq.Language = "es"
+	}
+
+	if err := app.Store.Chatbot.CreateFAQ(r.Context(), faq); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusCreated, faq)
+}
+
+func (app *Application) updateFAQHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid faq id"))
+		return
+	}
+
+	var payload models.UpdateFAQPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	faq, err := app.Store.Chatbot.GetFAQByID(ctx, id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	if payload.Keyword != nil {
+		faq.Keyword = *payload.Keyword
+	}
+	if payload.Question != nil {
+		faq.Question = *payload.Question
+	}
+	if payload.Answer != nil {
+		faq.Answer = *payload.Answer
+	}
+	if payload.Category != nil {
+		faq.Category = *payload.Category
+	}
+	if payload.Priority != nil {
+		faq.Priority = *payload.Priority
+	}
+	if payload.IsActive != nil {
+		faq.IsActive = *payload.IsActive
+	}
+	if payload.Language != nil {
+		faq.Language = *payload.Language
+	}
+
+	if err := app.Store.Chatbot.UpdateFAQ(ctx, faq); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, faq)
+}
+
+func (app *Application) deleteFAQHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid faq id"))
+		return
+	}
+
+	if err := app.Store.Chatbot.DeleteFAQ(r.Context(), id); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *Application) getChatbotIntentsHandler(w http.ResponseWriter, r *http.Request) {
+	intents, err := app.Store.Chatbot.ListIntents(r.Context(), false)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if intents == nil {
+		intents = []models.ChatbotIntent{}
+	}
+	app.jsonResponse(w, http.StatusOK, intents)
+}
+
+func (app *Application) createChatbotIntentHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.CreateChatbotIntentPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	intent := &models.ChatbotIntent{
+		Name:        payload.Name,
+		Description: payload.Description,
+		Phrases:     payload.Phrases,
+		Response:    payload.Response,
+		Action:      payload.Action,
+		Priority:    payload.Priority,
+		IsActive:    payload.IsActive == nil || *payload.IsActive,
+	}
+	if err := app.Store.Chatbot.CreateIntent(r.Context(), intent); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusCreated, intent)
+}
+
+func (app *Application) updateChatbotIntentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid intent id"))
+		return
+	}
+
+	var payload models.UpdateChatbotIntentPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	intent, err := app.Store.Chatbot.GetIntentByID(ctx, id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	if payload.Name != nil {
+		intent.Name = *payload.Name
+	}
+	if payload.Description != nil {
+		intent.Description = *payload.Description
+	}
+	if payload.Phrases != nil {
+		intent.Phrases = *payload.Phrases
+	}
+	if payload.Response != nil {
+		intent.Response = *payload.Response
+	}
+	if payload.Action != nil {
+		intent.Action = *payload.Action
+	}
+	if payload.Priority != nil {
+		intent.Priority = *payload.Priority
+	}
+	if payload.IsActive != nil {
+		intent.IsActive = *payload.IsActive
+	}
+
+	if err := app.Store.Chatbot.UpdateIntent(ctx, intent); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, intent)
+}
+
+func (app *Application) deleteChatbotIntentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid intent id"))
+		return
+	}
+
+	if err := app.Store.Chatbot.DeleteIntent(r.Context(), id); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *Application) getChatbotSynonymsHandler(w http.ResponseWriter, r *http.Request) {
+	synonyms, err := app.Store.Chatbot.ListSynonyms(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if synonyms == nil {
+		synonyms = []models.ChatbotSynonym{}
+	}
+	app.jsonResponse(w, http.StatusOK, synonyms)
+}
+
+func (app *Application) upsertChatbotSynonymHandler(w http.ResponseWriter, r *http.Request) {
+	var synonym models.ChatbotSynonym
+	if err := readJson(w, r, &synonym); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(synonym); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	// Terms are matched normalized, so store them that way and an edit of
+	// "Matrimonio" replaces "matrimonio" instead of adding a second row.
+	synonym.Term = chatbot.Normalize(synonym.Term)
+	if synonym.Term == "" {
+		app.badRequest(w, r, errors.New("term must contain letters or digits"))
+		return
+	}
+
+	if err := app.Store.Chatbot.UpsertSynonym(r.Context(), &synonym); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, synonym)
+}
+
+func (app *Application) deleteChatbotSynonymHandler(w http.ResponseWriter, r *http.Request) {
+	if err := app.Store.Chatbot.DeleteSynonym(r.Context(), chatbot.Normalize(chi.URLParam(r, "term"))); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
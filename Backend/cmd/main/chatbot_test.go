@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/store"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChatbotMessage(t *testing.T) {
+	leadID := uuid.New()
+
+	// setupCatalog gives the bot a pricing intent that collects a lead.
+	setupCatalog := func(app *Application) {
+		bot := app.Store.Chatbot.(*storeMocks.ChatbotStore)
+		bot.On("ListIntents", mock.Anything, true).Return([]models.ChatbotIntent{
+			{ID: uuid.New(), Name: "precio", Phrases: []string{"precio"}, Response: "Depende del evento.", Action: models.ChatbotActionCollectLead},
+		}, nil)
+		bot.On("ListFAQs", mock.Anything, "", true).Return([]models.FAQ{}, nil)
+		bot.On("ListSynonyms", mock.Anything).Return([]models.ChatbotSynonym{{Term: "costo", Canonical: "precio"}}, nil)
+		app.Store.EventTypes.(*storeMocks.EventTypesStore).On("GetAll", mock.Anything).Return([]models.EventType{{Name: "Boda"}, {Name: "Corporativo"}}, nil)
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		setupMocks   func(*Application)
+		assertMocks  func(*testing.T, *Application)
+		expectedData map[string]interface{}
+	}{
+		{
+			name: "should start collecting event details",
+			body: `{"message":"¿Cuál es el COSTO?","session_id":"abc"}`,
+			setupMocks: func(app *Application) {
+				setupCatalog(app)
+				bot := app.Store.Chatbot.(*storeMocks.ChatbotStore)
+				session := &models.ChatbotSession{ID: uuid.New(), SessionKey: "abc", State: models.ChatbotStateIdle}
+				bot.On("GetOrCreateSession", mock.Anything, "abc", "", "").Return(session, nil)
+				bot.On("SaveTurn", mock.Anything, session, mock.AnythingOfType("*models.ChatbotTurn")).Return(nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Leads.(*storeMocks.LeadsStore).AssertNotCalled(t, "CreateLead", mock.Anything, mock.Anything)
+				app.Store.Chatbot.(*storeMocks.ChatbotStore).AssertExpectations(t)
+			},
+			expectedData: map[string]interface{}{"state": models.ChatbotStateCollecting, "pending_slot": models.SlotEventType},
+		},
+		{
+			name: "should create a lead once every slot is filled",
+			body: `{"message":"una boda el 20/12/2099 para 120 personas","session_id":"abc"}`,
+			setupMocks: func(app *Application) {
+				setupCatalog(app)
+				bot := app.Store.Chatbot.(*storeMocks.ChatbotStore)
+				leads := app.Store.Leads.(*storeMocks.LeadsStore)
+				session := &models.ChatbotSession{
+					ID: uuid.New(), SessionKey: "abc", State: models.ChatbotStateCollecting, PendingSlot: models.SlotEventType,
+					ClientName: "Ana", ClientPhone: "8095551234",
+				}
+				bot.On("GetOrCreateSession", mock.Anything, "abc", "", "").Return(session, nil)
+				leads.On("CreateLead", mock.Anything, mock.MatchedBy(func(l *models.Lead) bool {
+					return l.Source == "chatbot" && l.EventType == "Boda" && l.GuestCount == 120 && l.EventDate != nil && l.ClientPhone == "8095551234"
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.Lead).ID = leadID
+				}).Return(nil)
+				leads.On("LogLeadActivity", mock.Anything, leadID, "created", "Lead created from chatbot").Return(nil)
+				bot.On("SaveTurn", mock.Anything, mock.MatchedBy(func(s *models.ChatbotSession) bool {
+					return s.LeadID != nil && *s.LeadID == leadID
+				}), mock.AnythingOfType("*models.ChatbotTurn")).Return(nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Leads.(*storeMocks.LeadsStore).AssertExpectations(t)
+				app.Store.Chatbot.(*storeMocks.ChatbotStore).AssertExpectations(t)
+			},
+			expectedData: map[string]interface{}{"state": models.ChatbotStateIdle, "lead_id": leadID.String()},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/chatbot/message", bytes.NewBufferString(tc.body))
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr)
+
+			var resp struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tc.expectedData {
+				if resp.Data[key] != want {
+					t.Errorf("expected %s %v, got %v", key, want, resp.Data[key])
+				}
+			}
+			if tc.assertMocks != nil {
+				tc.assertMocks(t, app)
+			}
+		})
+	}
+}
+
+func TestChatbotFeedback(t *testing.T) {
+	turnID := uuid.New()
+	helpful := true
+
+	tests := []struct {
+		name         string
+		turnID       string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "should record feedback on a turn",
+			turnID:       turnID.String(),
+			body:         `{"was_helpful":true}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should return 404 for an unknown turn",
+			turnID:       uuid.NewString(),
+			body:         `{"was_helpful":false}`,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "should return 400 without a rating",
+			turnID:       turnID.String(),
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			bot := app.Store.Chatbot.(*storeMocks.ChatbotStore)
+			bot.On("RecordFeedback", mock.Anything, turnID, true).Return(&models.ChatbotTurn{ID: turnID, WasHelpful: &helpful}, nil)
+			bot.On("RecordFeedback", mock.Anything, mock.Anything, mock.Anything).Return(nil, store.ErrNotFound)
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPatch, "/v1/chatbot/conversations/"+tc.turnID+"/feedback", bytes.NewBufferString(tc.body))
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+		})
+	}
+}
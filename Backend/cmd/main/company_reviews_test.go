@@ -41,7 +41,7 @@ func TestCreateCompanyReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				reviewM := app.Store.CompanyReviews.(*storeMocks.CompanyReviewsStore)
 				reviewM.On("Create", mock.Anything, mock.MatchedBy(func(r *models.CompanyReview) bool {
@@ -64,7 +64,7 @@ func TestCreateCompanyReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -82,7 +82,7 @@ func TestCreateCompanyReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -99,7 +99,7 @@ func TestCreateCompanyReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -117,7 +117,7 @@ func TestCreateCompanyReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				reviewM := app.Store.CompanyReviews.(*storeMocks.CompanyReviewsStore)
 				reviewM.On("Create", mock.Anything, mock.MatchedBy(func(r *models.CompanyReview) bool {
@@ -1,7 +1,8 @@
 package configModels
 
 type AuthConfig struct {
-	Basic  AuthBasicConfig
-	Token  TokenConfig
-	ApiKey ApiKeyConfig
+	Basic     AuthBasicConfig
+	Token     TokenConfig
+	ApiKey    ApiKeyConfig
+	TwoFactor TwoFactorConfig
 }
@@ -16,6 +16,9 @@ type Config struct {
 	R2          R2Config
 	Firebase    FirebaseConfig
 	WhatsApp    WhatsAppConfig
+	PayPal      PayPalConfig
+	Delivery    DeliveryConfig
+	Fiscal      FiscalConfig
 }
 
 type R2Config struct {
@@ -33,4 +36,27 @@ type WhatsAppConfig struct {
 	PhoneNumberID string
 	AccessToken   string
 	FromName     string
+	VerifyToken   string
+	AppSecret     string
+}
+
+type PayPalConfig struct {
+	BaseURL   string
+	ClientID  string
+	Secret    string
+	WebhookID string
+	Currency  string
+}
+
+// DeliveryConfig is where deliveries leave from, in decimal degrees.
+type DeliveryConfig struct {
+	OriginLat float64
+	OriginLng float64
+}
+
+// FiscalConfig is the business as it appears on invoices.
+type FiscalConfig struct {
+	BusinessName string
+	RNC          string
+	Address      string
 }
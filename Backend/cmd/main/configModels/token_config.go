@@ -4,7 +4,10 @@ import "time"
 
 type TokenConfig struct {
 	Secret string
-	Aud    string
-	Iss    string
-	Exp    time.Duration
+	// KeysDir holds the RS256/EdDSA signing keys. When set, tokens are
+	// signed with them instead of Secret.
+	KeysDir string
+	Aud     string
+	Iss     string
+	Exp     time.Duration
 }
@@ -0,0 +1,9 @@
+package configModels
+
+type TwoFactorConfig struct {
+	// Issuer names the app in users' authenticators.
+	Issuer string
+	// RequiredForStaff keeps users whose role grants any permission out of
+	// the admin app until they enable two-factor authentication.
+	RequiredForStaff bool
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"Backend/internal/delivery"
+	"Backend/internal/store/models"
+)
+
+var errVenueLocked = errors.New("the venue can't be moved once the quote is approved")
+
+// quoteDelivery prices delivering to the point from the configured
+// warehouse.
+func (app *Application) quoteDelivery(ctx context.Context, lat, lng float64) (*models.DeliveryFeeResponse, error) {
+	zones, err := app.Store.DeliveryZones.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	origin := delivery.Point{Lat: app.Config.Delivery.OriginLat, Lng: app.Config.Delivery.OriginLng}
+	quote := delivery.Quote(zones, delivery.DistanceKm(origin, delivery.Point{Lat: lat, Lng: lng}))
+	return &quote, nil
+}
+
+// setEventLocation moves the event's venue and reprices its delivery. Nil
+// coordinates leave the event as is. Once the client approved the quote its
+// delivery fee is settled, so the venue can no longer move.
+func (app *Application) setEventLocation(ctx context.Context, event *models.Event, lat, lng *float64) error {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	if event.Latitude != nil && event.Longitude != nil && *event.Latitude == *lat && *event.Longitude == *lng {
+		return nil
+	}
+	if event.QuoteApprovedAt != nil {
+		return errVenueLocked
+	}
+
+	quote, err := app.quoteDelivery(ctx, *lat, *lng)
+	if err != nil {
+		return err
+	}
+	event.Latitude, event.Longitude = lat, lng
+	event.DeliveryFee = quote.Fee
+	return nil
+}
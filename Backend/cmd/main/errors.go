@@ -7,6 +7,7 @@ import (
 	"runtime"
 
 	"Backend/internal/store"
+	"Backend/internal/store/models"
 )
 
 func (app *Application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
@@ -37,12 +38,73 @@ func (app *Application) conflictResponse(w http.ResponseWriter, r *http.Request,
 	writeJsonError(w, http.StatusConflict, "The requested resource could not be created due to a conflict.")
 }
 
+// insufficientStockResponse answers 409 and lists, per article, how many
+// units were requested and how many are actually left for the date.
+func (app *Application) insufficientStockResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.Logger.Warnf("insufficient stock: %s, path: %s error %s", r.Method, r.URL.Path, err.Error())
+
+	shortfalls := []store.StockShortfall{}
+	var stockErr *store.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		shortfalls = stockErr.Shortfalls
+	}
+
+	message := "Not enough stock available for the requested date."
+	if err := writeJson(w, http.StatusConflict, map[string]any{
+		"data":    shortfalls,
+		"status":  http.StatusConflict,
+		"error":   message,
+		"message": message,
+		"code":    http.StatusConflict,
+	}); err != nil {
+		app.Logger.Errorf("error writing insufficient stock response: %s", err)
+	}
+}
+
+// invalidTransitionResponse answers 409 naming the rejected status move and
+// the statuses the event, or invoice, can move to instead.
+func (app *Application) invalidTransitionResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.Logger.Warnf("invalid status transition: %s, path: %s error %s", r.Method, r.URL.Path, err.Error())
+
+	data := map[string]any{}
+	message := "The event cannot move to the requested status."
+	var transitionErr *store.InvalidTransitionError
+	if errors.As(err, &transitionErr) {
+		data["from"] = transitionErr.From
+		data["to"] = transitionErr.To
+		if transitionErr.Entity == "invoice" {
+			data["allowed"] = models.NextInvoiceStatuses(transitionErr.From)
+			message = fmt.Sprintf("An invoice in status %q cannot move to %q.", transitionErr.From, transitionErr.To)
+		} else {
+			data["allowed"] = models.NextEventStatuses(transitionErr.From)
+			message = fmt.Sprintf("An event in status %q cannot move to %q.", transitionErr.From, transitionErr.To)
+		}
+	}
+
+	if err := writeJson(w, http.StatusConflict, map[string]any{
+		"data":    data,
+		"status":  http.StatusConflict,
+		"error":   message,
+		"message": message,
+		"code":    http.StatusConflict,
+	}); err != nil {
+		app.Logger.Errorf("error writing invalid transition response: %s", err)
+	}
+}
+
 func (app *Application) handleError(w http.ResponseWriter, r *http.Request, err error) {
 	switch {
+	case errors.Is(err, store.ErrInvalidTransition):
+		app.invalidTransitionResponse(w, r, err)
+	case errors.Is(err, store.ErrInsufficientStock):
+		app.insufficientStockResponse(w, r, err)
 	case errors.Is(err, store.ErrNotFound):
 		app.notFoundResponse(w, r, err)
 	case errors.Is(err, store.ErrConflict):
 		app.conflictResponse(w, r, err)
+	case errors.Is(err, store.ErrPaymentMethodUnavailable), errors.Is(err, store.ErrInvoicePaidByInstallments),
+		errors.Is(err, store.ErrSystemRole):
+		app.badRequest(w, r, err)
 	default:
 		app.internalServerError(w, r, err)
 	}
@@ -82,3 +144,9 @@ func (app *Application) rateLimitExceededResponse(w http.ResponseWriter, r *http
 	w.Header().Set("Retry-After", retryAfter)
 	writeJsonError(w, http.StatusTooManyRequests, "Rate limit exceeded")
 }
+
+func (app *Application) serviceUnavailableResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.Logger.Warnf("service unavailable: %s, path: %s error %q", r.Method, r.URL.Path, err.Error())
+
+	writeJsonError(w, http.StatusServiceUnavailable, "The service is temporarily unavailable.")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var errNothingToReturn = errors.New("only booked events have items to return")
+
+// adminGetEventReturnHandler returns the event's return inspection, or the
+// checklist to fill in if its items haven't been inspected yet.
+func (app *Application) adminGetEventReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	ret, err := app.Store.EventReturns.Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		if _, err := app.Store.Events.GetByID(ctx, id); err != nil {
+			app.handleError(w, r, err)
+			return
+		}
+		items, err := app.Store.Events.GetItems(ctx, id)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		ret = models.NewEventReturn(id, items)
+	} else if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": ret})
+}
+
+// adminRecordEventReturnHandler records how the event's items came back.
+// Damaged items go to maintenance, lost ones leave the inventory, and the
+// client is billed for both. An event's items are inspected once.
+func (app *Application) adminRecordEventReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	var payload models.ReturnInspectionPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	event, err := app.Store.Events.GetByID(ctx, id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	switch event.Status {
+	case models.EventStatusConfirmed, models.EventStatusPaid, models.EventStatusCompleted:
+	default:
+		app.badRequest(w, r, errNothingToReturn)
+		return
+	}
+
+	items, err := app.Store.Events.GetItems(ctx, id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	ret := models.NewEventReturn(id, items)
+	if err := ret.Apply(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	ret.InspectedBy = &GetUserFromCtx(r).ID
+
+	if err := app.Store.EventReturns.Create(ctx, ret); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusCreated, map[string]interface{}{"data": ret})
+}
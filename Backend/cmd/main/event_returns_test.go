@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/store"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecordEventReturn(t *testing.T) {
+	adminID := uuid.New()
+	eventID := uuid.New()
+	cost := 800.0
+	item := models.EventItem{ID: uuid.New(), EventID: eventID, ArticleID: uuid.New(), Quantity: 4,
+		Article: &models.Article{NameTemplate: "Copa"}, Variant: &models.ArticleVariant{ReplacementCost: &cost}}
+
+	tests := []struct {
+		name         string
+		status       string
+		body         string
+		setupMocks   func(*Application)
+		expectedCode int
+	}{
+		{
+			name:   "should bill damaged and lost items",
+			status: models.EventStatusCompleted,
+			body: `{"lines":[{"event_item_id":"` + item.ID.String() + `","condition":"damaged","quantity":1},` +
+				`{"event_item_id":"` + item.ID.String() + `","condition":"lost","quantity":2}]}`,
+			setupMocks: func(app *Application) {
+				app.Store.EventReturns.(*storeMocks.EventReturnsStore).On("Create", mock.Anything, mock.MatchedBy(func(ret *models.EventReturn) bool {
+					l := ret.Lines[0]
+					return ret.EventID == eventID && *ret.InspectedBy == adminID && ret.DamageCharge == 2400 &&
+						l.Damaged == 1 && l.Lost == 2 && l.Returned() == 1
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "should return 409 when already inspected",
+			status: models.EventStatusPaid,
+			body:   `{"lines":[]}`,
+			setupMocks: func(app *Application) {
+				app.Store.EventReturns.(*storeMocks.EventReturnsStore).On("Create", mock.Anything, mock.Anything).Return(store.ErrConflict).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "should return 400 for events that were never booked",
+			status:       models.EventStatusRequested,
+			body:         `{"lines":[]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should return 400 for more units than were rented",
+			status:       models.EventStatusCompleted,
+			body:         `{"lines":[{"event_item_id":"` + item.ID.String() + `","condition":"lost","quantity":5}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateAdmin(app, adminID)
+			evtM := app.Store.Events.(*storeMocks.EventStore)
+			evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{ID: eventID, Status: tc.status}, nil)
+			evtM.On("GetItems", mock.Anything, eventID).Return([]models.EventItem{item}, nil)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/admin/events/"+eventID.String()+"/return", bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			returnsM := app.Store.EventReturns.(*storeMocks.EventReturnsStore)
+			if tc.setupMocks != nil {
+				returnsM.AssertExpectations(t)
+			} else {
+				returnsM.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -44,7 +44,7 @@ func TestCreateEventReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -74,7 +74,7 @@ func TestCreateEventReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(nil, store.ErrNotFound).Once()
@@ -96,7 +96,7 @@ func TestCreateEventReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -121,7 +121,7 @@ func TestCreateEventReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -145,7 +145,7 @@ func TestCreateEventReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -197,7 +197,7 @@ func TestGetEventReviews(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -224,7 +224,7 @@ func TestGetEventReviews(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(nil, store.ErrNotFound).Once()
@@ -242,7 +242,7 @@ func TestGetEventReviews(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				eventM := app.Store.Events.(*storeMocks.EventStore)
 				eventM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+)
+
+// ownerEventStatuses are the statuses an event owner may set directly
+// through updateEventHandler. Every other status is reached through the
+// quote and payment flows.
+var ownerEventStatuses = map[string]bool{
+	models.EventStatusRequested: true,
+	models.EventStatusCancelled: true,
+}
+
+// checkEventTransition answers 409 and returns false when the event
+// lifecycle does not allow event to move to status.
+func (app *Application) checkEventTransition(w http.ResponseWriter, r *http.Request, event *models.Event, status string) bool {
+	if models.CanTransitionEvent(event.Status, status) {
+		return true
+	}
+	app.invalidTransitionResponse(w, r, &store.InvalidTransitionError{From: event.Status, To: status})
+	return false
+}
+
+// checkQuoteDecision answers 409 and returns false unless the event has a
+// quote adjusted by an admin for the client to approve or reject.
+func (app *Application) checkQuoteDecision(w http.ResponseWriter, r *http.Request, event *models.Event, status string) bool {
+	if event.Status != models.EventStatusAdjusted {
+		app.invalidTransitionResponse(w, r, &store.InvalidTransitionError{From: event.Status, To: status})
+		return false
+	}
+	return app.checkEventTransition(w, r, event, status)
+}
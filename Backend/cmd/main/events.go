@@ -58,13 +58,18 @@ func (app *Application) createEventHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	event := &models.Event{
-		UserID:     user.ID,
-		Name:       payload.Name,
-		Date:       &date,
-		Location:   payload.Location,
-		GuestCount: payload.GuestCount,
-		Budget:     payload.Budget,
-		Status:     "planning",
+		UserID:      user.ID,
+		Name:        payload.Name,
+		Date:        &date,
+		Location:    payload.Location,
+		GuestCount:  payload.GuestCount,
+		Budget:      payload.Budget,
+		Status:      "planning",
+		EventTypeID: payload.EventTypeID,
+	}
+	if err := app.setEventLocation(r.Context(), event, payload.Latitude, payload.Longitude); err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 
 	if err := app.Store.Events.Create(r.Context(), event); err != nil {
@@ -224,6 +229,10 @@ func (app *Application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 		}
 		event.Date = &date
 	}
+	if err := setRentalWindow(event, payload.PickupAt, payload.ReturnAt); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
 	if payload.Location != nil {
 		event.Location = *payload.Location
 	}
@@ -233,12 +242,32 @@ func (app *Application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 	if payload.Budget != nil {
 		event.Budget = *payload.Budget
 	}
-	if payload.Status != nil {
+	if payload.EventTypeID != nil {
+		event.EventTypeID = payload.EventTypeID
+	}
+	if err := app.setEventLocation(r.Context(), event, payload.Latitude, payload.Longitude); err != nil {
+		if errors.Is(err, errVenueLocked) {
+			app.conflictResponse(w, r, err)
+		} else {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if payload.Status != nil && *payload.Status != event.Status {
+		if !ownerEventStatuses[*payload.Status] {
+			app.forbidden(w, r, fmt.Errorf("status %q is set through the quote and payment flow", *payload.Status))
+			return
+		}
+		if !app.checkEventTransition(w, r, event, *payload.Status) {
+			return
+		}
 		event.Status = *payload.Status
 	}
+	event.StatusChange = &models.EventStatusChange{ActorID: user.ID, Reason: payload.StatusReason}
 
 	if err := app.Store.Events.Update(r.Context(), event); err != nil {
-		app.internalServerError(w, r, err)
+		app.handleError(w, r, err)
 		return
 	}
 
@@ -247,6 +276,22 @@ func (app *Application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// setRentalWindow gives the event its own pickup and return times. Nil times
+// leave the event as is.
+func setRentalWindow(event *models.Event, pickupAt, returnAt *time.Time) error {
+	if pickupAt == nil && returnAt == nil {
+		return nil
+	}
+	if pickupAt == nil || returnAt == nil {
+		return errors.New("pickup_at and return_at are set together")
+	}
+	if err := (store.RentalWindow{PickupAt: *pickupAt, ReturnAt: *returnAt}).Validate(); err != nil {
+		return err
+	}
+	event.PickupAt, event.ReturnAt = pickupAt, returnAt
+	return nil
+}
+
 // deleteEventHandler godoc
 //
 //	@Summary		Delete an event
@@ -315,8 +360,9 @@ func (app *Application) addEventItemHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	var payload struct {
-		ArticleID uuid.UUID `json:"article_id"`
-		Quantity  int       `json:"quantity"`
+		ArticleID uuid.UUID  `json:"article_id"`
+		VariantID *uuid.UUID `json:"variant_id"`
+		Quantity  int        `json:"quantity"`
 	}
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
@@ -342,30 +388,24 @@ func (app *Application) addEventItemHandler(w http.ResponseWriter, r *http.Reque
 	item := &models.EventItem{
 		EventID:   eventID,
 		ArticleID: payload.ArticleID,
+		VariantID: payload.VariantID,
 		Quantity:  payload.Quantity,
 	}
 	if item.Quantity <= 0 {
 		item.Quantity = 1
 	}
 
-	// Phase 17: Availability & Inventory Check
-	availability, err := app.Store.Articles.GetAvailability(r.Context(), payload.ArticleID, *event.Date)
-	if err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			app.notFoundResponse(w, r, err)
-		} else {
-			app.internalServerError(w, r, err)
-		}
-		return
-	}
-
-	if availability < item.Quantity {
-		app.badRequest(w, r, errors.New("insufficient stock for this date"))
-		return
+	// Undated drafts have nothing to reserve against. Otherwise the store
+	// adds the line and re-syncs the event's holds for its rental window in
+	// one transaction, so requested and confirmed events never carry items
+	// without stock.
+	if event.Date == nil {
+		err = app.Store.Events.AddItem(r.Context(), item)
+	} else {
+		err = app.Store.Events.AddItemWithReservation(r.Context(), item)
 	}
-
-	if err := app.Store.Events.AddItem(r.Context(), item); err != nil {
-		app.internalServerError(w, r, err)
+	if err != nil {
+		app.handleError(w, r, err)
 		return
 	}
 
@@ -483,14 +523,15 @@ func (app *Application) getEventItemsHandler(w http.ResponseWriter, r *http.Requ
 
 // payEventHandler godoc
 //
-//	@Summary		Simulate event payment
-//	@Description	Simulate event payment and update status to 'paid'
+//	@Summary		Pay for an event
+//	@Description	Pay the deposit, the pending installments or the whole quote. Methods that need manual confirmation return 202 until an admin confirms the payment.
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
 //	@Param			id		path		string	true	"Event ID"
 //	@Param			payload	body		object	true	"Payment payload"
 //	@Success		200		{object}	models.Event
+//	@Success		202		{array}		models.InstallmentPayment
 //	@Failure		400		{object}	error
 //	@Failure		404		{object}	error
 //	@Failure		500		{object}	error
@@ -507,6 +548,8 @@ func (app *Application) payEventHandler(w http.ResponseWriter, r *http.Request)
 		PaymentMethod string `json:"payment_method" validate:"required"`
 		Phone        string `json:"phone"`
 		IsDeposit    bool   `json:"is_deposit"`
+		// Pays a single installment of the plan instead.
+		InstallmentID *uuid.UUID `json:"installment_id"`
 	}
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
@@ -535,8 +578,10 @@ func (app *Application) payEventHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if event.Status != "confirmed" {
-		app.badRequest(w, r, errors.New("only confirmed events can be paid"))
+	// Only confirmed events take payments; a full payment then moves the
+	// event to paid while a deposit leaves it confirmed.
+	if event.Status != models.EventStatusConfirmed {
+		app.invalidTransitionResponse(w, r, &store.InvalidTransitionError{From: event.Status, To: models.EventStatusPaid})
 		return
 	}
 
@@ -548,75 +593,52 @@ func (app *Application) payEventHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	now := time.Now()
-
-	// Handle deposit vs full payment
-	if payload.IsDeposit {
-		// Deposit payment - 50% of total quote
-		if event.DepositPaid {
-			app.badRequest(w, r, errors.New("deposit already paid for this event"))
-			return
-		}
-
-		// Calculate 50% deposit
-		depositAmount := event.TotalQuote / 2
-		remainingAmount := event.TotalQuote - depositAmount
+	if payload.IsDeposit && event.DepositPaid {
+		app.badRequest(w, r, errDepositAlreadyPaid)
+		return
+	}
 
-		// Set deposit fields
-		event.DepositPaid = true
-		event.DepositAmount = depositAmount
-		event.DepositPaidAt = &now
-		event.RemainingAmount = remainingAmount
+	if _, err := app.enabledPaymentMethod(r.Context(), payload.PaymentMethod); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
 
-		// Set installment due date to 7 days before event (or 30 days from now, whichever is earlier)
-		dueDate := now.AddDate(0, 0, 30) // Default: 30 days from now
-		if event.Date != nil {
-			daysUntilEvent := int(time.Until(*event.Date).Hours() / 24)
-			if daysUntilEvent > 7 {
-				dueDate = now.AddDate(0, 0, daysUntilEvent-7)
-			} else {
-				dueDate = now.AddDate(0, 0, 7) // Minimum 7 days
-			}
-		}
-		event.InstallmentDueDate = &dueDate
+	purpose := models.InstallmentPurposeFull
+	switch {
+	case payload.InstallmentID != nil:
+		purpose = models.InstallmentPurposeInstallment
+	case payload.IsDeposit:
+		purpose = models.InstallmentPurposeDeposit
+	}
 
-		// Create pending installment payment record for the remaining amount
-		_, err = app.Store.Installments.CreateInstallmentPayment(r.Context(), event.ID, remainingAmount, &dueDate)
-		if err != nil {
-			app.internalServerError(w, r, err)
+	paymentReason := "payment"
+	event.StatusChange = &models.EventStatusChange{ActorID: user.ID, Reason: &paymentReason}
+	payments, settled, err := app.submitEventPayment(r.Context(), event, purpose, payload.InstallmentID, payload.PaymentMethod)
+	if err != nil {
+		if errors.Is(err, errDepositAlreadyPaid) || errors.Is(err, errPaymentAwaitingVerification) ||
+			errors.Is(err, errNothingToPay) || errors.Is(err, errInstallmentSettled) {
+			app.badRequest(w, r, err)
 			return
 		}
-
-		// Mark the deposit payment as completed via installment system
-		// (We use event.PaymentStatus for tracking overall status)
-		event.PaymentStatus = "deposit_paid"
-		event.PaymentMethod = &payload.PaymentMethod
-		event.PaidAt = &now
-		// Event status remains "confirmed" until full payment
-	} else {
-		// Full payment
-		if event.DepositPaid {
-			// This is the final payment - mark the pending installment as paid
-			installments, _ := app.Store.Installments.GetInstallmentByEventID(r.Context(), event.ID)
-			for _, inst := range installments {
-				if inst.PaymentStatus == "pending" {
-					_ = app.Store.Installments.MarkPaid(r.Context(), inst.ID, payload.PaymentMethod)
-				}
-			}
-			event.PaymentStatus = "completed"
-		} else {
-			// Paying full amount directly (no deposit was made)
-			event.PaymentStatus = "completed"
-		}
-
-		event.PaymentMethod = &payload.PaymentMethod
-		event.PaidAt = &now
-		event.Status = "paid"
-		event.RemainingAmount = 0
+		app.handleError(w, r, err)
+		return
 	}
 
-	if err := app.Store.Events.Update(r.Context(), event); err != nil {
-		app.internalServerError(w, r, err)
+	// Payments that need an admin to confirm the money arrived leave the
+	// event untouched for now.
+	if !settled {
+		_ = app.Store.AuditLogs.Log(r.Context(), &models.AuditLog{
+			UserID:     &user.ID,
+			EventID:    &event.ID,
+			Action:     models.AuditActionEventPay,
+			EntityType: "installment_payment",
+			EntityID:   &payments[0].ID,
+			NewValue:   &payload.PaymentMethod,
+		})
+		_ = app.Notifications.NotifyStatusChange(r.Context(), user.FCMToken, event.Name, "Pago en verificación")
+		if err := app.jsonResponse(w, http.StatusAccepted, payments); err != nil {
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 
@@ -642,6 +664,8 @@ func (app *Application) payEventHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+var errDepositAlreadyPaid = errors.New("deposit already paid for this event")
+
 // getPaymentScheduleHandler godoc
 //
 //	@Summary		Get payment schedule for an event
@@ -680,8 +704,7 @@ func (app *Application) getPaymentScheduleHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Get pending installments
-	installments, err := app.Store.Installments.GetInstallmentByEventID(r.Context(), id)
+	installments, err := app.ensurePaymentSchedule(r.Context(), event)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -689,20 +712,32 @@ func (app *Application) getPaymentScheduleHandler(w http.ResponseWriter, r *http
 
 	// Filter pending payments
 	var pendingPayments []models.InstallmentPayment
+	overdue := 0
 	for _, inst := range installments {
-		if inst.PaymentStatus == "pending" {
+		if inst.Outstanding() {
 			pendingPayments = append(pendingPayments, inst)
 		}
+		if inst.Overdue {
+			overdue += inst.Amount
+		}
+	}
+
+	planName := ""
+	if plan, err := app.Store.PaymentPlans.ForEvent(r.Context(), id); err == nil {
+		planName = plan.Name
 	}
 
 	schedule := models.PaymentSchedule{
+		PlanName:           planName,
 		DepositPaid:        event.DepositPaid,
 		DepositAmount:      event.DepositAmount,
 		DepositPaidAt:      event.DepositPaidAt,
 		RemainingAmount:    event.RemainingAmount,
 		InstallmentDueDate: event.InstallmentDueDate,
 		TotalQuote:         event.TotalQuote,
+		Installments:       installments,
 		PendingPayments:    pendingPayments,
+		OverdueAmount:      overdue,
 	}
 
 	if err := app.jsonResponse(w, http.StatusOK, schedule); err != nil {
@@ -756,28 +791,22 @@ func (app *Application) adjustQuoteHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !app.checkEventTransition(w, r, event, models.EventStatusAdjusted) {
+		return
+	}
+
 	// Update quotation fields
+	adminUser := GetUserFromCtx(r)
 	event.AdditionalCosts = payload.AdditionalCosts
 	event.AdminNotes = payload.AdminNotes
-	event.Status = "adjusted"
+	event.Status = models.EventStatusAdjusted
+	event.StatusChange = &models.EventStatusChange{ActorID: adminUser.ID, Action: models.AuditActionEventAdjust}
 
 	if err := app.Store.Events.Update(r.Context(), event); err != nil {
-		app.internalServerError(w, r, err)
+		app.handleError(w, r, err)
 		return
 	}
 
-	// Audit log
-	adminUser := GetUserFromCtx(r)
-	newVal := fmt.Sprintf("additional_costs=%.2f", payload.AdditionalCosts)
-	_ = app.Store.AuditLogs.Log(r.Context(), &models.AuditLog{
-		UserID:     &adminUser.ID,
-		EventID:   &event.ID,
-		Action:    models.AuditActionEventAdjust,
-		EntityType: "event",
-		EntityID:  &event.ID,
-		NewValue:  &newVal,
-	})
-
 	// Phase 20: Notify user about adjustment
 	// We need to fetch the user to get their FCM token
 	user, err := app.Store.Users.RetrieveById(r.Context(), event.UserID)
@@ -796,7 +825,7 @@ func (app *Application) adjustQuoteHandler(w http.ResponseWriter, r *http.Reques
 // approveQuoteHandler godoc
 //
 //	@Summary		Approve event quote
-//	@Description	Approve the adjusted quote for an event and set status to 'paid'
+//	@Description	Approve the adjusted quote for an event and set status to 'confirmed'
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
@@ -830,17 +859,19 @@ func (app *Application) approveQuoteHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if event.Status != models.EventStatusAdjusted {
-		app.badRequest(w, r, errors.New("only events with 'adjusted' status can be approved"))
+	if !app.checkQuoteDecision(w, r, event, models.EventStatusConfirmed) {
 		return
 	}
 
-	if err := app.Store.Events.ApproveQuote(r.Context(), id, user.ID); err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			app.notFoundResponse(w, r, err)
-		} else {
-			app.internalServerError(w, r, err)
-		}
+	// The approved quote is split into the installments of the event's
+	// payment plan, saved with the approval itself.
+	total, schedule, err := app.planPayments(r.Context(), event)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.Store.Events.ApproveQuote(r.Context(), id, user.ID, total, schedule); err != nil {
+		app.handleError(w, r, err)
 		return
 	}
 
@@ -851,15 +882,6 @@ func (app *Application) approveQuoteHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Audit log
-	_ = app.Store.AuditLogs.Log(r.Context(), &models.AuditLog{
-		UserID:     &user.ID,
-		EventID:   &event.ID,
-		Action:    models.AuditActionQuoteApprove,
-		EntityType: "event",
-		EntityID:  &event.ID,
-	})
-
 	// Send FCM notification
 	eventOwner, err := app.Store.Users.RetrieveById(r.Context(), event.UserID)
 	if err == nil && eventOwner.FCMToken != "" {
@@ -911,17 +933,12 @@ func (app *Application) rejectQuoteHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if event.Status != models.EventStatusAdjusted {
-		app.badRequest(w, r, errors.New("only events with 'adjusted' status can be rejected"))
+	if !app.checkQuoteDecision(w, r, event, models.EventStatusRejected) {
 		return
 	}
 
 	if err := app.Store.Events.RejectQuote(r.Context(), id, user.ID); err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			app.notFoundResponse(w, r, err)
-		} else {
-			app.internalServerError(w, r, err)
-		}
+		app.handleError(w, r, err)
 		return
 	}
 
@@ -932,15 +949,6 @@ func (app *Application) rejectQuoteHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Audit log
-	_ = app.Store.AuditLogs.Log(r.Context(), &models.AuditLog{
-		UserID:     &user.ID,
-		EventID:   &event.ID,
-		Action:    models.AuditActionEventReject,
-		EntityType: "event",
-		EntityID:  &event.ID,
-	})
-
 	if err := app.jsonResponse(w, http.StatusOK, event); err != nil {
 		app.internalServerError(w, r, err)
 	}
@@ -1083,12 +1091,12 @@ func (app *Application) getEventPhotosHandler(w http.ResponseWriter, r *http.Req
 // calculateDeliveryHandler godoc
 //
 //	@Summary		Calculate delivery fee
-//	@Description	Calculate delivery fee based on event address
+//	@Description	Price delivery to the given coordinates, or to the event's venue when none are given, by distance from the warehouse
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		string	true	"Event ID"
-//	@Param			payload	body		object	true	"Address payload"
+//	@Param			id		path		string						true	"Event ID"
+//	@Param			payload	body		models.DeliveryFeeRequest	false	"Venue coordinates"
 //	@Success		200		{object}	models.DeliveryFeeResponse
 //	@Failure		400		{object}	error
 //	@Failure		500		{object}	error
@@ -1118,20 +1126,27 @@ func (app *Application) calculateDeliveryHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	var payload struct {
-		Address string `json:"address" validate:"required"`
-	}
+	var payload models.DeliveryFeeRequest
 	if err := readJson(w, r, &payload); err != nil {
 		app.badRequest(w, r, err)
 		return
 	}
 
-	if payload.Address == "" {
-		app.badRequest(w, r, errors.New("address is required"))
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	lat, lng := payload.Latitude, payload.Longitude
+	if lat == nil {
+		lat, lng = event.Latitude, event.Longitude
+	}
+	if lat == nil || lng == nil {
+		app.badRequest(w, r, errors.New("latitude and longitude are required"))
 		return
 	}
 
-	feeResponse, err := app.Store.DeliveryZones.CalculateFee(r.Context(), payload.Address)
+	feeResponse, err := app.quoteDelivery(r.Context(), *lat, *lng)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -1494,7 +1509,7 @@ func (app *Application) getContractPDFHandler(w http.ResponseWriter, r *http.Req
 		paymentMethod = *event.PaymentMethod
 	}
 
-	total := subtotal + event.AdditionalCosts
+	total := event.QuoteTotal(subtotal)
 	depositPaid := total
 	remainingAmount := 0.0
 
@@ -1513,7 +1528,7 @@ func (app *Application) getContractPDFHandler(w http.ResponseWriter, r *http.Req
 		ClientPhone:     clientUser.PhoneNumber,
 		Items:           contractItems,
 		Subtotal:        subtotal,
-		DeliveryFee:     0,
+		DeliveryFee:     event.DeliveryFee,
 		AdditionalCosts: event.AdditionalCosts,
 		Total:           total,
 		DepositPaid:     depositPaid,
@@ -3,12 +3,14 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
 
 	"Backend/cmd/main/configModels"
 	authMocks "Backend/internal/auth/mocks"
+	"Backend/internal/store"
 	storeMocks "Backend/internal/store/mocks"
 	"Backend/internal/store/models"
 
@@ -36,7 +38,7 @@ func TestGetUserEvents(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				evtM := app.Store.Events.(*storeMocks.EventStore)
 				evtM.On("GetByUserID", mock.Anything, userID).Return([]models.Event{
@@ -56,7 +58,7 @@ func TestGetUserEvents(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				evtM := app.Store.Events.(*storeMocks.EventStore)
 				evtM.On("GetByUserID", mock.Anything, userID).Return([]models.Event{}, nil).Once()
@@ -104,7 +106,7 @@ func TestGetEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				evtM := app.Store.Events.(*storeMocks.EventStore)
 				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -127,7 +129,7 @@ func TestGetEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, mock.Anything).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, mock.Anything).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -178,7 +180,7 @@ func TestCreateEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				evtM := app.Store.Events.(*storeMocks.EventStore)
 				evtM.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
@@ -205,7 +207,7 @@ func TestCreateEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -223,7 +225,7 @@ func TestCreateEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -275,7 +277,7 @@ func TestUpdateEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				evtM := app.Store.Events.(*storeMocks.EventStore)
 				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -289,6 +291,232 @@ func TestUpdateEvent(t *testing.T) {
 			},
 			expectedCode: http.StatusOK,
 		},
+		{
+			name:    "should set the event's own rental window",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"pickup_at": date.Add(-6 * time.Hour).Format(time.RFC3339),
+				"return_at": date.Add(30 * time.Hour).Format(time.RFC3339),
+			},
+			setupMocks: func(app *Application) {
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID:     eventID,
+					UserID: userID,
+					Name:   "Wedding",
+					Date:   &date,
+					Status: models.EventStatusPlanning,
+				}, nil).Once()
+				evtM.On("Update", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+					return e.PickupAt != nil && e.ReturnAt != nil && e.ReturnAt.Sub(*e.PickupAt) == 36*time.Hour
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "should return 400 when the rental window ends before it starts",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"pickup_at": date.Add(30 * time.Hour).Format(time.RFC3339),
+				"return_at": date.Add(-6 * time.Hour).Format(time.RFC3339),
+			},
+			setupMocks: func(app *Application) {
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID:     eventID,
+					UserID: userID,
+					Name:   "Wedding",
+					Date:   &date,
+					Status: models.EventStatusPlanning,
+				}, nil).Once()
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:    "should submit a draft and record the transition",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"status":        models.EventStatusRequested,
+				"status_reason": "ready for a quote",
+			},
+			setupMocks: func(app *Application) {
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID:     eventID,
+					UserID: userID,
+					Date:   &date,
+					Status: models.EventStatusDraft,
+				}, nil).Once()
+				evtM.On("Update", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+					return e.Status == models.EventStatusRequested &&
+						e.StatusChange != nil && e.StatusChange.ActorID == userID &&
+						e.StatusChange.Reason != nil && *e.StatusChange.Reason == "ready for a quote"
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "should return 409 when reopening a cancelled event",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"status": models.EventStatusRequested,
+			},
+			setupMocks: func(app *Application) {
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID:     eventID,
+					UserID: userID,
+					Status: models.EventStatusCancelled,
+				}, nil).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:    "should return 403 when the owner sets a payment status",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"status": models.EventStatusPaid,
+			},
+			setupMocks: func(app *Application) {
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID:     eventID,
+					UserID: userID,
+					Status: models.EventStatusDraft,
+				}, nil).Once()
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:    "should price delivery when the venue moves",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"latitude":  18.4539,
+				"longitude": -69.6064,
+			},
+			setupMocks: func(app *Application) {
+				app.Config.Delivery = configModels.DeliveryConfig{OriginLat: 18.4167, OriginLng: -70.1056}
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+
+				zonesM := &storeMocks.DeliveryZonesStore{}
+				app.Store.DeliveryZones = zonesM
+				zonesM.On("GetAll", mock.Anything).Return([]models.DeliveryZone{
+					{Name: "Centro", BaseRadiusKm: 10, MaxRadiusKm: 30, IsActive: true},
+					{Name: "Extendido", BaseRadiusKm: 30, MaxRadiusKm: 80, TravelFee: 1500, IsActive: true},
+				}, nil).Once()
+
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID:     eventID,
+					UserID: userID,
+					Date:   &date,
+					Status: models.EventStatusPlanning,
+				}, nil).Once()
+				// Boca Chica is about 53 km from San Cristóbal.
+				evtM.On("Update", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+					return e.Latitude != nil && *e.Latitude == 18.4539 && e.DeliveryFee == 1500
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "should return 409 when the venue moves after the quote is approved",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"latitude":  18.4861,
+				"longitude": -69.9312,
+			},
+			setupMocks: func(app *Application) {
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+
+				approved := time.Now()
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID:              eventID,
+					UserID:          userID,
+					Status:          models.EventStatusConfirmed,
+					QuoteApprovedAt: &approved,
+				}, nil).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:    "should return 400 for a latitude without a longitude",
+			eventID: eventID.String(),
+			payload: map[string]interface{}{
+				"latitude": 18.4861,
+			},
+			setupMocks: func(app *Application) {
+				token := &jwt.Token{
+					Claims: jwt.MapClaims{"sub": userIDStr},
+					Valid:  true,
+				}
+				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+				storeM := app.Store.Users.(*storeMocks.UserStore)
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+			},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:    "should return 400 for invalid UUID",
 			eventID: "invalid-uuid",
@@ -303,7 +531,7 @@ func TestUpdateEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, mock.Anything).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, mock.Anything).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -349,7 +577,7 @@ func TestDeleteEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 
 				evtM := app.Store.Events.(*storeMocks.EventStore)
 				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{
@@ -372,7 +600,7 @@ func TestDeleteEvent(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, mock.Anything).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, mock.Anything).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -393,4 +621,339 @@ func TestDeleteEvent(t *testing.T) {
 			checkResponseCode(t, tc.expectedCode, rr)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestAddEventItem(t *testing.T) {
+	userID := uuid.New()
+	userIDStr := userID.String()
+	eventID := uuid.New()
+	articleID := uuid.New()
+	variantID := uuid.New()
+	date := time.Now().Add(72 * time.Hour)
+
+	authenticate := func(app *Application) {
+		token := &jwt.Token{
+			Claims: jwt.MapClaims{"sub": userIDStr},
+			Valid:  true,
+		}
+		app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+
+		storeM := app.Store.Users.(*storeMocks.UserStore)
+		storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+	}
+
+	tests := []struct {
+		name         string
+		payload      map[string]interface{}
+		setupMocks   func(*Application)
+		expectedCode int
+		checkBody    func(*testing.T, []byte)
+	}{
+		{
+			name:    "should reserve stock for dated events",
+			payload: map[string]interface{}{"article_id": articleID, "variant_id": variantID, "quantity": 2},
+			setupMocks: func(app *Application) {
+				authenticate(app)
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{ID: eventID, UserID: userID, Date: &date}, nil).Once()
+				evtM.On("AddItemWithReservation", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:    "should add without reservation when the draft has no date",
+			payload: map[string]interface{}{"article_id": articleID, "quantity": 1},
+			setupMocks: func(app *Application) {
+				authenticate(app)
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{ID: eventID, UserID: userID}, nil).Once()
+				evtM.On("AddItem", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:    "should return 409 with the shortfall when stock runs out",
+			payload: map[string]interface{}{"article_id": articleID, "variant_id": variantID, "quantity": 5},
+			setupMocks: func(app *Application) {
+				authenticate(app)
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{ID: eventID, UserID: userID, Date: &date}, nil).Once()
+				evtM.On("AddItemWithReservation", mock.Anything, mock.Anything).Return(&store.InsufficientStockError{
+					Shortfalls: []store.StockShortfall{{ArticleID: variantID, Requested: 5, Available: 3, Shortfall: 2}},
+				}).Once()
+			},
+			expectedCode: http.StatusConflict,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp struct {
+					Data []store.StockShortfall `json:"data"`
+				}
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("invalid response body: %v", err)
+				}
+				if len(resp.Data) != 1 || resp.Data[0].Shortfall != 2 {
+					t.Errorf("expected a shortfall of 2, got %+v", resp.Data)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			body, _ := json.Marshal(tc.payload)
+			req, _ := http.NewRequest(http.MethodPost, "/v1/events/"+eventID.String()+"/items", bytes.NewBuffer(body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.checkBody != nil {
+				tc.checkBody(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestQuoteDecision(t *testing.T) {
+	userID := uuid.New()
+	userIDStr := userID.String()
+	eventID := uuid.New()
+
+	tests := []struct {
+		name         string
+		action       string
+		status       string
+		setupMocks   func(*Application)
+		expectedCode int
+	}{
+		{
+			name:   "should approve the quote with its payment schedule",
+			action: "approve-quote",
+			status: models.EventStatusAdjusted,
+			setupMocks: func(app *Application) {
+				plans := &storeMocks.PaymentPlansStore{}
+				app.Store.PaymentPlans = plans
+				plans.On("ForEvent", mock.Anything, eventID).Return(nil, store.ErrNotFound)
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("ApproveQuote", mock.Anything, eventID, userID, 10000, mock.MatchedBy(func(s []models.ScheduledInstallment) bool {
+					total := 0
+					for _, inst := range s {
+						total += inst.Amount
+					}
+					return len(s) > 0 && total == 10000
+				})).Return(nil).Once()
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{ID: eventID, UserID: userID, Status: models.EventStatusConfirmed}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "should not approve when the payment schedule can't be planned",
+			action: "approve-quote",
+			status: models.EventStatusAdjusted,
+			setupMocks: func(app *Application) {
+				plans := &storeMocks.PaymentPlansStore{}
+				app.Store.PaymentPlans = plans
+				plans.On("ForEvent", mock.Anything, eventID).Return(nil, errors.New("db down"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "should return 409 approving a quote that was already approved",
+			action:       "approve-quote",
+			status:       models.EventStatusConfirmed,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "should return 409 approving a draft",
+			action:       "approve-quote",
+			status:       models.EventStatusDraft,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "should return 409 approving a quote an admin never adjusted",
+			action:       "approve-quote",
+			status:       models.EventStatusRequested,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "should return 409 rejecting a quote an admin never adjusted",
+			action:       "reject-quote",
+			status:       models.EventStatusRequested,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "should return 409 rejecting a paid event",
+			action:       "reject-quote",
+			status:       models.EventStatusPaid,
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			token := &jwt.Token{
+				Claims: jwt.MapClaims{"sub": userIDStr},
+				Valid:  true,
+			}
+			app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
+			app.Store.Users.(*storeMocks.UserStore).On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Maybe()
+			evtM := app.Store.Events.(*storeMocks.EventStore)
+			evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{ID: eventID, UserID: userID, Status: tc.status, TotalQuote: 10000}, nil).Once()
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/v1/events/"+eventID.String()+"/"+tc.action, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, app.Mount())
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.expectedCode == http.StatusOK {
+				evtM.AssertExpectations(t)
+			} else {
+				evtM.AssertNotCalled(t, "ApproveQuote", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				evtM.AssertNotCalled(t, "RejectQuote", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPayEvent(t *testing.T) {
+	userID := uuid.New()
+	eventID := uuid.New()
+
+	deposit := models.InstallmentPayment{ID: uuid.New(), EventID: eventID, Purpose: models.InstallmentPurposeInstallment, Amount: 5000, PaymentStatus: models.InstallmentStatusPending}
+	balance := models.InstallmentPayment{ID: uuid.New(), EventID: eventID, Purpose: models.InstallmentPurposeInstallment, Amount: 5000, PaymentStatus: models.InstallmentStatusPending}
+	paid := func(p models.InstallmentPayment, status string) *models.InstallmentPayment {
+		p.PaymentStatus = status
+		return &p
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		setupMocks   func(*Application)
+		assertMocks  func(*testing.T, *Application)
+		expectedCode int
+	}{
+		{
+			name: "should leave transfers waiting for verification",
+			body: `{"payment_method":"transferencia"}`,
+			setupMocks: func(app *Application) {
+				instM := app.Store.Installments.(*storeMocks.InstallmentsStore)
+				instM.On("GetInstallmentByEventID", mock.Anything, eventID).Return([]models.InstallmentPayment{deposit, balance}, nil)
+				instM.On("MarkPaid", mock.Anything, deposit.ID, "transferencia").Return(paid(deposit, models.InstallmentStatusPendingVerification), nil)
+				instM.On("MarkPaid", mock.Anything, balance.ID, "transferencia").Return(paid(balance, models.InstallmentStatusPendingVerification), nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Installments.(*storeMocks.InstallmentsStore).AssertExpectations(t)
+				app.Store.Events.(*storeMocks.EventStore).AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name: "should settle the event for methods without confirmation",
+			body: `{"payment_method":"tarjeta","is_deposit":true}`,
+			setupMocks: func(app *Application) {
+				instM := app.Store.Installments.(*storeMocks.InstallmentsStore)
+				instM.On("GetInstallmentByEventID", mock.Anything, eventID).Return([]models.InstallmentPayment{deposit, balance}, nil).Once()
+				instM.On("MarkPaid", mock.Anything, deposit.ID, "tarjeta").Return(paid(deposit, models.InstallmentStatusPaid), nil)
+				instM.On("GetInstallmentByEventID", mock.Anything, eventID).Return([]models.InstallmentPayment{*paid(deposit, models.InstallmentStatusPaid), balance}, nil)
+				app.Store.Events.(*storeMocks.EventStore).On("Update", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+					return e.DepositPaid && e.RemainingAmount == 5000 && e.PaymentStatus == "deposit_paid"
+				})).Return(nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Installments.(*storeMocks.InstallmentsStore).AssertNotCalled(t, "MarkPaid", mock.Anything, balance.ID, mock.Anything)
+				app.Store.Events.(*storeMocks.EventStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "should generate the schedule from the event's plan",
+			body: `{"payment_method":"tarjeta","is_deposit":true}`,
+			setupMocks: func(app *Application) {
+				app.Store.PaymentPlans.(*storeMocks.PaymentPlansStore).On("ForEvent", mock.Anything, eventID).Return(&models.PaymentPlan{Name: "50/50", Installments: []models.PaymentPlanInstallment{
+					{Sequence: 1, Label: "Reserva", Percent: 50},
+					{Sequence: 2, Label: "Saldo", Percent: 50},
+				}}, nil)
+				instM := app.Store.Installments.(*storeMocks.InstallmentsStore)
+				instM.On("GetInstallmentByEventID", mock.Anything, eventID).Return([]models.InstallmentPayment{}, nil).Once()
+				instM.On("CreateSchedule", mock.Anything, eventID, mock.MatchedBy(func(s []models.ScheduledInstallment) bool {
+					return len(s) == 2 && s[0].Amount == 5000 && s[1].Amount == 5000
+				})).Return([]models.InstallmentPayment{deposit, balance}, nil)
+				instM.On("MarkPaid", mock.Anything, deposit.ID, "tarjeta").Return(paid(deposit, models.InstallmentStatusPaid), nil)
+				instM.On("GetInstallmentByEventID", mock.Anything, eventID).Return([]models.InstallmentPayment{*paid(deposit, models.InstallmentStatusPaid), balance}, nil)
+				app.Store.Events.(*storeMocks.EventStore).On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Installments.(*storeMocks.InstallmentsStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "should reject paying an installment twice",
+			body: `{"payment_method":"tarjeta","installment_id":"` + deposit.ID.String() + `"}`,
+			setupMocks: func(app *Application) {
+				app.Store.Installments.(*storeMocks.InstallmentsStore).On("GetInstallmentByEventID", mock.Anything, eventID).
+					Return([]models.InstallmentPayment{*paid(deposit, models.InstallmentStatusPaid), balance}, nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "should reject a payment while another is being verified",
+			body: `{"payment_method":"transferencia"}`,
+			setupMocks: func(app *Application) {
+				app.Store.Installments.(*storeMocks.InstallmentsStore).On("GetInstallmentByEventID", mock.Anything, eventID).
+					Return([]models.InstallmentPayment{*paid(deposit, models.InstallmentStatusPendingVerification), balance}, nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should reject a disabled method",
+			body:         `{"payment_method":"cheque"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should reject an unknown method",
+			body:         `{"payment_method":"bitcoin"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticate(app, &models.User{ID: userID, Role: models.Role{Name: "buyer"}})
+			app.Store.AuditLogs.(*storeMocks.AuditLogsStore).On("Log", mock.Anything, mock.Anything).Return(nil)
+			app.Store.Events.(*storeMocks.EventStore).On("GetByID", mock.Anything, eventID).Return(&models.Event{
+				ID: eventID, UserID: userID, Name: "Boda", Status: models.EventStatusConfirmed, TotalQuote: 10000,
+			}, nil)
+			methods := app.Store.PaymentMethods.(*storeMocks.PaymentMethodsStore)
+			methods.On("GetByCode", mock.Anything, "transferencia").Return(&models.PaymentMethod{Code: "transferencia", Enabled: true, RequiresConfirmation: true}, nil)
+			methods.On("GetByCode", mock.Anything, "tarjeta").Return(&models.PaymentMethod{Code: "tarjeta", Enabled: true}, nil)
+			methods.On("GetByCode", mock.Anything, "cheque").Return(&models.PaymentMethod{Code: "cheque"}, nil)
+			methods.On("GetByCode", mock.Anything, mock.Anything).Return(nil, store.ErrNotFound)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/events/"+eventID.String()+"/pay", bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.expectedCode == http.StatusBadRequest {
+				app.Store.Installments.(*storeMocks.InstallmentsStore).AssertNotCalled(t, "MarkPaid", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tc.assertMocks != nil {
+				tc.assertMocks(t, app)
+			}
+		})
+	}
+}
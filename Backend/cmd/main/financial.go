@@ -2,10 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"Backend/internal/store"
 	"Backend/internal/store/models"
 
 	"github.com/go-chi/chi/v5"
@@ -18,52 +20,51 @@ func (app *Application) MountFinancialRoutes() http.Handler {
 
 	r.Route("/categories", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
-		r.Get("/", app.getFinancialCategoriesHandler)
-		r.Post("/", app.createFinancialCategoryHandler)
+		r.With(app.RequirePermission(models.PermFinancialRead)).Get("/", app.getFinancialCategoriesHandler)
+		r.With(app.RequirePermission(models.PermFinancialWrite)).Post("/", app.createFinancialCategoryHandler)
 	})
 
 	r.Route("/records", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
-		r.Get("/", app.getFinancialRecordsHandler)
-		r.Post("/", app.createFinancialRecordHandler)
-		r.Post("/{id}/reconcile", app.reconcileFinancialRecordHandler)
+		r.With(app.RequirePermission(models.PermFinancialRead)).Get("/", app.getFinancialRecordsHandler)
+		r.With(app.RequirePermission(models.PermFinancialWrite)).Post("/", app.createFinancialRecordHandler)
+		r.With(app.RequirePermission(models.PermFinancialWrite)).Post("/{id}/reconcile", app.reconcileFinancialRecordHandler)
 	})
 
 	r.Route("/summary", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
+		r.Use(app.RequirePermission(models.PermFinancialRead))
 		r.Get("/", app.getFinancialSummaryHandler)
 	})
 
 	r.Route("/income-by-category", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
+		r.Use(app.RequirePermission(models.PermFinancialRead))
 		r.Get("/", app.getIncomeByCategoryHandler)
 	})
 
 	r.Route("/expenses-by-category", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
+		r.Use(app.RequirePermission(models.PermFinancialRead))
 		r.Get("/", app.getExpensesByCategoryHandler)
 	})
 
 	r.Route("/invoices", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
-		r.Get("/", app.getInvoicesHandler)
-		r.Post("/", app.createInvoiceHandler)
+		r.With(app.RequirePermission(models.PermFinancialRead)).Get("/", app.getInvoicesHandler)
+		r.With(app.RequirePermission(models.PermFinancialWrite)).Post("/", app.createInvoiceHandler)
+		r.With(app.RequirePermission(models.PermFinancialRead)).Get("/{id}", app.getInvoiceHandler)
+		r.With(app.RequirePermission(models.PermFinancialRead)).Get("/{id}/pdf", app.getInvoicePDFHandler)
+		r.With(app.RequirePermission(models.PermFinancialWrite)).Patch("/{id}/status", app.updateInvoiceStatusHandler)
 	})
 
 	r.Route("/vendors", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
-		r.Get("/", app.getExpenseVendorsHandler)
-		r.Post("/", app.createExpenseVendorHandler)
+		r.With(app.RequirePermission(models.PermFinancialRead)).Get("/", app.getExpenseVendorsHandler)
+		r.With(app.RequirePermission(models.PermFinancialWrite)).Post("/", app.createExpenseVendorHandler)
 		r.Route("/{vendorId}/payments", func(r chi.Router) {
-			r.Get("/", app.getVendorPaymentsHandler)
-			r.Post("/", app.createVendorPaymentHandler)
+			r.With(app.RequirePermission(models.PermFinancialRead)).Get("/", app.getVendorPaymentsHandler)
+			r.With(app.RequirePermission(models.PermFinancialWrite)).Post("/", app.createVendorPaymentHandler)
 		})
 	})
 
@@ -76,20 +77,25 @@ func (app *Application) MountFinancialRoutes() http.Handler {
 		r.Get("/claims", app.getInsuranceClaimsHandler)
 		r.Post("/claims", app.createInsuranceClaimHandler)
 
-		r.With(app.RoleMiddleware("admin")).Patch("/claims/{claimId}/status", app.updateInsuranceClaimStatusHandler)
-		r.With(app.RoleMiddleware("admin")).Get("/all", app.getAllArticleInsuranceHandler)
+		r.With(app.RequirePermission(models.PermFinancialWrite)).Patch("/claims/{claimId}/status", app.updateInsuranceClaimStatusHandler)
+		r.With(app.RequirePermission(models.PermFinancialRead)).Get("/all", app.getAllArticleInsuranceHandler)
 	})
 
 	r.Route("/paypal", func(r chi.Router) {
-		r.Use(app.AuthTokenMiddleware())
-		r.Post("/create-order", app.createPayPalOrderHandler)
-		r.Post("/capture-order", app.capturePayPalOrderHandler)
-		r.Get("/event/{eventId}", app.getPayPalTransactionsByEventHandler)
+		// PayPal calls the webhook itself; it is authenticated by signature.
+		r.Post("/webhook", app.paypalWebhookHandler)
+
+		r.Group(func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware())
+			r.Post("/create-order", app.createPayPalOrderHandler)
+			r.Post("/capture-order", app.capturePayPalOrderHandler)
+			r.Get("/event/{eventId}", app.getPayPalTransactionsByEventHandler)
+		})
 	})
 
 	r.Route("/audit", func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
+		r.Use(app.RequirePermission(models.PermAuditRead))
 		r.Get("/client/{userId}", app.getClientAuditLogHandler)
 		r.Get("/", app.getAllAuditLogsHandler)
 	})
@@ -290,68 +296,6 @@ func (app *Application) getExpensesByCategoryHandler(w http.ResponseWriter, r *h
 	render.JSON(w, r, map[string]interface{}{"data": data})
 }
 
-func (app *Application) getInvoicesHandler(w http.ResponseWriter, r *http.Request) {
-	clientID := r.URL.Query().Get("client_id")
-	status := r.URL.Query().Get("status")
-
-	invoices, err := app.Store.Financial.GetInvoices(r.Context(), clientID, status)
-	if err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-	render.JSON(w, r, map[string]interface{}{"data": invoices})
-}
-
-func (app *Application) createInvoiceHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		EventID        string  `json:"event_id"`
-		ClientID       string  `json:"client_id"`
-		Subtotal       float64 `json:"subtotal"`
-		TaxAmount      float64 `json:"tax_amount"`
-		DiscountAmount float64 `json:"discount_amount"`
-		Total          float64 `json:"total"`
-		Currency       string  `json:"currency"`
-		Status         string  `json:"status"`
-		IssueDate      string  `json:"issue_date"`
-		DueDate        string  `json:"due_date"`
-		Notes          string  `json:"notes"`
-		Terms          string  `json:"terms"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-
-	clientID, _ := uuid.Parse(req.ClientID)
-	eventID, _ := uuid.Parse(req.EventID)
-	issueDate, _ := time.Parse("2006-01-02", req.IssueDate)
-
-	inv := &models.Invoice{
-		InvoiceNumber:  "INV-" + time.Now().Format("20060102") + "-" + uuid.New().String()[:8],
-		EventID:        &eventID,
-		ClientID:       clientID,
-		Subtotal:       req.Subtotal,
-		TaxAmount:      req.TaxAmount,
-		DiscountAmount: req.DiscountAmount,
-		Total:          req.Total,
-		Currency:       req.Currency,
-		Status:         req.Status,
-		IssueDate:      issueDate,
-	}
-
-	if req.DueDate != "" {
-		dueDate, _ := time.Parse("2006-01-02", req.DueDate)
-		inv.DueDate = &dueDate
-	}
-
-	if err := app.Store.Financial.CreateInvoice(r.Context(), inv); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-	render.JSON(w, r, map[string]interface{}{"data": inv})
-}
-
 func (app *Application) getExpenseVendorsHandler(w http.ResponseWriter, r *http.Request) {
 	vendors, err := app.Store.Financial.GetExpenseVendors(r.Context())
 	if err != nil {
@@ -421,6 +365,7 @@ func (app *Application) getVendorPaymentsHandler(w http.ResponseWriter, r *http.
 
 func (app *Application) createVendorPaymentHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
+		EventID         string  `json:"event_id"`
 		Amount          float64 `json:"amount"`
 		Currency        string  `json:"currency"`
 		PaymentDate     string  `json:"payment_date"`
@@ -451,8 +396,23 @@ func (app *Application) createVendorPaymentHandler(w http.ResponseWriter, r *htt
 	if req.Description != "" {
 		payment.Description = &req.Description
 	}
+	if req.EventID != "" {
+		eventID, err := uuid.Parse(req.EventID)
+		if err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+		payment.EventID = &eventID
+	}
+	if user := GetUserFromCtx(r); user != nil {
+		payment.RecordedBy = &user.ID
+	}
 
 	if err := app.Store.Financial.CreateVendorPayment(r.Context(), payment); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
 		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
 		return
 	}
@@ -657,71 +617,6 @@ func (app *Application) getAllArticleInsuranceHandler(w http.ResponseWriter, r *
 	render.JSON(w, r, map[string]interface{}{"data": insurance})
 }
 
-func (app *Application) createPayPalOrderHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		EventID  string  `json:"event_id"`
-		Amount   float64 `json:"amount"`
-		Currency string  `json:"currency"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-
-	user := r.Context().Value("user").(*models.User)
-	eventID, _ := uuid.Parse(req.EventID)
-
-	tx := &models.PayPalTransaction{
-		PayPalOrderID: "PP-" + time.Now().Format("20060102150405") + uuid.New().String()[:8],
-		EventID:       eventID,
-		UserID:        user.ID,
-		Amount:        req.Amount,
-		Currency:      req.Currency,
-		Status:        "pending",
-	}
-
-	if err := app.Store.PayPal.CreateTransaction(r.Context(), tx); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-
-	render.JSON(w, r, map[string]interface{}{
-		"data":            tx,
-		"paypal_order_id": tx.PayPalOrderID,
-	})
-}
-
-func (app *Application) capturePayPalOrderHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		PayPalOrderID string `json:"paypal_order_id"`
-		CaptureID     string `json:"capture_id"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-
-	if err := app.Store.PayPal.UpdateTransactionCapture(r.Context(), req.PayPalOrderID, req.CaptureID); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-
-	render.JSON(w, r, map[string]interface{}{"data": "captured"})
-}
-
-func (app *Application) getPayPalTransactionsByEventHandler(w http.ResponseWriter, r *http.Request) {
-	eventID, _ := uuid.Parse(chi.URLParam(r, "eventId"))
-
-	txs, err := app.Store.PayPal.GetTransactionsByEvent(r.Context(), eventID)
-	if err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-	render.JSON(w, r, map[string]interface{}{"data": txs})
-}
-
 func (app *Application) getClientAuditLogHandler(w http.ResponseWriter, r *http.Request) {
 	userID, _ := uuid.Parse(chi.URLParam(r, "userId"))
 
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/store"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateVendorPayment(t *testing.T) {
+	adminID := uuid.New()
+	vendorID := uuid.New()
+	eventID := uuid.New()
+
+	tests := []struct {
+		name         string
+		vendorID     uuid.UUID
+		body         string
+		setupMocks   func(*Application)
+		expectedCode int
+	}{
+		{
+			name:     "should link the payment to its event and recorder",
+			vendorID: vendorID,
+			body:     `{"amount":2500,"payment_method":"transferencia","payment_date":"2026-05-02","event_id":"` + eventID.String() + `"}`,
+			setupMocks: func(app *Application) {
+				app.Store.Financial.(*storeMocks.FinancialStore).On("CreateVendorPayment", mock.Anything, mock.MatchedBy(func(p *models.VendorPayment) bool {
+					return p.VendorID == vendorID && p.EventID != nil && *p.EventID == eventID &&
+						p.RecordedBy != nil && *p.RecordedBy == adminID && p.Amount == 2500
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:     "should return 404 for an unknown vendor",
+			vendorID: uuid.New(),
+			body:     `{"amount":2500}`,
+			setupMocks: func(app *Application) {
+				app.Store.Financial.(*storeMocks.FinancialStore).On("CreateVendorPayment", mock.Anything, mock.Anything).Return(store.ErrNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "should return 400 for an invalid event id",
+			vendorID:     vendorID,
+			body:         `{"amount":2500,"event_id":"abc"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateAdmin(app, adminID)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/financial/vendors/"+tc.vendorID.String()+"/payments", bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			financialM := app.Store.Financial.(*storeMocks.FinancialStore)
+			if tc.setupMocks != nil {
+				financialM.AssertExpectations(t)
+			} else {
+				financialM.AssertNotCalled(t, "CreateVendorPayment", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCreateInvoice(t *testing.T) {
+	adminID := uuid.New()
+	clientID := uuid.New()
+	eventID := uuid.New()
+	price := 150.0
+
+	tests := []struct {
+		name         string
+		body         string
+		setupMocks   func(*Application)
+		expectedCode int
+	}{
+		{
+			name: "should invoice an event at its approved total",
+			body: `{"event_id":"` + eventID.String() + `","ncf":"B0200000001"}`,
+			setupMocks: func(app *Application) {
+				evtM := app.Store.Events.(*storeMocks.EventStore)
+				evtM.On("GetByID", mock.Anything, eventID).Return(&models.Event{ID: eventID, UserID: clientID, DeliveryFee: 2000, TotalQuote: 17500}, nil)
+				evtM.On("GetItems", mock.Anything, eventID).Return([]models.EventItem{{Quantity: 100, PriceSnapshot: &price}}, nil)
+				app.Store.Financial.(*storeMocks.FinancialStore).On("CreateInvoice", mock.Anything, mock.MatchedBy(func(inv *models.Invoice) bool {
+					return inv.ClientID == clientID && inv.TaxIncluded && inv.Total == 17500 && len(inv.Lines) == 3 &&
+						inv.Lines[2].UnitPrice == 500 && inv.CreatedBy != nil && *inv.CreatedBy == adminID
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "should add ITBIS to other invoices",
+			body: `{"client_id":"` + clientID.String() + `","lines":[{"description":"Montaje","quantity":2,"unit_price":500}]}`,
+			setupMocks: func(app *Application) {
+				app.Store.Financial.(*storeMocks.FinancialStore).On("CreateInvoice", mock.Anything, mock.MatchedBy(func(inv *models.Invoice) bool {
+					return inv.ClientID == clientID && inv.Subtotal == 1000 && inv.TaxAmount == 180 && inv.Total == 1180
+				})).Return(nil).Once()
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "should reject an invoice with no client or event",
+			body:         `{"lines":[{"description":"Montaje","quantity":1,"unit_price":500}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should reject a tax credit invoice without RNC",
+			body:         `{"client_id":"` + clientID.String() + `","ncf":"B0100000001","lines":[{"description":"Montaje","quantity":1,"unit_price":500}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should reject an invoice with nothing to invoice",
+			body:         `{"client_id":"` + clientID.String() + `","lines":[{"description":"Montaje","quantity":1,"unit_price":0}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should reject a line without a quantity",
+			body:         `{"client_id":"` + clientID.String() + `","lines":[{"description":"Montaje","unit_price":500}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateAdmin(app, adminID)
+			app.Store.Users.(*storeMocks.UserStore).On("RetrieveById", mock.Anything, clientID).Return(&models.User{ID: clientID}, nil)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/financial/invoices", bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			financialM := app.Store.Financial.(*storeMocks.FinancialStore)
+			if tc.setupMocks != nil {
+				financialM.AssertExpectations(t)
+			} else {
+				financialM.AssertNotCalled(t, "CreateInvoice", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUpdateInvoiceStatus(t *testing.T) {
+	adminID := uuid.New()
+	invoiceID := uuid.New()
+
+	tests := []struct {
+		name         string
+		body         string
+		setupMocks   func(*Application)
+		expectedCode int
+	}{
+		{
+			name: "should move the invoice to the new status",
+			body: `{"status":"sent"}`,
+			setupMocks: func(app *Application) {
+				app.Store.Financial.(*storeMocks.FinancialStore).On("UpdateInvoiceStatus", mock.Anything, invoiceID, models.InvoiceStatusSent, (*string)(nil)).
+					Return(&models.Invoice{ID: invoiceID, Status: models.InvoiceStatusSent}, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "should return 409 for a move the lifecycle doesn't allow",
+			body: `{"status":"paid"}`,
+			setupMocks: func(app *Application) {
+				app.Store.Financial.(*storeMocks.FinancialStore).On("UpdateInvoiceStatus", mock.Anything, invoiceID, models.InvoiceStatusPaid, (*string)(nil)).
+					Return(nil, &store.InvalidTransitionError{Entity: "invoice", From: models.InvoiceStatusVoid, To: models.InvoiceStatusPaid}).Once()
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "should return 400 for voiding without a reason",
+			body:         `{"status":"void"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateAdmin(app, adminID)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPatch, "/v1/financial/invoices/"+invoiceID.String()+"/status", bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+		})
+	}
+}
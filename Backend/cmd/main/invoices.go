@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"Backend/internal/pdf"
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+var errInvoiceTotal = errors.New("invoice total must be greater than zero")
+
+func (app *Application) getInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	clientID := r.URL.Query().Get("client_id")
+	status := r.URL.Query().Get("status")
+
+	invoices, err := app.Store.Financial.GetInvoices(r.Context(), clientID, status)
+	if err != nil {
+		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	render.JSON(w, r, map[string]interface{}{"data": invoices})
+}
+
+// createInvoiceHandler issues an invoice with the next number of the year.
+// An invoice for an event lists the event's items at their quoted prices,
+// which include ITBIS, so that it adds up to what the installments collect.
+func (app *Application) createInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.CreateInvoicePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	inv := &models.Invoice{
+		EventID:        payload.EventID,
+		DiscountAmount: payload.DiscountAmount,
+		NCF:            payload.NCF,
+		ClientRNC:      payload.ClientRNC,
+		DueDate:        payload.DueDate,
+		Notes:          payload.Notes,
+		Terms:          payload.Terms,
+		CreatedBy:      &GetUserFromCtx(r).ID,
+	}
+
+	if payload.EventID != nil {
+		event, err := app.Store.Events.GetByID(ctx, *payload.EventID)
+		if err != nil {
+			app.handleError(w, r, err)
+			return
+		}
+		lines, err := app.eventInvoiceLines(ctx, event)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		inv.ClientID = event.UserID
+		inv.Lines = lines
+		inv.TaxIncluded = true
+	} else {
+		if _, err := app.Store.Users.RetrieveById(ctx, *payload.ClientID); err != nil {
+			app.handleError(w, r, err)
+			return
+		}
+		inv.ClientID = *payload.ClientID
+		inv.TaxIncluded = payload.TaxIncluded
+		for _, l := range payload.Lines {
+			inv.Lines = append(inv.Lines, models.InvoiceLine{Description: l.Description, Quantity: l.Quantity, UnitPrice: l.UnitPrice})
+		}
+	}
+
+	if err := inv.CheckFiscalFields(); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	inv.ComputeTotals()
+	if inv.Total <= 0 {
+		app.badRequest(w, r, errInvoiceTotal)
+		return
+	}
+
+	if err := app.Store.Financial.CreateInvoice(ctx, inv); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, inv); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// eventInvoiceLines lists the event's items, delivery and additional costs.
+// When the quote total saved at approval no longer matches the items, an
+// adjustment line keeps the invoice at the approved total.
+func (app *Application) eventInvoiceLines(ctx context.Context, event *models.Event) ([]models.InvoiceLine, error) {
+	items, err := app.Store.Events.GetItems(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []models.InvoiceLine
+	var gross float64
+	add := func(description string, quantity int, unitPrice float64) {
+		lines = append(lines, models.InvoiceLine{Description: description, Quantity: quantity, UnitPrice: unitPrice})
+		gross += unitPrice * float64(quantity)
+	}
+	for _, item := range items {
+		add(eventItemName(item), item.Quantity, item.UnitPrice())
+	}
+	if event.DeliveryFee > 0 {
+		add("Envío", 1, event.DeliveryFee)
+	}
+	if event.AdditionalCosts > 0 {
+		add("Costos adicionales", 1, event.AdditionalCosts)
+	}
+	if event.TotalQuote > 0 {
+		if diff := math.Round((float64(event.TotalQuote)-gross)*100) / 100; diff != 0 {
+			add("Ajuste de cotización", 1, diff)
+		}
+	}
+	return lines, nil
+}
+
+func (app *Application) getInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	inv, err := app.Store.Financial.GetInvoice(r.Context(), id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, inv); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// updateInvoiceStatusHandler marks an invoice sent, paid or void.
+func (app *Application) updateInvoiceStatusHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	var payload models.UpdateInvoiceStatusPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	inv, err := app.Store.Financial.UpdateInvoiceStatus(r.Context(), id, payload.Status, payload.Reason)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, inv); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *Application) getInvoicePDFHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	inv, err := app.Store.Financial.GetInvoice(ctx, id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	client, err := app.Store.Users.RetrieveById(ctx, inv.ClientID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	data := pdf.InvoiceData{
+		BusinessName:  app.Config.Fiscal.BusinessName,
+		BusinessRNC:   app.Config.Fiscal.RNC,
+		Address:       app.Config.Fiscal.Address,
+		InvoiceNumber: inv.InvoiceNumber,
+		NCF:           stringValue(inv.NCF),
+		IssueDate:     inv.IssueDate,
+		DueDate:       inv.DueDate,
+		Status:        inv.Status,
+		VoidReason:    stringValue(inv.VoidReason),
+		ClientName:    formatClientName(client),
+		ClientRNC:     stringValue(inv.ClientRNC),
+		ClientEmail:   client.Email,
+		ClientPhone:   client.PhoneNumber,
+		Gross:         inv.Gross(),
+		Discount:      inv.DiscountAmount,
+		Subtotal:      inv.Subtotal,
+		TaxRate:       inv.TaxRate,
+		Tax:           inv.TaxAmount,
+		TaxIncluded:   inv.TaxIncluded,
+		Total:         inv.Total,
+		AmountPaid:    inv.AmountPaid,
+		Notes:         stringValue(inv.Notes),
+		Terms:         stringValue(inv.Terms),
+		GeneratedAt:   time.Now(),
+	}
+	if data.BusinessName == "" {
+		data.BusinessName = "RosaFiesta"
+	}
+	for _, l := range inv.Lines {
+		data.Lines = append(data.Lines, pdf.InvoiceLine{Description: l.Description, Quantity: l.Quantity, UnitPrice: l.UnitPrice, Total: l.Total})
+	}
+	if inv.EventID != nil {
+		if event, err := app.Store.Events.GetByID(ctx, *inv.EventID); err == nil {
+			data.EventName = event.Name
+			if event.Date != nil {
+				data.EventDate = event.Date.Format("02/01/2006")
+			}
+		}
+	}
+
+	pdfBytes, err := pdf.GenerateInvoicePDF(data)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s.pdf\"", inv.InvoiceNumber))
+	w.Write(pdfBytes)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
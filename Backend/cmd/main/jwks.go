@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+)
+
+// @Summary		JSON Web Key Set
+// @Description	Returns the public keys access tokens can be verified with
+// @Tags			authentication
+// @Produce		json
+// @Success		200	{object}	auth.JWKS
+// @Router			/.well-known/jwks.json [get]
+func (app *Application) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	// Verifiers refetch the set when they see an unknown kid, and upcoming
+	// keys are published before they sign anything, so an hour is safe.
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if err := writeJson(w, http.StatusOK, app.Auth.JWKS()); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
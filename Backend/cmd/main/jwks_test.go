@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/auth"
+	authMocks "Backend/internal/auth/mocks"
+)
+
+func TestJWKS(t *testing.T) {
+	app := newTestApplication(t, configModels.Config{})
+	keys := auth.JWKS{Keys: []auth.JWK{{KeyType: "OKP", KeyID: "2026-10-01", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: "abc"}}}
+	app.Auth.(*authMocks.Authenticator).On("JWKS").Return(keys)
+
+	req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rr := executeRequest(req, app.Mount())
+	checkResponseCode(t, http.StatusOK, rr)
+
+	var got auth.JWKS
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Keys) != 1 || got.Keys[0].KeyID != "2026-10-01" {
+		t.Fatalf("unexpected key set %+v", got)
+	}
+}
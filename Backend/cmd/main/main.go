@@ -14,6 +14,7 @@ import (
 	"Backend/internal/env"
 	"Backend/internal/mailer"
 	"Backend/internal/notifications"
+	"Backend/internal/paypal"
 	"Backend/internal/ratelimiter"
 	"Backend/internal/store"
 	"Backend/internal/whatsapp"
@@ -84,15 +85,20 @@ func main() {
 				Pass: env.GetString("BASIC_AUTH_PASS", "admin"),
 			},
 			Token: configModels.TokenConfig{
-				Secret: env.GetString("JWT_TOKEN_SECRET", "example"),
-				Exp:    time.Hour * 24 * 7,
-				Aud:    env.GetString("JWT_TOKEN_AUD", "gophersocial"),
-				Iss:    env.GetString("JWT_TOKEN_ISS", "gophersocial"),
+				Secret:  env.GetString("JWT_TOKEN_SECRET", "example"),
+				KeysDir: env.GetString("JWT_KEYS_DIR", ""),
+				Exp:     time.Hour * 24 * 7,
+				Aud:     env.GetString("JWT_TOKEN_AUD", "gophersocial"),
+				Iss:     env.GetString("JWT_TOKEN_ISS", "gophersocial"),
 			},
 			ApiKey: configModels.ApiKeyConfig{
 				Header: env.GetString("API_KEY_HEADER", "X-Api-Key"),
 				Value:  env.GetString("API_KEY_VALUE", "your-default-key"),
 			},
+			TwoFactor: configModels.TwoFactorConfig{
+				Issuer:           env.GetString("TWO_FACTOR_ISSUER", "Rosa Fiesta"),
+				RequiredForStaff: env.GetBool("TWO_FACTOR_REQUIRED_FOR_STAFF", false),
+			},
 		},
 		Redis: configModels.RedisConfig{
 			Addr:    env.GetString("REDIS_ADDR", "xd"),
@@ -111,6 +117,30 @@ func main() {
 			SecretKey: env.GetString("R2_SECRET_KEY", ""),
 			Bucket:    env.GetString("R2_BUCKET", "rosafiesta"),
 		},
+		WhatsApp: configModels.WhatsAppConfig{
+			PhoneNumberID: env.GetString("WHATSAPP_PHONE_NUMBER_ID", ""),
+			AccessToken:   env.GetString("WHATSAPP_ACCESS_TOKEN", ""),
+			FromName:      env.GetString("WHATSAPP_FROM_NAME", "Rosa Fiesta"),
+			VerifyToken:   env.GetString("WHATSAPP_VERIFY_TOKEN", ""),
+			AppSecret:     env.GetString("WHATSAPP_APP_SECRET", ""),
+		},
+		PayPal: configModels.PayPalConfig{
+			BaseURL:   env.GetString("PAYPAL_BASE_URL", paypal.SandboxBaseURL),
+			ClientID:  env.GetString("PAYPAL_CLIENT_ID", ""),
+			Secret:    env.GetString("PAYPAL_SECRET", ""),
+			WebhookID: env.GetString("PAYPAL_WEBHOOK_ID", ""),
+			Currency:  env.GetString("PAYPAL_CURRENCY", "USD"),
+		},
+		Delivery: configModels.DeliveryConfig{
+			// Defaults to San Cristóbal, where the warehouse is.
+			OriginLat: env.GetFloat("DELIVERY_ORIGIN_LAT", 18.4167),
+			OriginLng: env.GetFloat("DELIVERY_ORIGIN_LNG", -70.1056),
+		},
+		Fiscal: configModels.FiscalConfig{
+			BusinessName: env.GetString("FISCAL_BUSINESS_NAME", "RosaFiesta"),
+			RNC:          env.GetString("FISCAL_RNC", ""),
+			Address:      env.GetString("FISCAL_ADDRESS", "San Cristóbal, República Dominicana"),
+		},
 	}
 
 	logger := zap.Must(zap.NewProduction()).Sugar()
@@ -140,28 +170,35 @@ func main() {
 
 	rateLimiter := ratelimiter.NewFixedWindowRateLimiter(cfg.RateLimiter.RequestsPerTimeFrame, cfg.RateLimiter.TimeFrame)
 
-	/*mailTrap, err := mailer.NewMailTrapClient(cfg.Mail.MailTrap.ApiKey, cfg.Mail.MailTrap.FromEmail)
+	/*mailTrap, err := mailer.NewMailTrapClient(cfg.Mail.MailTrap.ApiKey, cfg.Mail.MailTrap.FromEmail, appStore.EmailTemplates)
 	if err != nil {
 		logger.Fatal(err)
 	}*/
 
-	mailGo, err := mailer.NewGoMailClient(cfg.Mail.Password, cfg.Mail.FromEmail)
+	mailGo, err := mailer.NewGoMailClient(cfg.Mail.Password, cfg.Mail.FromEmail, appStore.EmailTemplates)
 
-	jwtAuthenticator := auth.NewJWTAuthenticator(cfg.Auth.Token.Secret, cfg.Auth.Token.Aud, cfg.Auth.Token.Iss)
+	var jwtAuthenticator auth.Authenticator
+	if cfg.Auth.Token.KeysDir != "" {
+		keys, err := auth.LoadSigningKeys(cfg.Auth.Token.KeysDir)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		jwtAuthenticator, err = auth.NewKeySetAuthenticator(keys, cfg.Auth.Token.Aud, cfg.Auth.Token.Iss, cfg.Auth.Token.Exp)
+		if err != nil {
+			logger.Fatal(err)
+		}
+	} else {
+		logger.Warn("JWT_KEYS_DIR is not set, signing tokens with the shared HS256 secret")
+		jwtAuthenticator = auth.NewJWTAuthenticator(cfg.Auth.Token.Secret, cfg.Auth.Token.Aud, cfg.Auth.Token.Iss)
+	}
 	notificationService, _ := notifications.NewNotificationService()
 
 	chatHub := newHub()
 	go chatHub.run()
 
-	delayChecker := worker.NewDelayChecker(appStore, logger, notificationService)
-	go delayChecker.Start(context.Background())
-
-	emailSender := worker.NewEmailSender(appStore, logger, mailGo)
-	go emailSender.Start(context.Background(), 30*time.Minute)
-
-	if notificationService != nil {
-		notificationSender := worker.NewNotificationSender(appStore, logger, notificationService)
-		go notificationSender.Start(context.Background(), 30*time.Minute)
+	store.HoldTTL = time.Duration(env.GetInt("INVENTORY_HOLD_TTL_HOURS", 72)) * time.Hour
+	if rentals := env.GetInt("DEPRECIATION_RENTALS", 50); rentals > 0 {
+		store.DepreciationRentals = rentals
 	}
 
 	var r2Client *store.R2Client
@@ -185,10 +222,47 @@ func main() {
 			PhoneNumberID: cfg.WhatsApp.PhoneNumberID,
 			AccessToken:   cfg.WhatsApp.AccessToken,
 			FromName:     cfg.WhatsApp.FromName,
+			VerifyToken:   cfg.WhatsApp.VerifyToken,
+			AppSecret:     cfg.WhatsApp.AppSecret,
 		})
 		logger.Info("WhatsApp client initialized")
 	}
 
+	// Background work runs through the job queue, which Application.run
+	// starts and drains on shutdown.
+	queue := worker.NewQueue(appStore, logger, worker.QueueConfig{
+		Concurrency:  env.GetInt("JOB_QUEUE_CONCURRENCY", 2),
+		PollInterval: time.Duration(env.GetInt("JOB_QUEUE_POLL_SECONDS", 5)) * time.Second,
+		Lease:        time.Duration(env.GetInt("JOB_QUEUE_LEASE_MINUTES", 10)) * time.Minute,
+	})
+
+	delayChecker := worker.NewDelayChecker(appStore, logger, notificationService)
+	queue.Register(worker.DelayCheckJob, delayChecker.Run)
+	queue.Schedule(worker.DelayCheckJob, 5*time.Minute)
+
+	holdExpirer := worker.NewHoldExpirer(appStore, logger)
+	queue.Register(worker.HoldExpireJob, holdExpirer.Run)
+	queue.Schedule(worker.HoldExpireJob, 15*time.Minute)
+
+	triggerScheduler := worker.NewTriggerScheduler(appStore, logger, mailGo, whatsappClient, notificationService, cfg.FrontendURL)
+	queue.Register(worker.TriggerEvaluateJob, triggerScheduler.Run)
+	queue.Schedule(worker.TriggerEvaluateJob, 15*time.Minute)
+
+	var paypalClient *paypal.Client
+	if cfg.PayPal.ClientID != "" && cfg.PayPal.Secret != "" {
+		paypalClient, err = paypal.NewClient(paypal.Config{
+			BaseURL:   cfg.PayPal.BaseURL,
+			ClientID:  cfg.PayPal.ClientID,
+			Secret:    cfg.PayPal.Secret,
+			WebhookID: cfg.PayPal.WebhookID,
+		})
+		if err != nil {
+			logger.Warnf("failed to initialize PayPal client: %v", err)
+		} else {
+			logger.Infow("PayPal client initialized", "base_url", cfg.PayPal.BaseURL)
+		}
+	}
+
 	app := &Application{
 		Config:        cfg,
 		Store:         appStore,
@@ -201,7 +275,9 @@ func main() {
 		ChatHub:       chatHub,
 		R2:            r2Client,
 		WhatsApp:      whatsappClient,
+		PayPal:        paypalClient,
 		Redis:         rdb,
+		Queue:         queue,
 	}
 
 	expvar.NewString("version").Set(Version)
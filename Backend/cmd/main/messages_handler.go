@@ -1,13 +1,16 @@
 package main
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"Backend/internal/store/models"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 )
 
 func (app *Application) getMessagesHandler(w http.ResponseWriter, r *http.Request) {
@@ -70,6 +73,10 @@ func (app *Application) wsHandler(w http.ResponseWriter, r *http.Request) {
 		app.badRequest(w, r, err)
 		return
 	}
+	if eventID == whatsAppInboxRoom {
+		app.badRequest(w, r, errors.New("invalid event id"))
+		return
+	}
 
 	// Upgrade the connection
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -78,40 +85,70 @@ func (app *Application) wsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := app.wsAuthenticate(conn, r)
+	if !ok {
+		return
+	}
+
+	client := &Client{
+		hub:     app.ChatHub,
+		conn:    conn,
+		send:    make(chan interface{}, 256),
+		eventID: eventID,
+		userID:  userID,
+	}
+	client.hub.register <- client
+
+	// Start read/write pumps
+	go client.writePump()
+	go client.readPump()
+}
+
+// wsAuthenticate validates the token query parameter of a websocket
+// connection and returns the user it belongs to. On failure it reports the
+// error to the peer and closes the connection.
+func (app *Application) wsAuthenticate(conn *websocket.Conn, r *http.Request) (uuid.UUID, bool) {
 	// Authenticate user via token in query param
 	tokenStr := r.URL.Query().Get("token")
 	if tokenStr == "" {
 		conn.WriteJSON(map[string]string{"error": "authorization token required"})
 		conn.Close()
-		return
+		return uuid.Nil, false
 	}
 
 	token, err := app.Auth.ValidateToken(tokenStr)
 	if err != nil {
 		conn.WriteJSON(map[string]string{"error": "invalid token"})
 		conn.Close()
-		return
+		return uuid.Nil, false
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 		conn.WriteJSON(map[string]string{"error": "failed to parse claims"})
 		conn.Close()
-		return
+		return uuid.Nil, false
 	}
-	userIDStr := claims["sub"].(string)
-	userID, _ := uuid.Parse(userIDStr)
-
-	client := &Client{
-		hub:     app.ChatHub,
-		conn:    conn,
-		send:    make(chan interface{}, 256),
-		eventID: eventID,
-		userID:  userID,
+	userIDStr, _ := claims["sub"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		conn.WriteJSON(map[string]string{"error": "invalid token subject"})
+		conn.Close()
+		return uuid.Nil, false
 	}
-	client.hub.register <- client
 
-	// Start read/write pumps
-	go client.writePump()
-	go client.readPump()
+	user, err := app.GetUser(r.Context(), userID)
+	if err != nil || user.Blocked(time.Now()) || tokenVersion(claims) != user.TokenVersion {
+		conn.WriteJSON(map[string]string{"error": "invalid token"})
+		conn.Close()
+		return uuid.Nil, false
+	}
+	if sid := sessionID(claims); sid != uuid.Nil {
+		if exists, err := app.Store.RefreshTokens.SessionExists(r.Context(), sid); err != nil || !exists {
+			conn.WriteJSON(map[string]string{"error": "invalid token"})
+			conn.Close()
+			return uuid.Nil, false
+		}
+	}
+	return userID, true
 }
@@ -36,7 +36,7 @@ func TestGetMessages(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "admin", Level: 5}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "admin"}}, nil).Maybe()
 
 				msgM := app.Store.Messages.(*storeMocks.MessagesStore)
 				msgM.On("GetByEventID", mock.Anything, eventID).Return([]models.EventMessage{
@@ -56,7 +56,7 @@ func TestGetMessages(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "admin", Level: 5}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "admin"}}, nil).Maybe()
 
 				msgM := app.Store.Messages.(*storeMocks.MessagesStore)
 				msgM.On("GetByEventID", mock.Anything, eventID).Return([]models.EventMessage{}, nil).Once()
@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"Backend/internal/store/models"
 
@@ -13,78 +16,117 @@ import (
 	"github.com/google/uuid"
 )
 
-func (app *Application) CheckRole(role string, next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		user := GetUserFromCtx(r)
+var (
+	errAccountBlocked = errors.New("this account has been blocked")
+	errTokenRevoked   = errors.New("token has been revoked")
+)
 
-		allowed, err := app.checkRolePrecedence(r.Context(), user, role)
-		if err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
+var errNoPermission = errors.New("you do not have permission to access this resource")
 
-		if !allowed {
-			app.forbidden(w, r, fmt.Errorf("you do not have permission to access this resource"))
-			return
-		}
+// PermissionsCtx caches the permissions of the request's user.
+const PermissionsCtx userKey = "permissions"
 
-		next(w, r)
+// userPermissions returns the permissions granted to the request's user,
+// and the request with them cached for later checks.
+func (app *Application) userPermissions(r *http.Request) ([]string, *http.Request, error) {
+	if permissions, ok := r.Context().Value(PermissionsCtx).([]string); ok {
+		return permissions, r, nil
 	}
+
+	user := GetUserFromCtx(r)
+	if user == nil || user.Role.ID == uuid.Nil {
+		return nil, r, nil
+	}
+	permissions, err := app.Store.Roles.Permissions(r.Context(), user.Role.ID)
+	if err != nil {
+		return nil, r, err
+	}
+	return permissions, r.WithContext(context.WithValue(r.Context(), PermissionsCtx, permissions)), nil
 }
 
-func (app *Application) CheckPostOwnerShip(role string, next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		user := GetUserFromCtx(r)
-		post := GetPostFromCtx(r)
+// hasPermission reports whether the request's user was granted the
+// permission.
+func (app *Application) hasPermission(r *http.Request, permission string) (bool, error) {
+	permissions, _, err := app.userPermissions(r)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(permissions, permission), nil
+}
 
-		if post.UserID == user.ID {
-			next(w, r)
-			return
-		}
+// RequirePermission lets the request through only if the user was granted
+// every one of the permissions.
+func (app *Application) RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			granted, r, err := app.userPermissions(r)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			for _, permission := range permissions {
+				if !slices.Contains(granted, permission) {
+					app.forbidden(w, r, errNoPermission)
+					return
+				}
+			}
+			if !app.checkStaffTwoFactor(w, r) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-		allowed, err := app.checkRolePrecedence(r.Context(), user, role)
+// StaffMiddleware lets the request through only if the user was granted
+// any permission at all, keeping clients out of the admin app.
+func (app *Application) StaffMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		granted, r, err := app.userPermissions(r)
 		if err != nil {
 			app.internalServerError(w, r, err)
 			return
 		}
-
-		if !allowed {
-			app.forbidden(w, r, fmt.Errorf("you do not have permission to access this resource"))
+		if len(granted) == 0 {
+			app.forbidden(w, r, errNoPermission)
+			return
+		}
+		if !app.checkStaffTwoFactor(w, r) {
 			return
 		}
-
 		next.ServeHTTP(w, r)
-	}
+	})
 }
 
-func (app *Application) checkRolePrecedence(ctx context.Context, user *models.User, roleName string) (bool, error) {
-	if user == nil || user.Role.Name == "" {
-		return false, nil
+// checkStaffTwoFactor refuses the request of a staff user who hasn't
+// enabled two-factor authentication when the policy requires it, and
+// reports whether the request may go on.
+func (app *Application) checkStaffTwoFactor(w http.ResponseWriter, r *http.Request) bool {
+	if !app.Config.Auth.TwoFactor.RequiredForStaff {
+		return true
 	}
-
-	role, err := app.Store.Roles.RetrieveByName(ctx, roleName)
-	if err != nil {
-		return false, err
+	user := GetUserFromCtx(r)
+	// The API key's virtual user isn't a person and has no second factor.
+	if user.TwoFactorEnabled || user.ID == uuid.Nil {
+		return true
 	}
-
-	return role.Level <= user.Role.Level, nil
+	app.forbidden(w, r, errTwoFactorRequired)
+	return false
 }
 
-func (app *Application) RoleMiddleware(roles ...string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			user := GetUserFromCtx(r)
+// CheckPostOwnerShip lets the post's author through, and anyone else
+// granted the permission.
+func (app *Application) CheckPostOwnerShip(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := GetUserFromCtx(r)
+		post := GetPostFromCtx(r)
 
-			for _, role := range roles {
-				allowed, err := app.checkRolePrecedence(r.Context(), user, role)
-				if err != nil || !allowed {
-					app.forbidden(w, r, fmt.Errorf("you do not have permission to access this resource"))
-					return
-				}
-			}
+		if post.UserID == user.ID {
+			next(w, r)
+			return
+		}
 
-			next.ServeHTTP(w, r)
-		})
+		app.RequirePermission(permission)(next).ServeHTTP(w, r)
 	}
 }
 
@@ -118,21 +160,22 @@ func (app *Application) APIKeyMiddleware() func(http.Handler) http.Handler {
 				return
 			}
 
-			// Provide a virtual user for auditing purposes
+			// Provide a virtual user for auditing purposes, trusted with
+			// every permission
 			virtualUser := &models.User{
 				UserName: "API-Key-User",
 				Role: models.Role{
-					Name:  "admin",
-					Level: 5, // Admin level (highest)
+					Name: models.RoleAdmin,
 				},
 			}
 			ctx := context.WithValue(r.Context(), UserCtx, virtualUser)
+			ctx = context.WithValue(ctx, PermissionsCtx, models.PermissionNames())
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-func (app *Application) AuthTokenMiddleware(roles ...string) func(http.Handler) http.Handler {
+func (app *Application) AuthTokenMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var token string
@@ -182,15 +225,33 @@ func (app *Application) AuthTokenMiddleware(roles ...string) func(http.Handler)
 				return
 			}
 
-			for _, role := range roles {
-				allowed, err := app.checkRolePrecedence(ctx, user, role)
-				if err != nil || !allowed {
-					app.forbidden(w, r, fmt.Errorf("you do not have permission to access this resource"))
+			if user.Blocked(time.Now()) {
+				app.forbidden(w, r, errAccountBlocked)
+				return
+			}
+			if tokenVersion(claims) != user.TokenVersion {
+				app.unauthorized(w, r, errTokenRevoked)
+				return
+			}
+			// Signing out of a session also ends the access tokens issued
+			// to it.
+			sid := sessionID(claims)
+			if sid != uuid.Nil {
+				exists, err := app.Store.RefreshTokens.SessionExists(ctx, sid)
+				if err != nil {
+					app.internalServerError(w, r, err)
+					return
+				}
+				if !exists {
+					app.unauthorized(w, r, errTokenRevoked)
 					return
 				}
 			}
 
 			ctx = context.WithValue(r.Context(), UserCtx, user)
+			if sid != uuid.Nil {
+				ctx = context.WithValue(ctx, SessionCtx, sid)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -266,6 +327,22 @@ func (app *Application) GetUser(ctx context.Context, userID uuid.UUID) (*models.
 	return user, nil
 }
 
+// evictUser drops the cached copy of the user after a change that must
+// take effect on their next request, such as a block or revoked tokens.
+func (app *Application) evictUser(ctx context.Context, userID uuid.UUID) error {
+	if !app.Config.Redis.Enabled {
+		return nil
+	}
+	return app.CacheStorage.Users.Delete(ctx, userID)
+}
+
+// tokenVersion is the token version an access token was issued at. Tokens
+// issued before versions existed have none and count as version 0.
+func tokenVersion(claims jwt.MapClaims) int {
+	ver, _ := claims["ver"].(float64)
+	return int(ver)
+}
+
 func (app *Application) RateLimiterMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if app.Config.RateLimiter.Enabled {
@@ -2,11 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
+	"Backend/internal/store"
 	"Backend/internal/store/models"
 
 	"github.com/go-chi/chi/v5"
@@ -18,18 +19,24 @@ func (app *Application) MountLeadsRoutes() http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(app.AuthTokenMiddleware())
-	r.Use(app.RoleMiddleware("admin"))
-
-	r.Get("/", app.getLeadsHandler)
-	r.Get("/stats", app.getLeadsStatsHandler)
-	r.Get("/{id}", app.getLeadByIDHandler)
-	r.Post("/", app.createLeadHandler)
-	r.Patch("/{id}/status", app.updateLeadStatusHandler)
-	r.Post("/{id}/followups", app.addLeadFollowupHandler)
-	r.Get("/{id}/followups", app.getLeadFollowupsHandler)
-	r.Patch("/followups/{followupId}/complete", app.completeFollowupHandler)
-	r.Get("/{id}/activities", app.getLeadActivitiesHandler)
-	r.Get("/overdue-followups", app.getOverdueFollowupsHandler)
+
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermLeadsRead))
+		r.Get("/", app.getLeadsHandler)
+		r.Get("/stats", app.getLeadsStatsHandler)
+		r.Get("/{id}", app.getLeadByIDHandler)
+		r.Get("/{id}/followups", app.getLeadFollowupsHandler)
+		r.Get("/{id}/activities", app.getLeadActivitiesHandler)
+		r.Get("/overdue-followups", app.getOverdueFollowupsHandler)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(app.RequirePermission(models.PermLeadsWrite))
+		r.Post("/", app.createLeadHandler)
+		r.Patch("/{id}/status", app.updateLeadStatusHandler)
+		r.Post("/{id}/followups", app.addLeadFollowupHandler)
+		r.Patch("/followups/{followupId}/complete", app.completeFollowupHandler)
+	})
 
 	return r
 }
@@ -49,32 +56,12 @@ func (app *Application) MountAvailabilityRoutes() http.Handler {
 	return r
 }
 
-func (app *Application) MountChatbotRoutes() http.Handler {
-	r := chi.NewRouter()
-
-	r.Group(func(r chi.Router) {
-		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
-		r.Post("/faqs", app.createFAQHandler)
-		r.Put("/faqs/{id}", app.updateFAQHandler)
-		r.Delete("/faqs/{id}", app.deleteFAQHandler)
-	})
-
-	r.Get("/faqs", app.getFAQsHandler)
-	r.Get("/faqs/category/{category}", app.getFAQsByCategoryHandler)
-	r.Post("/message", app.handleChatbotMessageHandler)
-	r.Get("/conversations", app.getChatbotConversationsHandler)
-	r.Patch("/conversations/{id}/feedback", app.provideChatbotFeedbackHandler)
-
-	return r
-}
-
 func (app *Application) MountReviewsRoutes() http.Handler {
 	r := chi.NewRouter()
 
 	r.Group(func(r chi.Router) {
 		r.Use(app.AuthTokenMiddleware())
-		r.Use(app.RoleMiddleware("admin"))
+		r.Use(app.RequirePermission(models.PermContentModerate))
 		r.Patch("/{id}/verify", app.verifyReviewHandler)
 	})
 
@@ -385,6 +372,8 @@ func (app *Application) reserveInventoryHandler(w http.ResponseWriter, r *http.R
 		ArticleID string `json:"article_id"`
 		EventID   string `json:"event_id"`
 		Date      string `json:"date"`
+		PickupAt  string `json:"pickup_at"`
+		ReturnAt  string `json:"return_at"`
 		Quantity  int    `json:"quantity"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -392,12 +381,48 @@ func (app *Application) reserveInventoryHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	articleID, _ := uuid.Parse(req.ArticleID)
-	eventID, _ := uuid.Parse(req.EventID)
-	date, _ := time.Parse("2006-01-02", req.Date)
+	articleID, err := uuid.Parse(req.ArticleID)
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid article id"))
+		return
+	}
+	eventID, err := uuid.Parse(req.EventID)
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid event id"))
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		app.badRequest(w, r, errors.New("invalid date format"))
+		return
+	}
+	if req.Quantity <= 0 {
+		app.badRequest(w, r, errors.New("quantity must be greater than zero"))
+		return
+	}
 
-	if err := app.Store.Availability.ReserveInventory(r.Context(), articleID, eventID, date, req.Quantity); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
+	// Without explicit times the rental spans the day before through the
+	// day after the event.
+	window := store.DefaultRentalWindow(date)
+	if req.PickupAt != "" {
+		if window.PickupAt, err = time.Parse(time.RFC3339, req.PickupAt); err != nil {
+			app.badRequest(w, r, errors.New("invalid pickup_at, expected RFC3339"))
+			return
+		}
+	}
+	if req.ReturnAt != "" {
+		if window.ReturnAt, err = time.Parse(time.RFC3339, req.ReturnAt); err != nil {
+			app.badRequest(w, r, errors.New("invalid return_at, expected RFC3339"))
+			return
+		}
+	}
+	if err := window.Validate(); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	if err := app.Store.Availability.ReserveInventory(r.Context(), articleID, eventID, window, req.Quantity); err != nil {
+		app.handleError(w, r, err)
 		return
 	}
 	render.JSON(w, r, map[string]interface{}{"data": map[string]string{"status": "reserved"}})
@@ -447,108 +472,6 @@ func (app *Application) releaseInventoryHandler(w http.ResponseWriter, r *http.R
 	render.JSON(w, r, map[string]interface{}{"data": map[string]string{"status": "released"}})
 }
 
-// Chatbot Handlers
-
-type FAQ struct {
-	ID       string `json:"id"`
-	Keyword  string `json:"keyword"`
-	Question string `json:"question"`
-	Answer   string `json:"answer"`
-	Category string `json:"category"`
-	Priority int    `json:"priority"`
-}
-
-func (app *Application) getFAQsHandler(w http.ResponseWriter, r *http.Request) {
-	faqs := []FAQ{
-		{ID: "1", Keyword: "precio", Question: "¿Cuánto cuesta?", Answer: "Nuestros precios varían según el tipo de evento. Contáctanos para una cotización personalizada.", Category: "precios", Priority: 10},
-		{ID: "2", Keyword: "disponibilidad", Question: "¿Está disponible?", Answer: "Para verificar disponibilidad,我们需要 saber la fecha de tu evento. ¿Cuándo es?", Category: "reservas", Priority: 9},
-		{ID: "3", Keyword: "alquiler", Question: "¿Cómo funciona el alquiler?", Answer: "El alquiler incluye entrega, montaje y recogida. El período típico es de 1-3 días.", Category: "servicios", Priority: 8},
-		{ID: "4", Keyword: "boda", Question: "婚礼服务", Answer: "Sí, ofrecemos paquetes especiales para bodas. Tenemos decoración completa, mesas, sillas, iluminación y más.", Category: "eventos", Priority: 7},
-	}
-	render.JSON(w, r, map[string]interface{}{"data": faqs})
-}
-
-func (app *Application) getFAQsByCategoryHandler(w http.ResponseWriter, r *http.Request) {
-	category := chi.URLParam(r, "category")
-	faqs := []FAQ{
-		{ID: "1", Keyword: "precio", Question: "¿Cuánto cuesta?", Answer: "Precios según tipo de evento.", Category: category},
-	}
-	render.JSON(w, r, map[string]interface{}{"data": faqs})
-}
-
-func (app *Application) handleChatbotMessageHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Message    string `json:"message"`
-		Phone      string `json:"phone"`
-		ClientName string `json:"client_name"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-
-	response := "Gracias por tu mensaje. Un asesor se pondrá en contacto pronto."
-
-	lowerMsg := req.Message
-	if strings.Contains(lowerMsg, "precio") || strings.Contains(lowerMsg, "costo") || strings.Contains(lowerMsg, "cuánto") {
-		response = "¡Hola! Para darte un precio exacto necesitamos saber: ¿Qué tipo de evento? ¿Cuántos invitados? ¿Qué fecha?"
-	} else if strings.Contains(lowerMsg, "disponible") || strings.Contains(lowerMsg, "disponibilidad") {
-		response = "Para verificar disponibilidad necesitamos la fecha de tu evento. ¿Cuándo es?"
-	} else if strings.Contains(lowerMsg, "boda") {
-		response = "¡Tenemos paquetes especiales para bodas! Incluye decoración, mesas, sillas e iluminación. ¿Te gustaría una cotización?"
-	} else if strings.Contains(lowerMsg, "cumpleaños") || strings.Contains(lowerMsg, "fiesta") {
-		response = "¡Celebramos tu especiales! Tenemos opciones para cumpleaños y fiestas. ¿Cuántos invitados esperas?"
-	} else if strings.Contains(lowerMsg, "gracias") {
-		response = "¡Gracias a ti! Estamos aquí para ayudarte. ¿Hay algo más en lo que podamos asistirte?"
-	}
-
-	render.JSON(w, r, map[string]interface{}{
-		"data": map[string]interface{}{
-			"response":    response,
-			"was_helpful": nil,
-		},
-	})
-}
-
-func (app *Application) getChatbotConversationsHandler(w http.ResponseWriter, r *http.Request) {
-	render.JSON(w, r, map[string]interface{}{"data": []interface{}{}})
-}
-
-func (app *Application) provideChatbotFeedbackHandler(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	var req struct {
-		WasHelpful bool `json:"was_helpful"`
-	}
-	json.NewDecoder(r.Body).Decode(&req)
-	render.JSON(w, r, map[string]interface{}{"data": map[string]interface{}{"id": id, "was_helpful": req.WasHelpful}})
-}
-
-func (app *Application) createFAQHandler(w http.ResponseWriter, r *http.Request) {
-	var faq FAQ
-	if err := json.NewDecoder(r.Body).Decode(&faq); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-	faq.ID = uuid.New().String()
-	render.JSON(w, r, map[string]interface{}{"data": faq})
-}
-
-func (app *Application) updateFAQHandler(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	var faq FAQ
-	if err := json.NewDecoder(r.Body).Decode(&faq); err != nil {
-		render.JSON(w, r, map[string]interface{}{"error": err.Error()})
-		return
-	}
-	faq.ID = id
-	render.JSON(w, r, map[string]interface{}{"data": faq})
-}
-
-func (app *Application) deleteFAQHandler(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	render.JSON(w, r, map[string]interface{}{"data": map[string]string{"deleted": id}})
-}
-
 // Reviews Handlers
 
 type VerifiedReview struct {
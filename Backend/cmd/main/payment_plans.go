@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	errNothingToPay        = errors.New("nothing is left to pay for this event")
+	errInstallmentSettled  = errors.New("installment is already paid")
+	errUnknownPaymentScope = errors.New("unknown payment purpose")
+)
+
+// ensurePaymentSchedule returns the event's installments, generating them
+// from its payment plan first if the event is confirmed and has none yet.
+// Quotes are scheduled on approval; this covers events confirmed some other
+// way or before plans existed.
+func (app *Application) ensurePaymentSchedule(ctx context.Context, event *models.Event) ([]models.InstallmentPayment, error) {
+	installments, err := app.Store.Installments.GetInstallmentByEventID(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range installments {
+		if inst.Purpose == models.InstallmentPurposeInstallment {
+			return installments, nil
+		}
+	}
+	if event.Status != models.EventStatusConfirmed {
+		return installments, nil
+	}
+	return app.schedulePayments(ctx, event)
+}
+
+// planPayments splits the event's quote into installments following its
+// payment plan, returning the quote total and the schedule.
+func (app *Application) planPayments(ctx context.Context, event *models.Event) (int, []models.ScheduledInstallment, error) {
+	total, err := app.eventQuoteTotal(ctx, event)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	plan, err := app.Store.PaymentPlans.ForEvent(ctx, event.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		plan = &models.FallbackPaymentPlan
+	} else if err != nil {
+		return 0, nil, err
+	}
+	return total, plan.Schedule(total, time.Now(), event.Date), nil
+}
+
+// schedulePayments saves the installments planPayments splits the event's
+// quote into, along with the event's payment fields.
+func (app *Application) schedulePayments(ctx context.Context, event *models.Event) ([]models.InstallmentPayment, error) {
+	total, schedule, err := app.planPayments(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	installments, err := app.Store.Installments.CreateSchedule(ctx, event.ID, schedule)
+	if errors.Is(err, store.ErrConflict) {
+		// Scheduled concurrently.
+		return app.Store.Installments.GetInstallmentByEventID(ctx, event.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event.TotalQuote = total
+	event.ApplySchedule(installments)
+	if err := app.Store.Events.Update(ctx, event); err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// eventQuoteTotal is the quote the client approved, in whole pesos. An
+// admin may have set it explicitly; otherwise it is worked out from the
+// event's items the same way the quote PDF does.
+func (app *Application) eventQuoteTotal(ctx context.Context, event *models.Event) (int, error) {
+	if event.TotalQuote > 0 {
+		return event.TotalQuote, nil
+	}
+	items, err := app.Store.Events.GetItems(ctx, event.ID)
+	if err != nil {
+		return 0, err
+	}
+	var subtotal float64
+	for _, item := range items {
+		subtotal += item.UnitPrice() * float64(item.Quantity)
+	}
+	return int(math.Round(event.QuoteTotal(subtotal))), nil
+}
+
+// installmentsToPay picks the installments a payment covers: the first one
+// for a deposit, the given one for an installment, or everything still owed
+// for a full payment.
+func installmentsToPay(installments []models.InstallmentPayment, purpose string, installmentID *uuid.UUID) ([]models.InstallmentPayment, error) {
+	var owed []models.InstallmentPayment
+	var first *models.InstallmentPayment
+	for i := range installments {
+		inst := installments[i]
+		if inst.Purpose != models.InstallmentPurposeInstallment {
+			continue
+		}
+		if first == nil {
+			first = &installments[i]
+		}
+		if inst.Outstanding() {
+			owed = append(owed, inst)
+		}
+	}
+
+	switch purpose {
+	case models.InstallmentPurposeDeposit:
+		if first == nil {
+			return nil, errNothingToPay
+		}
+		if !first.Outstanding() {
+			return nil, errDepositAlreadyPaid
+		}
+		return []models.InstallmentPayment{*first}, nil
+	case models.InstallmentPurposeInstallment:
+		if installmentID == nil {
+			return nil, errors.New("installment_id is required to pay an installment")
+		}
+		for _, inst := range installments {
+			if inst.ID != *installmentID {
+				continue
+			}
+			if !inst.Outstanding() {
+				return nil, errInstallmentSettled
+			}
+			return []models.InstallmentPayment{inst}, nil
+		}
+		return nil, store.ErrNotFound
+	case models.InstallmentPurposeFull:
+		if len(owed) == 0 {
+			return nil, errNothingToPay
+		}
+		return owed, nil
+	}
+	return nil, errUnknownPaymentScope
+}
+
+func sumInstallments(installments []models.InstallmentPayment) int {
+	total := 0
+	for _, inst := range installments {
+		total += inst.Amount
+	}
+	return total
+}
+
+// adminListPaymentPlansHandler lists the payment plans, the default first.
+func (app *Application) adminListPaymentPlansHandler(w http.ResponseWriter, r *http.Request) {
+	plans, err := app.Store.PaymentPlans.List(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": plans})
+}
+
+func (app *Application) readPaymentPlanPayload(w http.ResponseWriter, r *http.Request) (*models.PaymentPlanPayload, bool) {
+	var payload models.PaymentPlanPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return nil, false
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return nil, false
+	}
+	if err := payload.Check(); err != nil {
+		app.badRequest(w, r, err)
+		return nil, false
+	}
+	return &payload, true
+}
+
+func (app *Application) adminCreatePaymentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	payload, ok := app.readPaymentPlanPayload(w, r)
+	if !ok {
+		return
+	}
+	plan, err := app.Store.PaymentPlans.Create(r.Context(), payload)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusCreated, map[string]interface{}{"data": plan})
+}
+
+// adminUpdatePaymentPlanHandler changes a plan for events scheduled from
+// now on; installments already generated keep their amounts and dates.
+func (app *Application) adminUpdatePaymentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	payload, ok := app.readPaymentPlanPayload(w, r)
+	if !ok {
+		return
+	}
+	plan, err := app.Store.PaymentPlans.Update(r.Context(), id, payload)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": plan})
+}
+
+func (app *Application) adminDeletePaymentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := app.Store.PaymentPlans.Delete(r.Context(), id); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminSetEventPaymentPlanHandler sets the plan of one event. It only
+// takes effect if the event's installments haven't been scheduled yet.
+func (app *Application) adminSetEventPaymentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	app.assignPaymentPlan(w, r, app.Store.PaymentPlans.AssignToEvent)
+}
+
+// adminSetEventTypePaymentPlanHandler sets the plan of every event of a
+// type that has no plan of its own.
+func (app *Application) adminSetEventTypePaymentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	app.assignPaymentPlan(w, r, app.Store.PaymentPlans.AssignToEventType)
+}
+
+func (app *Application) assignPaymentPlan(w http.ResponseWriter, r *http.Request, assign func(context.Context, uuid.UUID, *uuid.UUID) error) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	var payload models.AssignPaymentPlanPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := assign(r.Context(), id, payload.PlanID); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": payload})
+}
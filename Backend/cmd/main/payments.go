@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var errPaymentAwaitingVerification = errors.New("a payment for this event is already waiting for verification")
+
+// listPaymentMethodsHandler lists the methods clients can pay with, with
+// the instructions to follow for each.
+func (app *Application) listPaymentMethodsHandler(w http.ResponseWriter, r *http.Request) {
+	methods, err := app.Store.PaymentMethods.List(r.Context(), true)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, methods); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// enabledPaymentMethod returns the method if clients may currently pay
+// with it.
+func (app *Application) enabledPaymentMethod(ctx context.Context, code string) (*models.PaymentMethod, error) {
+	method, err := app.Store.PaymentMethods.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, store.ErrPaymentMethodUnavailable
+		}
+		return nil, err
+	}
+	if !method.Enabled {
+		return nil, store.ErrPaymentMethodUnavailable
+	}
+	return method, nil
+}
+
+// submitEventPayment pays the installments of a confirmed event that the
+// purpose covers (see installmentsToPay) with a method the client chose.
+// When the method settles them right away the event is updated and settled
+// is true; otherwise the payments wait for an admin to confirm them.
+func (app *Application) submitEventPayment(ctx context.Context, event *models.Event, purpose string, installmentID *uuid.UUID, method string) (payments []models.InstallmentPayment, settled bool, err error) {
+	installments, err := app.ensurePaymentSchedule(ctx, event)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, inst := range installments {
+		if inst.PaymentStatus == models.InstallmentStatusPendingVerification {
+			return nil, false, errPaymentAwaitingVerification
+		}
+	}
+
+	due, err := installmentsToPay(installments, purpose, installmentID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	settled = true
+	for _, inst := range due {
+		p, err := app.Store.Installments.MarkPaid(ctx, inst.ID, method)
+		if err != nil {
+			return nil, false, err
+		}
+		payments = append(payments, *p)
+		settled = settled && p.PaymentStatus == models.InstallmentStatusPaid
+	}
+	if !settled {
+		return payments, false, nil
+	}
+	return payments, true, app.settleEventPayment(ctx, event, method)
+}
+
+// settleEventPayment updates the event after some of its installments were
+// paid: the deposit and remaining amount follow the installments, and the
+// event is paid once nothing is left owing.
+func (app *Application) settleEventPayment(ctx context.Context, event *models.Event, method string) error {
+	installments, err := app.Store.Installments.GetInstallmentByEventID(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	event.ApplySchedule(installments)
+
+	now := time.Now()
+	event.PaymentMethod = &method
+	event.PaidAt = &now
+	switch {
+	case event.RemainingAmount == 0:
+		event.PaymentStatus = "completed"
+		event.Status = models.EventStatusPaid
+	case event.DepositPaid:
+		event.PaymentStatus = "deposit_paid"
+	}
+	return app.Store.Events.Update(ctx, event)
+}
+
+// adminConfirmPaymentHandler confirms that the money of a payment waiting
+// for verification arrived, and updates the event accordingly.
+func (app *Application) adminConfirmPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	admin := GetUserFromCtx(r)
+	payment, err := app.Store.Installments.ConfirmPayment(ctx, id, admin.ID)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	event, err := app.Store.Events.GetByID(ctx, payment.EventID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	method := ""
+	if payment.PaymentMethod != nil {
+		method = *payment.PaymentMethod
+	}
+	reason := "payment confirmed"
+	event.StatusChange = &models.EventStatusChange{ActorID: admin.ID, Reason: &reason}
+	if err := app.settleEventPayment(ctx, event, method); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &admin.ID,
+		EventID:    &event.ID,
+		Action:     models.AuditActionEventPay,
+		EntityType: "installment_payment",
+		EntityID:   &payment.ID,
+		NewValue:   &method,
+	})
+
+	if owner, err := app.Store.Users.RetrieveById(ctx, event.UserID); err == nil {
+		_ = app.Notifications.NotifyStatusChange(ctx, owner.FCMToken, event.Name, "Pago confirmado")
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+		"payment": payment,
+		"event":   event,
+	}})
+}
+
+// receiptContentTypes are the receipt images clients may upload.
+var receiptContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// uploadPaymentReceiptHandler godoc
+//
+//	@Summary		Upload a payment receipt
+//	@Description	Upload the receipt of a transfer against a deposit or installment. The payment waits for an admin to verify it.
+//	@Tags			events
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id				path		string	true	"Event ID"
+//	@Param			paymentId		path		string	true	"Installment payment ID"
+//	@Param			file			formData	file	true	"Receipt image"
+//	@Param			payment_method	formData	string	false	"Payment method code, transferencia by default"
+//	@Success		202				{object}	models.InstallmentPayment
+//	@Failure		400				{object}	error
+//	@Failure		403				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		503				{object}	error
+//	@Router			/events/{id}/payments/{paymentId}/receipt [post]
+func (app *Application) uploadPaymentReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	paymentID, err := uuid.Parse(chi.URLParam(r, "paymentId"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	event, err := app.Store.Events.GetByID(ctx, eventID)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	user := GetUserFromCtx(r)
+	if event.UserID != user.ID {
+		app.forbidden(w, r, errors.New("you do not have permission to pay for this event"))
+		return
+	}
+
+	payment, err := app.Store.Installments.GetByID(ctx, paymentID)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if payment.EventID != event.ID {
+		app.notFoundResponse(w, r, store.ErrNotFound)
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10MB max
+		app.badRequest(w, r, err)
+		return
+	}
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		app.badRequest(w, r, errors.New("file is required"))
+		return
+	}
+	defer file.Close()
+
+	method := r.FormValue("payment_method")
+	if method == "" {
+		method = "transferencia"
+	}
+	if _, err := app.enabledPaymentMethod(ctx, method); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	contentType := http.DetectContentType(content)
+	if !receiptContentTypes[contentType] {
+		app.badRequest(w, r, fmt.Errorf("receipt must be a JPEG, PNG or WebP image, got %s", contentType))
+		return
+	}
+
+	// An admin has to see the receipt to verify the payment, so without R2
+	// there is nowhere to keep it.
+	if app.R2 == nil {
+		app.serviceUnavailableResponse(w, r, errors.New("receipt storage is not configured"))
+		return
+	}
+	url, err := app.R2.UploadFromBytes(ctx, event.ID, "receipt-"+fileHeader.Filename, contentType, content)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	payment, err = app.Store.Installments.SubmitReceipt(ctx, payment.ID, method, url)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &user.ID,
+		EventID:    &event.ID,
+		Action:     models.AuditActionEventPay,
+		EntityType: "installment_payment",
+		EntityID:   &payment.ID,
+		NewValue:   &url,
+	})
+
+	if err := app.jsonResponse(w, http.StatusAccepted, payment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// adminListPaymentVerificationsHandler returns the payments waiting for an
+// admin to check the receipt.
+func (app *Application) adminListPaymentVerificationsHandler(w http.ResponseWriter, r *http.Request) {
+	queue, err := app.Store.Installments.ListAwaitingVerification(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": queue})
+}
+
+// adminRejectPaymentHandler turns down a payment whose money never arrived
+// or whose receipt doesn't match, telling the client why.
+func (app *Application) adminRejectPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	var payload models.RejectPaymentPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	admin := GetUserFromCtx(r)
+	payment, err := app.Store.Installments.RejectPayment(ctx, id, admin.ID, payload.Reason)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &admin.ID,
+		EventID:    &payment.EventID,
+		Action:     models.AuditActionEventPay,
+		EntityType: "installment_payment",
+		EntityID:   &payment.ID,
+		NewValue:   &payment.PaymentStatus,
+		Reason:     &payload.Reason,
+	})
+
+	if event, err := app.Store.Events.GetByID(ctx, payment.EventID); err == nil {
+		if owner, err := app.Store.Users.RetrieveById(ctx, event.UserID); err == nil {
+			_ = app.Notifications.SendPush(ctx, owner.FCMToken, "Pago rechazado",
+				fmt.Sprintf("No pudimos verificar tu pago de %s: %s", event.Name, payload.Reason))
+		}
+	}
+
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": payment})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUploadPaymentReceipt(t *testing.T) {
+	userID := uuid.New()
+	eventID := uuid.New()
+	paymentID := uuid.New()
+	otherID := uuid.New()
+
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		paymentID    uuid.UUID
+		filename     string
+		data         []byte
+		setupMocks   func(*Application)
+		expectedCode int
+	}{
+		{
+			name:         "should return 503 when receipt storage isn't configured",
+			paymentID:    paymentID,
+			filename:     "voucher.png",
+			data:         pngData.Bytes(),
+			expectedCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:         "should reject files that are not images",
+			paymentID:    paymentID,
+			filename:     "voucher.txt",
+			data:         []byte("transferencia realizada"),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:      "should return 404 for payments of other events",
+			paymentID: otherID,
+			filename:  "voucher.png",
+			data:      pngData.Bytes(),
+			setupMocks: func(app *Application) {
+				app.Store.Installments.(*storeMocks.InstallmentsStore).On("GetByID", mock.Anything, otherID).
+					Return(&models.InstallmentPayment{ID: otherID, EventID: uuid.New()}, nil)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticate(app, &models.User{ID: userID, Role: models.Role{Name: "buyer"}})
+			app.Store.AuditLogs.(*storeMocks.AuditLogsStore).On("Log", mock.Anything, mock.Anything).Return(nil)
+			app.Store.Events.(*storeMocks.EventStore).On("GetByID", mock.Anything, eventID).Return(&models.Event{
+				ID: eventID, UserID: userID, Name: "Boda", Status: models.EventStatusConfirmed, TotalQuote: 10000,
+			}, nil)
+			app.Store.PaymentMethods.(*storeMocks.PaymentMethodsStore).On("GetByCode", mock.Anything, "transferencia").
+				Return(&models.PaymentMethod{Code: "transferencia", Enabled: true, RequiresConfirmation: true}, nil)
+			instM := app.Store.Installments.(*storeMocks.InstallmentsStore)
+			instM.On("GetByID", mock.Anything, paymentID).Return(&models.InstallmentPayment{
+				ID: paymentID, EventID: eventID, Purpose: models.InstallmentPurposeDeposit, Amount: 5000, PaymentStatus: models.InstallmentStatusPendingVerification,
+			}, nil)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			part, _ := form.CreateFormFile("file", tc.filename)
+			part.Write(tc.data)
+			form.Close()
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/events/"+eventID.String()+"/payments/"+tc.paymentID.String()+"/receipt", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			instM.AssertNotCalled(t, "SubmitReceipt", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,473 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"Backend/internal/paypal"
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const paypalPaymentMethod = "paypal"
+
+var (
+	errPayPalCaptureIncomplete = errors.New("paypal capture is not completed yet")
+	errPayPalAmountMismatch    = errors.New("paypal captured amount does not match the order")
+)
+
+type createPayPalOrderPayload struct {
+	EventID       uuid.UUID  `json:"event_id" validate:"required"`
+	Purpose       string     `json:"purpose" validate:"required,oneof=deposit full installment"`
+	InstallmentID *uuid.UUID `json:"installment_id"`
+}
+
+// createPayPalOrderHandler creates a PayPal order for a deposit, the full
+// balance, or a single installment of a confirmed event. The amount is
+// computed here from the event, never taken from the client.
+func (app *Application) createPayPalOrderHandler(w http.ResponseWriter, r *http.Request) {
+	if app.PayPal == nil {
+		app.serviceUnavailableResponse(w, r, paypal.ErrNotConfigured)
+		return
+	}
+
+	var payload createPayPalOrderPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	event, err := app.Store.Events.GetByID(ctx, payload.EventID)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	user := GetUserFromCtx(r)
+	if event.UserID != user.ID {
+		app.forbidden(w, r, errors.New("you do not have permission to pay for this event"))
+		return
+	}
+	if event.Status != models.EventStatusConfirmed {
+		app.invalidTransitionResponse(w, r, &store.InvalidTransitionError{From: event.Status, To: models.EventStatusPaid})
+		return
+	}
+
+	if _, err := app.enabledPaymentMethod(ctx, paypalPaymentMethod); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	amount, err := app.paypalAmountFor(ctx, event, payload.Purpose, payload.InstallmentID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.badRequest(w, r, err)
+		return
+	}
+
+	order, err := app.PayPal.CreateOrder(ctx, paypal.CreateOrderRequest{
+		ReferenceID: event.ID.String(),
+		CustomID:    payload.Purpose,
+		Description: event.Name,
+		Amount:      amount,
+		Currency:    app.Config.PayPal.Currency,
+	}, uuid.NewString())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	tx := &models.PayPalTransaction{
+		PayPalOrderID: order.ID,
+		EventID:       event.ID,
+		UserID:        user.ID,
+		Amount:        amount,
+		Currency:      app.Config.PayPal.Currency,
+		Status:        models.PayPalStatusPending,
+		Purpose:       payload.Purpose,
+		InstallmentID: payload.InstallmentID,
+	}
+	if err := app.Store.PayPal.CreateTransaction(ctx, tx); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, map[string]any{
+		"transaction":     tx,
+		"paypal_order_id": order.ID,
+		"approve_url":     order.ApproveURL(),
+	}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// paypalAmountFor returns what the payer owes for the installments the
+// purpose covers.
+func (app *Application) paypalAmountFor(ctx context.Context, event *models.Event, purpose string, installmentID *uuid.UUID) (float64, error) {
+	installments, err := app.ensurePaymentSchedule(ctx, event)
+	if err != nil {
+		return 0, err
+	}
+	due, err := installmentsToPay(installments, purpose, installmentID)
+	if err != nil {
+		return 0, err
+	}
+	return float64(sumInstallments(due)), nil
+}
+
+type capturePayPalOrderPayload struct {
+	PayPalOrderID string `json:"paypal_order_id" validate:"required"`
+}
+
+// capturePayPalOrderHandler captures an order the payer approved. The
+// capture comes from PayPal's answer, not from the client.
+func (app *Application) capturePayPalOrderHandler(w http.ResponseWriter, r *http.Request) {
+	if app.PayPal == nil {
+		app.serviceUnavailableResponse(w, r, paypal.ErrNotConfigured)
+		return
+	}
+
+	var payload capturePayPalOrderPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := app.Store.PayPal.GetTransactionByOrderID(ctx, payload.PayPalOrderID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if tx == nil {
+		app.notFoundResponse(w, r, fmt.Errorf("paypal order %s not found", payload.PayPalOrderID))
+		return
+	}
+
+	user := GetUserFromCtx(r)
+	if tx.UserID != user.ID {
+		app.forbidden(w, r, errors.New("you do not have permission to capture this order"))
+		return
+	}
+
+	if tx.Status != models.PayPalStatusCompleted {
+		order, err := app.PayPal.CaptureOrder(ctx, tx.PayPalOrderID, "capture-"+tx.PayPalOrderID)
+		var apiErr *paypal.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+			// Already captured (for instance by a retried request); read
+			// the order back instead of failing.
+			order, err = app.PayPal.GetOrder(ctx, tx.PayPalOrderID)
+		}
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		captures := order.Captures()
+		if len(captures) == 0 {
+			app.conflictResponse(w, r, errPayPalCaptureIncomplete)
+			return
+		}
+
+		raw, _ := json.Marshal(order)
+		response := string(raw)
+		if err := app.settlePayPalCapture(ctx, tx, captures[0], &response, user.ID); err != nil {
+			switch {
+			case errors.Is(err, errPayPalCaptureIncomplete), errors.Is(err, errPayPalAmountMismatch):
+				app.conflictResponse(w, r, err)
+			default:
+				app.handleError(w, r, err)
+			}
+			return
+		}
+	}
+
+	tx, err = app.Store.PayPal.GetTransactionByOrderID(ctx, tx.PayPalOrderID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, tx); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// settlePayPalCapture checks a capture against its transaction and applies
+// the payment to the event. Both the capture endpoint and the webhook call
+// it; CompleteCapture guarantees only one of them applies the payment. If
+// applying fails the transaction goes back to pending so a retry can finish
+// the job.
+func (app *Application) settlePayPalCapture(ctx context.Context, tx *models.PayPalTransaction, capture paypal.Capture, response *string, actorID uuid.UUID) error {
+	if capture.Status != paypal.CaptureStatusCompleted {
+		return errPayPalCaptureIncomplete
+	}
+	if capture.Amount.Value != paypal.FormatAmount(tx.Amount) || capture.Amount.CurrencyCode != tx.Currency {
+		app.Logger.Errorw("paypal capture amount mismatch",
+			"order_id", tx.PayPalOrderID,
+			"expected", paypal.FormatAmount(tx.Amount)+" "+tx.Currency,
+			"captured", capture.Amount.Value+" "+capture.Amount.CurrencyCode,
+		)
+		if err := app.Store.PayPal.UpdateTransactionStatus(ctx, tx.PayPalOrderID, models.PayPalStatusFailed); err != nil {
+			return err
+		}
+		return errPayPalAmountMismatch
+	}
+
+	completed, err := app.Store.PayPal.CompleteCapture(ctx, tx.PayPalOrderID, capture.ID, response)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return nil
+	}
+
+	if err := app.applyPayPalPayment(ctx, tx, actorID); err != nil {
+		if revertErr := app.Store.PayPal.UpdateTransactionStatus(ctx, tx.PayPalOrderID, models.PayPalStatusPending); revertErr != nil {
+			app.Logger.Errorw("failed to reopen paypal transaction", "order_id", tx.PayPalOrderID, "error", revertErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// applyPayPalPayment marks the deposit, the installment, or the whole event
+// as paid depending on what the order was for.
+func (app *Application) applyPayPalPayment(ctx context.Context, tx *models.PayPalTransaction, actorID uuid.UUID) error {
+	event, err := app.Store.Events.GetByID(ctx, tx.EventID)
+	if err != nil {
+		return err
+	}
+	reason := "paypal " + tx.Purpose
+	event.StatusChange = &models.EventStatusChange{ActorID: actorID, Reason: &reason}
+
+	// The order was paid, so what it covers is settled even if another
+	// payment for it is waiting for verification.
+	installments, err := app.ensurePaymentSchedule(ctx, event)
+	if err != nil {
+		return err
+	}
+	due, err := installmentsToPay(installments, tx.Purpose, tx.InstallmentID)
+	if err != nil {
+		return err
+	}
+	dueIDs := make([]uuid.UUID, len(due))
+	for i, inst := range due {
+		dueIDs[i] = inst.ID
+	}
+	if err := app.Store.PayPal.RecordInstallments(ctx, tx.ID, dueIDs); err != nil {
+		return err
+	}
+	for _, inst := range due {
+		if _, err := app.Store.Installments.MarkPaid(ctx, inst.ID, paypalPaymentMethod); err != nil {
+			return err
+		}
+	}
+	if err := app.settleEventPayment(ctx, event, paypalPaymentMethod); err != nil {
+		return err
+	}
+
+	method := paypalPaymentMethod
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &actorID,
+		EventID:    &event.ID,
+		Action:     models.AuditActionEventPay,
+		EntityType: "event",
+		EntityID:   &event.ID,
+		NewValue:   &method,
+	})
+	return nil
+}
+
+// paypalWebhookHandler receives PayPal webhook deliveries. The signature is
+// verified with PayPal before anything is trusted, and deliveries already
+// handled are acknowledged without being applied again.
+func (app *Application) paypalWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if app.PayPal == nil {
+		app.serviceUnavailableResponse(w, r, paypal.ErrNotConfigured)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	valid, err := app.PayPal.VerifyWebhookSignature(ctx, r.Header, body)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !valid {
+		app.unauthorized(w, r, errors.New("invalid paypal webhook signature"))
+		return
+	}
+
+	var event paypal.WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	processed, err := app.Store.PayPal.IsWebhookEventProcessed(ctx, event.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if processed {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := app.handlePayPalWebhookEvent(ctx, event); err != nil {
+		// A non-2xx answer makes PayPal retry the delivery later.
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.Store.PayPal.MarkWebhookEventProcessed(ctx, event.ID, event.EventType); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (app *Application) handlePayPalWebhookEvent(ctx context.Context, event paypal.WebhookEvent) error {
+	switch event.EventType {
+	case paypal.EventCaptureCompleted, paypal.EventCaptureDenied, paypal.EventCaptureDeclined, paypal.EventCaptureRefunded:
+	default:
+		app.Logger.Infow("ignoring paypal webhook", "event_id", event.ID, "event_type", event.EventType)
+		return nil
+	}
+
+	var capture paypal.Capture
+	if err := json.Unmarshal(event.Resource, &capture); err != nil {
+		return err
+	}
+	orderID := capture.SupplementaryData.RelatedIDs.OrderID
+
+	tx, err := app.Store.PayPal.GetTransactionByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		app.Logger.Warnw("paypal webhook for unknown order", "event_id", event.ID, "order_id", orderID)
+		return nil
+	}
+
+	switch event.EventType {
+	case paypal.EventCaptureCompleted:
+		response := string(event.Resource)
+		err := app.settlePayPalCapture(ctx, tx, capture, &response, tx.UserID)
+		if errors.Is(err, errPayPalAmountMismatch) {
+			// Recorded as failed; retrying would not change the outcome.
+			return nil
+		}
+		return err
+	case paypal.EventCaptureRefunded:
+		return app.refundPayPalPayment(ctx, tx, capture)
+	default:
+		return app.Store.PayPal.UpdateTransactionStatus(ctx, orderID, models.PayPalStatusFailed)
+	}
+}
+
+// refundPayPalPayment undoes a refunded order: what it paid is owed again
+// and the event's payment status goes back to match. A partial refund is
+// only logged, for an admin to settle by hand.
+func (app *Application) refundPayPalPayment(ctx context.Context, tx *models.PayPalTransaction, refund paypal.Capture) error {
+	if refund.Amount.Value != paypal.FormatAmount(tx.Amount) || refund.Amount.CurrencyCode != tx.Currency {
+		app.Logger.Errorw("partial paypal refund needs to be settled by hand",
+			"order_id", tx.PayPalOrderID,
+			"paid", paypal.FormatAmount(tx.Amount)+" "+tx.Currency,
+			"refunded", refund.Amount.Value+" "+refund.Amount.CurrencyCode,
+		)
+		return nil
+	}
+
+	reopened, err := app.Store.PayPal.Refund(ctx, tx.PayPalOrderID)
+	if err != nil || !reopened {
+		return err
+	}
+
+	event, err := app.Store.Events.GetByID(ctx, tx.EventID)
+	if err != nil {
+		return err
+	}
+	installments, err := app.Store.Installments.GetInstallmentByEventID(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	event.ApplySchedule(installments)
+
+	event.PaymentStatus = "pending"
+	if event.DepositPaid {
+		event.PaymentStatus = "deposit_paid"
+	}
+	if event.Status == models.EventStatusPaid {
+		event.Status = models.EventStatusConfirmed
+	}
+	reason := "paypal refund"
+	event.StatusChange = &models.EventStatusChange{ActorID: tx.UserID, Reason: &reason}
+	return app.Store.Events.Update(ctx, event)
+}
+
+func (app *Application) getPayPalTransactionsByEventHandler(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "eventId"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	event, err := app.Store.Events.GetByID(r.Context(), eventID)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	user := GetUserFromCtx(r)
+	if event.UserID != user.ID {
+		allowed, err := app.hasPermission(r, models.PermFinancialRead)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !allowed {
+			app.forbidden(w, r, errors.New("you do not have permission to view these payments"))
+			return
+		}
+	}
+
+	txs, err := app.Store.PayPal.GetTransactionsByEvent(r.Context(), eventID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if txs == nil {
+		txs = []models.PayPalTransaction{}
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, txs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
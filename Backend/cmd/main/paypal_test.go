@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/paypal"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPayPalWebhook(t *testing.T) {
+	completed := []byte(`{"id":"WH-EVT-1","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{}}`)
+	refunded := func(value string) []byte {
+		return []byte(`{"id":"WH-EVT-2","event_type":"PAYMENT.CAPTURE.REFUNDED","resource":{"id":"RF-1","status":"COMPLETED",` +
+			`"amount":{"currency_code":"USD","value":"` + value + `"},"supplementary_data":{"related_ids":{"order_id":"ORDER-1"}}}}`)
+	}
+
+	userID := uuid.New()
+	eventID := uuid.New()
+	transaction := &models.PayPalTransaction{
+		ID: uuid.New(), PayPalOrderID: "ORDER-1", EventID: eventID, UserID: userID,
+		Amount: 100, Currency: "USD", Status: models.PayPalStatusCompleted, Purpose: models.PayPalPurposeFull,
+	}
+
+	fake := func(status string) *httptest.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /v1/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "tok", "expires_in": 3600})
+		})
+		mux.HandleFunc("POST /v1/notifications/verify-webhook-signature", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]string{"verification_status": status})
+		})
+		return httptest.NewServer(mux)
+	}
+
+	tests := []struct {
+		name         string
+		configured   bool
+		verifyStatus string
+		body         []byte
+		setupMocks   func(*Application)
+		assertMocks  func(*testing.T, *Application)
+		expectedCode int
+	}{
+		{name: "paypal not configured", configured: false, body: completed, expectedCode: http.StatusServiceUnavailable},
+		{name: "invalid signature", configured: true, verifyStatus: "FAILURE", body: completed, expectedCode: http.StatusUnauthorized},
+		{
+			name:         "refund reopens the event's balance",
+			configured:   true,
+			verifyStatus: "SUCCESS",
+			body:         refunded("100.00"),
+			setupMocks: func(app *Application) {
+				app.Store.PayPal.(*storeMocks.PayPalStore).On("Refund", mock.Anything, "ORDER-1").Return(true, nil).Once()
+				app.Store.Events.(*storeMocks.EventStore).On("GetByID", mock.Anything, eventID).Return(&models.Event{
+					ID: eventID, UserID: userID, Status: models.EventStatusPaid, PaymentStatus: "completed",
+				}, nil)
+				app.Store.Installments.(*storeMocks.InstallmentsStore).On("GetInstallmentByEventID", mock.Anything, eventID).Return([]models.InstallmentPayment{
+					{ID: uuid.New(), EventID: eventID, Purpose: models.InstallmentPurposeInstallment, Amount: 50, PaymentStatus: models.InstallmentStatusPaid},
+					{ID: uuid.New(), EventID: eventID, Purpose: models.InstallmentPurposeInstallment, Amount: 50, PaymentStatus: models.InstallmentStatusPending},
+				}, nil)
+				app.Store.Events.(*storeMocks.EventStore).On("Update", mock.Anything, mock.MatchedBy(func(e *models.Event) bool {
+					return e.Status == models.EventStatusConfirmed && e.PaymentStatus == "deposit_paid" && e.RemainingAmount == 50
+				})).Return(nil).Once()
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.PayPal.(*storeMocks.PayPalStore).AssertExpectations(t)
+				app.Store.Events.(*storeMocks.EventStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "partial refund is left to an admin",
+			configured:   true,
+			verifyStatus: "SUCCESS",
+			body:         refunded("40.00"),
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.PayPal.(*storeMocks.PayPalStore).AssertNotCalled(t, "Refund", mock.Anything, mock.Anything)
+				app.Store.Events.(*storeMocks.EventStore).AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+
+			if tt.configured {
+				srv := fake(tt.verifyStatus)
+				defer srv.Close()
+
+				client, err := paypal.NewClient(paypal.Config{BaseURL: srv.URL, ClientID: "id", Secret: "secret", WebhookID: "WH-1"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				app.PayPal = client
+			}
+			payPalM := app.Store.PayPal.(*storeMocks.PayPalStore)
+			payPalM.On("IsWebhookEventProcessed", mock.Anything, mock.Anything).Return(false, nil)
+			payPalM.On("MarkWebhookEventProcessed", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			payPalM.On("GetTransactionByOrderID", mock.Anything, "ORDER-1").Return(transaction, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(app)
+			}
+
+			mux := app.Mount()
+
+			req, _ := http.NewRequest(http.MethodPost, "/v1/financial/paypal/webhook", bytes.NewReader(tt.body))
+			req.Header.Set("PAYPAL-TRANSMISSION-ID", "trans-1")
+			req.Header.Set("PAYPAL-TRANSMISSION-SIG", "sig")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tt.expectedCode, rr)
+			if tt.assertMocks != nil {
+				tt.assertMocks(t, app)
+			}
+		})
+	}
+}
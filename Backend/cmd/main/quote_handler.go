@@ -57,18 +57,8 @@ func (app *Application) getQuotePDFHandler(w http.ResponseWriter, r *http.Reques
 		unitPrice := item.UnitPrice()
 		lineTotal := unitPrice * float64(item.Quantity)
 		subtotal += lineTotal
-		name := ""
-		if item.Article != nil {
-			name = item.Article.NameTemplate
-			if item.Variant != nil {
-				name = fmt.Sprintf("%s - %s", item.Article.NameTemplate, item.Variant.Name)
-			}
-		}
-		if name == "" {
-			name = "Artículo"
-		}
 		quoteItems = append(quoteItems, pdf.QuoteItem{
-			Name:       name,
+			Name:       eventItemName(item),
 			Quantity:   item.Quantity,
 			UnitPrice:  unitPrice,
 			TotalPrice: lineTotal,
@@ -95,7 +85,8 @@ func (app *Application) getQuotePDFHandler(w http.ResponseWriter, r *http.Reques
 		Items:         quoteItems,
 		Subtotal:      subtotal,
 		AdditionalCosts: event.AdditionalCosts,
-		Total:         subtotal + event.AdditionalCosts,
+		DeliveryFee:   event.DeliveryFee,
+		Total:         event.QuoteTotal(subtotal),
 		PaymentMethod: paymentMethod,
 		AdminNotes:    event.AdminNotes,
 		QuoteNumber:   generateQuoteNumber(event.ID),
@@ -114,6 +105,21 @@ func (app *Application) getQuotePDFHandler(w http.ResponseWriter, r *http.Reques
 	w.Write(pdfBytes)
 }
 
+// eventItemName is how an event item is listed on quotes and invoices.
+func eventItemName(item models.EventItem) string {
+	name := ""
+	if item.Article != nil {
+		name = item.Article.NameTemplate
+		if item.Variant != nil {
+			name = fmt.Sprintf("%s - %s", item.Article.NameTemplate, item.Variant.Name)
+		}
+	}
+	if name == "" {
+		name = "Artículo"
+	}
+	return name
+}
+
 func formatClientName(user *models.User) string {
 	if user.FirstName != "" && user.LastName != "" {
 		return fmt.Sprintf("%s %s", user.FirstName, user.LastName)
@@ -41,13 +41,13 @@ func TestCreateReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				articleM := app.Store.Articles.(*storeMocks.ArticlesStore)
 				articleM.On("GetById", mock.Anything, articleID).Return(&models.Article{BaseModel: models.BaseModel{ID: articleID}}, nil).Once()
 
 				roleM := app.Store.Roles.(*storeMocks.RoleStore)
-				roleM.On("RetrieveByName", mock.Anything, "moderator").Return(&models.Role{Name: "moderator", Level: 1}, nil).Maybe()
+				roleM.On("Permissions", mock.Anything, mock.Anything).Return(models.PermissionNames(), nil).Maybe()
 
 				reviewM := app.Store.Reviews.(*storeMocks.ReviewsStore)
 				reviewM.On("Create", mock.Anything, mock.MatchedBy(func(r *models.Review) bool {
@@ -70,7 +70,7 @@ func TestCreateReview(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Once()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer", Level: 1}}, nil).Once()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "buyer"}}, nil).Once()
 
 				articleM := app.Store.Articles.(*storeMocks.ArticlesStore)
 				articleM.On("GetById", mock.Anything, articleID).Return(&models.Article{BaseModel: models.BaseModel{ID: articleID}}, nil).Once()
@@ -114,7 +114,7 @@ func TestGetArticleReviews(t *testing.T) {
 				articleM.On("GetById", mock.Anything, articleID).Return(&models.Article{BaseModel: models.BaseModel{ID: articleID}}, nil).Once()
 
 				roleM := app.Store.Roles.(*storeMocks.RoleStore)
-				roleM.On("RetrieveByName", mock.Anything, "moderator").Return(&models.Role{Name: "moderator", Level: 1}, nil).Maybe()
+				roleM.On("Permissions", mock.Anything, mock.Anything).Return(models.PermissionNames(), nil).Maybe()
 
 				reviewM := app.Store.Reviews.(*storeMocks.ReviewsStore)
 				reviewM.On("GetByArticleID", mock.Anything, articleID).Return([]models.Review{
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// adminListPermissionsHandler returns every permission a role can be
+// granted.
+func (app *Application) adminListPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": models.Permissions})
+}
+
+func (app *Application) adminListRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := app.Store.Roles.List(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": roles})
+}
+
+func (app *Application) adminCreateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.CreateRolePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	permissions, err := normalizePermissions(payload.Permissions)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	role := &models.Role{Name: payload.Name, Description: payload.Description, Permissions: permissions}
+	if err := app.Store.Roles.Create(r.Context(), role); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.logRoleChange(r, role)
+	app.jsonResponse(w, http.StatusCreated, map[string]interface{}{"data": role})
+}
+
+func (app *Application) adminUpdateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	var payload models.UpdateRolePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	role, err := app.Store.Roles.GetByID(ctx, id)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if payload.Name != nil {
+		role.Name = *payload.Name
+	}
+	if payload.Description != nil {
+		role.Description = *payload.Description
+	}
+	if payload.Permissions != nil {
+		if role.Permissions, err = normalizePermissions(payload.Permissions); err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+	}
+
+	if err := app.Store.Roles.Update(ctx, role); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	app.logRoleChange(r, role)
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": role})
+}
+
+func (app *Application) adminDeleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := app.Store.Roles.Delete(r.Context(), id); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminListStaffHandler returns the users whose role grants them access to
+// the admin app.
+func (app *Application) adminListStaffHandler(w http.ResponseWriter, r *http.Request) {
+	staff, err := app.Store.Users.ListStaff(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": staff})
+}
+
+// adminAssignRoleHandler gives a user a role, making them staff or taking
+// their access away.
+func (app *Application) adminAssignRoleHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	var payload models.AssignRolePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	admin := GetUserFromCtx(r)
+	if admin.ID == userID {
+		app.badRequest(w, r, errors.New("you can't change your own role"))
+		return
+	}
+
+	ctx := r.Context()
+	role, err := app.Store.Roles.GetByID(ctx, payload.RoleID)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if err := app.Store.Users.SetRole(ctx, userID, role.ID); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if err := app.evictUser(ctx, userID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	_ = app.Store.AuditLogs.Log(ctx, &models.AuditLog{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionRoleAssign,
+		EntityType: "user",
+		EntityID:   &userID,
+		NewValue:   &role.Name,
+	})
+	app.jsonResponse(w, http.StatusOK, map[string]interface{}{"data": role})
+}
+
+func (app *Application) logRoleChange(r *http.Request, role *models.Role) {
+	admin := GetUserFromCtx(r)
+	permissions := role.Name + ": " + strings.Join(role.Permissions, ", ")
+	_ = app.Store.AuditLogs.Log(r.Context(), &models.AuditLog{
+		UserID:     &admin.ID,
+		Action:     models.AuditActionRoleChange,
+		EntityType: "role",
+		EntityID:   &role.ID,
+		NewValue:   &permissions,
+	})
+}
+
+// normalizePermissions checks the permissions exist and drops duplicates.
+func normalizePermissions(permissions []string) ([]string, error) {
+	if err := models.ValidatePermissions(permissions); err != nil {
+		return nil, err
+	}
+	permissions = slices.Clone(permissions)
+	slices.Sort(permissions)
+	return slices.Compact(permissions), nil
+}
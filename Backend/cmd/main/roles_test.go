@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/store"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPermissions(t *testing.T) {
+	userID := uuid.New()
+	target, roleID := uuid.New(), uuid.New()
+	systemRoleID := uuid.New()
+
+	tests := []struct {
+		name         string
+		permissions  []string
+		method       string
+		url          string
+		body         string
+		setupMocks   func(*Application)
+		assertMocks  func(*testing.T, *Application)
+		expectedCode int
+	}{
+		{
+			name:         "should keep users without permissions out of the admin app",
+			method:       http.MethodGet,
+			url:          "/v1/admin/profile",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "should let staff reach what their role grants",
+			permissions:  []string{models.PermEventsRead},
+			method:       http.MethodGet,
+			url:          "/v1/admin/profile",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "should keep staff out of what their role doesn't grant",
+			permissions: []string{models.PermEventsRead},
+			method:      http.MethodGet,
+			url:         "/v1/admin/roles",
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Roles.(*storeMocks.RoleStore).AssertNotCalled(t, "List", mock.Anything)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:        "should create a role",
+			permissions: []string{models.PermRolesManage},
+			method:      http.MethodPost,
+			url:         "/v1/admin/roles",
+			body:        `{"name":"ventas","permissions":["leads:write","clients:read","leads:write"]}`,
+			setupMocks: func(app *Application) {
+				app.Store.Roles.(*storeMocks.RoleStore).On("Create", mock.Anything, mock.MatchedBy(func(r *models.Role) bool {
+					return r.Name == "ventas" && len(r.Permissions) == 2 &&
+						r.Permissions[0] == models.PermClientsRead && r.Permissions[1] == models.PermLeadsWrite
+				})).Return(nil).Once()
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Roles.(*storeMocks.RoleStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "should reject unknown permissions",
+			permissions: []string{models.PermRolesManage},
+			method:      http.MethodPost,
+			url:         "/v1/admin/roles",
+			body:        `{"name":"ventas","permissions":["leads:delete"]}`,
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Roles.(*storeMocks.RoleStore).AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "should not delete system roles",
+			permissions: []string{models.PermRolesManage},
+			method:      http.MethodDelete,
+			url:         "/v1/admin/roles/" + systemRoleID.String(),
+			setupMocks: func(app *Application) {
+				app.Store.Roles.(*storeMocks.RoleStore).On("Delete", mock.Anything, systemRoleID).Return(store.ErrSystemRole).Once()
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "should assign a role",
+			permissions: []string{models.PermRolesManage},
+			method:      http.MethodPut,
+			url:         "/v1/admin/users/" + target.String() + "/role",
+			body:        `{"role_id":"` + roleID.String() + `"}`,
+			setupMocks: func(app *Application) {
+				app.Store.Roles.(*storeMocks.RoleStore).On("GetByID", mock.Anything, roleID).Return(&models.Role{ID: roleID, Name: "ventas"}, nil)
+				app.Store.Users.(*storeMocks.UserStore).On("SetRole", mock.Anything, target, roleID).Return(nil).Once()
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Users.(*storeMocks.UserStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "should not let users change their own role",
+			permissions: []string{models.PermRolesManage},
+			method:      http.MethodPut,
+			url:         "/v1/admin/users/" + userID.String() + "/role",
+			body:        `{"role_id":"` + uuid.New().String() + `"}`,
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.Users.(*storeMocks.UserStore).AssertNotCalled(t, "SetRole", mock.Anything, mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticate(app, &models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "staff"}}, tc.permissions...)
+			app.Store.AuditLogs.(*storeMocks.AuditLogsStore).On("Log", mock.Anything, mock.Anything).Return(nil)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.assertMocks != nil {
+				tc.assertMocks(t, app)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// SessionCtx holds the ID of the session the request's access token was
+// issued to, if it has one.
+const SessionCtx userKey = "session"
+
+// refreshTokenTTL is how long a refresh token can be used for.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// listSessionsHandler godoc
+//
+//	@Summary		List sessions
+//	@Description	List the devices the current user is signed in on
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{array}		models.Session
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/sessions [get]
+func (app *Application) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromCtx(r)
+	sessions, err := app.Store.RefreshTokens.ListSessions(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	current, _ := r.Context().Value(SessionCtx).(uuid.UUID)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, sessions); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// deleteSessionHandler godoc
+//
+//	@Summary		Revoke a session
+//	@Description	Sign the current user out of one of their devices
+//	@Tags			users
+//	@Param			sessionId	path	string	true	"Session ID"
+//	@Success		204
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/sessions/{sessionId} [delete]
+func (app *Application) deleteSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionId"))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	user := GetUserFromCtx(r)
+	if err := app.Store.RefreshTokens.DeleteSession(r.Context(), user.ID, sessionID); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requestDevice describes the device a request was made from, to tell a
+// user's sessions apart.
+func requestDevice(r *http.Request) *models.Session {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return &models.Session{UserAgent: r.UserAgent(), IP: ip}
+}
+
+// sessionID is the session an access token was issued to. Tokens issued
+// before sessions existed have none.
+func sessionID(claims jwt.MapClaims) uuid.UUID {
+	sid, _ := claims["sid"].(string)
+	id, _ := uuid.Parse(sid)
+	return id
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	"Backend/internal/store"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSessions(t *testing.T) {
+	userID := uuid.New()
+	current := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		sessionExists bool
+		setupMocks    func(*Application)
+		assertMocks   func(*testing.T, *Application)
+		assertBody    func(*testing.T, []byte)
+		expectedCode  int
+	}{
+		{
+			name:          "should list the user's sessions",
+			method:        http.MethodGet,
+			url:           "/v1/users/sessions",
+			sessionExists: true,
+			setupMocks: func(app *Application) {
+				app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("ListSessions", mock.Anything, userID).
+					Return([]models.Session{{ID: other}, {ID: current}}, nil).Once()
+			},
+			assertBody: func(t *testing.T, b []byte) {
+				var body struct {
+					Data []models.Session `json:"data"`
+				}
+				if err := json.Unmarshal(b, &body); err != nil {
+					t.Fatal(err)
+				}
+				assert.Len(t, body.Data, 2)
+				assert.False(t, body.Data[0].Current)
+				assert.True(t, body.Data[1].Current)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "should revoke a session",
+			method:        http.MethodDelete,
+			url:           "/v1/users/sessions/" + other.String(),
+			sessionExists: true,
+			setupMocks: func(app *Application) {
+				app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("DeleteSession", mock.Anything, userID, other).Return(nil).Once()
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:          "should return 404 for sessions of other users",
+			method:        http.MethodDelete,
+			url:           "/v1/users/sessions/" + other.String(),
+			sessionExists: true,
+			setupMocks: func(app *Application) {
+				app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("DeleteSession", mock.Anything, userID, other).Return(store.ErrNotFound).Once()
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "should refuse access tokens of revoked sessions",
+			method: http.MethodGet,
+			url:    "/v1/users/sessions",
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).AssertNotCalled(t, "ListSessions", mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateWithClaims(app, jwt.MapClaims{"sub": userID.String(), "sid": current.String()}, &models.User{ID: userID})
+			app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("SessionExists", mock.Anything, current).Return(tc.sessionExists, nil)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(tc.method, tc.url, nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.assertBody != nil {
+				tc.assertBody(t, rr.Body.Bytes())
+			}
+			if tc.assertMocks != nil {
+				tc.assertMocks(t, app)
+			}
+		})
+	}
+}
@@ -33,10 +33,10 @@ func TestGetStats(t *testing.T) {
 				app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil).Maybe()
 
 				storeM := app.Store.Users.(*storeMocks.UserStore)
-				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{Name: "admin", Level: 5}}, nil).Maybe()
+				storeM.On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, Role: models.Role{ID: uuid.New(), Name: "admin"}}, nil).Maybe()
 
 				roleM := app.Store.Roles.(*storeMocks.RoleStore)
-				roleM.On("RetrieveByName", mock.Anything, "admin").Return(&models.Role{Name: "admin", Level: 5}, nil).Maybe()
+				roleM.On("Permissions", mock.Anything, mock.Anything).Return(models.PermissionNames(), nil).Maybe()
 
 				statsM := app.Store.Stats.(*storeMocks.StatsStore)
 				statsM.On("GetSummary", mock.Anything).Return(&models.AdminStats{
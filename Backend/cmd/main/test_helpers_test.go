@@ -13,7 +13,11 @@ import (
 	"Backend/internal/ratelimiter"
 	"Backend/internal/store"
 	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 )
 
@@ -30,6 +34,7 @@ func newTestApplication(t *testing.T, cfg configModels.Config) *Application {
 		Roles:            &storeMocks.RoleStore{},
 		Categories:       &storeMocks.CategoryStore{},
 		RefreshTokens:    &storeMocks.RefreshTokenStore{},
+		TwoFactor:        &storeMocks.TwoFactorStore{},
 		Events:           &storeMocks.EventStore{},
 		Guests:           &storeMocks.GuestStore{},
 		EventTasks:       &storeMocks.EventTaskStore{},
@@ -47,6 +52,16 @@ func newTestApplication(t *testing.T, cfg configModels.Config) *Application {
 		EventPhotos:      &storeMocks.EventPhotosStore{},
 		AuditLogs:        &storeMocks.AuditLogsStore{},
 		Installments:     &storeMocks.InstallmentsStore{},
+		WhatsApp:         &storeMocks.WhatsAppStore{},
+		Chatbot:          &storeMocks.ChatbotStore{},
+		Leads:            &storeMocks.LeadsStore{},
+		EventTypes:       &storeMocks.EventTypesStore{},
+		PaymentMethods:   &storeMocks.PaymentMethodsStore{},
+		PaymentPlans:     &storeMocks.PaymentPlansStore{},
+		Financial:        &storeMocks.FinancialStore{},
+		PayPal:           &storeMocks.PayPalStore{},
+		Analytics:        &storeMocks.AnalyticsStore{},
+		EventReturns:     &storeMocks.EventReturnsStore{},
 	}
 
 	mockCacheStore := cache.Storage{
@@ -83,6 +98,27 @@ func newTestApplication(t *testing.T, cfg configModels.Config) *Application {
 	}
 }
 
+// authenticate signs requests carrying "Bearer valid-token" in as user. A
+// user with a role is granted the given permissions.
+func authenticate(app *Application, user *models.User, permissions ...string) {
+	authenticateWithClaims(app, jwt.MapClaims{"sub": user.ID.String()}, user, permissions...)
+}
+
+// authenticateWithClaims is authenticate for a token with the given claims.
+func authenticateWithClaims(app *Application, claims jwt.MapClaims, user *models.User, permissions ...string) {
+	token := &jwt.Token{Claims: claims, Valid: true}
+	app.Auth.(*authMocks.Authenticator).On("ValidateToken", "valid-token").Return(token, nil)
+	app.Store.Users.(*storeMocks.UserStore).On("RetrieveById", mock.Anything, user.ID).Return(user, nil)
+	if user.Role.ID != uuid.Nil {
+		app.Store.Roles.(*storeMocks.RoleStore).On("Permissions", mock.Anything, user.Role.ID).Return(permissions, nil)
+	}
+}
+
+// authenticateAdmin signs requests in as an admin with every permission.
+func authenticateAdmin(app *Application, id uuid.UUID) {
+	authenticate(app, &models.User{ID: id, Role: models.Role{ID: uuid.New(), Name: "admin"}}, models.PermissionNames()...)
+}
+
 func executeRequest(req *http.Request, mux http.Handler) *httptest.ResponseRecorder {
 	rr := httptest.NewRecorder()
 	mux.ServeHTTP(rr, req)
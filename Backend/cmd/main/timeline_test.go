@@ -22,7 +22,7 @@ func TestTimelineHandlers(t *testing.T) {
 	mux := app.Mount()
 
 	userID := uuid.New()
-	user := &models.User{ID: userID, Role: models.Role{Level: 1}}
+	user := &models.User{ID: userID, Role: models.Role{Name: "user"}}
 	eventID := uuid.New()
 	event := &models.Event{ID: eventID, UserID: userID}
 
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"Backend/cmd/main/view_models/users"
+	"Backend/internal/auth"
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// twoFactorChallengeTTL is how long the user has to enter their code
+	// after their password.
+	twoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorMaxAttempts is how many codes can be tried per challenge
+	// before the password has to be entered again.
+	twoFactorMaxAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+var (
+	errTwoFactorRequired    = errors.New("two-factor authentication is required for staff accounts")
+	errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	errTwoFactorChallenge   = errors.New("two-factor challenge is invalid or expired, sign in again")
+)
+
+// issueTwoFactorChallenge answers a correct password for a user with
+// two-factor enabled with a challenge to finish signing in with.
+func (app *Application) issueTwoFactorChallenge(w http.ResponseWriter, r *http.Request, user *models.User) {
+	challenge := &models.TwoFactorChallenge{
+		UserID:    user.ID,
+		Token:     uuid.New().String(),
+		ExpiresAt: time.Now().Add(twoFactorChallengeTTL),
+	}
+	if err := app.Store.TwoFactor.CreateChallenge(r.Context(), challenge); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := users.TwoFactorChallengeResponse{
+		TwoFactorRequired:            true,
+		ChallengeToken:               challenge.Token,
+		ChallengeExpirationTimestamp: challenge.ExpiresAt.Unix(),
+	}
+	if err := app.jsonResponse(w, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// verifyTwoFactorLoginHandler godoc
+//
+//	@Summary		Finish signing in with two-factor authentication
+//	@Description	Exchange the challenge token returned for the password, and a code from the authenticator or a recovery code, for tokens
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.TwoFactorLoginPayload	true	"Challenge and code"
+//	@Success		200		{object}	users.LoginResponse
+//	@Failure		400		{object}	error	"Bad request"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		403		{object}	error	"Account blocked"
+//	@Failure		500		{object}	error	"Internal server error"
+//	@Router			/authentication/token/2fa [post]
+func (app *Application) verifyTwoFactorLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.TwoFactorLoginPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	userID, err := app.Store.TwoFactor.AttemptChallenge(ctx, payload.ChallengeToken, twoFactorMaxAttempts)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorized(w, r, errTwoFactorChallenge)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	user, err := app.Store.Users.RetrieveById(ctx, userID)
+	if err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if user.Blocked(time.Now()) {
+		app.forbidden(w, r, errAccountBlocked)
+		return
+	}
+
+	tf, err := app.Store.TwoFactor.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorized(w, r, errTwoFactorChallenge)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	ok, err := app.checkSecondFactor(ctx, tf, payload.TwoFactorCodePayload)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !ok {
+		app.unauthorized(w, r, errInvalidTwoFactorCode)
+		return
+	}
+
+	if err := app.Store.TwoFactor.DeleteChallenge(ctx, payload.ChallengeToken); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.issueLoginTokens(w, r, user)
+}
+
+// twoFactorStatusHandler godoc
+//
+//	@Summary		Get two-factor status
+//	@Description	Whether the current user has two-factor authentication enabled, must enable it, and how many recovery codes they have left
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	models.TwoFactorStatus
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/2fa [get]
+func (app *Application) twoFactorStatusHandler(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromCtx(r)
+	tf, err := app.Store.TwoFactor.Get(r.Context(), user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+	required, err := app.twoFactorRequired(r)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	status := models.TwoFactorStatus{Required: required}
+	if tf.Enabled() {
+		status.Enabled = true
+		status.EnabledAt = tf.EnabledAt
+		status.RecoveryCodesLeft = tf.RecoveryCodesLeft
+	}
+	if err := app.jsonResponse(w, http.StatusOK, status); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// enrollTwoFactorHandler godoc
+//
+//	@Summary		Start enrolling an authenticator
+//	@Description	Generate a TOTP secret for the current user. Add it to an authenticator app by scanning the provisioning URI as a QR code, then confirm with a code at /users/2fa/enable
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	models.TwoFactorEnrollment
+//	@Failure		401	{object}	error
+//	@Failure		409	{object}	error	"Already enabled"
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/2fa/enroll [post]
+func (app *Application) enrollTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromCtx(r)
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.Store.TwoFactor.Enroll(r.Context(), user.ID, secret); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+
+	enrollment := models.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(secret, app.Config.Auth.TwoFactor.Issuer, user.Email),
+	}
+	if err := app.jsonResponse(w, http.StatusOK, enrollment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// enableTwoFactorHandler godoc
+//
+//	@Summary		Enable two-factor authentication
+//	@Description	Finish enrolling with a code from the authenticator. Returns the recovery codes, which are only shown this once
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.EnableTwoFactorPayload	true	"Code from the authenticator"
+//	@Success		200		{array}		string
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/2fa/enable [post]
+func (app *Application) enableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.EnableTwoFactorPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user := GetUserFromCtx(r)
+	tf, err := app.Store.TwoFactor.Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if tf == nil || tf.Enabled() {
+		app.badRequest(w, r, errors.New("start enrolling an authenticator first"))
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(tf.Secret, payload.Code, time.Now())
+	if !ok {
+		app.badRequest(w, r, errInvalidTwoFactorCode)
+		return
+	}
+	codes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.Store.TwoFactor.Enable(ctx, user.ID, step, codes); err != nil {
+		app.handleError(w, r, err)
+		return
+	}
+	if err := app.evictUser(ctx, user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, codes); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// disableTwoFactorHandler godoc
+//
+//	@Summary		Disable two-factor authentication
+//	@Description	Remove the current user's authenticator and recovery codes. Staff can't while the policy requires two-factor
+//	@Tags			users
+//	@Accept			json
+//	@Param			payload	body	models.TwoFactorCodePayload	true	"Code from the authenticator or a recovery code"
+//	@Success		204
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error	"Not enabled"
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/2fa [delete]
+func (app *Application) disableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	required, err := app.twoFactorRequired(r)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if required {
+		app.badRequest(w, r, errTwoFactorRequired)
+		return
+	}
+
+	user := GetUserFromCtx(r)
+	if !app.confirmSecondFactor(w, r, user.ID) {
+		return
+	}
+
+	ctx := r.Context()
+	if err := app.Store.TwoFactor.Disable(ctx, user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.evictUser(ctx, user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// regenerateRecoveryCodesHandler godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace the current user's recovery codes, invalidating the old ones
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.TwoFactorCodePayload	true	"Code from the authenticator or a recovery code"
+//	@Success		200		{array}		string
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error	"Not enabled"
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/2fa/recovery-codes [post]
+func (app *Application) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromCtx(r)
+	if !app.confirmSecondFactor(w, r, user.ID) {
+		return
+	}
+
+	codes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.Store.TwoFactor.ReplaceRecoveryCodes(r.Context(), user.ID, codes); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, codes); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// confirmSecondFactor reads a TwoFactorCodePayload and checks it against
+// the user's enabled authenticator, responding with an error and returning
+// false if it doesn't check out.
+func (app *Application) confirmSecondFactor(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	var payload models.TwoFactorCodePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return false
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return false
+	}
+
+	tf, err := app.Store.TwoFactor.Get(r.Context(), userID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if !tf.Enabled() {
+		app.notFoundResponse(w, r, errors.New("two-factor authentication is not enabled"))
+		return false
+	}
+
+	ok, err := app.checkSecondFactor(r.Context(), tf, payload)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if !ok {
+		app.badRequest(w, r, errInvalidTwoFactorCode)
+		return false
+	}
+	return true
+}
+
+// checkSecondFactor reports whether the payload holds a code from the
+// user's authenticator or one of their recovery codes, and spends it so it
+// can't be used again.
+func (app *Application) checkSecondFactor(ctx context.Context, tf *models.TwoFactor, payload models.TwoFactorCodePayload) (bool, error) {
+	if !tf.Enabled() {
+		return false, nil
+	}
+	if payload.RecoveryCode != "" {
+		return app.Store.TwoFactor.UseRecoveryCode(ctx, tf.UserID, payload.RecoveryCode)
+	}
+
+	step, ok := auth.ValidateTOTP(tf.Secret, payload.Code, time.Now())
+	if !ok || step <= tf.LastStep {
+		return false, nil
+	}
+	return app.Store.TwoFactor.UseStep(ctx, tf.UserID, step)
+}
+
+// twoFactorRequired reports whether the policy requires the request's user
+// to use two-factor authentication: when it is on, everyone whose role
+// grants any permission must.
+func (app *Application) twoFactorRequired(r *http.Request) (bool, error) {
+	if !app.Config.Auth.TwoFactor.RequiredForStaff {
+		return false, nil
+	}
+	permissions, _, err := app.userPermissions(r)
+	return len(permissions) > 0, err
+}
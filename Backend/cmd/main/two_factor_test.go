@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"Backend/cmd/main/configModels"
+	"Backend/cmd/main/view_models/users"
+	"Backend/internal/auth"
+	authMocks "Backend/internal/auth/mocks"
+	"Backend/internal/store"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTwoFactorLogin(t *testing.T) {
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	userID := uuid.New()
+	enabledAt := time.Now().Add(-24 * time.Hour)
+
+	step := auth.TOTPStep(time.Now())
+	code, err := auth.TOTPCode(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// setupChallenge answers the challenge for a user whose authenticator
+	// last accepted a code from lastStep.
+	setupChallenge := func(app *Application, lastStep int64) {
+		twoFactor := app.Store.TwoFactor.(*storeMocks.TwoFactorStore)
+		twoFactor.On("AttemptChallenge", mock.Anything, "challenge", twoFactorMaxAttempts).Return(userID, nil)
+		twoFactor.On("Get", mock.Anything, userID).Return(&models.TwoFactor{UserID: userID, Secret: secret, EnabledAt: &enabledAt, LastStep: lastStep}, nil)
+		app.Store.Users.(*storeMocks.UserStore).On("RetrieveById", mock.Anything, userID).Return(&models.User{ID: userID, TwoFactorEnabled: true}, nil)
+	}
+
+	tests := []struct {
+		name         string
+		url          string
+		body         string
+		setupMocks   func(*Application)
+		assertMocks  func(*testing.T, *Application)
+		assertBody   func(*testing.T, []byte)
+		expectedCode int
+	}{
+		{
+			name: "should answer the password alone with a challenge",
+			url:  "/v1/authentication/token",
+			body: `{"email":"staff@example.com","password":"password123"}`,
+			setupMocks: func(app *Application) {
+				user := &models.User{ID: userID, Email: "staff@example.com", IsActive: true, TwoFactorEnabled: true}
+				user.Password.Set("password123")
+				app.Store.Users.(*storeMocks.UserStore).On("GetByEmail", mock.Anything, "staff@example.com").Return(user, nil)
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).On("CreateChallenge", mock.Anything, mock.MatchedBy(func(c *models.TwoFactorChallenge) bool {
+					return c.UserID == userID && c.Token != "" && c.ExpiresAt.After(time.Now())
+				})).Return(nil).Once()
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).AssertExpectations(t)
+				app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+			},
+			assertBody: func(t *testing.T, b []byte) {
+				var body struct {
+					Data users.TwoFactorChallengeResponse `json:"data"`
+				}
+				if err := json.Unmarshal(b, &body); err != nil {
+					t.Fatal(err)
+				}
+				assert.True(t, body.Data.TwoFactorRequired)
+				assert.NotEmpty(t, body.Data.ChallengeToken)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "should finish signing in with a code",
+			url:  "/v1/authentication/token/2fa",
+			body: `{"challenge_token":"challenge","code":"` + code + `"}`,
+			setupMocks: func(app *Application) {
+				setupChallenge(app, 0)
+				twoFactor := app.Store.TwoFactor.(*storeMocks.TwoFactorStore)
+				twoFactor.On("UseStep", mock.Anything, userID, step).Return(true, nil).Once()
+				twoFactor.On("DeleteChallenge", mock.Anything, "challenge").Return(nil).Once()
+				app.Auth.(*authMocks.Authenticator).On("GenerateToken", mock.Anything).Return("access-token", nil)
+				app.Store.RefreshTokens.(*storeMocks.RefreshTokenStore).On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				app.Store.Events.(*storeMocks.EventStore).On("GetPendingByUserID", mock.Anything, userID).Return([]models.Event{}, nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).AssertExpectations(t)
+			},
+			assertBody: func(t *testing.T, b []byte) {
+				var body struct {
+					Data users.LoginResponse `json:"data"`
+				}
+				if err := json.Unmarshal(b, &body); err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, "access-token", body.Data.AccessToken)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "should not accept a code twice",
+			url:  "/v1/authentication/token/2fa",
+			body: `{"challenge_token":"challenge","code":"` + code + `"}`,
+			setupMocks: func(app *Application) {
+				setupChallenge(app, step)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).AssertNotCalled(t, "DeleteChallenge", mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "should refuse spent recovery codes",
+			url:  "/v1/authentication/token/2fa",
+			body: `{"challenge_token":"challenge","recovery_code":"abcde-fghij"}`,
+			setupMocks: func(app *Application) {
+				setupChallenge(app, 0)
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).On("UseRecoveryCode", mock.Anything, userID, "abcde-fghij").Return(false, nil).Once()
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "should refuse expired challenges",
+			url:  "/v1/authentication/token/2fa",
+			body: `{"challenge_token":"challenge","code":"123456"}`,
+			setupMocks: func(app *Application) {
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).On("AttemptChallenge", mock.Anything, "challenge", twoFactorMaxAttempts).Return(uuid.Nil, store.ErrNotFound)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, tc.url, bytes.NewBufferString(tc.body))
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.assertBody != nil {
+				tc.assertBody(t, rr.Body.Bytes())
+			}
+			if tc.assertMocks != nil {
+				tc.assertMocks(t, app)
+			}
+		})
+	}
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	userID := uuid.New()
+	required := configModels.Config{Auth: configModels.AuthConfig{TwoFactor: configModels.TwoFactorConfig{RequiredForStaff: true}}}
+
+	tests := []struct {
+		name         string
+		config       configModels.Config
+		user         models.User
+		permissions  []string
+		method       string
+		url          string
+		body         string
+		setupMocks   func(*Application)
+		assertMocks  func(*testing.T, *Application)
+		expectedCode int
+	}{
+		{
+			name:   "should not enable it with a wrong code",
+			method: http.MethodPost,
+			url:    "/v1/users/2fa/enable",
+			body:   `{"code":"000000"}`,
+			setupMocks: func(app *Application) {
+				secret, _ := auth.NewTOTPSecret()
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).On("Get", mock.Anything, userID).Return(&models.TwoFactor{UserID: userID, Secret: secret}, nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should keep staff without it out of the admin app when required",
+			config:       required,
+			permissions:  []string{models.PermEventsRead},
+			method:       http.MethodGet,
+			url:          "/v1/admin/profile",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "should let staff with it into the admin app when required",
+			config:       required,
+			user:         models.User{TwoFactorEnabled: true},
+			permissions:  []string{models.PermEventsRead},
+			method:       http.MethodGet,
+			url:          "/v1/admin/profile",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "should not let staff disable it while required",
+			config:      required,
+			user:        models.User{TwoFactorEnabled: true},
+			permissions: []string{models.PermEventsRead},
+			method:      http.MethodDelete,
+			url:         "/v1/users/2fa",
+			body:        `{"code":"123456"}`,
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.TwoFactor.(*storeMocks.TwoFactorStore).AssertNotCalled(t, "Disable", mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, tc.config)
+			user := tc.user
+			user.ID = userID
+			user.Role = models.Role{ID: uuid.New(), Name: "staff"}
+			authenticate(app, &user, tc.permissions...)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.assertMocks != nil {
+				tc.assertMocks(t, app)
+			}
+		})
+	}
+}
+
+func TestTwoFactorEnable(t *testing.T) {
+	userID := uuid.New()
+
+	app := newTestApplication(t, configModels.Config{})
+	authenticate(app, &models.User{ID: userID, Email: "ana@example.com", Role: models.Role{ID: uuid.New(), Name: "staff"}})
+	twoFactor := app.Store.TwoFactor.(*storeMocks.TwoFactorStore)
+	mux := app.Mount()
+
+	var secret string
+	twoFactor.On("Enroll", mock.Anything, userID, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		secret = args.String(2)
+	}).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/v1/users/2fa/enroll", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rr := executeRequest(req, mux)
+	checkResponseCode(t, http.StatusOK, rr)
+
+	var enrollment struct {
+		Data models.TwoFactorEnrollment `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &enrollment); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, secret, enrollment.Data.Secret)
+	assert.Contains(t, enrollment.Data.ProvisioningURI, "ana@example.com")
+
+	// The code from the newly enrolled secret finishes enrollment.
+	twoFactor.On("Get", mock.Anything, userID).Return(&models.TwoFactor{UserID: userID, Secret: secret}, nil)
+	step := auth.TOTPStep(time.Now())
+	code, _ := auth.TOTPCode(secret, step)
+	twoFactor.On("Enable", mock.Anything, userID, step, mock.MatchedBy(func(codes []string) bool {
+		return len(codes) == recoveryCodeCount
+	})).Return(nil)
+
+	req, _ = http.NewRequest(http.MethodPost, "/v1/users/2fa/enable", bytes.NewBufferString(`{"code":"`+code+`"}`))
+	req.Header.Set("Authorization", "Bearer valid-token")
+	checkResponseCode(t, http.StatusOK, executeRequest(req, mux))
+	twoFactor.AssertExpectations(t)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"Backend/cmd/main/configModels"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlockUser(t *testing.T) {
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name         string
+		userID       uuid.UUID
+		action       string
+		body         string
+		setupMocks   func(*Application)
+		expectedCode int
+	}{
+		{
+			name:   "should block the user",
+			userID: userID,
+			action: "block",
+			body:   `{"reason":"fraude"}`,
+			setupMocks: func(app *Application) {
+				app.Store.Users.(*storeMocks.UserStore).On("Block", mock.Anything, userID, adminID, (*time.Time)(nil), "fraude").Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should require a reason",
+			userID:       userID,
+			action:       "block",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should require suspensions to end in the future",
+			userID:       userID,
+			action:       "block",
+			body:         `{"reason":"spam","until":"` + time.Now().Add(-time.Hour).Format(time.RFC3339) + `"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "should not let admins block themselves",
+			userID:       adminID,
+			action:       "block",
+			body:         `{"reason":"prueba"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "should revoke tokens on force logout",
+			userID: userID,
+			action: "force-logout",
+			setupMocks: func(app *Application) {
+				app.Store.Users.(*storeMocks.UserStore).On("RevokeTokens", mock.Anything, userID).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateAdmin(app, adminID)
+			app.Store.AuditLogs.(*storeMocks.AuditLogsStore).On("Log", mock.Anything, mock.Anything).Return(nil)
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodPost, "/v1/admin/users/"+tc.userID.String()+"/"+tc.action, bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			usersM := app.Store.Users.(*storeMocks.UserStore)
+			if tc.setupMocks != nil {
+				usersM.AssertExpectations(t)
+			} else {
+				usersM.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAuthTokenRevocation(t *testing.T) {
+	userID := uuid.New()
+	blockedAt := time.Now().Add(-time.Hour)
+	ended := time.Now().Add(-30 * time.Minute)
+	role := models.Role{ID: uuid.New(), Name: "admin"}
+
+	tests := []struct {
+		name         string
+		claims       jwt.MapClaims
+		user         *models.User
+		expectedCode int
+	}{
+		{
+			name:         "should refuse tokens issued before a revocation",
+			claims:       jwt.MapClaims{"sub": userID.String(), "ver": float64(1)},
+			user:         &models.User{ID: userID, TokenVersion: 2, Role: role},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "should refuse blocked users",
+			claims:       jwt.MapClaims{"sub": userID.String()},
+			user:         &models.User{ID: userID, BlockedAt: &blockedAt, Role: role},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "should let users in once their suspension ended",
+			claims:       jwt.MapClaims{"sub": userID.String()},
+			user:         &models.User{ID: userID, BlockedAt: &blockedAt, BlockedUntil: &ended, Role: role},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			authenticateWithClaims(app, tc.claims, tc.user, models.PermissionNames()...)
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(http.MethodGet, "/v1/admin/profile", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+		})
+	}
+}
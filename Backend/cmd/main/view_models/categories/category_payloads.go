@@ -1,15 +1,19 @@
 package categories
 
 type CreateCategoryPayload struct {
-	Name        string  `json:"name" validate:"required,max=100"`
-	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
-	ImageURL    *string `json:"image_url,omitempty" validate:"omitempty,url"`
-	ParentID    *string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+	Name                string  `json:"name" validate:"required,max=100"`
+	Description         *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	ImageURL            *string `json:"image_url,omitempty" validate:"omitempty,url"`
+	ParentID            *string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+	SetupBufferHours    *int    `json:"setup_buffer_hours,omitempty" validate:"omitempty,min=0,max=168"`
+	CleaningBufferHours *int    `json:"cleaning_buffer_hours,omitempty" validate:"omitempty,min=0,max=168"`
 }
 
 type UpdateCategoryPayload struct {
-	Name        string  `json:"name" validate:"required,max=100"`
-	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
-	ImageURL    *string `json:"image_url,omitempty" validate:"omitempty,url"`
-	ParentID    *string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+	Name                string  `json:"name" validate:"required,max=100"`
+	Description         *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	ImageURL            *string `json:"image_url,omitempty" validate:"omitempty,url"`
+	ParentID            *string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+	SetupBufferHours    *int    `json:"setup_buffer_hours,omitempty" validate:"omitempty,min=0,max=168"`
+	CleaningBufferHours *int    `json:"cleaning_buffer_hours,omitempty" validate:"omitempty,min=0,max=168"`
 }
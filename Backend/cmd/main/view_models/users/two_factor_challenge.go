@@ -0,0 +1,11 @@
+package users
+
+// TwoFactorChallengeResponse is returned by the token endpoint instead of
+// a LoginResponse when the user has two-factor authentication enabled.
+// The challenge token and a code get the tokens from
+// /authentication/token/2fa.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired            bool   `json:"twoFactorRequired"`
+	ChallengeToken               string `json:"challengeToken"`
+	ChallengeExpirationTimestamp int64  `json:"challengeExpirationTimestamp"`
+}
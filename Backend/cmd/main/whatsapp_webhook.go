@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"Backend/internal/store/models"
+	"Backend/internal/whatsapp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// whatsAppInboxRoom is the Hub room staff join to see every WhatsApp
+// message as it arrives, whether or not it belongs to an event. No event
+// has the nil UUID, and wsHandler refuses to join it.
+var whatsAppInboxRoom = uuid.Nil
+
+var errWhatsAppNotConfigured = errors.New("whatsapp is not configured")
+
+func (app *Application) MountWhatsAppRoutes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/webhook", app.whatsAppVerifyWebhookHandler)
+	r.Post("/webhook", app.whatsAppWebhookHandler)
+	r.Get("/inbox/ws", app.whatsAppInboxWSHandler)
+
+	return r
+}
+
+// whatsAppVerifyWebhookHandler answers the handshake Meta sends when the
+// webhook URL is registered, echoing the challenge if the token matches.
+func (app *Application) whatsAppVerifyWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if app.WhatsApp == nil {
+		app.serviceUnavailableResponse(w, r, errWhatsAppNotConfigured)
+		return
+	}
+
+	q := r.URL.Query()
+	if !app.WhatsApp.VerifySubscription(q.Get("hub.mode"), q.Get("hub.verify_token")) {
+		app.forbidden(w, r, errors.New("invalid whatsapp verify token"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, q.Get("hub.challenge"))
+}
+
+// whatsAppWebhookHandler receives messages clients send to the business
+// number and delivery statuses for messages we sent. Anything that fails is
+// answered with a 500 so Meta redelivers it; messages are stored by their
+// WhatsApp ID, so a redelivery never duplicates them.
+func (app *Application) whatsAppWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if app.WhatsApp == nil {
+		app.serviceUnavailableResponse(w, r, errWhatsAppNotConfigured)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	if !app.WhatsApp.VerifySignature(body, r.Header.Get(whatsapp.SignatureHeader)) {
+		app.unauthorized(w, r, errors.New("invalid whatsapp webhook signature"))
+		return
+	}
+
+	messages, statuses, err := whatsapp.ParseWebhook(body)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	for _, m := range messages {
+		if err := app.receiveWhatsAppMessage(ctx, m); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+	for _, s := range statuses {
+		if err := app.Store.WhatsApp.UpdateStatus(ctx, s.MessageID, s.Status); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (app *Application) receiveWhatsAppMessage(ctx context.Context, m whatsapp.InboundMessage) error {
+	contact, err := app.Store.WhatsApp.Contact(ctx, m.From)
+	if err != nil {
+		return fmt.Errorf("matching whatsapp sender: %w", err)
+	}
+
+	msg := &models.WhatsAppMessage{
+		WAMessageID: &m.ID,
+		Phone:       m.From,
+		ContactName: m.Name,
+		MessageType: m.Type,
+		Body:        m.Body,
+		UserID:      contact.UserID,
+		LeadID:      contact.LeadID,
+		EventID:     contact.EventID,
+	}
+
+	created, err := app.Store.WhatsApp.SaveInbound(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("saving whatsapp message: %w", err)
+	}
+	if !created {
+		return nil
+	}
+
+	app.Logger.Infow("WhatsApp message received", "phone", m.From, "user_id", contact.UserID, "lead_id", contact.LeadID, "event_id", contact.EventID)
+	app.broadcastWhatsApp(msg)
+	return nil
+}
+
+// broadcastWhatsApp pushes a message to the staff inbox and, when it was
+// threaded into an event, to that event's chat.
+func (app *Application) broadcastWhatsApp(msg *models.WhatsAppMessage) {
+	if app.ChatHub == nil {
+		return
+	}
+	app.ChatHub.broadcast <- &BroadcastMessage{EventID: whatsAppInboxRoom, Payload: msg}
+	if msg.EventMessage != nil {
+		app.ChatHub.broadcast <- &BroadcastMessage{EventID: msg.EventMessage.EventID, Payload: msg.EventMessage}
+	}
+}
+
+// whatsAppInboxWSHandler streams every WhatsApp message to staff. Like the
+// event chat socket it authenticates with a token query parameter.
+func (app *Application) whatsAppInboxWSHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		app.Logger.Errorf("failed to upgrade connection: %v", err)
+		return
+	}
+
+	userID, ok := app.wsAuthenticate(conn, r)
+	if !ok {
+		return
+	}
+
+	user, err := app.Store.Users.RetrieveById(r.Context(), userID)
+	var permissions []string
+	if err == nil {
+		permissions, err = app.Store.Roles.Permissions(r.Context(), user.Role.ID)
+	}
+	if err != nil || !slices.Contains(permissions, models.PermMessagesWrite) {
+		conn.WriteJSON(map[string]string{"error": "you do not have permission to access this resource"})
+		conn.Close()
+		return
+	}
+
+	client := &Client{
+		hub:     app.ChatHub,
+		conn:    conn,
+		send:    make(chan interface{}, 256),
+		eventID: whatsAppInboxRoom,
+		userID:  userID,
+	}
+	client.hub.register <- client
+
+	go client.writePump()
+	go client.readPump()
+}
+
+func (app *Application) adminListWhatsAppConversationsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	conversations, err := app.Store.WhatsApp.ListConversations(r.Context(), limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if conversations == nil {
+		conversations = []models.WhatsAppConversation{}
+	}
+	app.jsonResponse(w, http.StatusOK, conversations)
+}
+
+func (app *Application) adminGetWhatsAppThreadHandler(w http.ResponseWriter, r *http.Request) {
+	phone := whatsapp.NormalizePhone(chi.URLParam(r, "phone"))
+	if phone == "" {
+		app.badRequest(w, r, errors.New("invalid phone number"))
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	messages, err := app.Store.WhatsApp.GetThread(r.Context(), phone, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if messages == nil {
+		messages = []models.WhatsAppMessage{}
+	}
+	app.jsonResponse(w, http.StatusOK, messages)
+}
+
+// adminReplyWhatsAppHandler sends a free-form reply to a client. WhatsApp
+// only delivers these within 24 hours of the client's last message; outside
+// that window the API call fails and the error is returned as is.
+func (app *Application) adminReplyWhatsAppHandler(w http.ResponseWriter, r *http.Request) {
+	if app.WhatsApp == nil {
+		app.serviceUnavailableResponse(w, r, errWhatsAppNotConfigured)
+		return
+	}
+
+	phone := whatsapp.NormalizePhone(chi.URLParam(r, "phone"))
+	if phone == "" {
+		app.badRequest(w, r, errors.New("invalid phone number"))
+		return
+	}
+
+	var payload models.WhatsAppReplyPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	contact, err := app.Store.WhatsApp.Contact(ctx, phone)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	waID, err := app.WhatsApp.SendText(ctx, phone, payload.Body)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	staff := GetUserFromCtx(r)
+	msg := &models.WhatsAppMessage{
+		Phone:   phone,
+		Body:    payload.Body,
+		UserID:  contact.UserID,
+		LeadID:  contact.LeadID,
+		EventID: contact.EventID,
+		SentBy:  &staff.ID,
+	}
+	if waID != "" {
+		msg.WAMessageID = &waID
+	}
+
+	if err := app.Store.WhatsApp.SaveOutbound(ctx, msg); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.broadcastWhatsApp(msg)
+
+	app.jsonResponse(w, http.StatusCreated, msg)
+}
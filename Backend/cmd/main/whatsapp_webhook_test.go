@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"Backend/cmd/main/configModels"
+	storeMocks "Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+	"Backend/internal/whatsapp"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWhatsAppWebhook(t *testing.T) {
+	const secret = "app-secret"
+	body := []byte(`{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{
+		"contacts":[{"wa_id":"18095551234","profile":{"name":"Ana"}}],
+		"messages":[{"id":"wamid.1","from":"18095551234","timestamp":"1748772000","type":"text","text":{"body":"Hola, tengo una pregunta"}}],
+		"statuses":[{"id":"wamid.out","status":"delivered","timestamp":"1748772000","recipient_id":"18095551234"}]}}]}]}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	userID, eventID := uuid.New(), uuid.New()
+	client := whatsapp.NewClient(whatsapp.Config{VerifyToken: "verify-me", AppSecret: secret})
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		signature    string
+		client       *whatsapp.Client
+		setupMocks   func(*Application)
+		assertMocks  func(*testing.T, *Application)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "should answer the subscription handshake",
+			method:       http.MethodGet,
+			url:          "/v1/whatsapp/webhook?hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=12345",
+			client:       client,
+			expectedCode: http.StatusOK,
+			expectedBody: "12345",
+		},
+		{
+			name:         "should refuse a handshake with the wrong token",
+			method:       http.MethodGet,
+			url:          "/v1/whatsapp/webhook?hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=12345",
+			client:       client,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:      "should reject an unsigned payload",
+			method:    http.MethodPost,
+			url:       "/v1/whatsapp/webhook",
+			signature: "sha256=00",
+			client:    client,
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.WhatsApp.(*storeMocks.WhatsAppStore).AssertNotCalled(t, "SaveInbound", mock.Anything, mock.Anything)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:      "should store an inbound message for the matched user",
+			method:    http.MethodPost,
+			url:       "/v1/whatsapp/webhook",
+			signature: signature,
+			client:    client,
+			setupMocks: func(app *Application) {
+				wa := app.Store.WhatsApp.(*storeMocks.WhatsAppStore)
+				wa.On("Contact", mock.Anything, "18095551234").Return(&models.WhatsAppContact{UserID: &userID, EventID: &eventID}, nil)
+				wa.On("SaveInbound", mock.Anything, mock.MatchedBy(func(m *models.WhatsAppMessage) bool {
+					return *m.WAMessageID == "wamid.1" && m.Body == "Hola, tengo una pregunta" && m.ContactName == "Ana" &&
+						*m.UserID == userID && *m.EventID == eventID
+				})).Return(true, nil)
+				wa.On("UpdateStatus", mock.Anything, "wamid.out", "delivered").Return(nil)
+			},
+			assertMocks: func(t *testing.T, app *Application) {
+				app.Store.WhatsApp.(*storeMocks.WhatsAppStore).AssertExpectations(t)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "should be unavailable when not configured",
+			method:       http.MethodPost,
+			url:          "/v1/whatsapp/webhook",
+			signature:    signature,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newTestApplication(t, configModels.Config{})
+			app.WhatsApp = tc.client
+			if tc.setupMocks != nil {
+				tc.setupMocks(app)
+			}
+
+			mux := app.Mount()
+			req, _ := http.NewRequest(tc.method, tc.url, bytes.NewReader(body))
+			if tc.signature != "" {
+				req.Header.Set(whatsapp.SignatureHeader, tc.signature)
+			}
+
+			rr := executeRequest(req, mux)
+			checkResponseCode(t, tc.expectedCode, rr)
+			if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, rr.Body.String())
+			}
+			if tc.assertMocks != nil {
+				tc.assertMocks(t, app)
+			}
+		})
+	}
+}
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS inventory_availability_article_event_date_unique;
@@ -0,0 +1,39 @@
+-- Reservations used to insert a row per item, so an event that listed the
+-- same variant twice has several rows for one date. Fold each group into
+-- one row before the unique index goes on: an active row is kept over a
+-- returned one, newest first, and it takes the quantity of every active row
+-- in the group.
+WITH ranked AS (
+    SELECT id,
+           ROW_NUMBER() OVER w AS rn,
+           SUM(CASE WHEN status IS DISTINCT FROM 'returned' THEN quantity_used ELSE 0 END) OVER (PARTITION BY article_id, event_id, event_date) AS active_quantity
+    FROM inventory_availability
+    WHERE event_id IS NOT NULL
+    WINDOW w AS (
+        PARTITION BY article_id, event_id, event_date
+        ORDER BY status IS NOT DISTINCT FROM 'returned', updated_at DESC NULLS LAST, id ASC
+    )
+)
+UPDATE inventory_availability ia
+SET quantity_used = ranked.active_quantity, updated_at = NOW()
+FROM ranked
+WHERE ia.id = ranked.id
+  AND ranked.rn = 1
+  AND ia.status IS DISTINCT FROM 'returned';
+
+WITH ranked AS (
+    SELECT id,
+           ROW_NUMBER() OVER (
+               PARTITION BY article_id, event_id, event_date
+               ORDER BY status IS NOT DISTINCT FROM 'returned', updated_at DESC NULLS LAST, id ASC
+           ) AS rn
+    FROM inventory_availability
+    WHERE event_id IS NOT NULL
+)
+DELETE FROM inventory_availability
+WHERE id IN (SELECT id FROM ranked WHERE rn > 1);
+
+-- Reservations upsert on (article_id, event_id, event_date); the conflict
+-- target needs a matching unique index or the ON CONFLICT clause errors.
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_availability_article_event_date_unique
+    ON inventory_availability (article_id, event_id, event_date);
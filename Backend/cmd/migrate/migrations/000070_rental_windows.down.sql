@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_inventory_availability_blocked;
+
+ALTER TABLE inventory_availability
+    DROP CONSTRAINT IF EXISTS inventory_availability_window_check,
+    DROP COLUMN IF EXISTS blocked_until,
+    DROP COLUMN IF EXISTS blocked_from,
+    DROP COLUMN IF EXISTS return_at,
+    DROP COLUMN IF EXISTS pickup_at;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS cleaning_buffer_hours,
+    DROP COLUMN IF EXISTS setup_buffer_hours;
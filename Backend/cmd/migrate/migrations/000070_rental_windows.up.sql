@@ -0,0 +1,30 @@
+-- Reservations block stock for a rental window (pickup to return) widened
+-- by the category's setup and cleaning buffers, instead of a single day.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS setup_buffer_hours INT NOT NULL DEFAULT 0 CHECK (setup_buffer_hours >= 0),
+    ADD COLUMN IF NOT EXISTS cleaning_buffer_hours INT NOT NULL DEFAULT 0 CHECK (cleaning_buffer_hours >= 0);
+
+ALTER TABLE inventory_availability
+    ADD COLUMN IF NOT EXISTS pickup_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS return_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS blocked_from TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS blocked_until TIMESTAMPTZ;
+
+-- Existing rows keep blocking only their event day.
+UPDATE inventory_availability
+SET pickup_at = event_date::timestamptz,
+    return_at = (event_date + 1)::timestamptz,
+    blocked_from = event_date::timestamptz,
+    blocked_until = (event_date + 1)::timestamptz
+WHERE pickup_at IS NULL;
+
+ALTER TABLE inventory_availability
+    ALTER COLUMN pickup_at SET NOT NULL,
+    ALTER COLUMN return_at SET NOT NULL,
+    ALTER COLUMN blocked_from SET NOT NULL,
+    ALTER COLUMN blocked_until SET NOT NULL,
+    ADD CONSTRAINT inventory_availability_window_check CHECK (return_at > pickup_at);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_availability_blocked
+    ON inventory_availability (article_id, blocked_from, blocked_until)
+    WHERE status <> 'returned';
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_inventory_availability_held_until;
+
+UPDATE inventory_availability SET status = 'reserved' WHERE status = 'held';
+
+ALTER TABLE inventory_availability
+    DROP COLUMN IF EXISTS held_until,
+    DROP CONSTRAINT IF EXISTS inventory_availability_status_check;
+
+ALTER TABLE inventory_availability
+    ADD CONSTRAINT inventory_availability_status_check
+        CHECK (status IN ('reserved', 'confirmed', 'delivered', 'returned'));
@@ -0,0 +1,13 @@
+-- Requested events soft-hold their items until held_until; the expiry
+-- worker returns stale holds to stock.
+ALTER TABLE inventory_availability
+    DROP CONSTRAINT IF EXISTS inventory_availability_status_check;
+
+ALTER TABLE inventory_availability
+    ADD CONSTRAINT inventory_availability_status_check
+        CHECK (status IN ('held', 'reserved', 'confirmed', 'delivered', 'returned')),
+    ADD COLUMN IF NOT EXISTS held_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_availability_held_until
+    ON inventory_availability (held_until)
+    WHERE status = 'held';
@@ -0,0 +1,9 @@
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS reason;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events
+    ADD CONSTRAINT events_status_check
+    CHECK (status IN (
+        'draft', 'planning', 'requested', 'adjusted',
+        'confirmed', 'paid', 'completed', 'cancelled'
+    )) NOT VALID;
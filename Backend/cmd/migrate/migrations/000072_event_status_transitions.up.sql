@@ -0,0 +1,12 @@
+-- 'rejected' is a real status (RejectQuote sets it) but was missing from
+-- the constraint added in 000043.
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events
+    ADD CONSTRAINT events_status_check
+    CHECK (status IN (
+        'draft', 'planning', 'requested', 'adjusted',
+        'confirmed', 'paid', 'completed', 'cancelled', 'rejected'
+    ));
+
+-- Status transitions record why the event moved.
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS reason TEXT;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS paypal_webhook_events;
+
+DROP INDEX IF EXISTS paypal_transactions_order_id_unique;
+
+ALTER TABLE paypal_transactions
+    DROP CONSTRAINT IF EXISTS paypal_transactions_purpose_check,
+    DROP COLUMN IF EXISTS installment_id,
+    DROP COLUMN IF EXISTS purpose;
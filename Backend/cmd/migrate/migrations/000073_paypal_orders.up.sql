@@ -0,0 +1,23 @@
+-- 000061 created paypal_transactions without the order/capture columns the
+-- store uses. Bring the table in line and track what each payment is for.
+ALTER TABLE paypal_transactions
+    ADD COLUMN IF NOT EXISTS paypal_order_id TEXT,
+    ADD COLUMN IF NOT EXISTS paypal_capture_id TEXT,
+    ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS paypal_response JSONB,
+    ADD COLUMN IF NOT EXISTS purpose TEXT NOT NULL DEFAULT 'full',
+    ADD COLUMN IF NOT EXISTS installment_id UUID REFERENCES installment_payments(id) ON DELETE SET NULL;
+
+ALTER TABLE paypal_transactions
+    ADD CONSTRAINT paypal_transactions_purpose_check
+    CHECK (purpose IN ('deposit', 'full', 'installment'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS paypal_transactions_order_id_unique
+    ON paypal_transactions (paypal_order_id);
+
+-- Webhook deliveries already handled, so PayPal retries are no-ops.
+CREATE TABLE IF NOT EXISTS paypal_webhook_events (
+    id TEXT PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
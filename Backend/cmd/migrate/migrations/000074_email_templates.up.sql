@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS email_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key TEXT NOT NULL,
+    version INT NOT NULL CHECK (version > 0),
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (key, version)
+);
+
+-- At most one active version per template.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_templates_active ON email_templates(key) WHERE active;
@@ -0,0 +1,2 @@
+DELETE FROM notification_logs WHERE type LIKE 'trigger:%';
+DROP TABLE IF EXISTS notification_triggers;
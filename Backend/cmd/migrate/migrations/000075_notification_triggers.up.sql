@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS notification_triggers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    event_type VARCHAR(50) NOT NULL CHECK (event_type IN (
+        'days_before_event', 'days_after_event', 'quote_adjusted', 'installment_due'
+    )),
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'whatsapp', 'push')),
+    template_id VARCHAR(255) NOT NULL,
+    days_offset INT NOT NULL DEFAULT 0 CHECK (days_offset >= 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- The reminders the email and push workers used to hardcode.
+INSERT INTO notification_triggers (id, name, event_type, channel, template_id, days_offset) VALUES
+    ('6f1c2a10-0000-4000-8000-000000000001', 'Recordatorio 7 dias', 'days_before_event', 'email', 'auto_reminder_7d', 7),
+    ('6f1c2a10-0000-4000-8000-000000000002', 'Recordatorio 24h', 'days_before_event', 'email', 'event_reminder_24h', 1),
+    ('6f1c2a10-0000-4000-8000-000000000003', 'Agradecimiento post-evento', 'days_after_event', 'email', 'event_thank_you', 1),
+    ('6f1c2a10-0000-4000-8000-000000000004', 'Push recordatorio 7 dias', 'days_before_event', 'push', 'event_reminder_7d', 7),
+    ('6f1c2a10-0000-4000-8000-000000000005', 'Push recordatorio 24h', 'days_before_event', 'push', 'event_reminder_24h', 1),
+    ('6f1c2a10-0000-4000-8000-000000000006', 'Push solicitud de resena', 'days_after_event', 'push', 'post_event_review', 1),
+    ('6f1c2a10-0000-4000-8000-000000000007', 'Push cotizacion ajustada', 'quote_adjusted', 'push', 'quote_adjusted', 0),
+    ('6f1c2a10-0000-4000-8000-000000000008', 'Push cuota por vencer', 'installment_due', 'push', 'installment_due', 3)
+ON CONFLICT (id) DO NOTHING;
+
+-- Carry over what the old workers already sent so the seeded triggers don't
+-- send it again.
+INSERT INTO notification_logs (event_id, type, sent_at)
+SELECT l.event_id, 'trigger:' || m.trigger_id, l.sent_at
+FROM notification_logs l
+JOIN (VALUES
+    ('auto_reminder_7d', '6f1c2a10-0000-4000-8000-000000000001'),
+    ('auto_reminder_7d', '6f1c2a10-0000-4000-8000-000000000004'),
+    ('email-event-reminder-24h', '6f1c2a10-0000-4000-8000-000000000002'),
+    ('email-event-thank-you', '6f1c2a10-0000-4000-8000-000000000003'),
+    ('pre-event-reminder', '6f1c2a10-0000-4000-8000-000000000005'),
+    ('post-event-review', '6f1c2a10-0000-4000-8000-000000000006')
+) AS m(old_type, trigger_id) ON l.type = m.old_type
+ON CONFLICT (event_id, type) DO NOTHING;
@@ -0,0 +1,8 @@
+DELETE FROM lead_activities WHERE activity_type = 'whatsapp_received';
+ALTER TABLE lead_activities DROP CONSTRAINT IF EXISTS lead_activities_activity_type_check;
+ALTER TABLE lead_activities ADD CONSTRAINT lead_activities_activity_type_check CHECK (activity_type IN (
+    'created', 'status_changed', 'note_added', 'follow_up_set', 'call_made', 'email_sent', 'whatsapp_sent',
+    'meeting_scheduled'));
+
+ALTER TABLE event_messages DROP COLUMN IF EXISTS channel;
+DROP TABLE IF EXISTS whatsapp_messages;
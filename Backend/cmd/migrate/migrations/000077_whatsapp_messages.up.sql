@@ -0,0 +1,33 @@
+-- Every WhatsApp message sent or received through the Cloud API. Inbound
+-- messages from a known client are also copied into event_messages so they
+-- show up in the event chat.
+CREATE TABLE IF NOT EXISTS whatsapp_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wa_message_id TEXT,
+    direction TEXT NOT NULL CHECK (direction IN ('inbound', 'outbound')),
+    phone TEXT NOT NULL,
+    contact_name TEXT,
+    message_type TEXT NOT NULL DEFAULT 'text',
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'received',
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    lead_id UUID REFERENCES leads(id) ON DELETE SET NULL,
+    event_id UUID REFERENCES events(id) ON DELETE SET NULL,
+    event_message_id UUID REFERENCES event_messages(id) ON DELETE SET NULL,
+    sent_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Meta retries webhook deliveries, so the message id makes inserts idempotent.
+CREATE UNIQUE INDEX idx_whatsapp_messages_wa_id ON whatsapp_messages(wa_message_id) WHERE wa_message_id IS NOT NULL;
+CREATE INDEX idx_whatsapp_messages_phone ON whatsapp_messages(phone, created_at DESC);
+CREATE INDEX idx_whatsapp_messages_event ON whatsapp_messages(event_id) WHERE event_id IS NOT NULL;
+
+ALTER TABLE event_messages ADD COLUMN IF NOT EXISTS channel TEXT NOT NULL DEFAULT 'app';
+
+-- Inbound messages from a lead are logged as lead activity.
+ALTER TABLE lead_activities DROP CONSTRAINT IF EXISTS lead_activities_activity_type_check;
+ALTER TABLE lead_activities ADD CONSTRAINT lead_activities_activity_type_check CHECK (activity_type IN (
+    'created', 'status_changed', 'note_added', 'follow_up_set', 'call_made', 'email_sent', 'whatsapp_sent',
+    'meeting_scheduled', 'whatsapp_received'));
@@ -0,0 +1,11 @@
+ALTER TABLE chatbot_conversations DROP CONSTRAINT IF EXISTS chatbot_conversations_faq_id_fkey;
+ALTER TABLE chatbot_conversations ADD CONSTRAINT chatbot_conversations_faq_id_fkey
+    FOREIGN KEY (faq_id) REFERENCES whatsapp_faqs(id);
+DROP INDEX IF EXISTS idx_chatbot_conversations_faq;
+DROP INDEX IF EXISTS idx_chatbot_conversations_intent;
+ALTER TABLE chatbot_conversations DROP COLUMN IF EXISTS feedback_at;
+ALTER TABLE chatbot_conversations DROP COLUMN IF EXISTS intent_id;
+
+DROP TABLE IF EXISTS chatbot_sessions;
+DROP TABLE IF EXISTS chatbot_synonyms;
+DROP TABLE IF EXISTS chatbot_intents;
@@ -0,0 +1,81 @@
+-- Intents the chatbot recognises. A message matches an intent when any of
+-- its phrases appears in it after accents, case and synonyms are normalised.
+CREATE TABLE IF NOT EXISTS chatbot_intents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    phrases TEXT[] NOT NULL DEFAULT '{}',
+    response TEXT NOT NULL,
+    action TEXT NOT NULL DEFAULT '' CHECK (action IN ('', 'collect_lead')),
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS chatbot_synonyms (
+    term TEXT PRIMARY KEY,
+    canonical TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One row per conversation, holding the slots collected so far.
+CREATE TABLE IF NOT EXISTS chatbot_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_key TEXT NOT NULL UNIQUE,
+    client_phone TEXT,
+    client_name TEXT,
+    state TEXT NOT NULL DEFAULT 'idle' CHECK (state IN ('idle', 'collecting')),
+    pending_slot TEXT,
+    slots JSONB NOT NULL DEFAULT '{}',
+    lead_id UUID REFERENCES leads(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- chatbot_conversations holds one row per turn.
+ALTER TABLE chatbot_conversations ADD COLUMN IF NOT EXISTS intent_id UUID REFERENCES chatbot_intents(id) ON DELETE SET NULL;
+ALTER TABLE chatbot_conversations ADD COLUMN IF NOT EXISTS feedback_at TIMESTAMPTZ;
+ALTER TABLE chatbot_conversations DROP CONSTRAINT IF EXISTS chatbot_conversations_faq_id_fkey;
+ALTER TABLE chatbot_conversations ADD CONSTRAINT chatbot_conversations_faq_id_fkey
+    FOREIGN KEY (faq_id) REFERENCES whatsapp_faqs(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_chatbot_conversations_intent ON chatbot_conversations(intent_id);
+CREATE INDEX IF NOT EXISTS idx_chatbot_conversations_faq ON chatbot_conversations(faq_id);
+
+INSERT INTO chatbot_intents (name, description, phrases, response, action, priority) VALUES
+    ('saludo', 'El cliente saluda', ARRAY['hola', 'buenas', 'buenos dias', 'buenas tardes', 'buenas noches', 'saludos'],
+     '¡Hola! Soy el asistente de Rosa Fiesta. Puedo ayudarte con precios, disponibilidad o una cotización para tu evento. ¿En qué te ayudo?', '', 1),
+    ('precio', 'Pregunta por precios', ARRAY['precio'],
+     'Nuestros precios dependen del tipo de evento, la fecha y la cantidad de invitados. Con esos datos te preparamos una cotización.', 'collect_lead', 5),
+    ('cotizacion', 'Pide una cotización', ARRAY['cotizacion'],
+     '¡Con gusto te preparamos una cotización!', 'collect_lead', 6),
+    ('disponibilidad', 'Pregunta si hay disponibilidad', ARRAY['disponible'],
+     'Para verificar disponibilidad necesitamos algunos datos de tu evento.', 'collect_lead', 5),
+    ('asesor', 'Quiere hablar con una persona', ARRAY['asesor', 'humano', 'persona real', 'hablar con alguien'],
+     'Un asesor de nuestro equipo te contactará lo antes posible. Nuestro horario es de lunes a sábado, de 9:00 a.m. a 6:00 p.m.', '', 4),
+    ('gracias', 'El cliente agradece', ARRAY['gracias'],
+     '¡Gracias a ti! Estamos aquí para ayudarte. ¿Hay algo más en lo que podamos asistirte?', '', 1)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO chatbot_synonyms (term, canonical) VALUES
+    ('costo', 'precio'), ('costos', 'precio'), ('precios', 'precio'), ('tarifa', 'precio'), ('tarifas', 'precio'),
+    ('cuanto', 'precio'), ('cuanto cuesta', 'precio'), ('cuanto sale', 'precio'), ('cuanto cobran', 'precio'), ('cuanto vale', 'precio'),
+    ('presupuesto', 'cotizacion'), ('cotizaciones', 'cotizacion'), ('cotizar', 'cotizacion'),
+    ('disponibilidad', 'disponible'), ('disponibles', 'disponible'), ('fecha libre', 'disponible'),
+    ('matrimonio', 'boda'), ('casamiento', 'boda'), ('bodas', 'boda'),
+    ('quinceanera', 'cumpleanos 15'), ('quince anos', 'cumpleanos 15'), ('15 anos', 'cumpleanos 15'),
+    ('cumple', 'cumpleanos'), ('cumpleano', 'cumpleanos'),
+    ('graduaciones', 'graduacion'), ('empresarial', 'corporativo'), ('corporativa', 'corporativo'), ('de empresa', 'corporativo'),
+    ('alquilar', 'alquiler'), ('rentar', 'alquiler'), ('renta', 'alquiler'),
+    ('muchas gracias', 'gracias'), ('buen dia', 'buenos dias')
+ON CONFLICT (term) DO NOTHING;
+
+INSERT INTO whatsapp_faqs (keyword, question, answer, category, priority)
+SELECT v.keyword, v.question, v.answer, v.category, v.priority
+FROM (VALUES
+    ('alquiler', '¿Cómo funciona el alquiler?', 'El alquiler incluye entrega, montaje y recogida. El período típico es de 1 a 3 días.', 'servicios', 8),
+    ('boda', '¿Tienen paquetes para bodas?', 'Sí, ofrecemos paquetes especiales para bodas: decoración completa, mesas, sillas, iluminación y más.', 'eventos', 7),
+    ('deposito', '¿Cómo se paga?', 'Para reservar pedimos un depósito al aprobar la cotización; el resto se paga en cuotas antes del evento.', 'pagos', 6),
+    ('entrega', '¿Hacen entregas?', 'Sí, entregamos y montamos en todo el país. El costo de envío depende de la zona del evento.', 'servicios', 6)
+) AS v(keyword, question, answer, category, priority)
+WHERE NOT EXISTS (SELECT 1 FROM whatsapp_faqs f WHERE f.keyword = v.keyword);
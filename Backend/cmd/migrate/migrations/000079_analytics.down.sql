@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_paypal_transactions_completed;
+DROP INDEX IF EXISTS idx_installment_payments_paid_at;
+DROP INDEX IF EXISTS idx_events_date;
+DROP INDEX IF EXISTS idx_events_event_type_id;
+
+ALTER TABLE events DROP COLUMN IF EXISTS event_type_id;
@@ -0,0 +1,7 @@
+-- Events can be tagged with their type so the dashboard can filter by it.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS event_type_id UUID REFERENCES event_types(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_event_type_id ON events(event_type_id);
+CREATE INDEX IF NOT EXISTS idx_events_date ON events(date);
+CREATE INDEX IF NOT EXISTS idx_installment_payments_paid_at ON installment_payments(paid_at) WHERE payment_status = 'paid';
+CREATE INDEX IF NOT EXISTS idx_paypal_transactions_completed ON paypal_transactions(updated_at) WHERE status = 'completed';
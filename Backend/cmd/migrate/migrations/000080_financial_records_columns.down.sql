@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_financial_records_type;
+DROP INDEX IF EXISTS idx_financial_records_record_date;
+
+ALTER TABLE financial_records DROP CONSTRAINT IF EXISTS financial_records_event_id_fkey;
+ALTER TABLE financial_records DROP CONSTRAINT IF EXISTS financial_records_category_id_fkey;
+
+ALTER TABLE financial_records
+    DROP COLUMN IF EXISTS recorded_by,
+    DROP COLUMN IF EXISTS payment_method,
+    DROP COLUMN IF EXISTS reference_number,
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE financial_records RENAME COLUMN record_date TO date;
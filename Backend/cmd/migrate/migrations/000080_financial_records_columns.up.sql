@@ -0,0 +1,44 @@
+-- 000063 created financial_records without the columns FinancialStore
+-- reads and writes, and without the default categories. Bring the table in
+-- line with the store.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'financial_records' AND column_name = 'date') THEN
+        ALTER TABLE financial_records RENAME COLUMN date TO record_date;
+    END IF;
+END $$;
+
+ALTER TABLE financial_records
+    ADD COLUMN IF NOT EXISTS currency TEXT DEFAULT 'DOP',
+    ADD COLUMN IF NOT EXISTS reference_number TEXT,
+    ADD COLUMN IF NOT EXISTS payment_method TEXT,
+    ADD COLUMN IF NOT EXISTS recorded_by UUID REFERENCES users(id);
+
+ALTER TABLE financial_records DROP CONSTRAINT IF EXISTS financial_records_category_id_fkey;
+ALTER TABLE financial_records
+    ADD CONSTRAINT financial_records_category_id_fkey
+    FOREIGN KEY (category_id) REFERENCES financial_categories(id);
+
+ALTER TABLE financial_records DROP CONSTRAINT IF EXISTS financial_records_event_id_fkey;
+ALTER TABLE financial_records
+    ADD CONSTRAINT financial_records_event_id_fkey
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_financial_records_record_date ON financial_records(record_date);
+CREATE INDEX IF NOT EXISTS idx_financial_records_type ON financial_records(type);
+
+INSERT INTO financial_categories (name, type, color, description)
+SELECT v.name, v.type, v.color, v.description
+FROM (VALUES
+    ('Alquiler de equipos', 'income', '#22C55E', 'Ingresos por alquiler de artículos'),
+    ('Venta de productos', 'income', '#10B981', 'Ingresos por ventas directas'),
+    ('Servicios adicionales', 'income', '#14B8A6', 'Cargos por delivery, montaje, etc'),
+    ('Proveedores', 'expense', '#EF4444', 'Pagos a proveedores y servicios'),
+    ('Transporte', 'expense', '#F97316', 'Gastos de transporte y combustible'),
+    ('Mantenimiento', 'expense', '#EAB308', 'Reparación y limpieza de equipos'),
+    ('Servicios públicos', 'expense', '#6366F1', 'Electricidad, agua, internet'),
+    ('Marketing', 'expense', '#EC4899', 'Publicidad y promoción'),
+    ('Administración', 'expense', '#8B5CF6', 'Gastos generales de oficina')
+) AS v(name, type, color, description)
+WHERE NOT EXISTS (SELECT 1 FROM financial_categories c WHERE c.name = v.name AND c.type = v.type);
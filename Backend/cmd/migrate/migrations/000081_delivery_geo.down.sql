@@ -0,0 +1,7 @@
+ALTER TABLE delivery_zones DROP CONSTRAINT IF EXISTS delivery_zones_radius_check;
+ALTER TABLE delivery_zones DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_coordinates_check;
+ALTER TABLE events DROP COLUMN IF EXISTS delivery_fee;
+ALTER TABLE events DROP COLUMN IF EXISTS longitude;
+ALTER TABLE events DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,18 @@
+-- Events are located by coordinates so the delivery fee can be computed from
+-- the distance to the warehouse. The fee is kept on the event and added to
+-- its quote.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS delivery_fee DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE events ADD CONSTRAINT events_coordinates_check CHECK (
+    (latitude IS NULL) = (longitude IS NULL)
+    AND (latitude IS NULL OR latitude BETWEEN -90 AND 90)
+    AND (longitude IS NULL OR longitude BETWEEN -180 AND 180)
+);
+
+-- Zones are edited from the admin panel now.
+ALTER TABLE delivery_zones ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ DEFAULT NOW();
+ALTER TABLE delivery_zones ADD CONSTRAINT delivery_zones_radius_check CHECK (
+    base_radius_km >= 0 AND max_radius_km > base_radius_km AND travel_fee >= 0
+);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_installment_payments_pending_verification;
+
+-- Payments still waiting for verification go back to unpaid.
+UPDATE installment_payments SET payment_status = 'pending' WHERE payment_status = 'pending_verification';
+DELETE FROM installment_payments WHERE purpose <> 'installment' AND payment_status <> 'paid';
+
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS verified_by;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS verified_at;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS submitted_at;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS surcharge;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS purpose;
+
+DROP TABLE IF EXISTS payment_methods;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    -- Shown to the client when paying, e.g. the bank accounts for transfers.
+    instructions TEXT NOT NULL DEFAULT '',
+    surcharge_percent DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (surcharge_percent >= 0 AND surcharge_percent <= 100),
+    -- Payments with these methods wait for an admin to confirm the receipt.
+    requires_confirmation BOOLEAN NOT NULL DEFAULT false,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO payment_methods (code, name, enabled, instructions, surcharge_percent, requires_confirmation, sort_order) VALUES
+    ('transferencia', 'Transferencia bancaria', true,
+     E'Transferencia bancaria a nombre de RosaFiesta.\nBanco Popular: Cuenta de Ahorros No. XXXX-XXXX-XXXX\nBanreservas: Cuenta de Ahorros No. YYYY-YYYY-YYYY\nEnviar comprobante de pago al correo administracion@rosafiesta.com',
+     0, true, 1),
+    ('efectivo', 'Efectivo', true,
+     E'Pago en efectivo en nuestras oficinas.\nHorario: Lunes a Viernes 9:00 AM - 5:00 PM',
+     0, true, 2),
+    ('tarjeta', 'Tarjeta de crédito/débito', false,
+     'Pago con tarjeta de crédito/débito. Se aplica un cargo adicional del 3% por procesamiento.',
+     3, false, 3),
+    ('paypal', 'PayPal', true, '', 0, false, 4)
+ON CONFLICT (code) DO NOTHING;
+
+-- Installments now also record deposits and full payments made by hand, so
+-- the receipt of a transfer can be confirmed before the event is marked paid.
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS purpose VARCHAR(20) NOT NULL DEFAULT 'installment'
+    CHECK (purpose IN ('deposit', 'full', 'installment'));
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS surcharge INT NOT NULL DEFAULT 0;
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ;
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS verified_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_installment_payments_pending_verification
+    ON installment_payments(submitted_at) WHERE payment_status = 'pending_verification';
@@ -0,0 +1,4 @@
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS rejected_by;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS rejected_at;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS rejection_reason;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS receipt_url;
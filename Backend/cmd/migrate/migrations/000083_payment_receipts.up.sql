@@ -0,0 +1,6 @@
+-- Clients upload a receipt against a deposit or installment; an admin then
+-- approves it, or rejects it with a reason the client can see.
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS receipt_url TEXT;
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS rejected_at TIMESTAMPTZ;
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS rejected_by UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+DELETE FROM notification_triggers WHERE event_type = 'installment_overdue';
+ALTER TABLE notification_triggers DROP CONSTRAINT IF EXISTS notification_triggers_event_type_check;
+ALTER TABLE notification_triggers ADD CONSTRAINT notification_triggers_event_type_check CHECK (event_type IN (
+    'days_before_event', 'days_after_event', 'quote_adjusted', 'installment_due'
+));
+
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS label;
+ALTER TABLE installment_payments DROP COLUMN IF EXISTS sequence;
+
+ALTER TABLE events DROP COLUMN IF EXISTS payment_plan_id;
+ALTER TABLE event_types DROP COLUMN IF EXISTS payment_plan_id;
+
+DROP TABLE IF EXISTS payment_plan_installments;
+DROP TABLE IF EXISTS payment_plans;
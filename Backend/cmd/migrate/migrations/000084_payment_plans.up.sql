@@ -0,0 +1,53 @@
+-- Payment plans split a quote into installments due relative to the event
+-- date. An event uses its own plan, else its event type's, else the default.
+CREATE TABLE IF NOT EXISTS payment_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_plans_default ON payment_plans(is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS payment_plan_installments (
+    plan_id UUID NOT NULL REFERENCES payment_plans(id) ON DELETE CASCADE,
+    sequence INT NOT NULL CHECK (sequence > 0),
+    label VARCHAR(100) NOT NULL DEFAULT '',
+    percent DECIMAL(5,2) NOT NULL CHECK (percent > 0 AND percent <= 100),
+    -- NULL means due as soon as the quote is approved.
+    days_before_event INT CHECK (days_before_event >= 0),
+    PRIMARY KEY (plan_id, sequence)
+);
+
+ALTER TABLE event_types ADD COLUMN IF NOT EXISTS payment_plan_id UUID REFERENCES payment_plans(id) ON DELETE SET NULL;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS payment_plan_id UUID REFERENCES payment_plans(id) ON DELETE SET NULL;
+
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS sequence INT;
+ALTER TABLE installment_payments ADD COLUMN IF NOT EXISTS label VARCHAR(100) NOT NULL DEFAULT '';
+
+-- The default keeps the old 50% deposit with the rest due a week before
+-- the event.
+INSERT INTO payment_plans (id, name, description, is_default) VALUES
+    ('8b2d4c10-0000-4000-8000-000000000001', 'Reserva 50%', '50% al aprobar la cotización y 50% una semana antes del evento', true),
+    ('8b2d4c10-0000-4000-8000-000000000002', '30/40/30', '30% al aprobar, 40% un mes antes y 30% una semana antes del evento', false)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO payment_plan_installments (plan_id, sequence, label, percent, days_before_event) VALUES
+    ('8b2d4c10-0000-4000-8000-000000000001', 1, 'Reserva', 50, NULL),
+    ('8b2d4c10-0000-4000-8000-000000000001', 2, 'Saldo', 50, 7),
+    ('8b2d4c10-0000-4000-8000-000000000002', 1, 'Reserva', 30, NULL),
+    ('8b2d4c10-0000-4000-8000-000000000002', 2, 'Segundo pago', 40, 30),
+    ('8b2d4c10-0000-4000-8000-000000000002', 3, 'Saldo', 30, 7)
+ON CONFLICT (plan_id, sequence) DO NOTHING;
+
+-- Installments still unpaid after their due date.
+ALTER TABLE notification_triggers DROP CONSTRAINT IF EXISTS notification_triggers_event_type_check;
+ALTER TABLE notification_triggers ADD CONSTRAINT notification_triggers_event_type_check CHECK (event_type IN (
+    'days_before_event', 'days_after_event', 'quote_adjusted', 'installment_due', 'installment_overdue'
+));
+
+INSERT INTO notification_triggers (id, name, event_type, channel, template_id, days_offset) VALUES
+    ('6f1c2a10-0000-4000-8000-000000000009', 'Push cuota vencida', 'installment_overdue', 'push', 'installment_overdue', 1)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_vendor_payments_event_id;
+ALTER TABLE vendor_payments
+    DROP COLUMN IF EXISTS event_id,
+    DROP COLUMN IF EXISTS recorded_by,
+    DROP COLUMN IF EXISTS currency;
+ALTER TABLE vendor_payments RENAME COLUMN description TO notes;
+ALTER TABLE vendor_payments RENAME COLUMN reference_number TO reference;
+
+DROP INDEX IF EXISTS idx_financial_records_source;
+ALTER TABLE financial_records
+    DROP COLUMN IF EXISTS source_id,
+    DROP COLUMN IF EXISTS source_type;
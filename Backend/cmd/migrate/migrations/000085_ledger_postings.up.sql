@@ -0,0 +1,39 @@
+-- Client and vendor payments post their own financial record. The source
+-- columns link a record to the payment that posted it, and the unique index
+-- keeps a replayed payment from posting twice.
+ALTER TABLE financial_records
+    ADD COLUMN IF NOT EXISTS source_type TEXT
+        CHECK (source_type IN ('installment_payment', 'vendor_payment')),
+    ADD COLUMN IF NOT EXISTS source_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_financial_records_source
+    ON financial_records(source_type, source_id) WHERE source_id IS NOT NULL;
+
+-- Confirmed payments were already booked with the payment ID as reference.
+UPDATE financial_records fr
+SET source_type = 'installment_payment', source_id = ip.id
+FROM installment_payments ip
+WHERE fr.source_id IS NULL
+  AND fr.type = 'income'
+  AND fr.reference_number = ip.id::text;
+
+-- 000063 created vendor_payments without the columns FinancialStore reads
+-- and writes.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'vendor_payments' AND column_name = 'reference') THEN
+        ALTER TABLE vendor_payments RENAME COLUMN reference TO reference_number;
+    END IF;
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'vendor_payments' AND column_name = 'notes') THEN
+        ALTER TABLE vendor_payments RENAME COLUMN notes TO description;
+    END IF;
+END $$;
+
+ALTER TABLE vendor_payments
+    ADD COLUMN IF NOT EXISTS currency TEXT DEFAULT 'DOP',
+    ADD COLUMN IF NOT EXISTS recorded_by UUID REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS event_id UUID REFERENCES events(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_vendor_payments_event_id ON vendor_payments(event_id);
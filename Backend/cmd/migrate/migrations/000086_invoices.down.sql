@@ -0,0 +1,36 @@
+DROP TABLE IF EXISTS invoice_lines;
+DROP INDEX IF EXISTS idx_invoices_event_id;
+DROP INDEX IF EXISTS idx_invoices_ncf;
+DROP TABLE IF EXISTS invoice_sequences;
+
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_fiscal_year_sequence_key;
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_status_check;
+UPDATE invoices SET status = CASE status
+    WHEN 'issued' THEN 'draft'
+    WHEN 'partially_paid' THEN 'sent'
+    WHEN 'void' THEN 'cancelled'
+    ELSE status END;
+ALTER TABLE invoices ALTER COLUMN status DROP NOT NULL;
+ALTER TABLE invoices ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE invoices ADD CONSTRAINT invoices_status_check
+    CHECK (status IN ('draft', 'sent', 'paid', 'cancelled', 'overdue'));
+
+ALTER TABLE invoices
+    DROP COLUMN IF EXISTS void_reason,
+    DROP COLUMN IF EXISTS voided_at,
+    DROP COLUMN IF EXISTS sent_at,
+    DROP COLUMN IF EXISTS client_rnc,
+    DROP COLUMN IF EXISTS ncf,
+    DROP COLUMN IF EXISTS tax_included,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS sequence,
+    DROP COLUMN IF EXISTS fiscal_year,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS terms,
+    DROP COLUMN IF EXISTS issue_date,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS amount_paid,
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS event_id;
+
+ALTER TABLE invoices RENAME COLUMN paid_date TO paid_at;
@@ -0,0 +1,78 @@
+-- 000063 created invoices without most of the columns FinancialStore reads
+-- and writes. Bring the table in line with the store, and add the fiscal
+-- fields and the lifecycle.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'invoices' AND column_name = 'paid_at') THEN
+        ALTER TABLE invoices RENAME COLUMN paid_at TO paid_date;
+    END IF;
+END $$;
+
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS event_id UUID REFERENCES events(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'DOP',
+    ADD COLUMN IF NOT EXISTS issue_date TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS terms TEXT,
+    ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS fiscal_year INT,
+    ADD COLUMN IF NOT EXISTS sequence INT,
+    ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5,4) NOT NULL DEFAULT 0.18,
+    ADD COLUMN IF NOT EXISTS tax_included BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS ncf TEXT,
+    ADD COLUMN IF NOT EXISTS client_rnc TEXT,
+    ADD COLUMN IF NOT EXISTS sent_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS void_reason TEXT;
+
+-- issued -> sent -> partially_paid -> paid, and void from any unpaid status.
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_status_check;
+UPDATE invoices SET status = CASE status
+    WHEN 'draft' THEN 'issued'
+    WHEN 'overdue' THEN 'sent'
+    WHEN 'cancelled' THEN 'void'
+    ELSE status END;
+ALTER TABLE invoices ALTER COLUMN status SET DEFAULT 'issued';
+ALTER TABLE invoices ALTER COLUMN status SET NOT NULL;
+ALTER TABLE invoices ADD CONSTRAINT invoices_status_check
+    CHECK (status IN ('issued', 'sent', 'partially_paid', 'paid', 'void'));
+
+-- Invoices are numbered per year without gaps. The counter row is bumped in
+-- the transaction that creates the invoice, so a rolled back invoice gives
+-- its number back.
+UPDATE invoices i
+SET fiscal_year = n.fiscal_year, sequence = n.sequence
+FROM (
+    SELECT id, EXTRACT(YEAR FROM issue_date)::int AS fiscal_year,
+           ROW_NUMBER() OVER (PARTITION BY EXTRACT(YEAR FROM issue_date) ORDER BY issue_date, created_at, id) AS sequence
+    FROM invoices
+) n
+WHERE i.id = n.id;
+
+ALTER TABLE invoices ALTER COLUMN fiscal_year SET NOT NULL;
+ALTER TABLE invoices ALTER COLUMN sequence SET NOT NULL;
+ALTER TABLE invoices ADD CONSTRAINT invoices_fiscal_year_sequence_key UNIQUE (fiscal_year, sequence);
+
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    fiscal_year INT PRIMARY KEY,
+    last_number INT NOT NULL
+);
+
+INSERT INTO invoice_sequences (fiscal_year, last_number)
+SELECT fiscal_year, MAX(sequence) FROM invoices GROUP BY fiscal_year
+ON CONFLICT (fiscal_year) DO NOTHING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_ncf ON invoices(ncf) WHERE ncf IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_invoices_event_id ON invoices(event_id);
+
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    description TEXT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10,2) NOT NULL,
+    total DECIMAL(10,2) NOT NULL,
+    PRIMARY KEY (invoice_id, position)
+);
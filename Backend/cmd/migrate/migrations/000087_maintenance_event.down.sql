@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_article_maintenance_logs_event_id;
+ALTER TABLE article_maintenance_logs DROP COLUMN IF EXISTS event_id;
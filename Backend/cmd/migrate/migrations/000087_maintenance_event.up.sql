@@ -0,0 +1,6 @@
+-- Maintenance caused by damage at an event is charged to that event's
+-- costs.
+ALTER TABLE article_maintenance_logs
+    ADD COLUMN IF NOT EXISTS event_id UUID REFERENCES events(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_article_maintenance_logs_event_id ON article_maintenance_logs(event_id);
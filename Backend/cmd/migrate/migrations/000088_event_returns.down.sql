@@ -0,0 +1,10 @@
+DELETE FROM installment_payments WHERE purpose = 'damage' AND payment_status <> 'paid';
+UPDATE installment_payments SET purpose = 'full' WHERE purpose = 'damage';
+ALTER TABLE installment_payments DROP CONSTRAINT IF EXISTS installment_payments_purpose_check;
+ALTER TABLE installment_payments ADD CONSTRAINT installment_payments_purpose_check
+    CHECK (purpose IN ('deposit', 'full', 'installment'));
+
+ALTER TABLE article_maintenance_logs DROP COLUMN IF EXISTS stock_held;
+
+DROP TABLE IF EXISTS event_return_lines;
+DROP TABLE IF EXISTS event_returns;
@@ -0,0 +1,38 @@
+-- Return inspections record how an event's items came back. Damaged units
+-- go to maintenance and stay out of stock until repaired; lost units are
+-- taken out of stock for good. The client is billed for both through a
+-- damage installment.
+CREATE TABLE IF NOT EXISTS event_returns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL UNIQUE REFERENCES events(id) ON DELETE CASCADE,
+    inspected_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    damage_charge INT NOT NULL DEFAULT 0,
+    damage_charge_id UUID REFERENCES installment_payments(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS event_return_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    return_id UUID NOT NULL REFERENCES event_returns(id) ON DELETE CASCADE,
+    event_item_id UUID REFERENCES event_items(id) ON DELETE SET NULL,
+    article_id UUID NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES article_variants(id) ON DELETE SET NULL,
+    name TEXT NOT NULL DEFAULT '',
+    quantity INT NOT NULL CHECK (quantity >= 0),
+    damaged INT NOT NULL DEFAULT 0 CHECK (damaged >= 0),
+    lost INT NOT NULL DEFAULT 0 CHECK (lost >= 0),
+    charge DECIMAL(10,2) NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT '',
+    maintenance_log_id UUID REFERENCES article_maintenance_logs(id) ON DELETE SET NULL,
+    CHECK (damaged + lost <= quantity)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_return_lines_return_id ON event_return_lines(return_id);
+
+-- Units a maintenance log took out of stock, given back when it completes.
+ALTER TABLE article_maintenance_logs ADD COLUMN IF NOT EXISTS stock_held INT NOT NULL DEFAULT 0;
+
+ALTER TABLE installment_payments DROP CONSTRAINT IF EXISTS installment_payments_purpose_check;
+ALTER TABLE installment_payments ADD CONSTRAINT installment_payments_purpose_check
+    CHECK (purpose IN ('deposit', 'full', 'installment', 'damage'));
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS token_version,
+    DROP COLUMN IF EXISTS blocked_by,
+    DROP COLUMN IF EXISTS blocked_reason,
+    DROP COLUMN IF EXISTS blocked_until,
+    DROP COLUMN IF EXISTS blocked_at;
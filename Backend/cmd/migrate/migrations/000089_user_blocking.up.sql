@@ -0,0 +1,11 @@
+-- A blocked user can't sign in or use the API. blocked_until makes the
+-- block a suspension that lifts by itself; NULL blocks until an admin
+-- unblocks the user.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS blocked_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS blocked_until TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS blocked_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS blocked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    -- Access tokens carry the version they were issued at; bumping it
+    -- revokes every outstanding token of the user.
+    ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
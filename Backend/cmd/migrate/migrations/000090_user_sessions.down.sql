@@ -0,0 +1,10 @@
+-- Hashed tokens can't be turned back into usable ones.
+DELETE FROM refresh_tokens;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_session_id;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS used_at,
+    DROP COLUMN IF EXISTS session_id;
+
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,28 @@
+-- A session is a family of refresh tokens issued to one device: every
+-- refresh rotates the token within the family. Presenting a token that was
+-- already rotated means it was copied, and the whole session is revoked.
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES user_sessions(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS used_at TIMESTAMP(0) WITH TIME ZONE;
+
+-- Existing tokens each become a session of their own, and are stored
+-- hashed like the new ones.
+INSERT INTO user_sessions (id, user_id, created_at, last_used_at)
+SELECT id, user_id, created_at, updated_at FROM refresh_tokens;
+
+UPDATE refresh_tokens SET session_id = id, token = encode(sha256(token::bytea), 'hex');
+
+ALTER TABLE refresh_tokens ALTER COLUMN session_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
@@ -0,0 +1,15 @@
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS level INT NOT NULL DEFAULT 0;
+
+UPDATE roles SET level = 2 WHERE name = 'admin';
+UPDATE roles SET level = 1 WHERE name = 'moderator';
+
+-- Roles created since have no level to fall back on; their users get the
+-- default role.
+UPDATE users SET role_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE role_id IN (SELECT id FROM roles WHERE name NOT IN ('admin', 'moderator', 'user', 'client'));
+
+DELETE FROM roles WHERE name NOT IN ('admin', 'moderator', 'user', 'client');
+
+ALTER TABLE roles DROP COLUMN IF EXISTS is_system;
+
+DROP TABLE IF EXISTS role_permissions;
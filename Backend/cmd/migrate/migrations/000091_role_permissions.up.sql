@@ -0,0 +1,45 @@
+-- Roles are granted named permissions instead of being ranked by level.
+-- The permission names are defined by the application (models.Permissions).
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE roles SET is_system = TRUE WHERE name IN ('admin', 'user');
+
+INSERT INTO roles (name, description)
+VALUES ('sales', 'Ventas: prospectos, clientes y eventos'),
+       ('warehouse', 'Almacén: inventario, mantenimiento y devoluciones'),
+       ('accountant', 'Contabilidad: finanzas, facturas y pagos')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+JOIN (VALUES
+    ('admin', 'dashboard:read'), ('admin', 'events:read'), ('admin', 'events:write'),
+    ('admin', 'clients:read'), ('admin', 'clients:write'), ('admin', 'users:block'),
+    ('admin', 'leads:read'), ('admin', 'leads:write'), ('admin', 'inventory:read'),
+    ('admin', 'inventory:write'), ('admin', 'inventory:adjust'), ('admin', 'financial:read'),
+    ('admin', 'financial:write'), ('admin', 'messages:write'), ('admin', 'content:moderate'),
+    ('admin', 'settings:write'), ('admin', 'audit:read'), ('admin', 'roles:manage'),
+
+    -- Moderators kept what their level gave them: managing the catalog
+    -- and moderating content.
+    ('moderator', 'inventory:read'), ('moderator', 'inventory:write'), ('moderator', 'content:moderate'),
+
+    ('sales', 'events:read'), ('sales', 'events:write'), ('sales', 'clients:read'),
+    ('sales', 'clients:write'), ('sales', 'leads:read'), ('sales', 'leads:write'),
+    ('sales', 'inventory:read'), ('sales', 'messages:write'),
+
+    ('warehouse', 'events:read'), ('warehouse', 'inventory:read'), ('warehouse', 'inventory:adjust'),
+
+    ('accountant', 'dashboard:read'), ('accountant', 'events:read'), ('accountant', 'clients:read'),
+    ('accountant', 'financial:read'), ('accountant', 'financial:write'), ('accountant', 'audit:read')
+) AS p(role, permission) ON p.role = r.name
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE roles DROP COLUMN IF EXISTS level;
@@ -0,0 +1,32 @@
+-- A user's TOTP authenticator. The secret is stored when enrollment
+-- starts and only protects the account once the user has proven they can
+-- generate codes with it and enabled_at is set.
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMP(0) WITH TIME ZONE,
+    -- The time step of the last code accepted, so a code can't be replayed.
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Single-use codes to sign in with when the authenticator is lost, stored
+-- hashed.
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code TEXT NOT NULL,
+    used_at TIMESTAMP(0) WITH TIME ZONE,
+    UNIQUE (user_id, code)
+);
+
+-- Issued when the password checks out for a user with two-factor enabled,
+-- and exchanged for tokens with a code.
+CREATE TABLE IF NOT EXISTS two_factor_challenges (
+    token TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP(0) WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_two_factor_challenges_user_id ON two_factor_challenges(user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_rental_window_check,
+    DROP COLUMN IF EXISTS return_at,
+    DROP COLUMN IF EXISTS pickup_at;
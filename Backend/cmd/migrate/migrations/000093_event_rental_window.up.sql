@@ -0,0 +1,6 @@
+-- An event's own rental window. Without one the items go out the day before
+-- the event and come back the day after.
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS pickup_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS return_at TIMESTAMPTZ,
+    ADD CONSTRAINT events_rental_window_check CHECK (return_at > pickup_at);
@@ -0,0 +1,7 @@
+DELETE FROM financial_records WHERE source_type = 'installment_refund';
+ALTER TABLE financial_records DROP CONSTRAINT IF EXISTS financial_records_source_type_check;
+ALTER TABLE financial_records
+    ADD CONSTRAINT financial_records_source_type_check
+    CHECK (source_type IN ('installment_payment', 'vendor_payment'));
+
+DROP TABLE IF EXISTS paypal_transaction_installments;
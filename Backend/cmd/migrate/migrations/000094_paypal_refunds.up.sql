@@ -0,0 +1,20 @@
+-- The installments each PayPal order paid, so a refund can reopen them.
+CREATE TABLE IF NOT EXISTS paypal_transaction_installments (
+    transaction_id UUID NOT NULL REFERENCES paypal_transactions(id) ON DELETE CASCADE,
+    installment_id UUID NOT NULL REFERENCES installment_payments(id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, installment_id)
+);
+
+-- Orders for a single installment already say which one they paid.
+INSERT INTO paypal_transaction_installments (transaction_id, installment_id)
+SELECT id, installment_id
+FROM paypal_transactions
+WHERE status = 'completed' AND purpose = 'installment' AND installment_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- A refunded payment posts a negative income record that points at the
+-- record it reverses.
+ALTER TABLE financial_records DROP CONSTRAINT IF EXISTS financial_records_source_type_check;
+ALTER TABLE financial_records
+    ADD CONSTRAINT financial_records_source_type_check
+    CHECK (source_type IN ('installment_payment', 'vendor_payment', 'installment_refund'));
@@ -5,6 +5,9 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// JWTAuthenticator signs tokens with a shared HS256 secret. Only whoever
+// holds the secret can verify them, so it publishes no keys; use
+// KeySetAuthenticator when other services need to verify tokens.
 type JWTAuthenticator struct {
 	secret string
 	aud    string
@@ -21,8 +24,6 @@ func NewJWTAuthenticator(secret, aud, iss string) *JWTAuthenticator {
 
 func (a *JWTAuthenticator) GenerateToken(claims jwt.Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token.Header["aud"] = a.aud
-	token.Header["iss"] = a.iss
 
 	tokenString, err := token.SignedString([]byte(a.secret))
 
@@ -47,3 +48,7 @@ func (a *JWTAuthenticator) ValidateToken(tokenString string) (*jwt.Token, error)
 		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
 	)
 }
+
+func (a *JWTAuthenticator) JWKS() JWKS {
+	return JWKS{Keys: []JWK{}}
+}
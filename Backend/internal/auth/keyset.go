@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a private key tokens are signed with from ActiveAt on.
+type SigningKey struct {
+	ID       string
+	ActiveAt time.Time
+	Key      crypto.Signer
+}
+
+func (k SigningKey) method() jwt.SigningMethod {
+	switch k.Key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+// KeySetAuthenticator signs tokens with RS256 or EdDSA keys identified by
+// their kid. Keys rotate on a schedule: the most recent key whose ActiveAt
+// has passed signs new tokens, and the key it replaced is still accepted
+// for maxAge, the longest a token it signed can live.
+type KeySetAuthenticator struct {
+	keys   []SigningKey // sorted by ActiveAt
+	aud    string
+	iss    string
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+func NewKeySetAuthenticator(keys []SigningKey, aud, iss string, maxAge time.Duration) (*KeySetAuthenticator, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.method() == nil {
+			return nil, fmt.Errorf("key %s: only RSA and Ed25519 keys are supported", k.ID)
+		}
+		if seen[k.ID] {
+			return nil, fmt.Errorf("duplicate key id %s", k.ID)
+		}
+		seen[k.ID] = true
+	}
+
+	sorted := append([]SigningKey(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ActiveAt.Before(sorted[j].ActiveAt) })
+	return &KeySetAuthenticator{keys: sorted, aud: aud, iss: iss, maxAge: maxAge, now: time.Now}, nil
+}
+
+// LoadSigningKeys reads the PEM private keys in dir. Each file is named
+// after the day its key starts signing, optionally followed by a suffix,
+// and the name without extension is the key's kid: 2026-10-01.pem, or
+// 2026-10-01-rsa.pem. Keys can be generated with
+//
+//	openssl genpkey -algorithm ed25519 -out 2026-10-01.pem
+//	openssl genpkey -algorithm rsa -pkeyopt rsa_keygen_bits:2048 -out 2026-10-01.pem
+func LoadSigningKeys(dir string) ([]SigningKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]SigningKey, 0, len(files))
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), ".pem")
+		if len(id) < len(time.DateOnly) {
+			return nil, fmt.Errorf("%s: file name must start with the date the key becomes active", file)
+		}
+		activeAt, err := time.Parse(time.DateOnly, id[:len(time.DateOnly)])
+		if err != nil {
+			return nil, fmt.Errorf("%s: file name must start with the date the key becomes active", file)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		keys = append(keys, SigningKey{ID: id, ActiveAt: activeAt, Key: key})
+	}
+	return keys, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("key can't sign")
+	}
+	return signer, nil
+}
+
+// signingKey is the key new tokens are signed with.
+func (a *KeySetAuthenticator) signingKey() (SigningKey, bool) {
+	now := a.now()
+	for i := len(a.keys) - 1; i >= 0; i-- {
+		if !a.keys[i].ActiveAt.After(now) {
+			return a.keys[i], true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// verificationKeys are the keys tokens may have been signed with: the
+// current key, the keys it replaced less than maxAge ago, and the keys
+// scheduled to sign next, so verifiers know them before they are used.
+func (a *KeySetAuthenticator) verificationKeys() []SigningKey {
+	now := a.now()
+	keys := make([]SigningKey, 0, len(a.keys))
+	for i, k := range a.keys {
+		if i+1 < len(a.keys) {
+			replacedAt := a.keys[i+1].ActiveAt
+			if !replacedAt.After(now) && now.Sub(replacedAt) > a.maxAge {
+				continue
+			}
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (a *KeySetAuthenticator) GenerateToken(claims jwt.Claims) (string, error) {
+	key, ok := a.signingKey()
+	if !ok {
+		return "", errors.New("no signing key is active yet")
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Key)
+}
+
+func (a *KeySetAuthenticator) ValidateToken(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, k := range a.verificationKeys() {
+			if k.ID != kid {
+				continue
+			}
+			if token.Method != k.method() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return k.Key.Public(), nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	},
+		jwt.WithExpirationRequired(),
+		jwt.WithAudience(a.aud),
+		jwt.WithIssuer(a.iss),
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithTimeFunc(a.now),
+	)
+}
+
+// JWKS publishes the public verification keys.
+func (a *KeySetAuthenticator) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range a.verificationKeys() {
+		jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.method().Alg()}
+		switch pub := k.Key.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
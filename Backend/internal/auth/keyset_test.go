@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestKeySetAuthenticator(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := SigningKey{ID: "2026-01-01", ActiveAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Key: edKey}
+	next := SigningKey{ID: "2026-06-01", ActiveAt: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), Key: rsaKey}
+	a, err := NewKeySetAuthenticator([]SigningKey{next, old}, "aud", "iss", 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(now time.Time) { a.now = func() time.Time { return now } }
+	sign := func(t *testing.T) string {
+		t.Helper()
+		token, err := a.GenerateToken(jwt.MapClaims{"sub": "user", "aud": "aud", "iss": "iss", "exp": a.now().Add(60 * 24 * time.Hour).Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	kids := func() []string {
+		var ids []string
+		for _, k := range a.JWKS().Keys {
+			ids = append(ids, k.KeyID)
+		}
+		return ids
+	}
+
+	at(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+	before := sign(t)
+	parsed, err := a.ValidateToken(before)
+	if err != nil {
+		t.Fatalf("expected token signed with the current key to be valid: %v", err)
+	}
+	if parsed.Header["kid"] != old.ID || parsed.Method != jwt.SigningMethodEdDSA {
+		t.Fatalf("expected an EdDSA token from %s, got %v from %v", old.ID, parsed.Method.Alg(), parsed.Header["kid"])
+	}
+	if got := kids(); len(got) != 2 {
+		t.Fatalf("expected the upcoming key to be published, got %v", got)
+	}
+
+	t.Run("rotates to the next key on schedule", func(t *testing.T) {
+		at(time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC))
+		parsed, err := a.ValidateToken(sign(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Header["kid"] != next.ID || parsed.Method != jwt.SigningMethodRS256 {
+			t.Fatalf("expected an RS256 token from %s, got %v from %v", next.ID, parsed.Method.Alg(), parsed.Header["kid"])
+		}
+	})
+
+	t.Run("accepts the old key while its tokens can live", func(t *testing.T) {
+		at(time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC))
+		if _, err := a.ValidateToken(before); err != nil {
+			t.Fatalf("expected tokens of the replaced key to still be valid: %v", err)
+		}
+	})
+
+	t.Run("retires the old key", func(t *testing.T) {
+		at(time.Date(2026, 6, 9, 0, 0, 0, 0, time.UTC))
+		if got := kids(); len(got) != 1 || got[0] != next.ID {
+			t.Fatalf("expected only %s to be published, got %v", next.ID, got)
+		}
+		_, err := a.ValidateToken(before)
+		if err == nil {
+			t.Fatal("expected tokens of the retired key to be rejected")
+		}
+	})
+
+	t.Run("rejects HS256 tokens", func(t *testing.T) {
+		at(time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC))
+		token, _ := NewJWTAuthenticator("secret", "aud", "iss").GenerateToken(jwt.MapClaims{"aud": "aud", "iss": "iss", "exp": time.Now().Add(time.Hour).Unix()})
+		if _, err := a.ValidateToken(token); err == nil {
+			t.Fatal("expected HS256 tokens to be rejected")
+		}
+	})
+}
+
+func TestLoadSigningKeys(t *testing.T) {
+	dir := t.TempDir()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, "2026-10-01-ed.pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadSigningKeys(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].ID != "2026-10-01-ed" || !keys[0].ActiveAt.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected keys %+v", keys)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "current.pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSigningKeys(dir); err == nil {
+		t.Fatal("expected keys not named after a date to be rejected")
+	}
+}
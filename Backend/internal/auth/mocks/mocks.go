@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"Backend/internal/auth"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,3 +23,8 @@ func (m *Authenticator) ValidateToken(token string) (*jwt.Token, error) {
 	}
 	return args.Get(0).(*jwt.Token), args.Error(1)
 }
+
+func (m *Authenticator) JWKS() auth.JWKS {
+	args := m.Called()
+	return args.Get(0).(auth.JWKS)
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// The SHA1 secret from RFC 6238 appendix B, "12345678901234567890".
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	t.Run("matches the RFC test vectors", func(t *testing.T) {
+		for unix, want := range map[int64]string{
+			59:         "287082",
+			1111111109: "081804",
+			1234567890: "005924",
+			2000000000: "279037",
+		} {
+			got, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("at %d: expected %s, got %s", unix, want, got)
+			}
+		}
+	})
+
+	t.Run("accepts codes one step off", func(t *testing.T) {
+		now := time.Unix(1234567890, 0)
+		previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+		step, ok := ValidateTOTP(secret, previous, now)
+		if !ok || step != TOTPStep(now)-1 {
+			t.Fatalf("expected the previous step's code to be accepted, got %d %v", step, ok)
+		}
+
+		stale, _ := TOTPCode(secret, TOTPStep(now)-2)
+		if _, ok := ValidateTOTP(secret, stale, now); ok {
+			t.Fatal("expected a code two steps old to be rejected")
+		}
+	})
+
+	t.Run("rejects malformed codes", func(t *testing.T) {
+		for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+			if _, ok := ValidateTOTP(secret, code, time.Now()); ok {
+				t.Errorf("expected %q to be rejected", code)
+			}
+		}
+	})
+
+	t.Run("provisioning URI", func(t *testing.T) {
+		u, err := url.Parse(TOTPProvisioningURI(secret, "Rosa Fiesta", "ana@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Rosa Fiesta:ana@example.com" {
+			t.Errorf("unexpected URI %s", u)
+		}
+		if q := u.Query(); q.Get("secret") != secret || q.Get("issuer") != "Rosa Fiesta" {
+			t.Errorf("unexpected parameters %s", u.RawQuery)
+		}
+	})
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' || strings.ToLower(code) != code {
+			t.Errorf("unexpected code %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
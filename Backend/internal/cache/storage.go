@@ -11,6 +11,7 @@ type Storage struct {
 	Users interface {
 		Get(context.Context, uuid.UUID) (*models.User, error)
 		Set(context.Context, *models.User) error
+		Delete(context.Context, uuid.UUID) error
 	}
 }
 
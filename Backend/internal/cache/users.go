@@ -57,3 +57,8 @@ func (u *UserStore) Set(ctx context.Context, user *models.User) error {
 	}
 	return nil
 }
+
+// Delete evicts the user, so the next request reads them from the database.
+func (u *UserStore) Delete(ctx context.Context, userID uuid.UUID) error {
+	return u.rdb.Del(ctx, fmt.Sprintf("user-%v", userID)).Err()
+}
@@ -0,0 +1,307 @@
+// Package chatbot answers client messages from the intents, FAQs and
+// synonyms staff maintain in the admin panel, and collects the details of an
+// event (type, date, guest count and contact) to turn a conversation into a
+// lead.
+package chatbot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+const fallbackReply = "No estoy seguro de haber entendido. Puedo ayudarte con precios, disponibilidad o una cotización para tu evento. " +
+	"Si prefieres, escribe \"asesor\" y una persona de nuestro equipo te contactará."
+
+// cancelWords stop slot filling.
+var cancelWords = []phrase{" cancelar ", " salir ", " olvidalo ", " no gracias "}
+
+// Knowledge is everything the bot answers from.
+type Knowledge struct {
+	Intents    []models.ChatbotIntent
+	FAQs       []models.FAQ
+	Synonyms   []models.ChatbotSynonym
+	EventTypes []string // names offered when asking for the event type
+}
+
+type intent struct {
+	*models.ChatbotIntent
+	phrases []phrase
+}
+
+type faq struct {
+	*models.FAQ
+	keyword phrase
+}
+
+type eventType struct {
+	name string
+	text phrase
+}
+
+// Bot matches messages against a snapshot of Knowledge. It holds no
+// per-conversation state, so one Bot can serve any number of sessions.
+type Bot struct {
+	synonyms   *synonyms
+	intents    []intent
+	faqs       []faq
+	eventTypes []eventType
+}
+
+func New(k Knowledge) *Bot {
+	b := &Bot{synonyms: newSynonyms()}
+	for _, s := range k.Synonyms {
+		b.synonyms.add(s.Term, s.Canonical)
+	}
+
+	for i := range k.Intents {
+		in := intent{ChatbotIntent: &k.Intents[i]}
+		for _, p := range in.Phrases {
+			if ws := b.synonyms.canonical(p); len(ws) > 0 {
+				in.phrases = append(in.phrases, toPhrase(ws))
+			}
+		}
+		b.intents = append(b.intents, in)
+	}
+
+	for i := range k.FAQs {
+		if ws := b.synonyms.canonical(k.FAQs[i].Keyword); len(ws) > 0 {
+			b.faqs = append(b.faqs, faq{FAQ: &k.FAQs[i], keyword: toPhrase(ws)})
+		}
+	}
+
+	for _, name := range k.EventTypes {
+		if ws := b.synonyms.canonical(name); len(ws) > 0 {
+			b.eventTypes = append(b.eventTypes, eventType{name: name, text: toPhrase(ws)})
+		}
+	}
+	// Prefer the most specific name, e.g. "Cumpleaños Infantil" over a
+	// shorter name it contains.
+	sort.SliceStable(b.eventTypes, func(i, j int) bool {
+		return len(b.eventTypes[i].text) > len(b.eventTypes[j].text)
+	})
+	return b
+}
+
+// Reply is the bot's answer to one message.
+type Reply struct {
+	Text     string
+	IntentID *uuid.UUID
+	FAQID    *uuid.UUID
+	// LeadReady is set when every slot is filled; the caller creates the
+	// lead and stores its ID on the session.
+	LeadReady bool
+}
+
+type match struct {
+	text     string
+	score    int
+	intent   *intent
+	faqID    *uuid.UUID
+	intentID *uuid.UUID
+}
+
+// Reply answers message and advances the session: it starts slot filling
+// when an intent asks to collect a lead, fills slots from what the client
+// writes, and asks for whatever is still missing.
+func (b *Bot) Reply(s *models.ChatbotSession, message string, now time.Time) Reply {
+	text := toPhrase(b.synonyms.canonical(message))
+
+	if s.State == models.ChatbotStateCollecting {
+		return b.collect(s, message, text, now)
+	}
+
+	m := b.match(text)
+	if m == nil {
+		return Reply{Text: fallbackReply}
+	}
+	reply := Reply{Text: m.text, IntentID: m.intentID, FAQID: m.faqID}
+
+	if m.intent == nil || m.intent.Action != models.ChatbotActionCollectLead {
+		return reply
+	}
+	if s.LeadID != nil {
+		reply.Text += "\n\nYa registramos tu solicitud y un asesor te contactará pronto."
+		return reply
+	}
+
+	s.State = models.ChatbotStateCollecting
+	b.fill(s, message, text, now)
+	return b.advance(s, reply)
+}
+
+func (b *Bot) collect(s *models.ChatbotSession, message string, text phrase, now time.Time) Reply {
+	for _, w := range cancelWords {
+		if text.contains(w) {
+			s.State = models.ChatbotStateIdle
+			s.PendingSlot = ""
+			return Reply{Text: "Entendido, dejamos la cotización para otro momento. ¿Hay algo más en lo que pueda ayudarte?"}
+		}
+	}
+
+	pending := s.PendingSlot
+	filled := b.fill(s, message, text, now)
+
+	var reply Reply
+	if !filled {
+		// The client may have asked something else in the middle; answer
+		// it and then repeat the question.
+		if m := b.match(text); m != nil {
+			reply = Reply{Text: m.text, IntentID: m.intentID, FAQID: m.faqID}
+		} else {
+			reply.Text = retryPrompts[pending]
+		}
+	}
+	return b.advance(s, reply)
+}
+
+// advance asks for the next missing slot after reply, or marks the lead as
+// ready when there is none.
+func (b *Bot) advance(s *models.ChatbotSession, reply Reply) Reply {
+	next := nextSlot(s)
+	if next == "" {
+		s.State = models.ChatbotStateIdle
+		s.PendingSlot = ""
+		reply.LeadReady = true
+		reply.Text = joinText(reply.Text, summary(s))
+		return reply
+	}
+	s.PendingSlot = next
+	reply.Text = joinText(reply.Text, b.prompt(next))
+	return reply
+}
+
+// fill extracts every slot it can from message and reports whether any was
+// filled.
+func (b *Bot) fill(s *models.ChatbotSession, message string, text phrase, now time.Time) bool {
+	filled := false
+
+	if s.Slots.EventType == "" {
+		for _, et := range b.eventTypes {
+			if text.contains(et.text) {
+				s.Slots.EventType = et.name
+				filled = true
+				break
+			}
+		}
+	}
+	if s.Slots.EventDate == nil {
+		if date, ok := parseDate(message, now); ok {
+			s.Slots.EventDate = date
+			filled = true
+		}
+	}
+	if s.Slots.GuestCount == 0 {
+		if n, ok := parseGuests(message, s.PendingSlot == models.SlotGuestCount); ok {
+			s.Slots.GuestCount = n
+			filled = true
+		}
+	}
+
+	// Names and phones have no telltale shape, so they are only taken as
+	// the answer to the question that asked for them.
+	switch s.PendingSlot {
+	case models.SlotClientName:
+		if name, ok := parseName(message); ok && s.ClientName == "" {
+			s.ClientName = name
+			filled = true
+		}
+	case models.SlotClientPhone:
+		if phone, ok := parsePhone(message); ok && s.ClientPhone == "" {
+			s.ClientPhone = phone
+			filled = true
+		}
+	}
+	return filled
+}
+
+func nextSlot(s *models.ChatbotSession) string {
+	switch {
+	case s.Slots.EventType == "":
+		return models.SlotEventType
+	case s.Slots.EventDate == nil:
+		return models.SlotEventDate
+	case s.Slots.GuestCount == 0:
+		return models.SlotGuestCount
+	case s.ClientName == "":
+		return models.SlotClientName
+	case s.ClientPhone == "":
+		return models.SlotClientPhone
+	}
+	return ""
+}
+
+var prompts = map[string]string{
+	models.SlotEventDate:   "¿Para qué fecha es el evento? (por ejemplo, 15/03 o 15 de marzo)",
+	models.SlotGuestCount:  "¿Cuántos invitados esperas?",
+	models.SlotClientName:  "¿A nombre de quién registramos la solicitud?",
+	models.SlotClientPhone: "¿A qué número de teléfono te podemos contactar?",
+}
+
+var retryPrompts = map[string]string{
+	models.SlotEventType:   "No reconocí el tipo de evento.",
+	models.SlotEventDate:   "No pude entender la fecha, o ya pasó.",
+	models.SlotGuestCount:  "No pude entender la cantidad de invitados.",
+	models.SlotClientName:  "No pude entender tu nombre.",
+	models.SlotClientPhone: "No pude entender el número de teléfono.",
+}
+
+func (b *Bot) prompt(slot string) string {
+	if slot != models.SlotEventType {
+		return prompts[slot]
+	}
+	if len(b.eventTypes) == 0 {
+		return "¿Qué tipo de evento estás planeando?"
+	}
+	names := make([]string, 0, len(b.eventTypes))
+	for _, et := range b.eventTypes {
+		names = append(names, et.name)
+	}
+	sort.Strings(names)
+	return "¿Qué tipo de evento estás planeando? Por ejemplo: " + strings.Join(names, ", ") + "."
+}
+
+func summary(s *models.ChatbotSession) string {
+	return fmt.Sprintf("¡Listo, %s! Registramos tu solicitud: %s el %s para %d invitados. "+
+		"Un asesor te contactará pronto con tu cotización.",
+		s.ClientName, s.Slots.EventType, s.Slots.EventDate.Format("02/01/2006"), s.Slots.GuestCount)
+}
+
+func joinText(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n\n" + b
+}
+
+// match finds the best intent or FAQ for text. Longer phrases beat shorter
+// ones and priority breaks ties; an intent beats an FAQ with the same score.
+func (b *Bot) match(text phrase) *match {
+	var best *match
+	consider := func(m match) {
+		if best == nil || m.score > best.score {
+			best = &m
+		}
+	}
+
+	for i := range b.intents {
+		in := &b.intents[i]
+		for _, p := range in.phrases {
+			if text.contains(p) {
+				consider(match{text: in.Response, score: p.wordCount()*100 + in.Priority, intent: in, intentID: &in.ID})
+			}
+		}
+	}
+	for i := range b.faqs {
+		f := &b.faqs[i]
+		if text.contains(f.keyword) {
+			consider(match{text: f.Answer, score: f.keyword.wordCount()*100 + f.Priority, faqID: &f.ID})
+		}
+	}
+	return best
+}
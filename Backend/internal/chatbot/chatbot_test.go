@@ -0,0 +1,175 @@
+package chatbot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+var now = time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func testBot() *Bot {
+	return New(Knowledge{
+		Intents: []models.ChatbotIntent{
+			{ID: uuid.New(), Name: "saludo", Phrases: []string{"hola", "buenos días"}, Response: "¡Hola!", Priority: 1},
+			{ID: uuid.New(), Name: "precio", Phrases: []string{"precio"}, Response: "Depende del evento.", Action: models.ChatbotActionCollectLead, Priority: 5},
+		},
+		FAQs: []models.FAQ{
+			{ID: uuid.New(), Keyword: "alquiler", Answer: "El alquiler incluye montaje."},
+		},
+		Synonyms: []models.ChatbotSynonym{
+			{Term: "cuánto cuesta", Canonical: "precio"},
+			{Term: "costo", Canonical: "precio"},
+			{Term: "matrimonio", Canonical: "boda"},
+			{Term: "quinceañera", Canonical: "cumpleaños 15"},
+			{Term: "rentar", Canonical: "alquiler"},
+		},
+		EventTypes: []string{"Boda", "Cumpleaños 15", "Cumpleaños Infantil"},
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("¿CUÁNTO   cuesta la Quinceañera?"); got != "cuanto cuesta la quinceanera" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
+
+func TestReply_matchesIntentsAndFAQs(t *testing.T) {
+	bot := testBot()
+
+	tests := []struct {
+		message string
+		intent  string
+		faq     bool
+	}{
+		{message: "HOLA!!", intent: "saludo"},
+		{message: "Buenos Dias", intent: "saludo"},
+		{message: "¿Cuánto cuesta?", intent: "precio"},
+		{message: "y el COSTO?", intent: "precio"},
+		{message: "hola, cuanto cuesta?", intent: "precio"},
+		{message: "quiero rentar sillas", faq: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			s := &models.ChatbotSession{State: models.ChatbotStateIdle, ClientName: "Ana", ClientPhone: "8095551234"}
+			reply := bot.Reply(s, tt.message, now)
+
+			if tt.faq {
+				if reply.FAQID == nil {
+					t.Fatalf("expected an FAQ answer, got %q", reply.Text)
+				}
+				return
+			}
+			if reply.IntentID == nil {
+				t.Fatalf("expected intent %s, got %q", tt.intent, reply.Text)
+			}
+			for _, in := range bot.intents {
+				if in.ID == *reply.IntentID && in.Name != tt.intent {
+					t.Errorf("matched intent %s, want %s", in.Name, tt.intent)
+				}
+			}
+		})
+	}
+
+	s := &models.ChatbotSession{State: models.ChatbotStateIdle}
+	if reply := bot.Reply(s, "asdf qwerty", now); reply.Text != fallbackReply {
+		t.Errorf("expected fallback, got %q", reply.Text)
+	}
+}
+
+func TestReply_slotFilling(t *testing.T) {
+	bot := testBot()
+	s := &models.ChatbotSession{State: models.ChatbotStateIdle}
+
+	reply := bot.Reply(s, "¿Cuánto cuesta un matrimonio?", now)
+	if s.State != models.ChatbotStateCollecting || s.Slots.EventType != "Boda" {
+		t.Fatalf("expected collecting with event type Boda, got %+v", s)
+	}
+	if s.PendingSlot != models.SlotEventDate || !strings.Contains(reply.Text, "fecha") {
+		t.Fatalf("expected to be asked for the date, got %q", reply.Text)
+	}
+
+	reply = bot.Reply(s, "el 31/02", now)
+	if s.Slots.EventDate != nil || s.PendingSlot != models.SlotEventDate {
+		t.Fatalf("invalid date should not fill the slot: %+v", s)
+	}
+
+	bot.Reply(s, "Sería el 15 de marzo", now)
+	if s.Slots.EventDate == nil || !s.Slots.EventDate.Equal(time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected next 15 March, got %v", s.Slots.EventDate)
+	}
+
+	bot.Reply(s, "unos 120", now)
+	if s.Slots.GuestCount != 120 {
+		t.Fatalf("expected 120 guests, got %d", s.Slots.GuestCount)
+	}
+
+	bot.Reply(s, "Me llamo Ana Pérez", now)
+	if s.ClientName != "Ana Pérez" {
+		t.Fatalf("expected name, got %q", s.ClientName)
+	}
+
+	reply = bot.Reply(s, "+1 (809) 555-1234", now)
+	if !reply.LeadReady || s.ClientPhone != "18095551234" || s.State != models.ChatbotStateIdle {
+		t.Fatalf("expected lead ready, got %+v / %+v", reply, s)
+	}
+}
+
+func TestReply_fillsSeveralSlotsAtOnce(t *testing.T) {
+	bot := testBot()
+	s := &models.ChatbotSession{State: models.ChatbotStateIdle, ClientName: "Ana", ClientPhone: "8095551234"}
+
+	reply := bot.Reply(s, "precio de una quinceañera el 2026-08-20 para 150 personas", now)
+	if !reply.LeadReady {
+		t.Fatalf("expected lead ready, got %q (%+v)", reply.Text, s)
+	}
+	if s.Slots.EventType != "Cumpleaños 15" || s.Slots.GuestCount != 150 {
+		t.Errorf("unexpected slots %+v", s.Slots)
+	}
+}
+
+func TestReply_answersQuestionsWhileCollecting(t *testing.T) {
+	bot := testBot()
+	s := &models.ChatbotSession{State: models.ChatbotStateCollecting, PendingSlot: models.SlotEventType}
+
+	reply := bot.Reply(s, "¿el alquiler incluye montaje?", now)
+	if reply.FAQID == nil || !strings.Contains(reply.Text, "tipo de evento") {
+		t.Errorf("expected FAQ answer followed by the pending question, got %q", reply.Text)
+	}
+
+	bot.Reply(s, "cancelar", now)
+	if s.State != models.ChatbotStateIdle {
+		t.Errorf("expected cancel to stop slot filling")
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "15/03/2027", want: "2027-03-15"},
+		{text: "el 5-9", want: "2026-09-05"},
+		{text: "1 de enero", want: "2027-01-01"},
+		{text: "20 de Diciembre del 2026", want: "2026-12-20"},
+		{text: "2026-07-04", want: "2026-07-04"},
+		{text: "15/03/2020", want: ""},
+		{text: "sin fecha", want: ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseDate(tt.text, now)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("parseDate(%q) = %v, want none", tt.text, got)
+			}
+			continue
+		}
+		if !ok || got.Format("2006-01-02") != tt.want {
+			t.Errorf("parseDate(%q) = %v, want %s", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package chatbot
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxGuestCount = 5000
+
+var months = map[string]time.Month{
+	"enero": time.January, "febrero": time.February, "marzo": time.March, "abril": time.April,
+	"mayo": time.May, "junio": time.June, "julio": time.July, "agosto": time.August,
+	"septiembre": time.September, "setiembre": time.September, "octubre": time.October,
+	"noviembre": time.November, "diciembre": time.December,
+}
+
+var (
+	isoDateRe     = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	numericDateRe = regexp.MustCompile(`\b(\d{1,2})[/-](\d{1,2})(?:[/-](\d{2,4}))?\b`)
+	writtenDateRe = regexp.MustCompile(`\b(\d{1,2})\s+(?:de\s+)?(enero|febrero|marzo|abril|mayo|junio|julio|agosto|septiembre|setiembre|octubre|noviembre|diciembre)(?:\s+(?:de\s+|del\s+)?(\d{4}))?\b`)
+	guestsRe      = regexp.MustCompile(`\b(\d{1,5})\s*(?:invitados?|personas?|gente|pax|adultos?|asistentes?)\b`)
+	numberRe      = regexp.MustCompile(`\b\d{1,5}\b`)
+	namePrefixRe  = regexp.MustCompile(`(?i)^\s*(?:me llamo|mi nombre es|soy)\s+`)
+)
+
+// parseDate finds an event date in text. Dates are read day first, as they
+// are written in the Dominican Republic; without a year the next occurrence
+// is used. Dates before today are rejected.
+func parseDate(text string, now time.Time) (*time.Time, bool) {
+	text = fold(text)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var (
+		day, year int
+		month     time.Month
+	)
+	if m := isoDateRe.FindStringSubmatch(text); m != nil {
+		year, _ = strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		month = time.Month(mo)
+		day, _ = strconv.Atoi(m[3])
+	} else if m := writtenDateRe.FindStringSubmatch(text); m != nil {
+		day, _ = strconv.Atoi(m[1])
+		month = months[m[2]]
+		if m[3] != "" {
+			year, _ = strconv.Atoi(m[3])
+		}
+	} else if m := numericDateRe.FindStringSubmatch(text); m != nil {
+		day, _ = strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		month = time.Month(mo)
+		if m[3] != "" {
+			year, _ = strconv.Atoi(m[3])
+			if year < 100 {
+				year += 2000
+			}
+		}
+	} else {
+		return nil, false
+	}
+
+	if month < time.January || month > time.December || day < 1 || day > 31 {
+		return nil, false
+	}
+
+	explicitYear := year != 0
+	if !explicitYear {
+		year = today.Year()
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	if date.Day() != day {
+		return nil, false // e.g. 31/02
+	}
+	if date.Before(today) {
+		if explicitYear {
+			return nil, false
+		}
+		date = date.AddDate(1, 0, 0)
+	}
+	return &date, true
+}
+
+// parseGuests finds a guest count in text. A bare number only counts when
+// the bot just asked for the guest count.
+func parseGuests(text string, asked bool) (int, bool) {
+	text = fold(text)
+	if m := guestsRe.FindStringSubmatch(text); m != nil {
+		return validGuests(m[1])
+	}
+	if !asked {
+		return 0, false
+	}
+	// Dates are not guest counts.
+	text = isoDateRe.ReplaceAllString(text, " ")
+	text = numericDateRe.ReplaceAllString(text, " ")
+	text = writtenDateRe.ReplaceAllString(text, " ")
+	if m := numberRe.FindString(text); m != "" {
+		return validGuests(m)
+	}
+	return 0, false
+}
+
+func validGuests(s string) (int, bool) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > maxGuestCount {
+		return 0, false
+	}
+	return n, true
+}
+
+// parseName takes the whole answer as the client's name, minus an
+// introduction like "me llamo".
+func parseName(text string) (string, bool) {
+	name := strings.TrimSpace(namePrefixRe.ReplaceAllString(text, ""))
+	name = strings.Trim(name, ".,;:!¡?¿ ")
+	if name == "" || len(name) > 100 || numberRe.MatchString(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// parsePhone accepts an answer with at least seven digits as a phone number.
+func parsePhone(text string) (string, bool) {
+	var b strings.Builder
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() < 7 || b.Len() > 15 {
+		return "", false
+	}
+	return b.String(), true
+}
@@ -0,0 +1,91 @@
+package chatbot
+
+import (
+	"strings"
+	"unicode"
+)
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n",
+)
+
+// fold lowercases s and strips Spanish accents, keeping punctuation.
+func fold(s string) string {
+	return accents.Replace(strings.ToLower(s))
+}
+
+// Normalize folds s and turns everything but letters and digits into single
+// spaces, so "¿Cuánto cuesta?" and "cuanto  cuesta" compare equal.
+func Normalize(s string) string {
+	return strings.Join(words(s), " ")
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// synonyms rewrites words and phrases to their canonical form. Longer terms
+// win over shorter ones that start at the same word.
+type synonyms struct {
+	terms    map[string][]string
+	maxWords int
+}
+
+func newSynonyms() *synonyms {
+	return &synonyms{terms: make(map[string][]string), maxWords: 1}
+}
+
+func (s *synonyms) add(term, canonical string) {
+	key := Normalize(term)
+	value := words(canonical)
+	if key == "" || len(value) == 0 {
+		return
+	}
+	s.terms[key] = value
+	s.maxWords = max(s.maxWords, len(strings.Fields(key)))
+}
+
+// canonical returns the normalized words of text with synonyms replaced.
+func (s *synonyms) canonical(text string) []string {
+	in := words(text)
+	out := make([]string, 0, len(in))
+	for i := 0; i < len(in); {
+		n := min(s.maxWords, len(in)-i)
+		for ; n > 0; n-- {
+			if canon, ok := s.terms[strings.Join(in[i:i+n], " ")]; ok {
+				out = append(out, canon...)
+				break
+			}
+		}
+		if n == 0 {
+			out = append(out, in[i])
+			n = 1
+		}
+		i += n
+	}
+	return out
+}
+
+// phrase is canonical text padded with spaces, so containment checks only
+// match whole words.
+type phrase string
+
+func toPhrase(ws []string) phrase {
+	return phrase(" " + strings.Join(ws, " ") + " ")
+}
+
+func (p phrase) contains(q phrase) bool {
+	return len(q) > 2 && strings.Contains(string(p), string(q))
+}
+
+// wordCount is the number of words in a padded phrase.
+func (p phrase) wordCount() int {
+	return len(strings.Fields(string(p)))
+}
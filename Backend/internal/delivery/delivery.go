@@ -0,0 +1,83 @@
+// Package delivery prices delivering an event's items by the straight-line
+// distance from the warehouse to the venue.
+package delivery
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"Backend/internal/store/models"
+)
+
+const earthRadiusKm = 6371.0
+
+// Point is a position in decimal degrees.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// DistanceKm is the great-circle distance between a and b, by the haversine
+// formula.
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Quote prices a delivery km away from the warehouse. The nearest active
+// zone whose max radius reaches the venue applies its travel fee; venues
+// within the innermost zone's base radius are delivered free. Past the last
+// zone the delivery is out of range and quoted at the farthest zone's fee.
+func Quote(zones []models.DeliveryZone, km float64) models.DeliveryFeeResponse {
+	active := make([]models.DeliveryZone, 0, len(zones))
+	for _, z := range zones {
+		if z.IsActive {
+			active = append(active, z)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].MaxRadiusKm < active[j].MaxRadiusKm })
+
+	resp := models.DeliveryFeeResponse{DistanceKm: math.Round(km*10) / 10}
+	if len(active) == 0 {
+		resp.Message = "La entrega se coordinará contigo."
+		return resp
+	}
+
+	inner := active[0]
+	for _, z := range active[1:] {
+		if z.BaseRadiusKm < inner.BaseRadiusKm {
+			inner = z
+		}
+	}
+	if km <= inner.BaseRadiusKm {
+		resp.Zone = inner.Name
+		resp.InRange = true
+		resp.Message = fmt.Sprintf("Entrega gratuita en %s", inner.Name)
+		return resp
+	}
+
+	for _, z := range active {
+		if km <= z.MaxRadiusKm {
+			resp.Fee = z.TravelFee
+			resp.Zone = z.Name
+			resp.InRange = true
+			if z.TravelFee == 0 {
+				resp.Message = fmt.Sprintf("Entrega gratuita en %s", z.Name)
+			} else {
+				resp.Message = fmt.Sprintf("Entrega en %s a %.1f km", z.Name, resp.DistanceKm)
+			}
+			return resp
+		}
+	}
+
+	farthest := active[len(active)-1]
+	resp.Fee = farthest.TravelFee
+	resp.Zone = farthest.Name
+	resp.Message = "Tu dirección está fuera de nuestras zonas de entrega. El equipo de RosaFiesta coordinará contigo el envío."
+	return resp
+}
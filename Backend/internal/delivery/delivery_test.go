@@ -0,0 +1,56 @@
+package delivery
+
+import (
+	"math"
+	"testing"
+
+	"Backend/internal/store/models"
+)
+
+func TestDistanceKm(t *testing.T) {
+	sanCristobal := Point{Lat: 18.4167, Lng: -70.1056}
+	santiago := Point{Lat: 19.4517, Lng: -70.6970}
+
+	if d := DistanceKm(sanCristobal, sanCristobal); d != 0 {
+		t.Errorf("expected 0 km to itself, got %f", d)
+	}
+	// About 130 km as the crow flies.
+	if d := DistanceKm(sanCristobal, santiago); math.Abs(d-130) > 3 {
+		t.Errorf("unexpected distance %f", d)
+	}
+	if DistanceKm(sanCristobal, santiago) != DistanceKm(santiago, sanCristobal) {
+		t.Error("distance is not symmetric")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	zones := []models.DeliveryZone{
+		{Name: "Zona Remota", BaseRadiusKm: 80, MaxRadiusKm: 150, TravelFee: 3500, IsActive: true},
+		{Name: "Centro", BaseRadiusKm: 10, MaxRadiusKm: 30, TravelFee: 0, IsActive: true},
+		{Name: "Extendido", BaseRadiusKm: 30, MaxRadiusKm: 80, TravelFee: 1500, IsActive: true},
+		{Name: "Cerrada", BaseRadiusKm: 0, MaxRadiusKm: 50, TravelFee: 99, IsActive: false},
+	}
+
+	tests := []struct {
+		km      float64
+		fee     float64
+		zone    string
+		inRange bool
+	}{
+		{km: 4, fee: 0, zone: "Centro", inRange: true},
+		{km: 30, fee: 0, zone: "Centro", inRange: true},
+		{km: 30.1, fee: 1500, zone: "Extendido", inRange: true},
+		{km: 120, fee: 3500, zone: "Zona Remota", inRange: true},
+		{km: 200, fee: 3500, zone: "Zona Remota", inRange: false},
+	}
+	for _, tc := range tests {
+		q := Quote(zones, tc.km)
+		if q.Fee != tc.fee || q.Zone != tc.zone || q.InRange != tc.inRange {
+			t.Errorf("%.1f km: got %+v", tc.km, q)
+		}
+	}
+
+	if q := Quote(nil, 5); q.Fee != 0 || q.InRange {
+		t.Errorf("expected no zones to be out of range, got %+v", q)
+	}
+}
@@ -4,17 +4,17 @@ import (
 	"bytes"
 	"errors"
 	"gopkg.in/gomail.v2"
-	"html/template"
 	"log"
 )
 
 type GoMailClient struct {
 	fromEmail string
 	password  string
+	templates TemplateStore
 }
 
 func (m GoMailClient) Send(templateFile, userName, email string, data any, isSandbox bool) (int, error) {
-	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	tmpl, err := resolve(m.templates, templateFile)
 	if err != nil {
 		return -1, err
 	}
@@ -48,7 +48,7 @@ func (m GoMailClient) Send(templateFile, userName, email string, data any, isSan
 	return 200, nil
 }
 
-func NewGoMailClient(password, fromEmail string) (GoMailClient, error) {
+func NewGoMailClient(password, fromEmail string, templates TemplateStore) (GoMailClient, error) {
 	if fromEmail == "" {
 		return GoMailClient{}, errors.New("main key is required")
 	}
@@ -56,5 +56,6 @@ func NewGoMailClient(password, fromEmail string) (GoMailClient, error) {
 	return GoMailClient{
 		fromEmail: fromEmail,
 		password:  password,
+		templates: templates,
 	}, nil
 }
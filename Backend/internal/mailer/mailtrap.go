@@ -4,16 +4,16 @@ import (
 	"bytes"
 	"errors"
 	gomail "gopkg.in/mail.v2"
-	"html/template"
 	"log"
 )
 
 type MailTrapClient struct {
 	fromEmail string
 	apiKey    string
+	templates TemplateStore
 }
 
-func NewMailTrapClient(apiKey, fromEmail string) (MailTrapClient, error) {
+func NewMailTrapClient(apiKey, fromEmail string, templates TemplateStore) (MailTrapClient, error) {
 	if apiKey == "" {
 		return MailTrapClient{}, errors.New("main key is required")
 	}
@@ -21,11 +21,12 @@ func NewMailTrapClient(apiKey, fromEmail string) (MailTrapClient, error) {
 	return MailTrapClient{
 		fromEmail: fromEmail,
 		apiKey:    apiKey,
+		templates: templates,
 	}, nil
 }
 
 func (m MailTrapClient) Send(templateFile, username, email string, data any, isSandbox bool) (int, error) {
-	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	tmpl, err := resolve(m.templates, templateFile)
 	if err != nil {
 		return -1, err
 	}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
-	"html/template"
 	"time"
 )
 
@@ -13,15 +12,17 @@ type SendGridMailer struct {
 	fromEmail string
 	apiKey    string
 	client    *sendgrid.Client
+	templates TemplateStore
 }
 
-func NewSendgrid(apiKey, fromEmail string) *SendGridMailer {
+func NewSendgrid(apiKey, fromEmail string, templates TemplateStore) *SendGridMailer {
 	client := sendgrid.NewSendClient(apiKey)
 
 	return &SendGridMailer{
 		fromEmail: fromEmail,
 		apiKey:    apiKey,
 		client:    client,
+		templates: templates,
 	}
 }
 
@@ -29,7 +30,7 @@ func (m *SendGridMailer) Send(templateFile, username, email string, data any, is
 	from := mail.NewEmail(FromName, m.fromEmail)
 	to := mail.NewEmail(username, email)
 
-	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	tmpl, err := resolve(m.templates, templateFile)
 	if err != nil {
 		return -1, err
 	}
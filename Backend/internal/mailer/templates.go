@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+)
+
+// TemplateStore supplies template versions edited from the admin panel.
+// GetActive returns store.ErrNotFound when a template was never edited.
+type TemplateStore interface {
+	GetActive(ctx context.Context, key string) (*models.EmailTemplate, error)
+}
+
+// TemplateInfo describes an embedded template the admin panel can edit.
+type TemplateInfo struct {
+	File string
+	Name string
+}
+
+// Templates lists every embedded template in the order the admin panel
+// shows them.
+var Templates = []TemplateInfo{
+	{File: UserWelcomeTemplate, Name: "Invitacion de registro"},
+	{File: PasswordResetTemplate, Name: "Reset password"},
+	{File: AutoReminder7dTemplate, Name: "Recordatorio automatico 7 dias"},
+	{File: EventReminder7dTemplate, Name: "Recordatorio 7 dias"},
+	{File: EventReminder24hTemplate, Name: "Recordatorio 24h"},
+	{File: EventThankYouTemplate, Name: "Agradecimiento post-evento"},
+}
+
+// TemplateKey is the key a template file is stored under in the database.
+func TemplateKey(templateFile string) string {
+	return strings.TrimSuffix(templateFile, ".tmpl")
+}
+
+// LookupTemplate returns the embedded template with the given key.
+func LookupTemplate(key string) (TemplateInfo, bool) {
+	for _, t := range Templates {
+		if TemplateKey(t.File) == key {
+			return t, true
+		}
+	}
+	return TemplateInfo{}, false
+}
+
+// Compile parses a stored subject and body into a template with the same
+// "subject" and "body" definitions the embedded files use.
+func Compile(subject, body string) (*template.Template, error) {
+	tmpl, err := template.New("subject").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	if _, err := tmpl.New("body").Parse(body); err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	return tmpl, nil
+}
+
+// EmbeddedSource returns the subject and body source of an embedded
+// template, so the admin panel can start editing from the shipped version.
+func EmbeddedSource(templateFile string) (subject, body string, err error) {
+	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return "", "", err
+	}
+	subjectTmpl, bodyTmpl := tmpl.Lookup("subject"), tmpl.Lookup("body")
+	if subjectTmpl == nil || bodyTmpl == nil {
+		return "", "", fmt.Errorf("template %s must define subject and body", templateFile)
+	}
+	return subjectTmpl.Tree.Root.String(), strings.TrimSpace(bodyTmpl.Tree.Root.String()), nil
+}
+
+// resolve returns the active database version of templateFile, falling back
+// to the embedded file when there is none or the database can't be reached.
+func resolve(templates TemplateStore, templateFile string) (*template.Template, error) {
+	if templates != nil {
+		stored, err := templates.GetActive(context.Background(), TemplateKey(templateFile))
+		switch {
+		case err == nil:
+			tmpl, err := Compile(stored.Subject, stored.Body)
+			if err == nil {
+				return tmpl, nil
+			}
+			log.Printf("mailer: stored template %s v%d is invalid, using embedded: %v", stored.Key, stored.Version, err)
+		case !errors.Is(err, store.ErrNotFound):
+			log.Printf("mailer: loading stored template %s, using embedded: %v", templateFile, err)
+		}
+	}
+	return template.ParseFS(FS, "templates/"+templateFile)
+}
+
+// Render executes the subject and body of tmpl with data.
+func Render(tmpl *template.Template, data any) (subject, body string, err error) {
+	subjectBuf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(subjectBuf, "subject", data); err != nil {
+		return "", "", err
+	}
+
+	bodyBuf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(bodyBuf, "body", data); err != nil {
+		return "", "", err
+	}
+	return subjectBuf.String(), bodyBuf.String(), nil
+}
@@ -0,0 +1,108 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+)
+
+type fakeTemplateStore struct {
+	templates map[string]*models.EmailTemplate
+	err       error
+}
+
+func (f *fakeTemplateStore) GetActive(_ context.Context, key string) (*models.EmailTemplate, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	t, ok := f.templates[key]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return t, nil
+}
+
+func TestResolvePrefersStoredVersion(t *testing.T) {
+	data := map[string]any{"UserName": "Ana", "EventName": "Boda", "ReviewURL": "https://example.com"}
+
+	tests := []struct {
+		name        string
+		templates   TemplateStore
+		wantSubject string
+	}{
+		{
+			name: "stored version",
+			templates: &fakeTemplateStore{templates: map[string]*models.EmailTemplate{
+				"event_thank_you": {Key: "event_thank_you", Version: 2, Subject: "Gracias {{.UserName}}", Body: "<p>{{.EventName}}</p>"},
+			}},
+			wantSubject: "Gracias Ana",
+		},
+		{
+			name:        "never edited falls back to embedded",
+			templates:   &fakeTemplateStore{},
+			wantSubject: "¡Gracias por confiar en Rosa Fiesta! — Boda",
+		},
+		{
+			name:        "store error falls back to embedded",
+			templates:   &fakeTemplateStore{err: errors.New("connection refused")},
+			wantSubject: "¡Gracias por confiar en Rosa Fiesta! — Boda",
+		},
+		{
+			name: "invalid stored version falls back to embedded",
+			templates: &fakeTemplateStore{templates: map[string]*models.EmailTemplate{
+				"event_thank_you": {Key: "event_thank_you", Version: 3, Subject: "{{.UserName", Body: "x"},
+			}},
+			wantSubject: "¡Gracias por confiar en Rosa Fiesta! — Boda",
+		},
+		{
+			name:        "no store",
+			wantSubject: "¡Gracias por confiar en Rosa Fiesta! — Boda",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := resolve(tt.templates, EventThankYouTemplate)
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			subject, _, err := Render(tmpl, data)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if subject != tt.wantSubject {
+				t.Errorf("expected subject %q, got %q", tt.wantSubject, subject)
+			}
+		})
+	}
+}
+
+func TestEmbeddedSourceRoundTrips(t *testing.T) {
+	data := map[string]any{"Username": "ana", "ActivationURL": "https://example.com/confirm/tok"}
+
+	for _, info := range Templates {
+		t.Run(info.File, func(t *testing.T) {
+			subject, body, err := EmbeddedSource(info.File)
+			if err != nil {
+				t.Fatalf("embedded source: %v", err)
+			}
+			if _, err := Compile(subject, body); err != nil {
+				t.Fatalf("compile embedded source: %v", err)
+			}
+		})
+	}
+
+	subject, body, _ := EmbeddedSource(UserWelcomeTemplate)
+	tmpl, _ := Compile(subject, body)
+	_, rendered, err := Render(tmpl, data)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(rendered, "https://example.com/confirm/tok") {
+		t.Errorf("expected activation url in body, got %s", rendered)
+	}
+}
@@ -0,0 +1,369 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SandboxBaseURL = "https://api-m.sandbox.paypal.com"
+	LiveBaseURL    = "https://api-m.paypal.com"
+)
+
+// Order and capture statuses returned by the Orders v2 API.
+const (
+	OrderStatusCreated   = "CREATED"
+	OrderStatusApproved  = "APPROVED"
+	OrderStatusCompleted = "COMPLETED"
+
+	CaptureStatusCompleted = "COMPLETED"
+	CaptureStatusPending   = "PENDING"
+	CaptureStatusDeclined  = "DECLINED"
+)
+
+// Webhook event types handled by the API.
+const (
+	EventCaptureCompleted = "PAYMENT.CAPTURE.COMPLETED"
+	EventCaptureDenied    = "PAYMENT.CAPTURE.DENIED"
+	EventCaptureDeclined  = "PAYMENT.CAPTURE.DECLINED"
+	EventCaptureRefunded  = "PAYMENT.CAPTURE.REFUNDED"
+)
+
+var ErrNotConfigured = errors.New("paypal is not configured")
+
+// Config holds PayPal REST API configuration.
+type Config struct {
+	BaseURL   string // SandboxBaseURL, LiveBaseURL, or a local fake in tests
+	ClientID  string
+	Secret    string
+	WebhookID string // ID of the webhook registered in the developer dashboard
+}
+
+// Client talks to the PayPal Orders v2 REST API. It fetches and caches the
+// OAuth access token on demand.
+type Client struct {
+	config     Config
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// NewClient creates a PayPal client. The base URL defaults to the sandbox.
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.ClientID == "" || cfg.Secret == "" {
+		return nil, ErrNotConfigured
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = SandboxBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &Client{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}, nil
+}
+
+// APIError is a non-2xx answer from PayPal.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Name       string `json:"name"`
+	Message    string `json:"message"`
+	DebugID    string `json:"debug_id"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("paypal: %d %s: %s (debug_id %s)", e.StatusCode, e.Name, e.Message, e.DebugID)
+}
+
+type Money struct {
+	CurrencyCode string `json:"currency_code"`
+	Value        string `json:"value"`
+}
+
+// FormatAmount renders an amount the way PayPal expects it.
+func FormatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+type Link struct {
+	Href   string `json:"href"`
+	Rel    string `json:"rel"`
+	Method string `json:"method"`
+}
+
+type Capture struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Amount   Money  `json:"amount"`
+	CustomID string `json:"custom_id,omitempty"`
+
+	SupplementaryData struct {
+		RelatedIDs struct {
+			OrderID string `json:"order_id"`
+		} `json:"related_ids"`
+	} `json:"supplementary_data"`
+}
+
+type PurchaseUnit struct {
+	ReferenceID string `json:"reference_id,omitempty"`
+	CustomID    string `json:"custom_id,omitempty"`
+	Description string `json:"description,omitempty"`
+	Amount      Money  `json:"amount"`
+	Payments    *struct {
+		Captures []Capture `json:"captures"`
+	} `json:"payments,omitempty"`
+}
+
+type Order struct {
+	ID            string         `json:"id"`
+	Status        string         `json:"status"`
+	PurchaseUnits []PurchaseUnit `json:"purchase_units"`
+	Links         []Link         `json:"links"`
+}
+
+// ApproveURL returns the link the payer follows to approve the order.
+func (o *Order) ApproveURL() string {
+	for _, l := range o.Links {
+		if l.Rel == "approve" || l.Rel == "payer-action" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// Captures returns every capture recorded on the order.
+func (o *Order) Captures() []Capture {
+	var captures []Capture
+	for _, pu := range o.PurchaseUnits {
+		if pu.Payments != nil {
+			captures = append(captures, pu.Payments.Captures...)
+		}
+	}
+	return captures
+}
+
+// CreateOrderRequest describes a single-unit order captured immediately.
+type CreateOrderRequest struct {
+	ReferenceID string
+	CustomID    string
+	Description string
+	Amount      float64
+	Currency    string
+	ReturnURL   string
+	CancelURL   string
+}
+
+// CreateOrder creates an order with intent CAPTURE. requestID is sent as
+// PayPal-Request-Id so retrying the same request does not create a second
+// order.
+func (c *Client) CreateOrder(ctx context.Context, req CreateOrderRequest, requestID string) (*Order, error) {
+	body := map[string]any{
+		"intent": "CAPTURE",
+		"purchase_units": []PurchaseUnit{{
+			ReferenceID: req.ReferenceID,
+			CustomID:    req.CustomID,
+			Description: req.Description,
+			Amount:      Money{CurrencyCode: req.Currency, Value: FormatAmount(req.Amount)},
+		}},
+	}
+	if req.ReturnURL != "" || req.CancelURL != "" {
+		body["application_context"] = map[string]string{
+			"return_url": req.ReturnURL,
+			"cancel_url": req.CancelURL,
+		}
+	}
+
+	var order Order
+	if err := c.do(ctx, http.MethodPost, "/v2/checkout/orders", requestID, body, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CaptureOrder captures the payment of an approved order.
+func (c *Client) CaptureOrder(ctx context.Context, orderID, requestID string) (*Order, error) {
+	var order Order
+	path := "/v2/checkout/orders/" + url.PathEscape(orderID) + "/capture"
+	if err := c.do(ctx, http.MethodPost, path, requestID, map[string]any{}, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrder fetches the current state of an order.
+func (c *Client) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	var order Order
+	if err := c.do(ctx, http.MethodGet, "/v2/checkout/orders/"+url.PathEscape(orderID), "", nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// WebhookEvent is the envelope PayPal posts to the webhook endpoint.
+type WebhookEvent struct {
+	ID           string          `json:"id"`
+	EventType    string          `json:"event_type"`
+	ResourceType string          `json:"resource_type"`
+	Resource     json.RawMessage `json:"resource"`
+}
+
+// VerifyWebhookSignature asks PayPal to check the transmission signature of
+// a webhook delivery against the configured webhook ID. body must be the
+// raw request body.
+func (c *Client) VerifyWebhookSignature(ctx context.Context, header http.Header, body []byte) (bool, error) {
+	if c.config.WebhookID == "" {
+		return false, errors.New("paypal: webhook id is not configured")
+	}
+
+	transmissionID := header.Get("PAYPAL-TRANSMISSION-ID")
+	signature := header.Get("PAYPAL-TRANSMISSION-SIG")
+	if transmissionID == "" || signature == "" {
+		return false, nil
+	}
+
+	req := map[string]any{
+		"auth_algo":         header.Get("PAYPAL-AUTH-ALGO"),
+		"cert_url":          header.Get("PAYPAL-CERT-URL"),
+		"transmission_id":   transmissionID,
+		"transmission_sig":  signature,
+		"transmission_time": header.Get("PAYPAL-TRANSMISSION-TIME"),
+		"webhook_id":        c.config.WebhookID,
+		"webhook_event":     json.RawMessage(body),
+	}
+
+	var resp struct {
+		VerificationStatus string `json:"verification_status"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/notifications/verify-webhook-signature", "", req, &resp); err != nil {
+		return false, err
+	}
+	return resp.VerificationStatus == "SUCCESS", nil
+}
+
+func (c *Client) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
+		return c.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+"/v1/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create token request: %w", err)
+	}
+	req.SetBasicAuth(c.config.ClientID, c.config.Secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", decodeAPIError(resp)
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("decode token: %w", err)
+	}
+
+	// Refresh a minute early so a request never goes out with a token that
+	// expires in flight.
+	c.accessToken = tok.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return c.accessToken, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path, requestID string, in, out any) error {
+	token, err := c.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal payload: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if requestID != "" {
+		req.Header.Set("PayPal-Request-Id", requestID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// Drop the cached token so the next call fetches a fresh one.
+		c.mu.Lock()
+		c.accessToken = ""
+		c.mu.Unlock()
+	}
+	if resp.StatusCode >= 400 {
+		return decodeAPIError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err := json.Unmarshal(b, apiErr); err != nil || apiErr.Name == "" {
+		// OAuth errors use a different shape.
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(b, &oauthErr) == nil && oauthErr.Error != "" {
+			apiErr.Name = oauthErr.Error
+			apiErr.Message = oauthErr.Description
+		} else if apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(b))
+		}
+	}
+	return apiErr
+}
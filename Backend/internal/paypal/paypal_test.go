@@ -0,0 +1,182 @@
+package paypal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// fakePayPal is a minimal stand-in for the PayPal REST API.
+type fakePayPal struct {
+	tokenCalls   atomic.Int32
+	requestIDs   []string
+	verifyStatus string
+}
+
+func (f *fakePayPal) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		f.tokenCalls.Add(1)
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "client" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client", "error_description": "bad credentials"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "tok", "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /v2/checkout/orders", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.requestIDs = append(f.requestIDs, r.Header.Get("PayPal-Request-Id"))
+		var body struct {
+			PurchaseUnits []PurchaseUnit `json:"purchase_units"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode order body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Order{
+			ID:            "ORDER-1",
+			Status:        OrderStatusCreated,
+			PurchaseUnits: body.PurchaseUnits,
+			Links:         []Link{{Href: "https://paypal.test/approve", Rel: "approve", Method: "GET"}},
+		})
+	})
+	mux.HandleFunc("POST /v2/checkout/orders/{id}/capture", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "ORDER-1" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]string{"name": "UNPROCESSABLE_ENTITY", "message": "order not approved", "debug_id": "dbg"})
+			return
+		}
+		unit := PurchaseUnit{Amount: Money{CurrencyCode: "USD", Value: "150.00"}}
+		unit.Payments = &struct {
+			Captures []Capture `json:"captures"`
+		}{Captures: []Capture{{ID: "CAP-1", Status: CaptureStatusCompleted, Amount: unit.Amount}}}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Order{ID: "ORDER-1", Status: OrderStatusCompleted, PurchaseUnits: []PurchaseUnit{unit}})
+	})
+	mux.HandleFunc("POST /v1/notifications/verify-webhook-signature", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			WebhookID    string          `json:"webhook_id"`
+			WebhookEvent json.RawMessage `json:"webhook_event"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode verify body: %v", err)
+		}
+		if body.WebhookID != "WH-1" || len(body.WebhookEvent) == 0 {
+			t.Errorf("unexpected verify request: %+v", body)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"verification_status": f.verifyStatus})
+	})
+	return mux
+}
+
+func newTestClient(t *testing.T, f *fakePayPal) *Client {
+	t.Helper()
+	srv := httptest.NewServer(f.handler(t))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(Config{BaseURL: srv.URL, ClientID: "client", Secret: "secret", WebhookID: "WH-1"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return c
+}
+
+func TestCreateAndCaptureOrder(t *testing.T) {
+	f := &fakePayPal{}
+	c := newTestClient(t, f)
+	ctx := context.Background()
+
+	order, err := c.CreateOrder(ctx, CreateOrderRequest{
+		ReferenceID: "event-1",
+		CustomID:    "tx-1",
+		Amount:      150,
+		Currency:    "USD",
+	}, "req-1")
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if order.ID != "ORDER-1" || order.ApproveURL() != "https://paypal.test/approve" {
+		t.Errorf("unexpected order: %+v", order)
+	}
+	if got := order.PurchaseUnits[0].Amount.Value; got != "150.00" {
+		t.Errorf("expected amount 150.00, got %s", got)
+	}
+	if len(f.requestIDs) != 1 || f.requestIDs[0] != "req-1" {
+		t.Errorf("expected PayPal-Request-Id req-1, got %v", f.requestIDs)
+	}
+
+	captured, err := c.CaptureOrder(ctx, order.ID, "cap-req-1")
+	if err != nil {
+		t.Fatalf("capture order: %v", err)
+	}
+	captures := captured.Captures()
+	if len(captures) != 1 || captures[0].ID != "CAP-1" || captures[0].Status != CaptureStatusCompleted {
+		t.Errorf("unexpected captures: %+v", captures)
+	}
+
+	if calls := f.tokenCalls.Load(); calls != 1 {
+		t.Errorf("expected the access token to be cached, fetched %d times", calls)
+	}
+}
+
+func TestCaptureOrderAPIError(t *testing.T) {
+	c := newTestClient(t, &fakePayPal{})
+
+	_, err := c.CaptureOrder(context.Background(), "ORDER-X", "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Name != "UNPROCESSABLE_ENTITY" {
+		t.Errorf("unexpected api error: %+v", apiErr)
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"id":"WH-EVT-1","event_type":"PAYMENT.CAPTURE.COMPLETED","resource":{}}`)
+	header := http.Header{}
+	header.Set("PAYPAL-TRANSMISSION-ID", "trans-1")
+	header.Set("PAYPAL-TRANSMISSION-SIG", "sig")
+	header.Set("PAYPAL-TRANSMISSION-TIME", "2025-01-01T00:00:00Z")
+	header.Set("PAYPAL-AUTH-ALGO", "SHA256withRSA")
+	header.Set("PAYPAL-CERT-URL", "https://api.paypal.com/cert")
+
+	tests := []struct {
+		name     string
+		status   string
+		header   http.Header
+		expected bool
+	}{
+		{name: "valid signature", status: "SUCCESS", header: header, expected: true},
+		{name: "invalid signature", status: "FAILURE", header: header, expected: false},
+		{name: "missing headers", status: "SUCCESS", header: http.Header{}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, &fakePayPal{verifyStatus: tt.status})
+			ok, err := c.VerifyWebhookSignature(context.Background(), tt.header, body)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ok)
+			}
+		})
+	}
+}
+
+func TestNewClientRequiresCredentials(t *testing.T) {
+	if _, err := NewClient(Config{}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("expected ErrNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// InvoiceData holds everything printed on an invoice. The amounts are
+// already computed: Gross is the sum of the lines, Subtotal the taxable base
+// after the discount.
+type InvoiceData struct {
+	BusinessName string
+	BusinessRNC  string
+	Address      string
+
+	InvoiceNumber string
+	NCF           string
+	IssueDate     time.Time
+	DueDate       *time.Time
+	Status        string
+	VoidReason    string
+
+	ClientName  string
+	ClientEmail string
+	ClientPhone string
+	ClientRNC   string
+	EventName   string
+	EventDate   string
+
+	Lines       []InvoiceLine
+	Gross       float64
+	Discount    float64
+	Subtotal    float64
+	TaxRate     float64
+	Tax         float64
+	TaxIncluded bool
+	Total       float64
+	AmountPaid  float64
+
+	Notes       string
+	Terms       string
+	GeneratedAt time.Time
+}
+
+type InvoiceLine struct {
+	Description string
+	Quantity    int
+	UnitPrice   float64
+	Total       float64
+}
+
+// ncfTypeNames are the receipt types printed next to the NCF.
+var ncfTypeNames = map[string]string{
+	"B01": "Crédito Fiscal",
+	"B02": "Consumidor Final",
+	"B14": "Régimen Especial",
+	"B15": "Gubernamental",
+	"B16": "Exportaciones",
+	"E31": "Crédito Fiscal Electrónico",
+	"E32": "Consumo Electrónico",
+}
+
+var invoiceStatusNames = map[string]string{
+	"issued":         "Emitida",
+	"sent":           "Enviada",
+	"partially_paid": "Pago parcial",
+	"paid":           "Pagada",
+	"void":           "Anulada",
+}
+
+// GenerateInvoicePDF creates the invoice PDF, with the ITBIS and NCF fields
+// a Dominican invoice needs, and returns the PDF bytes.
+func GenerateInvoicePDF(data InvoiceData) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	// Issuer on the left, invoice number and NCF on the right.
+	top := pdf.GetY()
+	pdf.SetFont("Helvetica", "B", 24)
+	pdf.SetTextColor(255, 60, 172)
+	pdf.CellFormat(100, 12, tr(data.BusinessName), "", 0, "L", false, 0, "")
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(100, 100, 100)
+	if data.BusinessRNC != "" {
+		pdf.CellFormat(100, 5, "RNC: "+data.BusinessRNC, "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	pdf.CellFormat(100, 5, tr(data.Address), "", 0, "L", false, 0, "")
+	bottom := pdf.GetY() + 5
+
+	pdf.SetXY(115, top)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.CellFormat(80, 8, "Factura", "", 0, "R", false, 0, "")
+	pdf.SetXY(115, top+9)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(80, 5, "No. "+data.InvoiceNumber, "", 0, "R", false, 0, "")
+	if data.NCF != "" {
+		pdf.SetXY(115, top+14)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(80, 5, "NCF: "+data.NCF, "", 0, "R", false, 0, "")
+		if name, ok := ncfTypeNames[data.NCF[:min(3, len(data.NCF))]]; ok {
+			pdf.SetXY(115, top+19)
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(80, 5, tr(name), "", 0, "R", false, 0, "")
+		}
+	}
+	if y := top + 25; y > bottom {
+		bottom = y
+	}
+	pdf.SetY(bottom)
+
+	pdf.SetDrawColor(255, 60, 172)
+	pdf.SetLineWidth(0.8)
+	pdf.Line(15, pdf.GetY(), 195, pdf.GetY())
+	pdf.Ln(6)
+
+	if data.Status == "void" {
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetTextColor(200, 30, 30)
+		note := "FACTURA ANULADA"
+		if data.VoidReason != "" {
+			note += ": " + data.VoidReason
+		}
+		pdf.MultiCell(0, 6, tr(note), "", "L", false)
+		pdf.Ln(3)
+	}
+
+	// Client on the left, dates and status on the right.
+	top = pdf.GetY()
+	sectionTitle(pdf, tr, "Facturado a")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(40, 40, 40)
+	for _, line := range []string{
+		data.ClientName,
+		labelled("RNC/Cédula", data.ClientRNC),
+		data.ClientEmail,
+		labelled("Teléfono", data.ClientPhone),
+		labelled("Evento", data.EventName),
+		labelled("Fecha del evento", data.EventDate),
+	} {
+		if line == "" {
+			continue
+		}
+		pdf.CellFormat(95, 5, tr(line), "", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	bottom = pdf.GetY()
+
+	pdf.SetY(top)
+	for _, row := range [][2]string{
+		{"Fecha de emisión", data.IssueDate.Format("02/01/2006")},
+		{"Vencimiento", formatOptionalDate(data.DueDate)},
+		{"Estado", invoiceStatusNames[data.Status]},
+	} {
+		if row[1] == "" {
+			continue
+		}
+		pdf.SetX(115)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.SetTextColor(80, 80, 80)
+		pdf.CellFormat(40, 6, tr(row[0]+":"), "", 0, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetTextColor(40, 40, 40)
+		pdf.CellFormat(40, 6, tr(row[1]), "", 0, "R", false, 0, "")
+		pdf.Ln(6)
+	}
+	if pdf.GetY() < bottom {
+		pdf.SetY(bottom)
+	}
+	pdf.Ln(6)
+
+	widths := []float64{90, 20, 35, 35}
+	tableHeader(pdf, tr, widths, []string{"Descripción", "Cant.", "Precio Unit.", "Total"})
+	for i, line := range data.Lines {
+		tableRow(pdf, tr, i, widths, []string{
+			truncateString(line.Description, 50),
+			fmt.Sprintf("%d", line.Quantity),
+			formatCurrency(line.UnitPrice),
+			formatCurrency(line.Total),
+		})
+	}
+	pdf.Ln(4)
+
+	totalRow := func(label, value string, bold bool) {
+		pdf.SetX(105)
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.SetTextColor(80, 80, 80)
+		pdf.CellFormat(50, 7, tr(label), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(40, 40, 40)
+		pdf.CellFormat(40, 7, value, "", 0, "R", false, 0, "")
+		pdf.Ln(7)
+	}
+	if data.Discount > 0 {
+		totalRow("Importe:", formatCurrency(data.Gross), false)
+		totalRow("Descuento:", "-"+formatCurrency(data.Discount), false)
+	}
+	totalRow("Subtotal gravado:", formatCurrency(data.Subtotal), false)
+	totalRow(fmt.Sprintf("ITBIS (%.0f%%):", data.TaxRate*100), formatCurrency(data.Tax), false)
+
+	pdf.SetX(105)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetTextColor(255, 60, 172)
+	pdf.CellFormat(50, 9, "TOTAL:", "T", 0, "R", false, 0, "")
+	pdf.SetTextColor(40, 40, 40)
+	pdf.CellFormat(40, 9, formatCurrency(data.Total), "T", 0, "R", false, 0, "")
+	pdf.Ln(9)
+
+	if data.AmountPaid > 0 {
+		totalRow("Pagado:", formatCurrency(data.AmountPaid), false)
+		balance := data.Total - data.AmountPaid
+		if balance < 0 {
+			balance = 0
+		}
+		totalRow("Balance pendiente:", formatCurrency(balance), true)
+	}
+	if data.TaxIncluded {
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, tr("Los precios incluyen ITBIS."), "", 0, "R", false, 0, "")
+		pdf.Ln(5)
+	}
+	pdf.Ln(6)
+
+	for _, block := range [][2]string{{"Notas", data.Notes}, {"Términos y condiciones", data.Terms}} {
+		if block[1] == "" {
+			continue
+		}
+		sectionTitle(pdf, tr, block[0])
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(80, 80, 80)
+		pdf.MultiCell(0, 5, tr(block[1]), "", "L", false)
+		pdf.Ln(4)
+	}
+
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.SetLineWidth(0.3)
+	pdf.Line(15, pdf.GetY(), 195, pdf.GetY())
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetTextColor(150, 150, 150)
+	pdf.CellFormat(0, 5, tr(fmt.Sprintf("Generado por %s el %s", data.BusinessName, data.GeneratedAt.Format("02/01/2006 3:04 PM"))), "", 0, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// labelled prefixes value with its label, or is empty if value is.
+func labelled(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return label + ": " + value
+}
+
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("02/01/2006")
+}
@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestGenerateInvoicePDF(t *testing.T) {
+	due := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	data := InvoiceData{
+		BusinessName:  "RosaFiesta",
+		BusinessRNC:   "131234567",
+		Address:       "San Cristóbal, República Dominicana",
+		InvoiceNumber: "FAC-2026-000042",
+		NCF:           "B0100000042",
+		IssueDate:     time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC),
+		DueDate:       &due,
+		Status:        "partially_paid",
+		ClientName:    "Ana Pérez",
+		ClientRNC:     "101234567",
+		EventName:     "Boda Pérez",
+		Lines: []InvoiceLine{
+			{Description: "Silla Tiffany dorada", Quantity: 100, UnitPrice: 150, Total: 15000},
+			{Description: "Envío", Quantity: 1, UnitPrice: 2000, Total: 2000},
+		},
+		Gross:       17000,
+		Discount:    1000,
+		Subtotal:    13559.32,
+		TaxRate:     0.18,
+		Tax:         2440.68,
+		TaxIncluded: true,
+		Total:       16000,
+		AmountPaid:  8000,
+		Terms:       "Pago del 50% para reservar.",
+		GeneratedAt: time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC),
+	}
+
+	out, err := GenerateInvoicePDF(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-")) {
+		t.Fatalf("output is not a PDF: %q", out[:16])
+	}
+
+	// A void invoice without the optional fields still renders.
+	if _, err := GenerateInvoicePDF(InvoiceData{BusinessName: "RosaFiesta", InvoiceNumber: "FAC-2026-000043", Status: "void", VoidReason: "Duplicada"}); err != nil {
+		t.Fatal(err)
+	}
+}
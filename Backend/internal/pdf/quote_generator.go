@@ -19,6 +19,7 @@ type QuoteData struct {
 	Items        []QuoteItem
 	Subtotal     float64
 	AdditionalCosts float64
+	DeliveryFee  float64
 	Total        float64
 	PaymentMethod string
 	AdminNotes   string
@@ -148,6 +149,13 @@ func GenerateQuotePDF(data QuoteData) ([]byte, error) {
 	pdf.CellFormat(35, 7, formatCurrency(data.Subtotal), "", 0, "R", false, 0, "")
 	pdf.Ln(7)
 
+	if data.DeliveryFee > 0 {
+		pdf.SetX(115)
+		pdf.CellFormat(40, 7, "Envío:", "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 7, formatCurrency(data.DeliveryFee), "", 0, "R", false, 0, "")
+		pdf.Ln(7)
+	}
+
 	if data.AdditionalCosts > 0 {
 		pdf.SetX(115)
 		pdf.CellFormat(40, 7, "Costos adicionales:", "", 0, "R", false, 0, "")
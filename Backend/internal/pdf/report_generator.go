@@ -0,0 +1,406 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ReportData holds everything shown in the business report for one period.
+// To is exclusive.
+type ReportData struct {
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+
+	Periods            []ReportPeriod
+	IncomeByCategory   map[string]float64
+	ExpensesByCategory map[string]float64
+
+	TopArticles []ReportArticle
+
+	LeadsTotal int
+	LeadsLost  int
+	Funnel     []ReportFunnelStage
+
+	Outstanding []ReportInstallment
+	Maintenance []ReportMaintenance
+}
+
+// ReportPeriod is one bar group of the revenue vs expenses chart.
+type ReportPeriod struct {
+	Label    string
+	Income   float64
+	Expenses float64
+}
+
+type ReportArticle struct {
+	Name    string
+	Rentals int
+	Events  int
+	Revenue float64
+}
+
+type ReportFunnelStage struct {
+	Stage string
+	Count int
+}
+
+type ReportInstallment struct {
+	EventName string
+	Client    string
+	Amount    float64
+	DueDate   *time.Time
+	Overdue   bool
+}
+
+type ReportMaintenance struct {
+	Type  string
+	Count int
+	Total float64
+}
+
+var (
+	incomeColor  = [3]int{255, 60, 172}
+	expenseColor = [3]int{120, 120, 120}
+)
+
+// GenerateReportPDF creates the multi-page business report and returns the
+// PDF bytes: finances on the first page, articles and the sales funnel on the
+// second, and outstanding payments and maintenance on the third.
+func GenerateReportPDF(data ReportData) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	period := fmt.Sprintf("%s - %s", data.From.Format("02/01/2006"), data.To.AddDate(0, 0, -1).Format("02/01/2006"))
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(150, 150, 150)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("Reporte RosaFiesta %s  ·  Generado el %s  ·  Página %d", period, data.GeneratedAt.Format("02/01/2006 3:04 PM"), pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+
+	// === PAGE 1: FINANCES ===
+	pdf.AddPage()
+	reportHeader(pdf, tr, period)
+
+	var income, expenses float64
+	for _, p := range data.Periods {
+		income += p.Income
+		expenses += p.Expenses
+	}
+	var outstanding float64
+	for _, i := range data.Outstanding {
+		outstanding += i.Amount
+	}
+
+	sectionTitle(pdf, tr, "Resumen")
+	summaryRow(pdf, tr, "Ingresos", formatCurrency(income))
+	summaryRow(pdf, tr, "Gastos", formatCurrency(expenses))
+	summaryRow(pdf, tr, "Resultado neto", formatCurrency(income-expenses))
+	summaryRow(pdf, tr, "Cuotas pendientes", formatCurrency(outstanding))
+	pdf.Ln(6)
+
+	sectionTitle(pdf, tr, "Ingresos vs. Gastos")
+	labels := make([]string, len(data.Periods))
+	series := [2][]float64{make([]float64, len(data.Periods)), make([]float64, len(data.Periods))}
+	for i, p := range data.Periods {
+		labels[i] = p.Label
+		series[0][i] = p.Income
+		series[1][i] = p.Expenses
+	}
+	groupedBarChart(pdf, tr, 70, labels, series)
+	pdf.Ln(4)
+
+	sectionTitle(pdf, tr, "Por categoría")
+	y := pdf.GetY()
+	categoryTable(pdf, tr, 15, "Ingresos", data.IncomeByCategory)
+	left := pdf.GetY()
+	pdf.SetY(y)
+	categoryTable(pdf, tr, 108, "Gastos", data.ExpensesByCategory)
+	pdf.SetY(math.Max(left, pdf.GetY()))
+
+	// === PAGE 2: ARTICLES AND SALES ===
+	pdf.AddPage()
+	sectionTitle(pdf, tr, "Artículos más alquilados")
+	tableHeader(pdf, tr, []float64{90, 25, 25, 40}, []string{"Artículo", "Unidades", "Eventos", "Ingresos"})
+	for i, a := range data.TopArticles {
+		tableRow(pdf, tr, i, []float64{90, 25, 25, 40}, []string{
+			truncateString(a.Name, 45), fmt.Sprintf("%d", a.Rentals), fmt.Sprintf("%d", a.Events), formatCurrency(a.Revenue),
+		})
+	}
+	if len(data.TopArticles) == 0 {
+		emptyNote(pdf, tr, "No hubo alquileres en el período.")
+	}
+	pdf.Ln(8)
+
+	sectionTitle(pdf, tr, "Embudo de ventas")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(80, 80, 80)
+	rate := 0.0
+	if data.LeadsTotal > 0 && len(data.Funnel) > 0 {
+		rate = float64(data.Funnel[len(data.Funnel)-1].Count) / float64(data.LeadsTotal) * 100
+	}
+	pdf.CellFormat(0, 6, tr(fmt.Sprintf("%d prospectos, %d perdidos, %.1f%% ganados.", data.LeadsTotal, data.LeadsLost, rate)), "", 0, "L", false, 0, "")
+	pdf.Ln(8)
+	stages := make([]string, len(data.Funnel))
+	counts := make([]float64, len(data.Funnel))
+	for i, s := range data.Funnel {
+		stages[i] = funnelStageNames[s.Stage]
+		if stages[i] == "" {
+			stages[i] = s.Stage
+		}
+		counts[i] = float64(s.Count)
+	}
+	horizontalBarChart(pdf, tr, stages, counts, func(v float64) string { return fmt.Sprintf("%.0f", v) })
+
+	// === PAGE 3: RECEIVABLES AND MAINTENANCE ===
+	pdf.AddPage()
+	sectionTitle(pdf, tr, "Cuotas pendientes")
+	widths := []float64{60, 55, 30, 35}
+	tableHeader(pdf, tr, widths, []string{"Evento", "Cliente", "Vence", "Monto"})
+	for i, inst := range data.Outstanding {
+		due := "-"
+		if inst.DueDate != nil {
+			due = inst.DueDate.Format("02/01/2006")
+		}
+		if inst.Overdue {
+			pdf.SetTextColor(200, 30, 30)
+		}
+		tableRow(pdf, tr, i, widths, []string{truncateString(inst.EventName, 30), truncateString(inst.Client, 28), due, formatCurrency(inst.Amount)})
+		pdf.SetTextColor(40, 40, 40)
+	}
+	if len(data.Outstanding) == 0 {
+		emptyNote(pdf, tr, "No hay cuotas pendientes.")
+	}
+	pdf.Ln(8)
+
+	sectionTitle(pdf, tr, "Costos de mantenimiento")
+	types := make([]string, len(data.Maintenance))
+	costs := make([]float64, len(data.Maintenance))
+	for i, m := range data.Maintenance {
+		types[i] = fmt.Sprintf("%s (%d)", m.Type, m.Count)
+		costs[i] = m.Total
+	}
+	if len(data.Maintenance) == 0 {
+		emptyNote(pdf, tr, "No se completó ningún mantenimiento en el período.")
+	} else {
+		horizontalBarChart(pdf, tr, types, costs, formatCurrency)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var funnelStageNames = map[string]string{
+	"new":         "Nuevos",
+	"contacted":   "Contactados",
+	"qualified":   "Calificados",
+	"proposal":    "Propuesta",
+	"negotiating": "Negociación",
+	"won":         "Ganados",
+}
+
+func reportHeader(pdf *fpdf.Fpdf, tr func(string) string, period string) {
+	pdf.SetFont("Helvetica", "B", 24)
+	pdf.SetTextColor(255, 60, 172)
+	pdf.CellFormat(0, 12, "RosaFiesta", "", 0, "L", false, 0, "")
+	pdf.Ln(12)
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.CellFormat(0, 8, tr("Reporte del negocio"), "", 0, "L", false, 0, "")
+	pdf.Ln(7)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(0, 5, tr("Período: "+period), "", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetDrawColor(255, 60, 172)
+	pdf.SetLineWidth(0.8)
+	pdf.Line(15, pdf.GetY(), 195, pdf.GetY())
+	pdf.Ln(6)
+}
+
+func sectionTitle(pdf *fpdf.Fpdf, tr func(string) string, title string) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetTextColor(255, 60, 172)
+	pdf.CellFormat(0, 8, tr(title), "", 0, "L", false, 0, "")
+	pdf.Ln(9)
+}
+
+func summaryRow(pdf *fpdf.Fpdf, tr func(string) string, label, value string) {
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(80, 80, 80)
+	pdf.CellFormat(60, 6, tr(label), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.CellFormat(50, 6, value, "", 0, "R", false, 0, "")
+	pdf.Ln(6)
+}
+
+func emptyNote(pdf *fpdf.Fpdf, tr func(string) string, note string) {
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.SetTextColor(150, 150, 150)
+	pdf.CellFormat(0, 7, tr(note), "", 0, "L", false, 0, "")
+	pdf.Ln(7)
+}
+
+func tableHeader(pdf *fpdf.Fpdf, tr func(string) string, widths []float64, titles []string) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(255, 60, 172)
+	pdf.SetTextColor(255, 255, 255)
+	for i, t := range titles {
+		align := "C"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 8, tr(t), "TB", 0, align, true, 0, "")
+	}
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(40, 40, 40)
+}
+
+func tableRow(pdf *fpdf.Fpdf, tr func(string) string, row int, widths []float64, cells []string) {
+	if row%2 == 0 {
+		pdf.SetFillColor(248, 248, 248)
+	} else {
+		pdf.SetFillColor(255, 255, 255)
+	}
+	for i, c := range cells {
+		align := "C"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, tr(c), "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(7)
+}
+
+// categoryTable lists a category breakdown at x, largest first.
+func categoryTable(pdf *fpdf.Fpdf, tr func(string) string, x float64, title string, totals map[string]float64) {
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return totals[names[i]] > totals[names[j]] })
+
+	pdf.SetX(x)
+	tableHeader(pdf, tr, []float64{52, 35}, []string{title, "Monto"})
+	for i, name := range names {
+		pdf.SetX(x)
+		tableRow(pdf, tr, i, []float64{52, 35}, []string{truncateString(name, 28), formatCurrency(totals[name])})
+	}
+	if len(names) == 0 {
+		pdf.SetX(x)
+		emptyNote(pdf, tr, "Sin registros.")
+	}
+}
+
+// groupedBarChart draws income and expense bars side by side for each label,
+// with a light axis and a legend, filling the page width at the current Y.
+func groupedBarChart(pdf *fpdf.Fpdf, tr func(string) string, height float64, labels []string, series [2][]float64) {
+	x0, y0 := 30.0, pdf.GetY()
+	width := 165.0
+	max := 0.0
+	for _, s := range series {
+		for _, v := range s {
+			max = math.Max(max, v)
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	// Axis and gridlines with the scale on the left.
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.SetTextColor(120, 120, 120)
+	pdf.SetLineWidth(0.1)
+	pdf.SetDrawColor(220, 220, 220)
+	for i := 0; i <= 4; i++ {
+		y := y0 + height - height*float64(i)/4
+		pdf.Line(x0, y, x0+width, y)
+		pdf.SetXY(10, y-2)
+		pdf.CellFormat(19, 4, compactAmount(max*float64(i)/4), "", 0, "R", false, 0, "")
+	}
+
+	if len(labels) > 0 {
+		group := width / float64(len(labels))
+		bar := math.Min(group*0.35, 12)
+		for i, label := range labels {
+			gx := x0 + group*float64(i) + (group-2*bar)/2
+			for s, color := range [2][3]int{incomeColor, expenseColor} {
+				h := height * series[s][i] / max
+				pdf.SetFillColor(color[0], color[1], color[2])
+				pdf.Rect(gx+bar*float64(s), y0+height-h, bar, h, "F")
+			}
+			pdf.SetXY(x0+group*float64(i), y0+height+1)
+			pdf.CellFormat(group, 4, tr(label), "", 0, "C", false, 0, "")
+		}
+	}
+
+	pdf.SetDrawColor(80, 80, 80)
+	pdf.SetLineWidth(0.3)
+	pdf.Line(x0, y0+height, x0+width, y0+height)
+
+	// Legend
+	pdf.SetXY(x0, y0+height+7)
+	for i, name := range []string{"Ingresos", "Gastos"} {
+		color := [2][3]int{incomeColor, expenseColor}[i]
+		pdf.SetFillColor(color[0], color[1], color[2])
+		pdf.Rect(pdf.GetX(), pdf.GetY()+1, 3, 3, "F")
+		pdf.SetX(pdf.GetX() + 4)
+		pdf.CellFormat(25, 5, name, "", 0, "L", false, 0, "")
+	}
+	pdf.SetXY(15, y0+height+14)
+}
+
+// horizontalBarChart draws one labelled bar per value, scaled to the largest.
+func horizontalBarChart(pdf *fpdf.Fpdf, tr func(string) string, labels []string, values []float64, format func(float64) string) {
+	const labelWidth, barWidth, barHeight = 45.0, 100.0, 6.0
+	max := 0.0
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	pdf.SetFont("Helvetica", "", 9)
+	for i, label := range labels {
+		y := pdf.GetY()
+		pdf.SetTextColor(40, 40, 40)
+		pdf.CellFormat(labelWidth, barHeight, tr(truncateString(label, 26)), "", 0, "L", false, 0, "")
+
+		w := barWidth * values[i] / max
+		pdf.SetFillColor(incomeColor[0], incomeColor[1], incomeColor[2])
+		pdf.Rect(15+labelWidth, y+0.5, w, barHeight-1, "F")
+
+		pdf.SetXY(15+labelWidth+w+2, y)
+		pdf.SetTextColor(80, 80, 80)
+		pdf.CellFormat(35, barHeight, format(values[i]), "", 0, "L", false, 0, "")
+		pdf.Ln(barHeight + 2)
+	}
+}
+
+// compactAmount shortens axis labels, e.g. 125000 to "125k".
+func compactAmount(v float64) string {
+	switch {
+	case v >= 1000000:
+		return fmt.Sprintf("%.1fM", v/1000000)
+	case v >= 1000:
+		return fmt.Sprintf("%.0fk", v/1000)
+	default:
+		return fmt.Sprintf("%.0f", v)
+	}
+}
@@ -0,0 +1,45 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestGenerateReportPDF(t *testing.T) {
+	due := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	data := ReportData{
+		From:        time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		GeneratedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Periods: []ReportPeriod{
+			{Label: "26/01", Income: 15000, Expenses: 4000},
+			{Label: "02/02", Income: 0, Expenses: 1200},
+			{Label: "09/02", Income: 32000, Expenses: 9000},
+		},
+		IncomeByCategory:   map[string]float64{"Alquiler de Artículos": 47000},
+		ExpensesByCategory: map[string]float64{"Transporte": 5200, "Mantenimiento": 9000},
+		TopArticles:        []ReportArticle{{Name: "Silla Tiffany dorada", Rentals: 200, Events: 3, Revenue: 30000}},
+		LeadsTotal:         12,
+		LeadsLost:          2,
+		Funnel:             []ReportFunnelStage{{"new", 10}, {"contacted", 7}, {"qualified", 5}, {"proposal", 4}, {"negotiating", 3}, {"won", 2}},
+		Outstanding:        []ReportInstallment{{EventName: "Boda Pérez", Client: "Ana Pérez", Amount: 12500, DueDate: &due, Overdue: true}},
+		Maintenance:        []ReportMaintenance{{Type: "cleaning", Count: 4, Total: 2500}},
+	}
+
+	out, err := GenerateReportPDF(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-")) {
+		t.Fatalf("output is not a PDF: %q", out[:16])
+	}
+	if pages := bytes.Count(out, []byte("/Type /Page\n")); pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+
+	// An empty period still renders.
+	if _, err := GenerateReportPDF(ReportData{From: data.From, To: data.To, GeneratedAt: data.GeneratedAt}); err != nil {
+		t.Fatal(err)
+	}
+}
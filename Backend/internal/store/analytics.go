@@ -0,0 +1,444 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lib/pq"
+
+	"Backend/internal/store/models"
+)
+
+// AnalyticsStore aggregates the admin dashboard from events, their items and
+// the payments recorded against them.
+//
+// Every query takes the same filter: the range is [From, To) and, when
+// EventTypeID is set, only events of that type count. Series are bucketed
+// with date_trunc and include empty periods, so charts have no gaps.
+type AnalyticsStore struct {
+	db *sql.DB
+}
+
+// bookedStatuses are the statuses of an event whose quote the client
+// accepted.
+const bookedStatuses = `('confirmed', 'paid', 'completed')`
+
+// periods expands the filter's range into one row per bucket. Its
+// parameters are $1 granularity, $2 from and $3 to.
+const periods = `generate_series(date_trunc($1, $2::timestamptz), date_trunc($1, $3::timestamptz - INTERVAL '1 microsecond'), ('1 ' || $1)::interval) AS p(period)`
+
+// rangeBound turns a zero time into NULL, which leaves that end of the range
+// open.
+func rangeBound(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// EventVolume counts events per period by the date they are held. Drafts
+// are ignored. Both ends of the range must be set.
+func (s *AnalyticsStore) EventVolume(ctx context.Context, f models.AnalyticsFilter) ([]models.EventVolumePoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.period,
+		       COUNT(e.id) FILTER (WHERE e.status NOT IN ('cancelled', 'rejected')),
+		       COUNT(e.id) FILTER (WHERE e.status IN `+bookedStatuses+`),
+		       COUNT(e.id) FILTER (WHERE e.status = 'cancelled'),
+		       COALESCE(SUM(e.total_quote) FILTER (WHERE e.status IN `+bookedStatuses+`), 0)
+		FROM `+periods+`
+		LEFT JOIN events e
+		       ON date_trunc($1, e.date::timestamptz) = p.period
+		      AND e.date >= $2 AND e.date < $3
+		      AND e.status <> 'draft'
+		      AND ($4::uuid IS NULL OR e.event_type_id = $4)
+		GROUP BY p.period
+		ORDER BY p.period`,
+		f.Granularity, f.From, f.To, f.EventTypeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.EventVolumePoint{}
+	for rows.Next() {
+		var pt models.EventVolumePoint
+		if err := rows.Scan(&pt.Period, &pt.Events, &pt.Booked, &pt.Cancelled, &pt.Revenue); err != nil {
+			return nil, err
+		}
+		points = append(points, pt)
+	}
+	return points, rows.Err()
+}
+
+// Revenue sums the money collected per period by the date it was paid.
+// PayPal captures mark the installments they cover paid, so paid
+// installments count every payment once, and the ones PayPal collected are
+// reported apart. Both ends of the range must be set.
+func (s *AnalyticsStore) Revenue(ctx context.Context, f models.AnalyticsFilter) ([]models.RevenuePoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH payments AS (
+			SELECT ip.event_id, ip.paid_at,
+			       CASE WHEN ip.payment_method = 'paypal' THEN 0 ELSE ip.amount END::numeric AS installments,
+			       CASE WHEN ip.payment_method = 'paypal' THEN ip.amount ELSE 0 END::numeric AS paypal
+			FROM installment_payments ip
+			WHERE ip.payment_status = 'paid' AND ip.paid_at IS NOT NULL
+		), filtered AS (
+			SELECT pay.*
+			FROM payments pay
+			JOIN events e ON e.id = pay.event_id
+			WHERE pay.paid_at >= $2 AND pay.paid_at < $3
+			  AND ($4::uuid IS NULL OR e.event_type_id = $4)
+		)
+		SELECT p.period, COALESCE(SUM(f.installments), 0), COALESCE(SUM(f.paypal), 0)
+		FROM `+periods+`
+		LEFT JOIN filtered f ON date_trunc($1, f.paid_at) = p.period
+		GROUP BY p.period
+		ORDER BY p.period`,
+		f.Granularity, f.From, f.To, f.EventTypeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.RevenuePoint{}
+	for rows.Next() {
+		var pt models.RevenuePoint
+		if err := rows.Scan(&pt.Period, &pt.Installments, &pt.PayPal); err != nil {
+			return nil, err
+		}
+		pt.Total = pt.Installments + pt.PayPal
+		points = append(points, pt)
+	}
+	return points, rows.Err()
+}
+
+// TopArticles ranks articles by units rented for booked events held in the
+// range. Revenue uses the price captured on the item, or the variant's
+// current rental price for items added before prices were captured.
+func (s *AnalyticsStore) TopArticles(ctx context.Context, f models.AnalyticsFilter, limit int) ([]models.TopArticle, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.id, a.name_template,
+		       SUM(ei.quantity),
+		       COUNT(DISTINCT ei.event_id),
+		       COALESCE(SUM(ei.quantity * COALESCE(ei.price_snapshot, v.rental_price, 0)), 0)
+		FROM event_items ei
+		JOIN events e ON e.id = ei.event_id
+		JOIN articles a ON a.id = ei.article_id
+		LEFT JOIN article_variants v ON v.id = ei.variant_id
+		WHERE e.status IN `+bookedStatuses+`
+		  AND ($1::timestamptz IS NULL OR e.date >= $1)
+		  AND ($2::timestamptz IS NULL OR e.date < $2)
+		  AND ($3::uuid IS NULL OR e.event_type_id = $3)
+		GROUP BY a.id, a.name_template
+		ORDER BY 3 DESC, 5 DESC
+		LIMIT $4`,
+		rangeBound(f.From), rangeBound(f.To), f.EventTypeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	articles := []models.TopArticle{}
+	for rows.Next() {
+		var a models.TopArticle
+		if err := rows.Scan(&a.ArticleID, &a.Name, &a.Rentals, &a.Events, &a.Revenue); err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// Conversion measures quotes for events held in the range. A quote counts
+// as sent once the client requested it; it is approved once the client
+// accepted it, even if the event was later cancelled.
+func (s *AnalyticsStore) Conversion(ctx context.Context, f models.AnalyticsFilter) (*models.ConversionStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var c models.ConversionStats
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE quote_approved_at IS NOT NULL OR status IN `+bookedStatuses+`),
+		       COUNT(*) FILTER (WHERE quote_approved_at IS NULL AND status NOT IN `+bookedStatuses+`
+		                          AND (status = 'rejected' OR quote_rejected_at IS NOT NULL)),
+		       COUNT(*) FILTER (WHERE status IN ('requested', 'adjusted'))
+		FROM events
+		WHERE status NOT IN ('draft', 'planning')
+		  AND ($1::timestamptz IS NULL OR date >= $1)
+		  AND ($2::timestamptz IS NULL OR date < $2)
+		  AND ($3::uuid IS NULL OR event_type_id = $3)`,
+		rangeBound(f.From), rangeBound(f.To), f.EventTypeID,
+	).Scan(&c.QuotesSent, &c.Approved, &c.Rejected, &c.Pending)
+	if err != nil {
+		return nil, err
+	}
+	if c.QuotesSent > 0 {
+		c.Rate = float64(c.Approved) / float64(c.QuotesSent)
+	}
+	return &c, nil
+}
+
+// PendingPayments lists payments still owed, including those waiting for
+// an admin to verify them, due in the range, oldest first.
+// Installments without a due date are included when the range is open.
+func (s *AnalyticsStore) PendingPayments(ctx context.Context, f models.AnalyticsFilter) ([]models.PendingPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ip.id, e.id, e.name, TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')),
+		       ip.amount, ip.due_date, COALESCE(ip.due_date < NOW(), false)
+		FROM installment_payments ip
+		JOIN events e ON e.id = ip.event_id
+		JOIN users u ON u.id = e.user_id
+		WHERE ip.payment_status IN ('pending', 'pending_verification')
+		  AND e.status NOT IN ('cancelled', 'rejected')
+		  AND ($1::timestamptz IS NULL OR ip.due_date >= $1)
+		  AND ($2::timestamptz IS NULL OR ip.due_date < $2)
+		  AND ($3::uuid IS NULL OR e.event_type_id = $3)
+		ORDER BY ip.due_date ASC NULLS LAST`,
+		rangeBound(f.From), rangeBound(f.To), f.EventTypeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.PendingPayment{}
+	for rows.Next() {
+		var p models.PendingPayment
+		if err := rows.Scan(&p.InstallmentID, &p.EventID, &p.EventName, &p.Client, &p.Amount, &p.DueDate, &p.Overdue); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// LeadFunnel counts leads created in the range per pipeline stage.
+func (s *AnalyticsStore) LeadFunnel(ctx context.Context, f models.AnalyticsFilter) (*models.LeadFunnel, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT status, COUNT(*)
+		FROM leads
+		WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR created_at < $2)
+		GROUP BY status`,
+		rangeBound(f.From), rangeBound(f.To))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byStatus := make(map[string]int)
+	funnel := &models.LeadFunnel{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		byStatus[status] = count
+		funnel.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	funnel.Lost = byStatus["lost"]
+	reached := 0
+	funnel.Stages = make([]models.FunnelStage, len(models.LeadStages))
+	for i := len(models.LeadStages) - 1; i >= 0; i-- {
+		reached += byStatus[models.LeadStages[i]]
+		funnel.Stages[i] = models.FunnelStage{Stage: models.LeadStages[i], Count: reached}
+	}
+	return funnel, nil
+}
+
+// MaintenanceCosts sums the cost of maintenance completed in the range, by
+// type.
+func (s *AnalyticsStore) MaintenanceCosts(ctx context.Context, f models.AnalyticsFilter) ([]models.MaintenanceCost, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT maintenance_type, COUNT(*), COALESCE(SUM(cost), 0)
+		FROM article_maintenance_logs
+		WHERE status = ANY($3)
+		  AND ($1::timestamptz IS NULL OR performed_at >= $1)
+		  AND ($2::timestamptz IS NULL OR performed_at < $2)
+		GROUP BY maintenance_type
+		ORDER BY 3 DESC`,
+		rangeBound(f.From), rangeBound(f.To), pq.Array([]string{string(models.MaintenanceStatusCompleted)}))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	costs := []models.MaintenanceCost{}
+	for rows.Next() {
+		var c models.MaintenanceCost
+		if err := rows.Scan(&c.Type, &c.Count, &c.Total); err != nil {
+			return nil, err
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
+
+// DepreciationRentals is how many rentals an item is expected to last. Each
+// rental of a variant is charged its replacement cost divided by this.
+var DepreciationRentals = 50
+
+// eventProfitQuery computes an EventProfit per event. Its $1 is the number
+// of rentals an item lasts; callers append their own WHERE clause.
+// Events quoted before total_quote was stored fall back to the quote
+// computed from their items. What was collected is the paid installments,
+// which PayPal captures settle too. Items without a variant, or whose
+// variant has no replacement cost, don't depreciate.
+const eventProfitQuery = `
+	SELECT e.id, e.name, e.date, e.status,
+	       TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')),
+	       COALESCE(NULLIF(e.total_quote, 0), items.subtotal + COALESCE(e.additional_costs, 0) + e.delivery_fee),
+	       e.delivery_fee, paid.total, vendors.total, maintenance.total, items.depreciation
+	FROM events e
+	JOIN users u ON u.id = e.user_id
+	LEFT JOIN LATERAL (
+		SELECT COALESCE(SUM(ei.quantity * COALESCE(ei.price_snapshot, v.rental_price, 0)), 0) AS subtotal,
+		       COALESCE(SUM(ei.quantity * COALESCE(v.replacement_cost, 0)), 0) / $1 AS depreciation
+		FROM event_items ei
+		LEFT JOIN article_variants v ON v.id = ei.variant_id
+		WHERE ei.event_id = e.id
+	) items ON true
+	LEFT JOIN LATERAL (
+		SELECT COALESCE(SUM(ip.amount + ip.surcharge), 0) AS total
+		FROM installment_payments ip
+		WHERE ip.event_id = e.id AND ip.payment_status = 'paid'
+	) paid ON true
+	LEFT JOIN LATERAL (
+		SELECT COALESCE(SUM(vp.amount), 0) AS total
+		FROM vendor_payments vp
+		WHERE vp.event_id = e.id
+	) vendors ON true
+	LEFT JOIN LATERAL (
+		SELECT COALESCE(SUM(ml.cost), 0) AS total
+		FROM article_maintenance_logs ml
+		WHERE ml.event_id = e.id AND ml.status <> 'cancelled'
+	) maintenance ON true`
+
+func scanEventProfit(row interface{ Scan(...any) error }) (*models.EventProfit, error) {
+	var p models.EventProfit
+	if err := row.Scan(
+		&p.EventID, &p.EventName, &p.EventDate, &p.Status, &p.Client,
+		&p.Revenue, &p.DeliveryFees, &p.Collected, &p.VendorCosts, &p.MaintenanceCosts, &p.Depreciation,
+	); err != nil {
+		return nil, err
+	}
+	p.ComputeTotals()
+	return &p, nil
+}
+
+// EventProfits computes the profit of every booked event held in the range,
+// ranked by margin, best first.
+func (s *AnalyticsStore) EventProfits(ctx context.Context, f models.AnalyticsFilter) ([]models.EventProfit, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, eventProfitQuery+`
+		WHERE e.status IN `+bookedStatuses+`
+		  AND ($2::timestamptz IS NULL OR e.date >= $2)
+		  AND ($3::timestamptz IS NULL OR e.date < $3)
+		  AND ($4::uuid IS NULL OR e.event_type_id = $4)`,
+		DepreciationRentals, rangeBound(f.From), rangeBound(f.To), f.EventTypeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profits := []models.EventProfit{}
+	for rows.Next() {
+		p, err := scanEventProfit(rows)
+		if err != nil {
+			return nil, err
+		}
+		profits = append(profits, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(profits, func(i, j int) bool {
+		if profits[i].Margin != profits[j].Margin {
+			return profits[i].Margin > profits[j].Margin
+		}
+		return profits[i].Profit > profits[j].Profit
+	})
+	return profits, nil
+}
+
+// EventProfit computes the profit of one event, whatever its status, and
+// lists its costs.
+func (s *AnalyticsStore) EventProfit(ctx context.Context, eventID uuid.UUID) (*models.EventProfit, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	p, err := scanEventProfit(s.db.QueryRowContext(ctx, eventProfitQuery+`
+		WHERE e.id = $2`, DepreciationRentals, eventID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT 'vendor', COALESCE(NULLIF(vp.description, ''), ev.name), vp.amount, vp.payment_date
+		FROM vendor_payments vp
+		JOIN expense_vendors ev ON ev.id = vp.vendor_id
+		WHERE vp.event_id = $1
+		UNION ALL
+		SELECT 'maintenance', a.name_template || ': ' || COALESCE(NULLIF(ml.description, ''), ml.maintenance_type),
+		       ml.cost, ml.performed_at
+		FROM article_maintenance_logs ml
+		JOIN articles a ON a.id = ml.article_id
+		WHERE ml.event_id = $1 AND ml.status <> 'cancelled' AND ml.cost IS NOT NULL
+		UNION ALL
+		SELECT 'depreciation', a.name_template || ' (' || v.name || ') x' || ei.quantity,
+		       ei.quantity * v.replacement_cost / $2, NULL
+		FROM event_items ei
+		JOIN article_variants v ON v.id = ei.variant_id
+		JOIN articles a ON a.id = ei.article_id
+		WHERE ei.event_id = $1 AND v.replacement_cost > 0
+		ORDER BY 1, 3 DESC`,
+		eventID, DepreciationRentals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	p.Costs = []models.EventCostLine{}
+	for rows.Next() {
+		var line models.EventCostLine
+		if err := rows.Scan(&line.Kind, &line.Description, &line.Amount, &line.Date); err != nil {
+			return nil, err
+		}
+		p.Costs = append(p.Costs, line)
+	}
+	return p, rows.Err()
+}
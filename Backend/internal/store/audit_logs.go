@@ -16,8 +16,8 @@ type AuditLogsStore struct {
 // Log records an audit trail entry.
 func (s *AuditLogsStore) Log(ctx context.Context, log *models.AuditLog) error {
 	query := `
-		INSERT INTO audit_logs (user_id, event_id, action, entity_type, entity_id, old_value, new_value)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO audit_logs (user_id, event_id, action, entity_type, entity_id, old_value, new_value, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at`
 
 	return s.db.QueryRowContext(ctx, query,
@@ -28,6 +28,7 @@ func (s *AuditLogsStore) Log(ctx context.Context, log *models.AuditLog) error {
 		log.EntityID,
 		log.OldValue,
 		log.NewValue,
+		log.Reason,
 	).Scan(&log.ID, &log.CreatedAt)
 }
 
@@ -35,7 +36,7 @@ func (s *AuditLogsStore) Log(ctx context.Context, log *models.AuditLog) error {
 func (s *AuditLogsStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]models.AuditLogWithUser, error) {
 	query := `
 		SELECT al.id, al.user_id, al.event_id, al.action, al.entity_type, al.entity_id,
-		       al.old_value, al.new_value, al.created_at,
+		       al.old_value, al.new_value, al.reason, al.created_at,
 		       COALESCE(CONCAT(u.first_name, ' ', u.last_name), u.email) as user_name
 		FROM audit_logs al
 		LEFT JOIN users u ON al.user_id = u.id
@@ -53,7 +54,7 @@ func (s *AuditLogsStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([
 		var l models.AuditLogWithUser
 		if err := rows.Scan(
 			&l.ID, &l.UserID, &l.EventID, &l.Action, &l.EntityType, &l.EntityID,
-			&l.OldValue, &l.NewValue, &l.CreatedAt, &l.UserName,
+			&l.OldValue, &l.NewValue, &l.Reason, &l.CreatedAt, &l.UserName,
 		); err != nil {
 			return nil, err
 		}
@@ -3,6 +3,10 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,6 +32,177 @@ type CalendarDay struct {
 	Availability []ArticleAvailability `json:"availability"`
 }
 
+// Unless a reservation says otherwise, rented items leave the warehouse the
+// day before the event and come back the day after.
+const (
+	DefaultPickupLeadDays = 1
+	DefaultReturnLagDays  = 1
+)
+
+// RentalWindow is the time the reserved units are out of the warehouse.
+// EventDate is the day of the event and keys the reservation row; PickupAt
+// and ReturnAt bound the rental itself. Category setup and cleaning buffers
+// are added around it when the reservation is stored.
+type RentalWindow struct {
+	EventDate time.Time
+	PickupAt  time.Time
+	ReturnAt  time.Time
+}
+
+// DefaultRentalWindow covers the whole day before the event through the
+// end of the day after it.
+func DefaultRentalWindow(eventDate time.Time) RentalWindow {
+	day := startOfDay(eventDate)
+	return RentalWindow{
+		EventDate: day,
+		PickupAt:  day.AddDate(0, 0, -DefaultPickupLeadDays),
+		ReturnAt:  day.AddDate(0, 0, 1+DefaultReturnLagDays),
+	}
+}
+
+func (w RentalWindow) Validate() error {
+	if w.PickupAt.IsZero() || w.ReturnAt.IsZero() {
+		return errors.New("pickup and return times are required")
+	}
+	if !w.ReturnAt.After(w.PickupAt) {
+		return errors.New("return time must be after pickup time")
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// reservedSpan is the blocked interval [from, until) of one reservation row.
+type reservedSpan struct {
+	articleID uuid.UUID
+	eventID   uuid.UUID
+	from      time.Time
+	until     time.Time
+	quantity  int
+}
+
+// peakUsage returns the highest number of units out at the same time within
+// [from, until). Spans that merely touch (one ends when the next starts) do
+// not overlap, so back-to-back rentals of the same unit are allowed.
+func peakUsage(spans []reservedSpan, from, until time.Time) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+
+	edges := make([]edge, 0, len(spans)*2)
+	for _, sp := range spans {
+		if !sp.from.Before(until) || !sp.until.After(from) {
+			continue
+		}
+		start, end := sp.from, sp.until
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(until) {
+			end = until
+		}
+		edges = append(edges, edge{at: start, delta: sp.quantity}, edge{at: end, delta: -sp.quantity})
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	peak, current := 0, 0
+	for _, e := range edges {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+	return peak
+}
+
+type spanQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// loadSpans returns the active reservations whose blocked window overlaps
+// [from, until), optionally limited to one variant.
+func loadSpans(ctx context.Context, q spanQuerier, articleID *uuid.UUID, from, until time.Time) ([]reservedSpan, error) {
+	query := `
+		SELECT article_id, event_id, blocked_from, blocked_until, quantity_used
+		FROM inventory_availability
+		WHERE status NOT IN ('returned')
+			AND blocked_from < $2
+			AND blocked_until > $1`
+	args := []any{from, until}
+	if articleID != nil {
+		query += ` AND article_id = $3`
+		args = append(args, *articleID)
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var spans []reservedSpan
+	for rows.Next() {
+		var sp reservedSpan
+		if err := rows.Scan(&sp.articleID, &sp.eventID, &sp.from, &sp.until, &sp.quantity); err != nil {
+			return nil, err
+		}
+		spans = append(spans, sp)
+	}
+	return spans, rows.Err()
+}
+
+func newCalendarDay(d time.Time) CalendarDay {
+	return CalendarDay{
+		Date:      d.Format("2006-01-02"),
+		DayOfWeek: int(d.Weekday()),
+		IsWeekend: d.Weekday() == time.Saturday || d.Weekday() == time.Sunday,
+		ItemsUsed: make(map[string]int),
+	}
+}
+
+// ReservationLine is one variant/quantity pair to reserve. ArticleID holds
+// the article_variants id, matching inventory_availability.article_id.
+type ReservationLine struct {
+	ArticleID uuid.UUID
+	Quantity  int
+}
+
+// StockShortfall describes a single line that could not be reserved.
+type StockShortfall struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Requested int       `json:"requested"`
+	Available int       `json:"available"`
+	Shortfall int       `json:"shortfall"`
+}
+
+// InsufficientStockError is returned when one or more lines of a
+// reservation exceed the remaining stock. It matches ErrInsufficientStock
+// through errors.Is so callers can branch on it like the other sentinels.
+type InsufficientStockError struct {
+	Shortfalls []StockShortfall
+}
+
+func (e *InsufficientStockError) Error() string {
+	parts := make([]string, 0, len(e.Shortfalls))
+	for _, sf := range e.Shortfalls {
+		parts = append(parts, fmt.Sprintf("%s: requested %d, available %d", sf.ArticleID, sf.Requested, sf.Available))
+	}
+	return fmt.Sprintf("%s (%s)", ErrInsufficientStock.Error(), strings.Join(parts, "; "))
+}
+
+func (e *InsufficientStockError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
+
 func (s *AvailabilityStore) CheckArticleAvailability(ctx context.Context, articleID uuid.UUID, date time.Time) (int, error) {
 	var totalStock int
 	stockQuery := `SELECT stock FROM article_variants WHERE id = $1`
@@ -35,57 +210,53 @@ func (s *AvailabilityStore) CheckArticleAvailability(ctx context.Context, articl
 		return 0, err
 	}
 
-	var reserved int
-	reservedQuery := `
-		SELECT COALESCE(SUM(quantity_used), 0)
-		FROM inventory_availability
-		WHERE article_id = $1 AND event_date = $2 AND status NOT IN ('returned')`
-	if err := s.db.QueryRowContext(ctx, reservedQuery, articleID, date).Scan(&reserved); err != nil {
+	from := startOfDay(date)
+	until := from.AddDate(0, 0, 1)
+	spans, err := loadSpans(ctx, s.db, &articleID, from, until)
+	if err != nil {
 		return 0, err
 	}
 
-	return totalStock - reserved, nil
+	return totalStock - peakUsage(spans, from, until), nil
 }
 
+// GetArticleAvailabilityRange reports, for each day in the range, the peak
+// number of units of the variant blocked by any rental window touching that
+// day, including setup and cleaning buffers.
 func (s *AvailabilityStore) GetArticleAvailabilityRange(ctx context.Context, articleID uuid.UUID, startDate, endDate time.Time) ([]CalendarDay, error) {
-	query := `
-		SELECT
-			av.event_date,
-			COALESCE(SUM(av.quantity_used), 0) as used
-		FROM inventory_availability av
-		WHERE av.article_id = $1
-			AND av.event_date BETWEEN $2 AND $3
-			AND av.status NOT IN ('returned')
-		GROUP BY av.event_date
-		ORDER BY av.event_date`
-
-	rows, err := s.db.QueryContext(ctx, query, articleID, startDate, endDate)
-	if err != nil {
+	var totalStock int
+	stockQuery := `SELECT COALESCE(stock, 0) FROM article_variants WHERE id = $1`
+	if err := s.db.QueryRowContext(ctx, stockQuery, articleID).Scan(&totalStock); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
-	defer rows.Close()
 
-	usageMap := make(map[string]int)
-	for rows.Next() {
-		var eventDate time.Time
-		var used int
-		if err := rows.Scan(&eventDate, &used); err != nil {
-			return nil, err
-		}
-		usageMap[eventDate.Format("2006-01-02")] = used
+	first := startOfDay(startDate)
+	last := startOfDay(endDate)
+	spans, err := loadSpans(ctx, s.db, &articleID, first, last.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
 	}
 
 	days := []CalendarDay{}
-	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		day := CalendarDay{
-			Date:      d.Format("2006-01-02"),
-			DayOfWeek: int(d.Weekday()),
-			IsWeekend: d.Weekday() == time.Saturday || d.Weekday() == time.Sunday,
-			ItemsUsed: make(map[string]int),
-		}
-		if used, ok := usageMap[day.Date]; ok {
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		day := newCalendarDay(d)
+		used := peakUsage(spans, d, d.AddDate(0, 0, 1))
+		if used > 0 {
 			day.ItemsUsed[articleID.String()] = used
 		}
+		available := totalStock - used
+		if available < 0 {
+			available = 0
+		}
+		day.Availability = []ArticleAvailability{{
+			ArticleID:  articleID,
+			TotalStock: totalStock,
+			Reserved:   used,
+			Available:  available,
+		}}
 		days = append(days, day)
 	}
 
@@ -94,20 +265,11 @@ func (s *AvailabilityStore) GetArticleAvailabilityRange(ctx context.Context, art
 
 func (s *AvailabilityStore) GetAllArticlesAvailability(ctx context.Context, date time.Time) ([]ArticleAvailability, error) {
 	query := `
-		SELECT
-			av.article_id,
-			av.stock,
-			COALESCE(used.quantity_used, 0) as reserved
-		FROM article_variants av
-		LEFT JOIN (
-			SELECT article_id, SUM(quantity_used) as quantity_used
-			FROM inventory_availability
-			WHERE event_date = $1 AND status NOT IN ('returned')
-			GROUP BY article_id
-		) used ON av.article_id = used.article_id
-		WHERE av.is_active = true`
-
-	rows, err := s.db.QueryContext(ctx, query, date)
+		SELECT id, COALESCE(stock, 0)
+		FROM article_variants
+		WHERE is_active = true`
+
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -116,30 +278,204 @@ func (s *AvailabilityStore) GetAllArticlesAvailability(ctx context.Context, date
 	var availability []ArticleAvailability
 	for rows.Next() {
 		var a ArticleAvailability
-		if err := rows.Scan(&a.ArticleID, &a.TotalStock, &a.Reserved); err != nil {
+		if err := rows.Scan(&a.ArticleID, &a.TotalStock); err != nil {
 			return nil, err
 		}
-		a.Available = a.TotalStock - a.Reserved
 		availability = append(availability, a)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	from := startOfDay(date)
+	until := from.AddDate(0, 0, 1)
+	spans, err := loadSpans(ctx, s.db, nil, from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	byArticle := make(map[uuid.UUID][]reservedSpan)
+	for _, sp := range spans {
+		byArticle[sp.articleID] = append(byArticle[sp.articleID], sp)
+	}
+
+	for i := range availability {
+		a := &availability[i]
+		a.Reserved = peakUsage(byArticle[a.ArticleID], from, until)
+		a.Available = a.TotalStock - a.Reserved
+	}
 
 	return availability, nil
 }
 
-func (s *AvailabilityStore) ReserveInventory(ctx context.Context, articleID, eventID uuid.UUID, date time.Time, quantity int) error {
-	query := `
-		INSERT INTO inventory_availability (article_id, event_id, event_date, quantity_used, status)
-		VALUES ($1, $2, $3, $4, 'reserved')
+// ReserveInventory atomically checks the remaining stock of a variant over
+// the rental window and reserves the quantity for the event. The variant
+// row is locked for the duration of the transaction so concurrent bookings
+// are serialized instead of both reading the same free stock.
+func (s *AvailabilityStore) ReserveInventory(ctx context.Context, articleID, eventID uuid.UUID, window RentalWindow, quantity int) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return reserveTx(ctx, tx, eventID, window, []ReservationLine{{ArticleID: articleID, Quantity: quantity}}, ReservationReserved, nil)
+	})
+}
+
+// stockCheck is the outcome of checkStockTx: the variants requested in id
+// order, the units wanted of each and the span each one would be blocked.
+type stockCheck struct {
+	ids       []uuid.UUID
+	requested map[uuid.UUID]int
+	blocked   map[uuid.UUID]blockedSpan
+}
+
+type blockedSpan struct{ from, until time.Time }
+
+// checkStockTx locks the variant rows of the lines in id order, so two
+// transactions reserving overlapping sets cannot deadlock, and checks that
+// each one can cover its quantity. Each line is blocked for the rental
+// window widened by its category's setup and cleaning buffers, and is
+// checked against the peak usage of every other reservation overlapping
+// that span. All shortfalls are collected before failing so the caller can
+// report every line that is short, not only the first one.
+func checkStockTx(ctx context.Context, tx *sql.Tx, window RentalWindow, lines []ReservationLine) (*stockCheck, error) {
+	check := &stockCheck{
+		requested: make(map[uuid.UUID]int),
+		blocked:   make(map[uuid.UUID]blockedSpan),
+	}
+	for _, line := range lines {
+		check.requested[line.ArticleID] += line.Quantity
+	}
+
+	check.ids = make([]uuid.UUID, 0, len(check.requested))
+	for id := range check.requested {
+		check.ids = append(check.ids, id)
+	}
+	sort.Slice(check.ids, func(i, j int) bool { return check.ids[i].String() < check.ids[j].String() })
+
+	var shortfalls []StockShortfall
+	for _, id := range check.ids {
+		var stock, setupHours, cleaningHours int
+		lockQuery := `
+			SELECT COALESCE(v.stock, 0),
+				COALESCE(c.setup_buffer_hours, 0),
+				COALESCE(c.cleaning_buffer_hours, 0)
+			FROM article_variants v
+			JOIN articles a ON a.id = v.article_id
+			LEFT JOIN categories c ON c.id = a.category_id
+			WHERE v.id = $1
+			FOR UPDATE OF v`
+		err := tx.QueryRowContext(ctx, lockQuery, id).Scan(&stock, &setupHours, &cleaningHours)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+
+		b := blockedSpan{
+			from:  window.PickupAt.Add(-time.Duration(setupHours) * time.Hour),
+			until: window.ReturnAt.Add(time.Duration(cleaningHours) * time.Hour),
+		}
+		check.blocked[id] = b
+
+		spans, err := loadSpans(ctx, tx, &id, b.from, b.until)
+		if err != nil {
+			return nil, err
+		}
+
+		requested := check.requested[id]
+		available := stock - peakUsage(spans, b.from, b.until)
+		if available < 0 {
+			available = 0
+		}
+		if requested > available {
+			shortfalls = append(shortfalls, StockShortfall{
+				ArticleID: id,
+				Requested: requested,
+				Available: available,
+				Shortfall: requested - available,
+			})
+		}
+	}
+
+	if len(shortfalls) > 0 {
+		return nil, &InsufficientStockError{Shortfalls: shortfalls}
+	}
+	return check, nil
+}
+
+// reserveTx reserves every line or none of them, after checkStockTx has
+// found room for all of them. New rows get the given status; heldUntil is
+// only set for soft holds.
+func reserveTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID, window RentalWindow, lines []ReservationLine, status string, heldUntil *time.Time) error {
+	check, err := checkStockTx(ctx, tx, window, lines)
+	if err != nil {
+		return err
+	}
+
+	// A previously released row for the same event is reused instead of
+	// stacking the new quantity on top of stock that was already returned,
+	// and takes the new window. A row still out keeps the window it was
+	// booked for.
+	upsertQuery := `
+		INSERT INTO inventory_availability (
+			article_id, event_id, event_date, quantity_used, status,
+			pickup_at, return_at, blocked_from, blocked_until, held_until
+		)
+		VALUES ($1, $2, $3, $4, $9, $5, $6, $7, $8, $10)
 		ON CONFLICT (article_id, event_id, event_date) DO UPDATE
-		SET quantity_used = inventory_availability.quantity_used + $4`
-	_, err := s.db.ExecContext(ctx, query, articleID, eventID, date, quantity)
-	return err
+		SET quantity_used = CASE
+				WHEN inventory_availability.status = 'returned' THEN EXCLUDED.quantity_used
+				ELSE inventory_availability.quantity_used + EXCLUDED.quantity_used
+			END,
+			status = CASE
+				WHEN inventory_availability.status = 'returned' THEN EXCLUDED.status
+				ELSE inventory_availability.status
+			END,
+			held_until = CASE
+				WHEN inventory_availability.status = 'returned' THEN EXCLUDED.held_until
+				ELSE inventory_availability.held_until
+			END,
+			pickup_at = CASE
+				WHEN inventory_availability.status = 'returned' THEN EXCLUDED.pickup_at
+				ELSE inventory_availability.pickup_at
+			END,
+			return_at = CASE
+				WHEN inventory_availability.status = 'returned' THEN EXCLUDED.return_at
+				ELSE inventory_availability.return_at
+			END,
+			blocked_from = CASE
+				WHEN inventory_availability.status = 'returned' THEN EXCLUDED.blocked_from
+				ELSE inventory_availability.blocked_from
+			END,
+			blocked_until = CASE
+				WHEN inventory_availability.status = 'returned' THEN EXCLUDED.blocked_until
+				ELSE inventory_availability.blocked_until
+			END,
+			updated_at = NOW()`
+	for _, id := range check.ids {
+		b := check.blocked[id]
+		if _, err := tx.ExecContext(ctx, upsertQuery,
+			id, eventID, window.EventDate, check.requested[id],
+			window.PickupAt, window.ReturnAt, b.from, b.until,
+			status, heldUntil,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (s *AvailabilityStore) ReleaseInventory(ctx context.Context, articleID, eventID uuid.UUID, date time.Time) error {
 	query := `
 		UPDATE inventory_availability
-		SET status = 'returned', updated_at = NOW()
+		SET status = 'returned', held_until = NULL, updated_at = NOW()
 		WHERE article_id = $1 AND event_id = $2 AND event_date = $3`
 	_, err := s.db.ExecContext(ctx, query, articleID, eventID, date)
 	return err
@@ -148,49 +484,77 @@ func (s *AvailabilityStore) ReleaseInventory(ctx context.Context, articleID, eve
 func (s *AvailabilityStore) ConfirmInventory(ctx context.Context, articleID, eventID uuid.UUID, date time.Time) error {
 	query := `
 		UPDATE inventory_availability
-		SET status = 'confirmed', updated_at = NOW()
+		SET status = 'confirmed', held_until = NULL, updated_at = NOW()
 		WHERE article_id = $1 AND event_id = $2 AND event_date = $3`
 	_, err := s.db.ExecContext(ctx, query, articleID, eventID, date)
 	return err
 }
 
-func (s *AvailabilityStore) GetCalendarView(ctx context.Context, startDate, endDate time.Time) ([]CalendarDay, error) {
+// ExpireHolds releases soft holds whose hold period ended before now and
+// returns the ids of the events that lost them.
+func (s *AvailabilityStore) ExpireHolds(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
 	query := `
-		SELECT
-			ia.event_date,
-			COUNT(DISTINCT ia.event_id) as events_count,
-			COUNT(*) as entries_count
-		FROM inventory_availability ia
-		WHERE ia.event_date BETWEEN $1 AND $2
-		GROUP BY ia.event_date
-		ORDER BY ia.event_date`
-
-	rows, err := s.db.QueryContext(ctx, query, startDate, endDate)
+		UPDATE inventory_availability
+		SET status = 'returned', held_until = NULL, updated_at = NOW()
+		WHERE status = 'held' AND held_until < $1
+		RETURNING event_id`
+
+	rows, err := s.db.QueryContext(ctx, query, now)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	eventsMap := make(map[string]int)
+	seen := make(map[uuid.UUID]struct{})
+	var eventIDs []uuid.UUID
 	for rows.Next() {
-		var eventDate time.Time
-		var eventsCount, entriesCount int
-		if err := rows.Scan(&eventDate, &eventsCount, &entriesCount); err != nil {
+		var eventID uuid.UUID
+		if err := rows.Scan(&eventID); err != nil {
 			return nil, err
 		}
-		eventsMap[eventDate.Format("2006-01-02")] = eventsCount
+		if _, ok := seen[eventID]; ok {
+			continue
+		}
+		seen[eventID] = struct{}{}
+		eventIDs = append(eventIDs, eventID)
+	}
+	return eventIDs, rows.Err()
+}
+
+// GetCalendarView counts, for each day in the range, the events whose
+// blocked rental window touches that day and the peak units of each
+// variant out on that day.
+func (s *AvailabilityStore) GetCalendarView(ctx context.Context, startDate, endDate time.Time) ([]CalendarDay, error) {
+	first := startOfDay(startDate)
+	last := startOfDay(endDate)
+	spans, err := loadSpans(ctx, s.db, nil, first, last.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
 	}
 
 	days := []CalendarDay{}
-	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		day := CalendarDay{
-			Date:      d.Format("2006-01-02"),
-			DayOfWeek: int(d.Weekday()),
-			IsWeekend: d.Weekday() == time.Saturday || d.Weekday() == time.Sunday,
-			ItemsUsed: make(map[string]int),
-		}
-		if count, ok := eventsMap[day.Date]; ok {
-			day.EventsCount = count
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		day := newCalendarDay(d)
+		next := d.AddDate(0, 0, 1)
+
+		events := make(map[uuid.UUID]struct{})
+		byArticle := make(map[uuid.UUID][]reservedSpan)
+		for _, sp := range spans {
+			if !sp.from.Before(next) || !sp.until.After(d) {
+				continue
+			}
+			events[sp.eventID] = struct{}{}
+			byArticle[sp.articleID] = append(byArticle[sp.articleID], sp)
+		}
+
+		day.EventsCount = len(events)
+		for articleID, articleSpans := range byArticle {
+			if used := peakUsage(articleSpans, d, next); used > 0 {
+				day.ItemsUsed[articleID.String()] = used
+			}
 		}
 		days = append(days, day)
 	}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+func TestPeakUsage(t *testing.T) {
+	day := time.Date(2025, time.June, 14, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return day.Add(time.Duration(hours) * time.Hour) }
+	span := func(from, until, qty int) reservedSpan {
+		return reservedSpan{articleID: uuid.New(), eventID: uuid.New(), from: at(from), until: at(until), quantity: qty}
+	}
+
+	tests := []struct {
+		name     string
+		spans    []reservedSpan
+		from     time.Time
+		until    time.Time
+		expected int
+	}{
+		{
+			name:     "no reservations",
+			from:     at(0),
+			until:    at(24),
+			expected: 0,
+		},
+		{
+			name:     "overlapping windows add up",
+			spans:    []reservedSpan{span(0, 48, 3), span(12, 30, 2)},
+			from:     at(0),
+			until:    at(24),
+			expected: 5,
+		},
+		{
+			name:     "disjoint windows in the same day do not add up",
+			spans:    []reservedSpan{span(0, 10, 3), span(12, 20, 4)},
+			from:     at(0),
+			until:    at(24),
+			expected: 4,
+		},
+		{
+			name:     "back to back windows do not overlap",
+			spans:    []reservedSpan{span(0, 12, 3), span(12, 24, 3)},
+			from:     at(0),
+			until:    at(24),
+			expected: 3,
+		},
+		{
+			name:     "windows outside the range are ignored",
+			spans:    []reservedSpan{span(-48, -24, 5), span(24, 48, 5)},
+			from:     at(0),
+			until:    at(24),
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := peakUsage(tt.spans, tt.from, tt.until); got != tt.expected {
+				t.Errorf("expected peak %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDefaultRentalWindow(t *testing.T) {
+	eventDate := time.Date(2025, time.June, 14, 15, 30, 0, 0, time.UTC)
+	w := DefaultRentalWindow(eventDate)
+
+	if want := time.Date(2025, time.June, 13, 0, 0, 0, 0, time.UTC); !w.PickupAt.Equal(want) {
+		t.Errorf("expected pickup %v, got %v", want, w.PickupAt)
+	}
+	if want := time.Date(2025, time.June, 16, 0, 0, 0, 0, time.UTC); !w.ReturnAt.Equal(want) {
+		t.Errorf("expected return %v, got %v", want, w.ReturnAt)
+	}
+	if err := w.Validate(); err != nil {
+		t.Errorf("expected default window to be valid, got %v", err)
+	}
+}
+
+func TestReservationActionFor(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected reservationAction
+	}{
+		{status: models.EventStatusDraft, expected: reservationRelease},
+		{status: models.EventStatusRequested, expected: reservationHold},
+		{status: models.EventStatusAdjusted, expected: reservationHold},
+		{status: models.EventStatusConfirmed, expected: reservationConfirm},
+		{status: models.EventStatusPaid, expected: reservationConfirm},
+		{status: models.EventStatusCompleted, expected: reservationKeep},
+		{status: models.EventStatusRejected, expected: reservationRelease},
+		{status: models.EventStatusCancelled, expected: reservationRelease},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := reservationActionFor(tt.status); got != tt.expected {
+				t.Errorf("expected action %d for %q, got %d", tt.expected, tt.status, got)
+			}
+		})
+	}
+}
@@ -15,7 +15,8 @@ type CategoriesStore struct {
 
 func (s *CategoriesStore) GetAll(ctx context.Context) ([]models.Category, error) {
 	query := `
-		SELECT id, created_by, name, description, image_url, icon, parent_id, created, updated, updated_by
+		SELECT id, created_by, name, description, image_url, icon, parent_id,
+			setup_buffer_hours, cleaning_buffer_hours, created, updated, updated_by
 		FROM categories
 		WHERE deleted IS NULL`
 
@@ -36,6 +37,8 @@ func (s *CategoriesStore) GetAll(ctx context.Context) ([]models.Category, error)
 			&category.ImageURL,
 			&category.Icon,
 			&category.ParentID,
+			&category.SetupBufferHours,
+			&category.CleaningBufferHours,
 			&category.Created,
 			&category.Updated,
 			&category.UpdatedBy,
@@ -55,9 +58,10 @@ func (s *CategoriesStore) GetAll(ctx context.Context) ([]models.Category, error)
 func (s *CategoriesStore) Create(ctx context.Context, category *models.Category) error {
 	query := `
 		INSERT INTO categories (
-			created_by, name, description, image_url, icon, parent_id
+			created_by, name, description, image_url, icon, parent_id,
+			setup_buffer_hours, cleaning_buffer_hours
 		) VALUES (
-			$1, $2, $3, $4, $5, $6
+			$1, $2, $3, $4, $5, $6, $7, $8
 		) RETURNING id, created`
 
 	err := s.db.QueryRowContext(ctx, query,
@@ -67,6 +71,8 @@ func (s *CategoriesStore) Create(ctx context.Context, category *models.Category)
 		category.ImageURL,
 		category.Icon,
 		category.ParentID,
+		category.SetupBufferHours,
+		category.CleaningBufferHours,
 	).Scan(&category.ID, &category.Created)
 
 	if err != nil {
@@ -78,7 +84,8 @@ func (s *CategoriesStore) Create(ctx context.Context, category *models.Category)
 
 func (s *CategoriesStore) GetById(ctx context.Context, id uuid.UUID) (*models.Category, error) {
 	query := `
-		SELECT id, created_by, name, description, image_url, icon, parent_id, created, updated, updated_by
+		SELECT id, created_by, name, description, image_url, icon, parent_id,
+			setup_buffer_hours, cleaning_buffer_hours, created, updated, updated_by
 		FROM categories
 		WHERE id = $1 AND deleted IS NULL`
 
@@ -92,6 +99,8 @@ func (s *CategoriesStore) GetById(ctx context.Context, id uuid.UUID) (*models.Ca
 		&category.ImageURL,
 		&category.Icon,
 		&category.ParentID,
+		&category.SetupBufferHours,
+		&category.CleaningBufferHours,
 		&category.Created,
 		&category.Updated,
 		&category.UpdatedBy,
@@ -110,8 +119,9 @@ func (s *CategoriesStore) GetById(ctx context.Context, id uuid.UUID) (*models.Ca
 func (s *CategoriesStore) Update(ctx context.Context, category *models.Category) error {
 	query := `
 		UPDATE categories
-		SET name = $1, description = $2, image_url = $3, icon = $4, parent_id = $5, updated = NOW(), updated_by = $6
-		WHERE id = $7 AND deleted IS NULL
+		SET name = $1, description = $2, image_url = $3, icon = $4, parent_id = $5,
+			setup_buffer_hours = $6, cleaning_buffer_hours = $7, updated = NOW(), updated_by = $8
+		WHERE id = $9 AND deleted IS NULL
 		RETURNING updated`
 
 	err := s.db.QueryRowContext(ctx, query,
@@ -120,6 +130,8 @@ func (s *CategoriesStore) Update(ctx context.Context, category *models.Category)
 		category.ImageURL,
 		category.Icon,
 		category.ParentID,
+		category.SetupBufferHours,
+		category.CleaningBufferHours,
 		category.UpdatedBy,
 		category.ID,
 	).Scan(&category.Updated)
@@ -0,0 +1,365 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ChatbotStore struct {
+	db *sql.DB
+}
+
+const faqColumns = `t.id, t.keyword, t.question, t.answer, COALESCE(t.category, ''), COALESCE(t.priority, 0),
+	COALESCE(t.is_active, true), COALESCE(t.language, 'es'),
+	(SELECT COUNT(*) FROM chatbot_conversations c WHERE c.faq_id = t.id AND c.was_helpful),
+	(SELECT COUNT(*) FROM chatbot_conversations c WHERE c.faq_id = t.id AND NOT c.was_helpful),
+	t.created_at, t.updated_at`
+
+func scanFAQ(row interface{ Scan(...any) error }, f *models.FAQ) error {
+	return row.Scan(&f.ID, &f.Keyword, &f.Question, &f.Answer, &f.Category, &f.Priority,
+		&f.IsActive, &f.Language, &f.HelpfulCount, &f.UnhelpfulCount, &f.CreatedAt, &f.UpdatedAt)
+}
+
+// ListFAQs returns FAQs by priority, optionally only one category and only
+// active ones.
+func (s *ChatbotStore) ListFAQs(ctx context.Context, category string, activeOnly bool) ([]models.FAQ, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+faqColumns+` FROM whatsapp_faqs t
+		WHERE ($1 = '' OR t.category = $1) AND (NOT $2 OR COALESCE(t.is_active, true))
+		ORDER BY t.priority DESC, t.keyword`, category, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var faqs []models.FAQ
+	for rows.Next() {
+		var f models.FAQ
+		if err := scanFAQ(rows, &f); err != nil {
+			return nil, err
+		}
+		faqs = append(faqs, f)
+	}
+	return faqs, rows.Err()
+}
+
+func (s *ChatbotStore) GetFAQByID(ctx context.Context, id uuid.UUID) (*models.FAQ, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var f models.FAQ
+	err := scanFAQ(s.db.QueryRowContext(ctx, `SELECT `+faqColumns+` FROM whatsapp_faqs t WHERE t.id = $1`, id), &f)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (s *ChatbotStore) CreateFAQ(ctx context.Context, f *models.FAQ) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return s.db.QueryRowContext(ctx, `
+		INSERT INTO whatsapp_faqs (keyword, question, answer, category, priority, is_active, language)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`,
+		f.Keyword, f.Question, f.Answer, f.Category, f.Priority, f.IsActive, f.Language,
+	).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+}
+
+func (s *ChatbotStore) UpdateFAQ(ctx context.Context, f *models.FAQ) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE whatsapp_faqs
+		SET keyword = $1, question = $2, answer = $3, category = $4, priority = $5, is_active = $6, language = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING updated_at`,
+		f.Keyword, f.Question, f.Answer, f.Category, f.Priority, f.IsActive, f.Language, f.ID,
+	).Scan(&f.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *ChatbotStore) DeleteFAQ(ctx context.Context, id uuid.UUID) error {
+	return s.delete(ctx, `DELETE FROM whatsapp_faqs WHERE id = $1`, id)
+}
+
+const intentColumns = `t.id, t.name, t.description, t.phrases, t.response, t.action, t.priority, t.is_active,
+	(SELECT COUNT(*) FROM chatbot_conversations c WHERE c.intent_id = t.id AND c.was_helpful),
+	(SELECT COUNT(*) FROM chatbot_conversations c WHERE c.intent_id = t.id AND NOT c.was_helpful),
+	t.created_at, t.updated_at`
+
+func scanIntent(row interface{ Scan(...any) error }, in *models.ChatbotIntent) error {
+	return row.Scan(&in.ID, &in.Name, &in.Description, pq.Array(&in.Phrases), &in.Response, &in.Action, &in.Priority,
+		&in.IsActive, &in.HelpfulCount, &in.UnhelpfulCount, &in.CreatedAt, &in.UpdatedAt)
+}
+
+func (s *ChatbotStore) ListIntents(ctx context.Context, activeOnly bool) ([]models.ChatbotIntent, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+intentColumns+` FROM chatbot_intents t
+		WHERE NOT $1 OR t.is_active
+		ORDER BY t.priority DESC, t.name`, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var intents []models.ChatbotIntent
+	for rows.Next() {
+		var in models.ChatbotIntent
+		if err := scanIntent(rows, &in); err != nil {
+			return nil, err
+		}
+		intents = append(intents, in)
+	}
+	return intents, rows.Err()
+}
+
+func (s *ChatbotStore) GetIntentByID(ctx context.Context, id uuid.UUID) (*models.ChatbotIntent, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var in models.ChatbotIntent
+	err := scanIntent(s.db.QueryRowContext(ctx, `SELECT `+intentColumns+` FROM chatbot_intents t WHERE t.id = $1`, id), &in)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &in, nil
+}
+
+func (s *ChatbotStore) CreateIntent(ctx context.Context, in *models.ChatbotIntent) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO chatbot_intents (name, description, phrases, response, action, priority, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`,
+		in.Name, in.Description, pq.Array(in.Phrases), in.Response, in.Action, in.Priority, in.IsActive,
+	).Scan(&in.ID, &in.CreatedAt, &in.UpdatedAt)
+	return intentNameTaken(err)
+}
+
+func (s *ChatbotStore) UpdateIntent(ctx context.Context, in *models.ChatbotIntent) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE chatbot_intents
+		SET name = $1, description = $2, phrases = $3, response = $4, action = $5, priority = $6, is_active = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING updated_at`,
+		in.Name, in.Description, pq.Array(in.Phrases), in.Response, in.Action, in.Priority, in.IsActive, in.ID,
+	).Scan(&in.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return intentNameTaken(err)
+}
+
+// intentNameTaken reports a duplicate intent name as ErrConflict.
+func intentNameTaken(err error) error {
+	if err != nil && err.Error() == `pq: duplicate key value violates unique constraint "chatbot_intents_name_key"` {
+		return ErrConflict
+	}
+	return err
+}
+
+func (s *ChatbotStore) DeleteIntent(ctx context.Context, id uuid.UUID) error {
+	return s.delete(ctx, `DELETE FROM chatbot_intents WHERE id = $1`, id)
+}
+
+func (s *ChatbotStore) ListSynonyms(ctx context.Context) ([]models.ChatbotSynonym, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT term, canonical FROM chatbot_synonyms ORDER BY canonical, term`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var synonyms []models.ChatbotSynonym
+	for rows.Next() {
+		var syn models.ChatbotSynonym
+		if err := rows.Scan(&syn.Term, &syn.Canonical); err != nil {
+			return nil, err
+		}
+		synonyms = append(synonyms, syn)
+	}
+	return synonyms, rows.Err()
+}
+
+// UpsertSynonym adds a synonym or changes the canonical form of an existing
+// term.
+func (s *ChatbotStore) UpsertSynonym(ctx context.Context, syn *models.ChatbotSynonym) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO chatbot_synonyms (term, canonical) VALUES ($1, $2)
+		ON CONFLICT (term) DO UPDATE SET canonical = EXCLUDED.canonical`, syn.Term, syn.Canonical)
+	return err
+}
+
+func (s *ChatbotStore) DeleteSynonym(ctx context.Context, term string) error {
+	return s.delete(ctx, `DELETE FROM chatbot_synonyms WHERE term = $1`, term)
+}
+
+func (s *ChatbotStore) delete(ctx context.Context, query string, arg any) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, arg)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const chatbotSessionColumns = `id, session_key, COALESCE(client_phone, ''), COALESCE(client_name, ''), state,
+	COALESCE(pending_slot, ''), slots, lead_id, created_at, updated_at`
+
+func scanChatbotSession(row interface{ Scan(...any) error }, sess *models.ChatbotSession) error {
+	var slots []byte
+	if err := row.Scan(&sess.ID, &sess.SessionKey, &sess.ClientPhone, &sess.ClientName, &sess.State,
+		&sess.PendingSlot, &slots, &sess.LeadID, &sess.CreatedAt, &sess.UpdatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(slots, &sess.Slots)
+}
+
+// GetOrCreateSession loads the session with key, creating it if needed.
+// A phone or name sent with the message fills in what the session lacks.
+func (s *ChatbotStore) GetOrCreateSession(ctx context.Context, key, phone, name string) (*models.ChatbotSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var sess models.ChatbotSession
+	err := scanChatbotSession(s.db.QueryRowContext(ctx, `
+		INSERT INTO chatbot_sessions (session_key, client_phone, client_name)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))
+		ON CONFLICT (session_key) DO UPDATE SET
+			client_phone = COALESCE(chatbot_sessions.client_phone, EXCLUDED.client_phone),
+			client_name = COALESCE(chatbot_sessions.client_name, EXCLUDED.client_name)
+		RETURNING `+chatbotSessionColumns, key, phone, name), &sess)
+	if err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// SaveTurn stores the session state the turn left behind together with the
+// turn itself.
+func (s *ChatbotStore) SaveTurn(ctx context.Context, sess *models.ChatbotSession, turn *models.ChatbotTurn) error {
+	slots, err := json.Marshal(sess.Slots)
+	if err != nil {
+		return err
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE chatbot_sessions
+			SET client_phone = NULLIF($2, ''), client_name = NULLIF($3, ''), state = $4, pending_slot = NULLIF($5, ''),
+				slots = $6, lead_id = $7, updated_at = NOW()
+			WHERE id = $1`,
+			sess.ID, sess.ClientPhone, sess.ClientName, sess.State, sess.PendingSlot, string(slots), sess.LeadID,
+		); err != nil {
+			return err
+		}
+
+		turn.SessionKey = sess.SessionKey
+		turn.ClientPhone = sess.ClientPhone
+		turn.ClientName = sess.ClientName
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO chatbot_conversations (client_phone, client_name, message, response, faq_id, intent_id, session_id)
+			VALUES (COALESCE(NULLIF($1, ''), 'desconocido'), NULLIF($2, ''), $3, $4, $5, $6, $7)
+			RETURNING id, created_at`,
+			turn.ClientPhone, turn.ClientName, turn.Message, turn.Response, turn.FAQID, turn.IntentID, turn.SessionKey,
+		).Scan(&turn.ID, &turn.CreatedAt)
+	})
+}
+
+const chatbotTurnColumns = `id, COALESCE(session_id, ''), client_phone, COALESCE(client_name, ''), message, COALESCE(response, ''),
+	faq_id, intent_id, was_helpful, created_at`
+
+func scanChatbotTurn(row interface{ Scan(...any) error }, t *models.ChatbotTurn) error {
+	return row.Scan(&t.ID, &t.SessionKey, &t.ClientPhone, &t.ClientName, &t.Message, &t.Response,
+		&t.FAQID, &t.IntentID, &t.WasHelpful, &t.CreatedAt)
+}
+
+// ListTurns returns the latest turns, newest first, optionally of a single
+// session.
+func (s *ChatbotStore) ListTurns(ctx context.Context, sessionKey string, limit int) ([]models.ChatbotTurn, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+chatbotTurnColumns+` FROM chatbot_conversations
+		WHERE $1 = '' OR session_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, sessionKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var turns []models.ChatbotTurn
+	for rows.Next() {
+		var t models.ChatbotTurn
+		if err := scanChatbotTurn(rows, &t); err != nil {
+			return nil, err
+		}
+		turns = append(turns, t)
+	}
+	return turns, rows.Err()
+}
+
+// RecordFeedback stores whether the answer given in a turn was helpful.
+func (s *ChatbotStore) RecordFeedback(ctx context.Context, turnID uuid.UUID, helpful bool) (*models.ChatbotTurn, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var t models.ChatbotTurn
+	err := scanChatbotTurn(s.db.QueryRowContext(ctx, `
+		UPDATE chatbot_conversations SET was_helpful = $2, feedback_at = NOW()
+		WHERE id = $1
+		RETURNING `+chatbotTurnColumns, turnID, helpful), &t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -3,138 +3,80 @@ package store
 import (
 	"context"
 	"database/sql"
-	"strings"
 
 	"Backend/internal/store/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type DeliveryZonesStore struct {
 	db *sql.DB
 }
 
+const deliveryZoneColumns = `id, name, base_radius_km, max_radius_km, travel_fee, is_active, created_at, updated_at`
+
+type zoneScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDeliveryZone(row zoneScanner, zone *models.DeliveryZone) error {
+	return row.Scan(
+		&zone.ID,
+		&zone.Name,
+		&zone.BaseRadiusKm,
+		&zone.MaxRadiusKm,
+		&zone.TravelFee,
+		&zone.IsActive,
+		&zone.CreatedAt,
+		&zone.UpdatedAt,
+	)
+}
+
+// GetAll returns every zone, active or not, nearest first.
 func (s *DeliveryZonesStore) GetAll(ctx context.Context) ([]models.DeliveryZone, error) {
-	query := `
-		SELECT id, name, base_radius_km, max_radius_km, travel_fee, is_active, created_at
-		FROM delivery_zones
-		WHERE is_active = true
-		ORDER BY base_radius_km ASC
-	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return getDeliveryZones(ctx, s.db)
+}
 
-	rows, err := s.db.QueryContext(ctx, query)
+type zoneQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func getDeliveryZones(ctx context.Context, q zoneQuerier) ([]models.DeliveryZone, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+deliveryZoneColumns+`
+		FROM delivery_zones
+		ORDER BY max_radius_km ASC, name ASC
+	`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var zones []models.DeliveryZone
+	zones := []models.DeliveryZone{}
 	for rows.Next() {
 		var zone models.DeliveryZone
-		err := rows.Scan(
-			&zone.ID,
-			&zone.Name,
-			&zone.BaseRadiusKm,
-			&zone.MaxRadiusKm,
-			&zone.TravelFee,
-			&zone.IsActive,
-			&zone.CreatedAt,
-		)
-		if err != nil {
+		if err := scanDeliveryZone(rows, &zone); err != nil {
 			return nil, err
 		}
 		zones = append(zones, zone)
 	}
-
-	if zones == nil {
-		zones = []models.DeliveryZone{}
-	}
-
-	return zones, nil
-}
-
-func (s *DeliveryZonesStore) CalculateFee(ctx context.Context, address string) (*models.DeliveryFeeResponse, error) {
-	address = strings.ToLower(address)
-
-	// Keywords that indicate remote zones (outside San Cristóbal province)
-	remoteKeywords := []string{
-		"santo domingo", "santiago", "la romana", "la altagracia", "punta cana",
-		"bayahibe", "boca chica", "san pedro de macoris", "samana", "las galeras",
-		"puerto plata", "sosua", "cabarete", "rio san juan", "miches", "nagua",
-		"higuey", "el seibo", "san jose de cofrentes", "hato mayor", "sabana de la mar",
-		"dominican republic", "rep. dom.", "rd", "+1", "phone:", "whatsapp",
-	}
-
-	// Keywords that indicate San Cristóbal area (free/extended delivery)
-	localKeywords := []string{
-		"san cristobal", "san Cristóbal", "santa cruz", "santa Cruz",
-		" Los Ranchos", "ranchitos", "La Valentina", "Hatillo",
-		"Berjon", "Mendoza", "Pizarro", "San Juan", "Macoris",
-		"km 27", "km 30", "carretera san cristobal", "av. libardo",
-	}
-
-	// Check for remote keywords first
-	isRemote := false
-	for _, keyword := range remoteKeywords {
-		if strings.Contains(address, keyword) {
-			isRemote = true
-			break
-		}
-	}
-
-	// Check for local keywords (they override remote detection)
-	isLocal := false
-	for _, keyword := range localKeywords {
-		if strings.Contains(address, keyword) {
-			isLocal = true
-			break
-		}
-	}
-
-	var response models.DeliveryFeeResponse
-	if isLocal {
-		// San Cristóbal Centro - free or low cost delivery
-		response = models.DeliveryFeeResponse{
-			Fee:     0,
-			Zone:    "San Cristóbal Centro",
-			Message: "Delivery gratuito en San Cristóbal",
-		}
-	} else if isRemote {
-		// Remote zone - high fee
-		response = models.DeliveryFeeResponse{
-			Fee:     3500,
-			Zone:    "Zona Remota",
-			Message: "Tu dirección está en zona remota. El equipo de RosaFiesta coordinará contigo el envío.",
-		}
-	} else {
-		// Default - extended zone
-		response = models.DeliveryFeeResponse{
-			Fee:     1500,
-			Zone:    "San Cristóbal Extendido",
-			Message: "Delivery dentro de la provincia de San Cristóbal",
-		}
-	}
-
-	return &response, nil
+	return zones, rows.Err()
 }
 
 func (s *DeliveryZonesStore) GetByID(ctx context.Context, id uuid.UUID) (*models.DeliveryZone, error) {
-	query := `
-		SELECT id, name, base_radius_km, max_radius_km, travel_fee, is_active, created_at
-		FROM delivery_zones
-		WHERE id = $1
-	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
 	var zone models.DeliveryZone
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&zone.ID,
-		&zone.Name,
-		&zone.BaseRadiusKm,
-		&zone.MaxRadiusKm,
-		&zone.TravelFee,
-		&zone.IsActive,
-		&zone.CreatedAt,
-	)
+	err := scanDeliveryZone(s.db.QueryRowContext(ctx, `
+		SELECT `+deliveryZoneColumns+`
+		FROM delivery_zones
+		WHERE id = $1
+	`, id), &zone)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -143,4 +85,64 @@ func (s *DeliveryZonesStore) GetByID(ctx context.Context, id uuid.UUID) (*models
 	}
 
 	return &zone, nil
-}
\ No newline at end of file
+}
+
+// ReplaceAll makes the zones exactly the given list: listed zones with an ID
+// are updated, the rest are created, and zones left out are deleted. An ID
+// that doesn't exist is ErrNotFound and nothing changes. Without is_active,
+// new zones are active and existing ones keep their state.
+func (s *DeliveryZonesStore) ReplaceAll(ctx context.Context, zones []models.DeliveryZonePayload) ([]models.DeliveryZone, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var result []models.DeliveryZone
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		keep := make([]uuid.UUID, 0, len(zones))
+		for _, z := range zones {
+			if z.ID == nil {
+				active := z.IsActive == nil || *z.IsActive
+				var id uuid.UUID
+				err := tx.QueryRowContext(ctx, `
+					INSERT INTO delivery_zones (name, base_radius_km, max_radius_km, travel_fee, is_active)
+					VALUES ($1, $2, $3, $4, $5)
+					RETURNING id`,
+					z.Name, z.BaseRadiusKm, z.MaxRadiusKm, z.TravelFee, active,
+				).Scan(&id)
+				if err != nil {
+					return err
+				}
+				keep = append(keep, id)
+				continue
+			}
+
+			res, err := tx.ExecContext(ctx, `
+				UPDATE delivery_zones
+				SET name = $1, base_radius_km = $2, max_radius_km = $3, travel_fee = $4,
+				    is_active = COALESCE($5, is_active), updated_at = NOW()
+				WHERE id = $6`,
+				z.Name, z.BaseRadiusKm, z.MaxRadiusKm, z.TravelFee, z.IsActive, *z.ID,
+			)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err != nil {
+				return err
+			} else if n == 0 {
+				return ErrNotFound
+			}
+			keep = append(keep, *z.ID)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM delivery_zones WHERE NOT (id = ANY($1))`, pq.Array(keep)); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = getDeliveryZones(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
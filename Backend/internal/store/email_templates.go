@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"Backend/internal/store/models"
+)
+
+type EmailTemplatesStore struct {
+	db *sql.DB
+}
+
+const emailTemplateColumns = `id, key, version, subject, body, active, created_by, created_at`
+
+func scanEmailTemplate(row interface{ Scan(...any) error }, t *models.EmailTemplate) error {
+	return row.Scan(&t.ID, &t.Key, &t.Version, &t.Subject, &t.Body, &t.Active, &t.CreatedBy, &t.CreatedAt)
+}
+
+// ListActive returns the active version of every template that has been
+// edited at least once.
+func (s *EmailTemplatesStore) ListActive(ctx context.Context) ([]models.EmailTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+emailTemplateColumns+` FROM email_templates WHERE active ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.EmailTemplate
+	for rows.Next() {
+		var t models.EmailTemplate
+		if err := scanEmailTemplate(rows, &t); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// GetActive returns the active version of a template, or ErrNotFound when
+// it was never edited and the embedded file applies.
+func (s *EmailTemplatesStore) GetActive(ctx context.Context, key string) (*models.EmailTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var t models.EmailTemplate
+	row := s.db.QueryRowContext(ctx, `SELECT `+emailTemplateColumns+` FROM email_templates WHERE key = $1 AND active`, key)
+	if err := scanEmailTemplate(row, &t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListVersions returns every saved version of a template, newest first.
+func (s *EmailTemplatesStore) ListVersions(ctx context.Context, key string) ([]models.EmailTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+emailTemplateColumns+` FROM email_templates WHERE key = $1 ORDER BY version DESC`, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.EmailTemplate
+	for rows.Next() {
+		var t models.EmailTemplate
+		if err := scanEmailTemplate(rows, &t); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// CreateVersion saves t as the next version of its template and makes it the
+// active one. Version, Active, ID and CreatedAt are filled in.
+func (s *EmailTemplatesStore) CreateVersion(ctx context.Context, t *models.EmailTemplate) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		// Serialize concurrent edits of the same template so two saves
+		// cannot pick the same version number.
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('email_templates:' || $1))`, t.Key); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COALESCE(MAX(version), 0) + 1 FROM email_templates WHERE key = $1`, t.Key,
+		).Scan(&t.Version); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE email_templates SET active = FALSE WHERE key = $1 AND active`, t.Key); err != nil {
+			return err
+		}
+
+		t.Active = true
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO email_templates (key, version, subject, body, active, created_by)
+			VALUES ($1, $2, $3, $4, TRUE, $5)
+			RETURNING id, created_at`,
+			t.Key, t.Version, t.Subject, t.Body, t.CreatedBy,
+		).Scan(&t.ID, &t.CreatedAt)
+	})
+}
+
+// Activate makes an earlier version the active one, for rolling back an
+// edit. It returns ErrNotFound when the version does not exist.
+func (s *EmailTemplatesStore) Activate(ctx context.Context, key string, version int) (*models.EmailTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var t models.EmailTemplate
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('email_templates:' || $1))`, key); err != nil {
+			return err
+		}
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM email_templates WHERE key = $1 AND version = $2)`, key, version,
+		).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE email_templates SET active = FALSE WHERE key = $1 AND active`, key); err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx, `
+			UPDATE email_templates SET active = TRUE
+			WHERE key = $1 AND version = $2
+			RETURNING `+emailTemplateColumns, key, version)
+		return scanEmailTemplate(row, &t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+type EventReturnsStore struct {
+	db *sql.DB
+}
+
+// Get returns the event's recorded return inspection, or ErrNotFound if
+// its items haven't been inspected yet.
+func (s *EventReturnsStore) Get(ctx context.Context, eventID uuid.UUID) (*models.EventReturn, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	ret := models.EventReturn{Inspected: true}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, event_id, inspected_by, notes, damage_charge, damage_charge_id, created_at
+		FROM event_returns
+		WHERE event_id = $1`, eventID,
+	).Scan(&ret.ID, &ret.EventID, &ret.InspectedBy, &ret.Notes, &ret.DamageCharge, &ret.DamageChargeID, &ret.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT l.id, l.event_item_id, l.article_id, l.variant_id, l.name, l.quantity, l.damaged, l.lost,
+		       COALESCE(v.replacement_cost, 0), l.charge, l.notes, l.maintenance_log_id
+		FROM event_return_lines l
+		LEFT JOIN article_variants v ON v.id = l.variant_id
+		WHERE l.return_id = $1
+		ORDER BY l.name`, ret.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret.Lines = []models.EventReturnLine{}
+	for rows.Next() {
+		var l models.EventReturnLine
+		if err := rows.Scan(
+			&l.ID, &l.EventItemID, &l.ArticleID, &l.VariantID, &l.Name, &l.Quantity, &l.Damaged, &l.Lost,
+			&l.ReplacementCost, &l.Charge, &l.Notes, &l.MaintenanceLogID,
+		); err != nil {
+			return nil, err
+		}
+		ret.Lines = append(ret.Lines, l)
+	}
+	return &ret, rows.Err()
+}
+
+// Create records the inspection and acts on it in one transaction. Damaged
+// units get a repair log and are taken out of stock until the repair is
+// completed; lost units are taken out of stock for good. Lines without a
+// variant use the article's first active one, as reservations do. A damage
+// charge is billed to the client as an extra installment, and the event's
+// reservations are released since its items are back. It is ErrConflict
+// if the event's items were already inspected.
+func (s *EventReturnsStore) Create(ctx context.Context, ret *models.EventReturn) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var eventName string
+		err := tx.QueryRowContext(ctx, `SELECT name FROM events WHERE id = $1 FOR UPDATE`, ret.EventID).Scan(&eventName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		var exists bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM event_returns WHERE event_id = $1)`, ret.EventID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrConflict
+		}
+
+		if ret.DamageCharge > 0 {
+			var chargeID uuid.UUID
+			err := tx.QueryRowContext(ctx, `
+				INSERT INTO installment_payments (event_id, purpose, label, amount, due_date, payment_status)
+				VALUES ($1, $2, $3, $4, $5, 'pending')
+				RETURNING id`,
+				ret.EventID, models.InstallmentPurposeDamage, models.DamageChargeLabel, ret.DamageCharge,
+				time.Now().Add(models.DamageChargeDue),
+			).Scan(&chargeID)
+			if err != nil {
+				return err
+			}
+			ret.DamageChargeID = &chargeID
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO event_returns (event_id, inspected_by, notes, damage_charge, damage_charge_id)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at`,
+			ret.EventID, ret.InspectedBy, ret.Notes, ret.DamageCharge, ret.DamageChargeID,
+		).Scan(&ret.ID, &ret.CreatedAt)
+		if err != nil {
+			return err
+		}
+		ret.Inspected = true
+
+		for i := range ret.Lines {
+			if err := createReturnLineTx(ctx, tx, ret, &ret.Lines[i], eventName); err != nil {
+				return err
+			}
+		}
+		return releaseEventTx(ctx, tx, ret.EventID)
+	})
+}
+
+func createReturnLineTx(ctx context.Context, tx *sql.Tx, ret *models.EventReturn, line *models.EventReturnLine, eventName string) error {
+	if line.VariantID == nil {
+		var variantID uuid.UUID
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM article_variants
+			WHERE article_id = $1 AND is_active = TRUE
+			ORDER BY created_at ASC
+			LIMIT 1`, line.ArticleID).Scan(&variantID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil {
+			line.VariantID = &variantID
+		}
+	}
+
+	// Without a variant there is no stock to take units out of.
+	held := 0
+	if line.VariantID != nil {
+		held = line.Damaged
+	}
+	if line.Damaged > 0 {
+		description := "Daño en devolución del evento " + eventName
+		if line.Notes != "" {
+			description += ": " + line.Notes
+		}
+		var logID uuid.UUID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO article_maintenance_logs (article_id, variant_id, event_id, maintenance_type, status,
+			                                      description, created_by, stock_held)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`,
+			line.ArticleID, line.VariantID, ret.EventID, models.MaintenanceTypeRepair, models.MaintenanceStatusScheduled,
+			description, ret.InspectedBy, held,
+		).Scan(&logID)
+		if err != nil {
+			return err
+		}
+		line.MaintenanceLogID = &logID
+	}
+
+	if out := line.Damaged + line.Lost; out > 0 && line.VariantID != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE article_variants SET stock = COALESCE(stock, 0) - $1 WHERE id = $2`, out, *line.VariantID); err != nil {
+			return err
+		}
+	}
+
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO event_return_lines (return_id, event_item_id, article_id, variant_id, name, quantity,
+		                                damaged, lost, charge, notes, maintenance_log_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		ret.ID, line.EventItemID, line.ArticleID, line.VariantID, line.Name, line.Quantity,
+		line.Damaged, line.Lost, line.Charge, line.Notes, line.MaintenanceLogID,
+	).Scan(&line.ID)
+}
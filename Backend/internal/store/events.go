@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"Backend/internal/store/models"
@@ -21,8 +22,8 @@ func (s *EventStore) Create(ctx context.Context, event *models.Event) error {
 	}
 
 	query := `
-		INSERT INTO events (user_id, name, date, location, guest_count, budget, status, additional_costs, admin_notes, payment_status, payment_method, paid_at, deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		INSERT INTO events (user_id, name, date, location, guest_count, budget, status, additional_costs, admin_notes, payment_status, payment_method, paid_at, deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote, event_type_id, latitude, longitude, delivery_fee, pickup_at, return_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -47,6 +48,12 @@ func (s *EventStore) Create(ctx context.Context, event *models.Event) error {
 		event.RemainingAmount,
 		event.InstallmentDueDate,
 		event.TotalQuote,
+		event.EventTypeID,
+		event.Latitude,
+		event.Longitude,
+		event.DeliveryFee,
+		event.PickupAt,
+		event.ReturnAt,
 	).Scan(
 		&event.ID,
 		&event.CreatedAt,
@@ -76,7 +83,7 @@ func (s *EventStore) GetOrCreateDraft(ctx context.Context, userID uuid.UUID) (*m
 		       additional_costs, admin_notes, payment_status, payment_method,
 		       paid_at,
 		       quote_approved_at, quote_approved_by, quote_rejected_at, quote_rejected_by,
-		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote,
+		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote, event_type_id, latitude, longitude, delivery_fee, pickup_at, return_at,
 		       created_at, updated_at
 		FROM events
 		WHERE user_id = $1 AND status = 'draft'
@@ -90,7 +97,7 @@ func (s *EventStore) GetOrCreateDraft(ctx context.Context, userID uuid.UUID) (*m
 			&ev.AdditionalCosts, &ev.AdminNotes,
 			&ev.PaymentStatus, &ev.PaymentMethod, &ev.PaidAt,
 			&ev.QuoteApprovedAt, &ev.QuoteApprovedBy, &ev.QuoteRejectedAt, &ev.QuoteRejectedBy,
-			&ev.DepositPaid, &ev.DepositAmount, &ev.DepositPaidAt, &ev.RemainingAmount, &ev.InstallmentDueDate, &ev.TotalQuote,
+			&ev.DepositPaid, &ev.DepositAmount, &ev.DepositPaidAt, &ev.RemainingAmount, &ev.InstallmentDueDate, &ev.TotalQuote, &ev.EventTypeID, &ev.Latitude, &ev.Longitude, &ev.DeliveryFee, &ev.PickupAt, &ev.ReturnAt,
 			&ev.CreatedAt, &ev.UpdatedAt,
 		)
 	}
@@ -129,7 +136,7 @@ func (s *EventStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Event,
 		SELECT id, user_id, name, date, location, guest_count, budget, status, additional_costs, admin_notes,
 		       payment_status, payment_method, paid_at,
 		       quote_approved_at, quote_approved_by, quote_rejected_at, quote_rejected_by,
-		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote,
+		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote, event_type_id, latitude, longitude, delivery_fee, pickup_at, return_at,
 		       created_at, updated_at
 		FROM events
 		WHERE id = $1
@@ -160,6 +167,12 @@ func (s *EventStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Event,
 		&event.RemainingAmount,
 		&event.InstallmentDueDate,
 		&event.TotalQuote,
+		&event.EventTypeID,
+		&event.Latitude,
+		&event.Longitude,
+		&event.DeliveryFee,
+		&event.PickupAt,
+		&event.ReturnAt,
 		&event.CreatedAt,
 		&event.UpdatedAt,
 	)
@@ -178,7 +191,7 @@ func (s *EventStore) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model
 		SELECT id, user_id, name, date, location, guest_count, budget, status, additional_costs, admin_notes,
 		       payment_status, payment_method, paid_at,
 		       quote_approved_at, quote_approved_by, quote_rejected_at, quote_rejected_by,
-		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote,
+		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote, event_type_id, latitude, longitude, delivery_fee, pickup_at, return_at,
 		       created_at, updated_at
 		FROM events
 		WHERE user_id = $1
@@ -218,6 +231,12 @@ func (s *EventStore) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model
 			&event.RemainingAmount,
 			&event.InstallmentDueDate,
 			&event.TotalQuote,
+			&event.EventTypeID,
+			&event.Latitude,
+			&event.Longitude,
+			&event.DeliveryFee,
+			&event.PickupAt,
+			&event.ReturnAt,
 			&event.CreatedAt,
 			&event.UpdatedAt,
 		)
@@ -239,7 +258,7 @@ func (s *EventStore) GetPendingByUserID(ctx context.Context, userID uuid.UUID) (
 		SELECT id, user_id, name, date, location, guest_count, budget, status, additional_costs, admin_notes,
 		       payment_status, payment_method, paid_at,
 		       quote_approved_at, quote_approved_by, quote_rejected_at, quote_rejected_by,
-		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote,
+		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote, event_type_id, latitude, longitude, delivery_fee, pickup_at, return_at,
 		       created_at, updated_at
 		FROM events
 		WHERE user_id = $1 AND status NOT IN ('completed', 'cancelled')
@@ -279,6 +298,12 @@ func (s *EventStore) GetPendingByUserID(ctx context.Context, userID uuid.UUID) (
 			&event.RemainingAmount,
 			&event.InstallmentDueDate,
 			&event.TotalQuote,
+			&event.EventTypeID,
+			&event.Latitude,
+			&event.Longitude,
+			&event.DeliveryFee,
+			&event.PickupAt,
+			&event.ReturnAt,
 			&event.CreatedAt,
 			&event.UpdatedAt,
 		)
@@ -296,7 +321,7 @@ func (s *EventStore) GetAll(ctx context.Context) ([]models.Event, error) {
 		SELECT id, user_id, name, date, location, guest_count, budget, status, additional_costs, admin_notes,
 		       payment_status, payment_method, paid_at,
 		       quote_approved_at, quote_approved_by, quote_rejected_at, quote_rejected_by,
-		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote,
+		       deposit_paid, deposit_amount, deposit_paid_at, remaining_amount, installment_due_date, total_quote, event_type_id, latitude, longitude, delivery_fee, pickup_at, return_at,
 		       created_at, updated_at
 		FROM events
 		ORDER BY date ASC
@@ -335,6 +360,12 @@ func (s *EventStore) GetAll(ctx context.Context) ([]models.Event, error) {
 			&event.RemainingAmount,
 			&event.InstallmentDueDate,
 			&event.TotalQuote,
+			&event.EventTypeID,
+			&event.Latitude,
+			&event.Longitude,
+			&event.DeliveryFee,
+			&event.PickupAt,
+			&event.ReturnAt,
 			&event.CreatedAt,
 			&event.UpdatedAt,
 		)
@@ -347,67 +378,280 @@ func (s *EventStore) GetAll(ctx context.Context) ([]models.Event, error) {
 	return events, nil
 }
 
+// GetForTriggers returns the events notification triggers may fire for:
+// booked and completed events dated between from and until, adjusted
+// events with their latest quote adjustment, and open events with an
+// installment owed by until.
+func (s *EventStore) GetForTriggers(ctx context.Context, from, until time.Time) ([]models.TriggerEvent, error) {
+	query := `
+		SELECT e.id, e.user_id, e.name, e.date, e.location, e.guest_count, e.budget, e.status, e.additional_costs, e.admin_notes,
+		       e.payment_status, e.payment_method, e.paid_at,
+		       e.quote_approved_at, e.quote_approved_by, e.quote_rejected_at, e.quote_rejected_by,
+		       e.deposit_paid, e.deposit_amount, e.deposit_paid_at, e.remaining_amount, e.installment_due_date, e.total_quote, e.event_type_id, e.latitude, e.longitude, e.delivery_fee, e.pickup_at, e.return_at,
+		       e.created_at, e.updated_at, adj.id
+		FROM events e
+		LEFT JOIN LATERAL (
+			SELECT al.id
+			FROM audit_logs al
+			WHERE al.event_id = e.id AND al.action = $3
+			ORDER BY al.created_at DESC
+			LIMIT 1
+		) adj ON e.status = 'adjusted'
+		WHERE (e.status IN ('confirmed', 'paid', 'completed') AND e.date BETWEEN $1 AND $2)
+		   OR e.status = 'adjusted'
+		   OR (e.status NOT IN ('completed', 'cancelled', 'paid') AND EXISTS (
+		          SELECT 1 FROM installment_payments ip
+		          WHERE ip.event_id = e.id AND ip.payment_status = 'pending' AND ip.due_date <= $2
+		      ))
+		ORDER BY e.date ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, from, until, models.AuditActionEventAdjust)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.TriggerEvent
+	for rows.Next() {
+		var event models.TriggerEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.UserID,
+			&event.Name,
+			&event.Date,
+			&event.Location,
+			&event.GuestCount,
+			&event.Budget,
+			&event.Status,
+			&event.AdditionalCosts,
+			&event.AdminNotes,
+			&event.PaymentStatus,
+			&event.PaymentMethod,
+			&event.PaidAt,
+			&event.QuoteApprovedAt,
+			&event.QuoteApprovedBy,
+			&event.QuoteRejectedAt,
+			&event.QuoteRejectedBy,
+			&event.DepositPaid,
+			&event.DepositAmount,
+			&event.DepositPaidAt,
+			&event.RemainingAmount,
+			&event.InstallmentDueDate,
+			&event.TotalQuote,
+			&event.EventTypeID,
+			&event.Latitude,
+			&event.Longitude,
+			&event.DeliveryFee,
+			&event.PickupAt,
+			&event.ReturnAt,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+			&event.LastAdjustmentID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// InvalidTransitionError is returned when an event, or another entity with
+// a lifecycle, is asked to move to a status the lifecycle does not allow
+// from its current one. It matches ErrInvalidTransition through errors.Is.
+type InvalidTransitionError struct {
+	Entity string // "event" when empty
+	From   string
+	To     string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	entity := e.Entity
+	if entity == "" {
+		entity = "event"
+	}
+	return fmt.Sprintf("%s: cannot move %s from %q to %q", ErrInvalidTransition.Error(), entity, e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// lockEventStatusTx locks the event row and checks that it may move to the
+// given status, returning the status it had. An event already in that
+// status can't move to it.
+func lockEventStatusTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID, to string) (string, error) {
+	var from string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM events WHERE id = $1 FOR UPDATE`, eventID).Scan(&from)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if from == to || !models.CanTransitionEvent(from, to) {
+		return from, &InvalidTransitionError{From: from, To: to}
+	}
+	return from, nil
+}
+
+// lockQuoteTx is lockEventStatusTx for the client's answer to a quote: only
+// a quote an admin adjusted can be approved or rejected.
+func lockQuoteTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID, to string) (string, error) {
+	from, err := lockEventStatusTx(ctx, tx, eventID, to)
+	if err != nil {
+		return from, err
+	}
+	if from != models.EventStatusAdjusted {
+		return from, &InvalidTransitionError{From: from, To: to}
+	}
+	return from, nil
+}
+
+// Update saves the event. The status change, if any, must be allowed by the
+// event lifecycle; the check runs under the row lock so two concurrent
+// updates cannot both move the event. A change of status is recorded in the
+// audit log, with event.StatusChange saying who made it and why; so is a
+// quote adjustment, even of an event that was already adjusted. When the
+// status, the date or the rental window changes, the event's inventory
+// reservations are brought in line in the same transaction.
 func (s *EventStore) Update(ctx context.Context, event *models.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
 	query := `
 		UPDATE events
 		SET name = $1, date = $2, location = $3, guest_count = $4, budget = $5, status = $6,
 		    additional_costs = $7, admin_notes = $8, payment_status = $9, payment_method = $10, paid_at = $11,
 		    deposit_paid = $12, deposit_amount = $13, deposit_paid_at = $14, remaining_amount = $15, installment_due_date = $16, total_quote = $17,
-		    updated_at = NOW()
-		WHERE id = $18
+		    event_type_id = $18, latitude = $19, longitude = $20, delivery_fee = $21,
+		    pickup_at = $22, return_at = $23, updated_at = NOW()
+		WHERE id = $24
 		RETURNING updated_at
 	`
 
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		event.Name,
-		event.Date,
-		event.Location,
-		event.GuestCount,
-		event.Budget,
-		event.Status,
-		event.AdditionalCosts,
-		event.AdminNotes,
-		event.PaymentStatus,
-		event.PaymentMethod,
-		event.PaidAt,
-		event.DepositPaid,
-		event.DepositAmount,
-		event.DepositPaidAt,
-		event.RemainingAmount,
-		event.InstallmentDueDate,
-		event.TotalQuote,
-		event.ID,
-	).Scan(&event.UpdatedAt)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNotFound
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var prevStatus string
+		var prevDate, prevPickupAt, prevReturnAt *time.Time
+		err := tx.QueryRowContext(ctx, `SELECT status, date, pickup_at, return_at FROM events WHERE id = $1 FOR UPDATE`, event.ID).
+			Scan(&prevStatus, &prevDate, &prevPickupAt, &prevReturnAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
 		}
-		return err
+		if !models.CanTransitionEvent(prevStatus, event.Status) {
+			return &InvalidTransitionError{From: prevStatus, To: event.Status}
+		}
+
+		err = tx.QueryRowContext(
+			ctx,
+			query,
+			event.Name,
+			event.Date,
+			event.Location,
+			event.GuestCount,
+			event.Budget,
+			event.Status,
+			event.AdditionalCosts,
+			event.AdminNotes,
+			event.PaymentStatus,
+			event.PaymentMethod,
+			event.PaidAt,
+			event.DepositPaid,
+			event.DepositAmount,
+			event.DepositPaidAt,
+			event.RemainingAmount,
+			event.InstallmentDueDate,
+			event.TotalQuote,
+			event.EventTypeID,
+			event.Latitude,
+			event.Longitude,
+			event.DeliveryFee,
+			event.PickupAt,
+			event.ReturnAt,
+			event.ID,
+		).Scan(&event.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		readjusted := event.StatusChange != nil && event.StatusChange.Action == models.AuditActionEventAdjust
+		if prevStatus != event.Status || readjusted {
+			if err := logStatusChangeTx(ctx, tx, event.ID, prevStatus, event.Status, event.StatusChange); err != nil {
+				return err
+			}
+		}
+
+		if prevStatus == event.Status && sameDate(prevDate, event.Date) &&
+			sameDate(prevPickupAt, event.PickupAt) && sameDate(prevReturnAt, event.ReturnAt) {
+			return nil
+		}
+		return syncEventReservationsTx(ctx, tx, event.ID)
+	})
+}
+
+// logStatusChangeTx records in the audit log that the event moved from one
+// status to another, in the transaction that moved it. Without a change the
+// move is recorded with no actor or reason.
+func logStatusChangeTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID, from, to string, change *models.EventStatusChange) error {
+	action := models.AuditActionEventStatus
+	var actorID *uuid.UUID
+	var reason *string
+	if change != nil {
+		if change.Action != "" {
+			action = change.Action
+		}
+		if change.ActorID != uuid.Nil {
+			actorID = &change.ActorID
+		}
+		reason = change.Reason
 	}
 
-	return nil
+	query := `
+		INSERT INTO audit_logs (user_id, event_id, action, entity_type, entity_id, old_value, new_value, reason)
+		VALUES ($1, $2, $3, 'event', $2, $4, $5, $6)`
+	_, err := tx.ExecContext(ctx, query, actorID, eventID, action, from, to, reason)
+	return err
 }
 
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// Delete removes the event together with its inventory reservations, which
+// frees whatever stock it was holding.
 func (s *EventStore) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM events WHERE id = $1`
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM inventory_availability WHERE event_id = $1`, id); err != nil {
+			return err
+		}
 
-	res, err := s.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return err
-	}
+		res, err := tx.ExecContext(ctx, `DELETE FROM events WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
 
-	if rows == 0 {
-		return ErrNotFound
-	}
+		if rows == 0 {
+			return ErrNotFound
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // AddItem upserts an event item. If a line for the same (event, article,
@@ -417,6 +661,52 @@ func (s *EventStore) AddItem(ctx context.Context, item *models.EventItem) error
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return addItemTx(ctx, tx, item)
+	})
+}
+
+// AddItemWithReservation behaves like AddItem but also re-syncs the event's
+// reservations for its rental window inside the same transaction, so a
+// line added to a requested or confirmed event is never stored without the
+// units backing it. Dated drafts hold nothing until they are requested, but
+// the line is still refused if the stock can't cover it. When the
+// item has no variant the article's first active variant is used, since
+// reservations are tracked per variant.
+func (s *EventStore) AddItemWithReservation(ctx context.Context, item *models.EventItem) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if item.VariantID == nil {
+			const variantQuery = `
+				SELECT id FROM article_variants
+				WHERE article_id = $1 AND is_active = TRUE
+				ORDER BY created_at ASC
+				LIMIT 1
+			`
+			var variantID uuid.UUID
+			if err := tx.QueryRowContext(ctx, variantQuery, item.ArticleID).Scan(&variantID); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrNotFound
+				}
+				return err
+			}
+			item.VariantID = &variantID
+		}
+
+		if err := addItemTx(ctx, tx, item); err != nil {
+			return err
+		}
+
+		if err := syncEventReservationsTx(ctx, tx, item.EventID); err != nil {
+			return err
+		}
+		return checkEventStockTx(ctx, tx, item.EventID)
+	})
+}
+
+func addItemTx(ctx context.Context, tx *sql.Tx, item *models.EventItem) error {
 	// Try to find an existing line for this (event, article, variant).
 	const checkQuery = `
 		SELECT id, quantity FROM event_items
@@ -426,7 +716,7 @@ func (s *EventStore) AddItem(ctx context.Context, item *models.EventItem) error
 	`
 	var existingID uuid.UUID
 	var existingQty int
-	err := s.db.QueryRowContext(ctx, checkQuery, item.EventID, item.ArticleID, item.VariantID).
+	err := tx.QueryRowContext(ctx, checkQuery, item.EventID, item.ArticleID, item.VariantID).
 		Scan(&existingID, &existingQty)
 
 	if err == nil {
@@ -437,7 +727,7 @@ func (s *EventStore) AddItem(ctx context.Context, item *models.EventItem) error
 			WHERE id = $2
 			RETURNING id, quantity, created_at, updated_at
 		`
-		return s.db.QueryRowContext(ctx, updateQuery, item.Quantity, existingID).
+		return tx.QueryRowContext(ctx, updateQuery, item.Quantity, existingID).
 			Scan(&item.ID, &item.Quantity, &item.CreatedAt, &item.UpdatedAt)
 	}
 	if !errors.Is(err, sql.ErrNoRows) {
@@ -450,7 +740,7 @@ func (s *EventStore) AddItem(ctx context.Context, item *models.EventItem) error
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at, updated_at
 	`
-	return s.db.QueryRowContext(
+	return tx.QueryRowContext(
 		ctx,
 		insertQuery,
 		item.EventID,
@@ -461,48 +751,56 @@ func (s *EventStore) AddItem(ctx context.Context, item *models.EventItem) error
 	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
 }
 
-// UpdateItemQuantity sets the absolute quantity for a given event item.
+// UpdateItemQuantity sets the absolute quantity for a given event item and
+// adjusts the event's reservations to match. Like AddItemWithReservation it
+// refuses quantities the stock can't cover, drafts included.
 func (s *EventStore) UpdateItemQuantity(ctx context.Context, itemID uuid.UUID, quantity int) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	const query = `
-		UPDATE event_items
-		SET quantity = $1, updated_at = NOW()
-		WHERE id = $2
-	`
-	res, err := s.db.ExecContext(ctx, query, quantity, itemID)
-	if err != nil {
-		return err
-	}
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rows == 0 {
-		return ErrNotFound
-	}
-	return nil
-}
-
-func (s *EventStore) RemoveItem(ctx context.Context, eventID, startID uuid.UUID) error {
-	query := `DELETE FROM event_items WHERE id = $1 AND event_id = $2`
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		const query = `
+			UPDATE event_items
+			SET quantity = $1, updated_at = NOW()
+			WHERE id = $2
+			RETURNING event_id
+		`
+		var eventID uuid.UUID
+		if err := tx.QueryRowContext(ctx, query, quantity, itemID).Scan(&eventID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
 
-	res, err := s.db.ExecContext(ctx, query, startID, eventID)
-	if err != nil {
-		return err
-	}
+		if err := syncEventReservationsTx(ctx, tx, eventID); err != nil {
+			return err
+		}
+		return checkEventStockTx(ctx, tx, eventID)
+	})
+}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
+// RemoveItem deletes the line and gives back whatever stock it was holding
+// for the event, so removed items stop counting against availability.
+func (s *EventStore) RemoveItem(ctx context.Context, eventID, itemID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
-	if rows == 0 {
-		return ErrNotFound
-	}
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM event_items WHERE id = $1 AND event_id = $2`, itemID, eventID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
 
-	return nil
+		return syncEventReservationsTx(ctx, tx, eventID)
+	})
 }
 
 func (s *EventStore) GetItems(ctx context.Context, eventID uuid.UUID) ([]models.EventItem, error) {
@@ -514,7 +812,7 @@ func (s *EventStore) GetItems(ctx context.Context, eventID uuid.UUID) ([]models.
 		       ei.price_snapshot, ei.created_at, ei.updated_at,
 		       a.id, a.name_template, a.description_template, a.category_id,
 		       a.is_active, COALESCE(a.type, ''),
-		       v.id, v.sku, v.name, v.image_url, v.rental_price, v.sale_price, v.stock,
+		       v.id, v.sku, v.name, v.image_url, v.rental_price, v.sale_price, v.stock, v.replacement_cost,
 		       COALESCE(
 		           ei.price_snapshot,
 		           v.rental_price,
@@ -547,6 +845,7 @@ func (s *EventStore) GetItems(ctx context.Context, eventID uuid.UUID) ([]models.
 			variantRental    sql.NullFloat64
 			variantSale      sql.NullFloat64
 			variantStock     sql.NullInt64
+			variantReplace   sql.NullFloat64
 			effectivePrice   sql.NullFloat64
 		)
 
@@ -566,7 +865,7 @@ func (s *EventStore) GetItems(ctx context.Context, eventID uuid.UUID) ([]models.
 			&item.Article.IsActive,
 			&item.Article.Type,
 			&variantID, &variantSku, &variantName, &variantImage,
-			&variantRental, &variantSale, &variantStock,
+			&variantRental, &variantSale, &variantStock, &variantReplace,
 			&effectivePrice,
 		); err != nil {
 			return nil, err
@@ -594,6 +893,10 @@ func (s *EventStore) GetItems(ctx context.Context, eventID uuid.UUID) ([]models.
 			if variantStock.Valid {
 				variant.Stock = int(variantStock.Int64)
 			}
+			if variantReplace.Valid {
+				rc := variantReplace.Float64
+				variant.ReplacementCost = &rc
+			}
 			item.Variant = &variant
 		}
 
@@ -696,7 +999,12 @@ func (s *EventStore) GetDebrief(ctx context.Context, id uuid.UUID) (*models.Even
 	}, nil
 }
 
-func (s *EventStore) ApproveQuote(ctx context.Context, eventID, userID uuid.UUID) error {
+// ApproveQuote confirms the event's adjusted quote and records the approval
+// in the audit log. In the same transaction the approved total is saved and split
+// into the scheduled installments, unless the event already has some, so an
+// approved event is never left without its payment plan. Payment then moves
+// it to paid.
+func (s *EventStore) ApproveQuote(ctx context.Context, eventID, userID uuid.UUID, total int, schedule []models.ScheduledInstallment) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
@@ -705,20 +1013,55 @@ func (s *EventStore) ApproveQuote(ctx context.Context, eventID, userID uuid.UUID
 		SET status = $1, quote_approved_at = NOW(), quote_approved_by = $2, updated_at = NOW()
 		WHERE id = $3
 	`
-	res, err := s.db.ExecContext(ctx, query, models.EventStatusPaid, userID, eventID)
-	if err != nil {
-		return err
-	}
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rows == 0 {
-		return ErrNotFound
-	}
-	return nil
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		from, err := lockQuoteTx(ctx, tx, eventID, models.EventStatusConfirmed)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, query, models.EventStatusConfirmed, userID, eventID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		change := &models.EventStatusChange{ActorID: userID, Action: models.AuditActionQuoteApprove}
+		if err := logStatusChangeTx(ctx, tx, eventID, from, models.EventStatusConfirmed, change); err != nil {
+			return err
+		}
+
+		installments, err := createScheduleTx(ctx, tx, eventID, schedule)
+		if err != nil && !errors.Is(err, ErrConflict) {
+			return err
+		}
+		if len(installments) > 0 {
+			event := models.Event{TotalQuote: total}
+			event.ApplySchedule(installments)
+			paymentQuery := `
+				UPDATE events
+				SET total_quote = $1, deposit_amount = $2, deposit_paid = $3, deposit_paid_at = $4,
+				    remaining_amount = $5, installment_due_date = $6
+				WHERE id = $7`
+			_, err := tx.ExecContext(ctx, paymentQuery,
+				event.TotalQuote, event.DepositAmount, event.DepositPaid, event.DepositPaidAt,
+				event.RemainingAmount, event.InstallmentDueDate, eventID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return syncEventReservationsTx(ctx, tx, eventID)
+	})
 }
 
+// RejectQuote rejects the event's adjusted quote and records the rejection
+// in the audit log.
 func (s *EventStore) RejectQuote(ctx context.Context, eventID, userID uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -728,16 +1071,28 @@ func (s *EventStore) RejectQuote(ctx context.Context, eventID, userID uuid.UUID)
 		SET status = $1, quote_rejected_at = NOW(), quote_rejected_by = $2, updated_at = NOW()
 		WHERE id = $3
 	`
-	res, err := s.db.ExecContext(ctx, query, models.EventStatusRejected, userID, eventID)
-	if err != nil {
-		return err
-	}
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rows == 0 {
-		return ErrNotFound
-	}
-	return nil
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		from, err := lockQuoteTx(ctx, tx, eventID, models.EventStatusRejected)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, query, models.EventStatusRejected, userID, eventID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		change := &models.EventStatusChange{ActorID: userID, Action: models.AuditActionEventReject}
+		if err := logStatusChangeTx(ctx, tx, eventID, from, models.EventStatusRejected, change); err != nil {
+			return err
+		}
+		return syncEventReservationsTx(ctx, tx, eventID)
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -81,7 +82,7 @@ func (s *FinancialStore) GetFinancialRecords(ctx context.Context, startDate, end
 	query := `
 		SELECT fr.id, fr.event_id, fr.category_id, fr.type, fr.amount, fr.currency, fr.description,
 			fr.reference_number, fr.payment_method, fr.recorded_by, fr.record_date, fr.is_reconciled,
-			fr.reconciled_at, fr.metadata, fr.created_at, fr.updated_at,
+			fr.reconciled_at, fr.metadata, fr.source_type, fr.source_id, fr.created_at, fr.updated_at,
 			fc.id, fc.name, fc.type, fc.description, fc.color
 		FROM financial_records fr
 		JOIN financial_categories fc ON fr.category_id = fc.id
@@ -120,7 +121,7 @@ func (s *FinancialStore) GetFinancialRecords(ctx context.Context, startDate, end
 		if err := rows.Scan(
 			&r.ID, &r.EventID, &r.CategoryID, &r.Type, &r.Amount, &r.Currency, &r.Description,
 			&r.ReferenceNumber, &r.PaymentMethod, &r.RecordedBy, &r.RecordDate, &r.IsReconciled,
-			&r.ReconciledAt, &metadataJSON, &r.CreatedAt, &r.UpdatedAt,
+			&r.ReconciledAt, &metadataJSON, &r.SourceType, &r.SourceID, &r.CreatedAt, &r.UpdatedAt,
 			&cat.ID, &cat.Name, &cat.Type, &cat.Description, &cat.Color,
 		); err != nil {
 			return nil, err
@@ -166,65 +167,38 @@ func (s *FinancialStore) GetFinancialSummary(ctx context.Context, startDate, end
 	return &summary, err
 }
 
-func (s *FinancialStore) CreateInvoice(ctx context.Context, inv *models.Invoice) error {
+// GetTotalsByPeriod sums income and expenses recorded in [from, to) per
+// day, week or month, including periods with nothing recorded.
+func (s *FinancialStore) GetTotalsByPeriod(ctx context.Context, granularity string, from, to time.Time) ([]models.FinancialPeriodTotal, error) {
 	query := `
-		INSERT INTO invoices (invoice_number, event_id, client_id, subtotal, tax_amount, discount_amount, total, amount_paid, currency, status, issue_date, due_date, notes, terms, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
-		RETURNING id, created_at, updated_at`
+		SELECT p.period,
+			COALESCE(SUM(fr.amount) FILTER (WHERE fr.type = 'income'), 0),
+			COALESCE(SUM(fr.amount) FILTER (WHERE fr.type = 'expense'), 0)
+		FROM ` + periods + `
+		LEFT JOIN financial_records fr
+			ON date_trunc($1, fr.record_date::timestamptz) = p.period
+			AND fr.record_date >= $2 AND fr.record_date < $3
+		GROUP BY p.period
+		ORDER BY p.period`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	return s.db.QueryRowContext(ctx, query,
-		inv.InvoiceNumber, inv.EventID, inv.ClientID, inv.Subtotal, inv.TaxAmount,
-		inv.DiscountAmount, inv.Total, inv.AmountPaid, inv.Currency, inv.Status,
-		inv.IssueDate, inv.DueDate, inv.Notes, inv.Terms, inv.CreatedBy,
-	).Scan(&inv.ID, &inv.CreatedAt, &inv.UpdatedAt)
-}
-
-func (s *FinancialStore) GetInvoices(ctx context.Context, clientID string, status string) ([]models.Invoice, error) {
-	query := `
-		SELECT id, invoice_number, event_id, client_id, subtotal, tax_amount, discount_amount,
-			total, amount_paid, currency, status, issue_date, due_date, paid_date, notes, terms, created_by, created_at, updated_at
-		FROM invoices WHERE 1=1`
-
-	args := []interface{}{}
-	argIdx := 1
-
-	if clientID != "" {
-		query += fmt.Sprintf(" AND client_id = $%d", argIdx)
-		args = append(args, clientID)
-		argIdx++
-	}
-	if status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argIdx)
-		args = append(args, status)
-	}
-
-	query += " ORDER BY issue_date DESC"
-
-	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
-	defer cancel()
-
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, query, granularity, from, to)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var invoices []models.Invoice
+	var totals []models.FinancialPeriodTotal
 	for rows.Next() {
-		var i models.Invoice
-		if err := rows.Scan(
-			&i.ID, &i.InvoiceNumber, &i.EventID, &i.ClientID, &i.Subtotal, &i.TaxAmount,
-			&i.DiscountAmount, &i.Total, &i.AmountPaid, &i.Currency, &i.Status,
-			&i.IssueDate, &i.DueDate, &i.PaidDate, &i.Notes, &i.Terms, &i.CreatedBy, &i.CreatedAt, &i.UpdatedAt,
-		); err != nil {
+		var t models.FinancialPeriodTotal
+		if err := rows.Scan(&t.Period, &t.Income, &t.Expenses); err != nil {
 			return nil, err
 		}
-		invoices = append(invoices, i)
+		totals = append(totals, t)
 	}
-	return invoices, rows.Err()
+	return totals, rows.Err()
 }
 
 func (s *FinancialStore) CreateExpenseVendor(ctx context.Context, vendor *models.ExpenseVendor) error {
@@ -265,23 +239,32 @@ func (s *FinancialStore) GetExpenseVendors(ctx context.Context) ([]models.Expens
 	return vendors, rows.Err()
 }
 
+// CreateVendorPayment records a payment to a vendor and books it as an
+// expense in the same transaction. It is ErrNotFound if the vendor doesn't
+// exist.
 func (s *FinancialStore) CreateVendorPayment(ctx context.Context, payment *models.VendorPayment) error {
 	query := `
-		INSERT INTO vendor_payments (vendor_id, amount, currency, payment_date, payment_method, reference_number, description, recorded_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at`
+		INSERT INTO vendor_payments (vendor_id, event_id, amount, currency, payment_date, payment_method, reference_number, description, recorded_by)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), 'DOP'), $5, $6, $7, $8, $9)
+		RETURNING id, currency, created_at`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	return s.db.QueryRowContext(ctx, query,
-		payment.VendorID, payment.Amount, payment.Currency, payment.PaymentDate,
-		payment.PaymentMethod, payment.ReferenceNumber, payment.Description, payment.RecordedBy,
-	).Scan(&payment.ID, &payment.CreatedAt)
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, query,
+			payment.VendorID, payment.EventID, payment.Amount, payment.Currency, payment.PaymentDate,
+			payment.PaymentMethod, payment.ReferenceNumber, payment.Description, payment.RecordedBy,
+		).Scan(&payment.ID, &payment.Currency, &payment.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return recordVendorExpenseTx(ctx, tx, payment)
+	})
 }
 
 func (s *FinancialStore) GetVendorPayments(ctx context.Context, vendorID uuid.UUID) ([]models.VendorPayment, error) {
-	query := `SELECT id, vendor_id, amount, currency, payment_date, payment_method, reference_number, description, recorded_by, created_at FROM vendor_payments WHERE vendor_id = $1 ORDER BY payment_date DESC`
+	query := `SELECT id, vendor_id, event_id, amount, currency, payment_date, payment_method, reference_number, description, recorded_by, created_at FROM vendor_payments WHERE vendor_id = $1 ORDER BY payment_date DESC`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -295,7 +278,7 @@ func (s *FinancialStore) GetVendorPayments(ctx context.Context, vendorID uuid.UU
 	var payments []models.VendorPayment
 	for rows.Next() {
 		var p models.VendorPayment
-		if err := rows.Scan(&p.ID, &p.VendorID, &p.Amount, &p.Currency, &p.PaymentDate, &p.PaymentMethod, &p.ReferenceNumber, &p.Description, &p.RecordedBy, &p.CreatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.VendorID, &p.EventID, &p.Amount, &p.Currency, &p.PaymentDate, &p.PaymentMethod, &p.ReferenceNumber, &p.Description, &p.RecordedBy, &p.CreatedAt); err != nil {
 			return nil, err
 		}
 		payments = append(payments, p)
@@ -447,30 +430,159 @@ func NewPayPalStore(db *sql.DB) *PayPalStore {
 
 func (s *PayPalStore) CreateTransaction(ctx context.Context, tx *models.PayPalTransaction) error {
 	query := `
-		INSERT INTO paypal_transactions (paypal_order_id, paypal_capture_id, event_id, user_id, amount, currency, status, paypal_response)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO paypal_transactions (paypal_order_id, paypal_capture_id, event_id, user_id, amount, currency, status, purpose, installment_id, paypal_response)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	return s.db.QueryRowContext(ctx, query,
-		tx.PayPalOrderID, tx.PayPalCaptureID, tx.EventID, tx.UserID, tx.Amount, tx.Currency, tx.Status, tx.PayPalResponse,
+		tx.PayPalOrderID, tx.PayPalCaptureID, tx.EventID, tx.UserID, tx.Amount, tx.Currency, tx.Status, tx.Purpose, tx.InstallmentID, tx.PayPalResponse,
 	).Scan(&tx.ID, &tx.CreatedAt, &tx.UpdatedAt)
 }
 
-func (s *PayPalStore) UpdateTransactionCapture(ctx context.Context, orderID, captureID string) error {
-	query := `UPDATE paypal_transactions SET paypal_capture_id = $1, status = 'completed', updated_at = NOW() WHERE paypal_order_id = $2`
+// CompleteCapture records the capture of an order. Only the first call for
+// a given order flips it to completed; the capture endpoint and the webhook
+// can both report the same capture, and only one of them may apply it.
+func (s *PayPalStore) CompleteCapture(ctx context.Context, orderID, captureID string, response *string) (bool, error) {
+	query := `
+		UPDATE paypal_transactions
+		SET paypal_capture_id = $1, paypal_response = COALESCE($2::jsonb, paypal_response), status = 'completed', updated_at = NOW()
+		WHERE paypal_order_id = $3 AND status <> 'completed'`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := s.db.ExecContext(ctx, query, captureID, orderID)
-	return err
+	res, err := s.db.ExecContext(ctx, query, captureID, response, orderID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (s *PayPalStore) UpdateTransactionStatus(ctx context.Context, orderID, status string) error {
+	query := `UPDATE paypal_transactions SET status = $1, updated_at = NOW() WHERE paypal_order_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, status, orderID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordInstallments links the order to the installments it pays, so a
+// refund knows which ones to reopen.
+func (s *PayPalStore) RecordInstallments(ctx context.Context, transactionID uuid.UUID, installmentIDs []uuid.UUID) error {
+	query := `
+		INSERT INTO paypal_transaction_installments (transaction_id, installment_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		for _, installmentID := range installmentIDs {
+			if _, err := tx.ExecContext(ctx, query, transactionID, installmentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Refund marks the order refunded. The first time a completed order is
+// refunded, the installments it paid are owed again, the income they posted
+// is reversed and the event's invoice follows. It reports whether any
+// installment was reopened.
+func (s *PayPalStore) Refund(ctx context.Context, orderID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	reopened := false
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var id, eventID uuid.UUID
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT id, event_id, status FROM paypal_transactions WHERE paypal_order_id = $1 FOR UPDATE`, orderID).
+			Scan(&id, &eventID, &status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE paypal_transactions SET status = 'refunded', updated_at = NOW() WHERE id = $1`, id); err != nil {
+			return err
+		}
+		if status != models.PayPalStatusCompleted {
+			return nil
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT ip.id
+			FROM installment_payments ip
+			JOIN paypal_transaction_installments pti ON pti.installment_id = ip.id
+			WHERE pti.transaction_id = $1 AND ip.payment_status = 'paid'
+			FOR UPDATE OF ip`, id)
+		if err != nil {
+			return err
+		}
+		var paymentIDs []uuid.UUID
+		for rows.Next() {
+			var paymentID uuid.UUID
+			if err := rows.Scan(&paymentID); err != nil {
+				rows.Close()
+				return err
+			}
+			paymentIDs = append(paymentIDs, paymentID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(paymentIDs) == 0 {
+			return nil
+		}
+
+		for _, paymentID := range paymentIDs {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE installment_payments
+				SET payment_status = 'pending', payment_method = NULL, surcharge = 0, paid_at = NULL
+				WHERE id = $1`, paymentID)
+			if err != nil {
+				return err
+			}
+			if err := reversePaymentIncomeTx(ctx, tx, paymentID); err != nil {
+				return err
+			}
+		}
+		// syncEventInvoicesTx leaves paid invoices alone; reopen them first.
+		if _, err := tx.ExecContext(ctx, `UPDATE invoices SET status = 'issued', updated_at = NOW() WHERE event_id = $1 AND status = 'paid'`, eventID); err != nil {
+			return err
+		}
+		reopened = true
+		return syncEventInvoicesTx(ctx, tx, eventID)
+	})
+	return reopened, err
 }
 
 func (s *PayPalStore) GetTransactionByOrderID(ctx context.Context, orderID string) (*models.PayPalTransaction, error) {
-	query := `SELECT id, paypal_order_id, paypal_capture_id, event_id, user_id, amount, currency, status, paypal_response, created_at, updated_at FROM paypal_transactions WHERE paypal_order_id = $1`
+	query := `SELECT id, paypal_order_id, paypal_capture_id, event_id, user_id, amount, currency, status, purpose, installment_id, paypal_response, created_at, updated_at FROM paypal_transactions WHERE paypal_order_id = $1`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -478,7 +590,7 @@ func (s *PayPalStore) GetTransactionByOrderID(ctx context.Context, orderID strin
 	var tx models.PayPalTransaction
 	err := s.db.QueryRowContext(ctx, query, orderID).Scan(
 		&tx.ID, &tx.PayPalOrderID, &tx.PayPalCaptureID, &tx.EventID, &tx.UserID,
-		&tx.Amount, &tx.Currency, &tx.Status, &tx.PayPalResponse, &tx.CreatedAt, &tx.UpdatedAt,
+		&tx.Amount, &tx.Currency, &tx.Status, &tx.Purpose, &tx.InstallmentID, &tx.PayPalResponse, &tx.CreatedAt, &tx.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -487,7 +599,7 @@ func (s *PayPalStore) GetTransactionByOrderID(ctx context.Context, orderID strin
 }
 
 func (s *PayPalStore) GetTransactionsByEvent(ctx context.Context, eventID uuid.UUID) ([]models.PayPalTransaction, error) {
-	query := `SELECT id, paypal_order_id, paypal_capture_id, event_id, user_id, amount, currency, status, paypal_response, created_at, updated_at FROM paypal_transactions WHERE event_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, paypal_order_id, paypal_capture_id, event_id, user_id, amount, currency, status, purpose, installment_id, paypal_response, created_at, updated_at FROM paypal_transactions WHERE event_id = $1 ORDER BY created_at DESC`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -501,7 +613,7 @@ func (s *PayPalStore) GetTransactionsByEvent(ctx context.Context, eventID uuid.U
 	var txs []models.PayPalTransaction
 	for rows.Next() {
 		var tx models.PayPalTransaction
-		if err := rows.Scan(&tx.ID, &tx.PayPalOrderID, &tx.PayPalCaptureID, &tx.EventID, &tx.UserID, &tx.Amount, &tx.Currency, &tx.Status, &tx.PayPalResponse, &tx.CreatedAt, &tx.UpdatedAt); err != nil {
+		if err := rows.Scan(&tx.ID, &tx.PayPalOrderID, &tx.PayPalCaptureID, &tx.EventID, &tx.UserID, &tx.Amount, &tx.Currency, &tx.Status, &tx.Purpose, &tx.InstallmentID, &tx.PayPalResponse, &tx.CreatedAt, &tx.UpdatedAt); err != nil {
 			return nil, err
 		}
 		txs = append(txs, tx)
@@ -509,6 +621,27 @@ func (s *PayPalStore) GetTransactionsByEvent(ctx context.Context, eventID uuid.U
 	return txs, rows.Err()
 }
 
+func (s *PayPalStore) IsWebhookEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM paypal_webhook_events WHERE id = $1)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var processed bool
+	err := s.db.QueryRowContext(ctx, query, eventID).Scan(&processed)
+	return processed, err
+}
+
+func (s *PayPalStore) MarkWebhookEventProcessed(ctx context.Context, eventID, eventType string) error {
+	query := `INSERT INTO paypal_webhook_events (id, event_type) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, eventID, eventType)
+	return err
+}
+
 type ClientAuditStore struct {
 	db *sql.DB
 }
@@ -15,45 +15,57 @@ type InstallmentStore struct {
 	db *sql.DB
 }
 
-func (s *InstallmentStore) CreateInstallmentPayment(ctx context.Context, eventID uuid.UUID, amount int, dueDate *time.Time) (*models.InstallmentPayment, error) {
-	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
-	defer cancel()
+const installmentColumns = `id, event_id, purpose, sequence, label, amount, surcharge, payment_method, payment_status, due_date, paid_at, submitted_at, verified_at, verified_by, receipt_url, rejection_reason, rejected_at, created_at`
 
-	query := `
-		INSERT INTO installment_payments (event_id, amount, due_date, payment_status)
-		VALUES ($1, $2, $3, 'pending')
-		RETURNING id, event_id, amount, payment_method, payment_status, due_date, paid_at, created_at
-	`
+type installmentScanner interface {
+	Scan(dest ...any) error
+}
 
-	var payment models.InstallmentPayment
+func scanInstallment(row installmentScanner, payment *models.InstallmentPayment) error {
 	var paymentMethod sql.NullString
-	var dueDateVal sql.NullTime
-	var paidAt sql.NullTime
-
-	err := s.db.QueryRowContext(ctx, query, eventID, amount, dueDate).Scan(
+	err := row.Scan(
 		&payment.ID,
 		&payment.EventID,
+		&payment.Purpose,
+		&payment.Sequence,
+		&payment.Label,
 		&payment.Amount,
+		&payment.Surcharge,
 		&paymentMethod,
 		&payment.PaymentStatus,
-		&dueDateVal,
-		&paidAt,
+		&payment.DueDate,
+		&payment.PaidAt,
+		&payment.SubmittedAt,
+		&payment.VerifiedAt,
+		&payment.VerifiedBy,
+		&payment.ReceiptURL,
+		&payment.RejectionReason,
+		&payment.RejectedAt,
 		&payment.CreatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
 	if paymentMethod.Valid {
 		payment.PaymentMethod = &paymentMethod.String
 	}
-	if dueDateVal.Valid {
-		payment.DueDate = &dueDateVal.Time
-	}
-	if paidAt.Valid {
-		payment.PaidAt = &paidAt.Time
-	}
+	payment.Overdue = payment.IsOverdue(time.Now())
+	return nil
+}
 
+func (s *InstallmentStore) CreateInstallmentPayment(ctx context.Context, eventID uuid.UUID, amount int, dueDate *time.Time) (*models.InstallmentPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO installment_payments (event_id, amount, due_date, payment_status)
+		VALUES ($1, $2, $3, 'pending')
+		RETURNING ` + installmentColumns
+
+	var payment models.InstallmentPayment
+	if err := scanInstallment(s.db.QueryRowContext(ctx, query, eventID, amount, dueDate), &payment); err != nil {
+		return nil, err
+	}
 	return &payment, nil
 }
 
@@ -62,10 +74,10 @@ func (s *InstallmentStore) GetInstallmentByEventID(ctx context.Context, eventID
 	defer cancel()
 
 	query := `
-		SELECT id, event_id, amount, payment_method, payment_status, due_date, paid_at, created_at
+		SELECT ` + installmentColumns + `
 		FROM installment_payments
 		WHERE event_id = $1
-		ORDER BY created_at ASC
+		ORDER BY sequence ASC NULLS LAST, created_at ASC
 	`
 
 	rows, err := s.db.QueryContext(ctx, query, eventID)
@@ -77,63 +89,289 @@ func (s *InstallmentStore) GetInstallmentByEventID(ctx context.Context, eventID
 	var payments []models.InstallmentPayment
 	for rows.Next() {
 		var payment models.InstallmentPayment
-		var paymentMethod sql.NullString
-		var dueDate sql.NullTime
-		var paidAt sql.NullTime
-
-		if err := rows.Scan(
-			&payment.ID,
-			&payment.EventID,
-			&payment.Amount,
-			&paymentMethod,
-			&payment.PaymentStatus,
-			&dueDate,
-			&paidAt,
-			&payment.CreatedAt,
-		); err != nil {
+		if err := scanInstallment(rows, &payment); err != nil {
 			return nil, err
 		}
+		payments = append(payments, payment)
+	}
 
-		if paymentMethod.Valid {
-			payment.PaymentMethod = &paymentMethod.String
-		}
-		if dueDate.Valid {
-			payment.DueDate = &dueDate.Time
-		}
-		if paidAt.Valid {
-			payment.PaidAt = &paidAt.Time
-		}
+	return payments, rows.Err()
+}
 
-		payments = append(payments, payment)
+// CreateSchedule saves the installments of the event's payment plan. It is
+// ErrConflict if the event already has installments, so a schedule is only
+// ever generated once.
+func (s *InstallmentStore) CreateSchedule(ctx context.Context, eventID uuid.UUID, schedule []models.ScheduledInstallment) ([]models.InstallmentPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var payments []models.InstallmentPayment
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var err error
+		payments, err = createScheduleTx(ctx, tx, eventID, schedule)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func createScheduleTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID, schedule []models.ScheduledInstallment) ([]models.InstallmentPayment, error) {
+	// Serialise schedule generation per event.
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM events WHERE id = $1 FOR UPDATE`, eventID); err != nil {
+		return nil, err
+	}
+	var exists bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM installment_payments WHERE event_id = $1 AND purpose = 'installment')`,
+		eventID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrConflict
 	}
 
+	payments := make([]models.InstallmentPayment, 0, len(schedule))
+	for _, inst := range schedule {
+		var payment models.InstallmentPayment
+		err := scanInstallment(tx.QueryRowContext(ctx, `
+			INSERT INTO installment_payments (event_id, purpose, sequence, label, amount, due_date, payment_status)
+			VALUES ($1, 'installment', $2, $3, $4, $5, 'pending')
+			RETURNING `+installmentColumns,
+			eventID, inst.Sequence, inst.Label, inst.Amount, inst.DueDate), &payment)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
 	return payments, nil
 }
 
-func (s *InstallmentStore) MarkPaid(ctx context.Context, paymentID uuid.UUID, paymentMethod string) error {
+// MarkPaid records that the installment was paid with the given method. A
+// method that needs manual confirmation only moves it to
+// pending_verification, to be settled by ConfirmPayment. The method must be
+// configured, but it may be disabled: clients choose among enabled methods
+// before paying, and a payment already taken is still recorded. A settled
+// payment is booked as income, and counted on the event's invoice, in the
+// same transaction. It is ErrConflict if the payment is already paid or was
+// rejected.
+func (s *InstallmentStore) MarkPaid(ctx context.Context, paymentID uuid.UUID, paymentMethod string) (*models.InstallmentPayment, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
+	var payment models.InstallmentPayment
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var status string
+		var amount int
+		err := tx.QueryRowContext(ctx, `SELECT payment_status, amount FROM installment_payments WHERE id = $1 FOR UPDATE`, paymentID).
+			Scan(&status, &amount)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if status != models.InstallmentStatusPending && status != models.InstallmentStatusPendingVerification {
+			return ErrConflict
+		}
+		if err := applyPaymentMethodTx(ctx, tx, paymentID, amount, paymentMethod, &payment); err != nil {
+			return err
+		}
+		if payment.PaymentStatus != models.InstallmentStatusPaid {
+			return nil
+		}
+		if err := recordPaymentIncomeTx(ctx, tx, &payment, nil); err != nil {
+			return err
+		}
+		return syncEventInvoicesTx(ctx, tx, payment.EventID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// applyPaymentMethodTx settles the payment with the method, or leaves it
+// waiting for verification when the method requires it, and charges the
+// method's surcharge.
+func applyPaymentMethodTx(ctx context.Context, tx *sql.Tx, paymentID uuid.UUID, amount int, code string, payment *models.InstallmentPayment) error {
+	var method models.PaymentMethod
+	err := tx.QueryRowContext(ctx, `SELECT surcharge_percent, requires_confirmation FROM payment_methods WHERE code = $1`, code).
+		Scan(&method.SurchargePercent, &method.RequiresConfirmation)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPaymentMethodUnavailable
+		}
+		return err
+	}
+
 	query := `
 		UPDATE installment_payments
-		SET payment_status = 'paid', payment_method = $1, paid_at = NOW()
-		WHERE id = $2
-	`
+		SET payment_status = 'paid', payment_method = $1, surcharge = $2, paid_at = NOW()
+		WHERE id = $3
+		RETURNING ` + installmentColumns
+	if method.RequiresConfirmation {
+		query = `
+			UPDATE installment_payments
+			SET payment_status = 'pending_verification', payment_method = $1, surcharge = $2, submitted_at = NOW()
+			WHERE id = $3
+			RETURNING ` + installmentColumns
+	}
+	return scanInstallment(tx.QueryRowContext(ctx, query, code, method.Surcharge(amount), paymentID), payment)
+}
+
+// SubmitReceipt attaches the client's receipt to a deposit or installment
+// and puts it in the queue for an admin to verify, whatever the method. A
+// receipt for a payment already waiting replaces the previous one. It is
+// ErrConflict if the payment is paid or was rejected.
+func (s *InstallmentStore) SubmitReceipt(ctx context.Context, paymentID uuid.UUID, paymentMethod, receiptURL string) (*models.InstallmentPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var payment models.InstallmentPayment
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var status string
+		var amount int
+		err := tx.QueryRowContext(ctx, `SELECT payment_status, amount FROM installment_payments WHERE id = $1 FOR UPDATE`, paymentID).
+			Scan(&status, &amount)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if status != models.InstallmentStatusPending && status != models.InstallmentStatusPendingVerification {
+			return ErrConflict
+		}
+
+		var method models.PaymentMethod
+		err = tx.QueryRowContext(ctx, `SELECT surcharge_percent FROM payment_methods WHERE code = $1`, paymentMethod).
+			Scan(&method.SurchargePercent)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPaymentMethodUnavailable
+			}
+			return err
+		}
 
-	res, err := s.db.ExecContext(ctx, query, paymentMethod, paymentID)
+		return scanInstallment(tx.QueryRowContext(ctx, `
+			UPDATE installment_payments
+			SET payment_status = 'pending_verification', payment_method = $1, surcharge = $2,
+			    receipt_url = $3, submitted_at = NOW()
+			WHERE id = $4
+			RETURNING `+installmentColumns,
+			paymentMethod, method.Surcharge(amount), receiptURL, paymentID), &payment)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return &payment, nil
+}
+
+// ConfirmPayment settles a payment waiting for verification once an admin
+// saw the money arrive, and books it as income and on the event's invoice
+// in the same transaction. It is ErrConflict if the payment isn't waiting.
+func (s *InstallmentStore) ConfirmPayment(ctx context.Context, paymentID, adminID uuid.UUID) (*models.InstallmentPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
-	rows, err := res.RowsAffected()
+	var payment models.InstallmentPayment
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		err := scanInstallment(tx.QueryRowContext(ctx, `
+			UPDATE installment_payments
+			SET payment_status = 'paid', paid_at = NOW(), verified_at = NOW(), verified_by = $1
+			WHERE id = $2 AND payment_status = 'pending_verification'
+			RETURNING `+installmentColumns, adminID, paymentID), &payment)
+		if err != nil {
+			return err
+		}
+		if err := recordPaymentIncomeTx(ctx, tx, &payment, &adminID); err != nil {
+			return err
+		}
+		return syncEventInvoicesTx(ctx, tx, payment.EventID)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := s.GetByID(ctx, paymentID); err != nil {
+			return nil, err
+		}
+		return nil, ErrConflict
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if rows == 0 {
-		return ErrNotFound
+	return &payment, nil
+}
+
+// RejectPayment turns down a payment waiting for verification. An
+// installment goes back to pending so the client can pay it again; a
+// deposit or full payment is kept as rejected and the client pays anew. It
+// is ErrConflict if the payment isn't waiting.
+func (s *InstallmentStore) RejectPayment(ctx context.Context, paymentID, adminID uuid.UUID, reason string) (*models.InstallmentPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var payment models.InstallmentPayment
+	err := scanInstallment(s.db.QueryRowContext(ctx, `
+		UPDATE installment_payments
+		SET payment_status = CASE WHEN purpose = 'installment' THEN 'pending' ELSE 'rejected' END,
+		    rejection_reason = $1, rejected_at = NOW(), rejected_by = $2
+		WHERE id = $3 AND payment_status = 'pending_verification'
+		RETURNING `+installmentColumns, reason, adminID, paymentID), &payment)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := s.GetByID(ctx, paymentID); err != nil {
+			return nil, err
+		}
+		return nil, ErrConflict
 	}
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
 
-	return nil
+// ListAwaitingVerification returns the admin queue of payments waiting for
+// verification, oldest submission first.
+func (s *InstallmentStore) ListAwaitingVerification(ctx context.Context) ([]models.PaymentVerification, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ip.id, ip.event_id, ip.purpose, ip.sequence, ip.label, ip.amount, ip.surcharge, ip.payment_method, ip.payment_status,
+		       ip.due_date, ip.paid_at, ip.submitted_at, ip.verified_at, ip.verified_by,
+		       ip.receipt_url, ip.rejection_reason, ip.rejected_at, ip.created_at,
+		       e.name, TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')), u.email
+		FROM installment_payments ip
+		JOIN events e ON e.id = ip.event_id
+		JOIN users u ON u.id = e.user_id
+		WHERE ip.payment_status = 'pending_verification'
+		ORDER BY ip.submitted_at ASC NULLS LAST`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queue := []models.PaymentVerification{}
+	for rows.Next() {
+		var v models.PaymentVerification
+		if err := scanInstallment(verificationScanner{rows, &v}, &v.InstallmentPayment); err != nil {
+			return nil, err
+		}
+		queue = append(queue, v)
+	}
+	return queue, rows.Err()
+}
+
+// verificationScanner appends the event and client columns to an
+// installment scan.
+type verificationScanner struct {
+	row installmentScanner
+	v   *models.PaymentVerification
+}
+
+func (s verificationScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, &s.v.EventName, &s.v.ClientName, &s.v.ClientEmail)...)
 }
 
 func (s *InstallmentStore) GetPendingInstallments(ctx context.Context) ([]models.InstallmentPayment, error) {
@@ -141,7 +379,9 @@ func (s *InstallmentStore) GetPendingInstallments(ctx context.Context) ([]models
 	defer cancel()
 
 	query := `
-		SELECT ip.id, ip.event_id, ip.amount, ip.payment_method, ip.payment_status, ip.due_date, ip.paid_at, ip.created_at
+		SELECT ip.id, ip.event_id, ip.purpose, ip.sequence, ip.label, ip.amount, ip.surcharge, ip.payment_method, ip.payment_status,
+		       ip.due_date, ip.paid_at, ip.submitted_at, ip.verified_at, ip.verified_by,
+		       ip.receipt_url, ip.rejection_reason, ip.rejected_at, ip.created_at
 		FROM installment_payments ip
 		JOIN events e ON ip.event_id = e.id
 		WHERE ip.payment_status = 'pending'
@@ -160,37 +400,13 @@ func (s *InstallmentStore) GetPendingInstallments(ctx context.Context) ([]models
 	var payments []models.InstallmentPayment
 	for rows.Next() {
 		var payment models.InstallmentPayment
-		var paymentMethod sql.NullString
-		var dueDate sql.NullTime
-		var paidAt sql.NullTime
-
-		if err := rows.Scan(
-			&payment.ID,
-			&payment.EventID,
-			&payment.Amount,
-			&paymentMethod,
-			&payment.PaymentStatus,
-			&dueDate,
-			&paidAt,
-			&payment.CreatedAt,
-		); err != nil {
+		if err := scanInstallment(rows, &payment); err != nil {
 			return nil, err
 		}
-
-		if paymentMethod.Valid {
-			payment.PaymentMethod = &paymentMethod.String
-		}
-		if dueDate.Valid {
-			payment.DueDate = &dueDate.Time
-		}
-		if paidAt.Valid {
-			payment.PaidAt = &paidAt.Time
-		}
-
 		payments = append(payments, payment)
 	}
 
-	return payments, nil
+	return payments, rows.Err()
 }
 
 func (s *InstallmentStore) GetByID(ctx context.Context, paymentID uuid.UUID) (*models.InstallmentPayment, error) {
@@ -198,42 +414,18 @@ func (s *InstallmentStore) GetByID(ctx context.Context, paymentID uuid.UUID) (*m
 	defer cancel()
 
 	query := `
-		SELECT id, event_id, amount, payment_method, payment_status, due_date, paid_at, created_at
+		SELECT ` + installmentColumns + `
 		FROM installment_payments
 		WHERE id = $1
 	`
 
 	var payment models.InstallmentPayment
-	var paymentMethod sql.NullString
-	var dueDate sql.NullTime
-	var paidAt sql.NullTime
-
-	err := s.db.QueryRowContext(ctx, query, paymentID).Scan(
-		&payment.ID,
-		&payment.EventID,
-		&payment.Amount,
-		&paymentMethod,
-		&payment.PaymentStatus,
-		&dueDate,
-		&paidAt,
-		&payment.CreatedAt,
-	)
-	if err != nil {
+	if err := scanInstallment(s.db.QueryRowContext(ctx, query, paymentID), &payment); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
 		}
 		return nil, err
 	}
 
-	if paymentMethod.Valid {
-		payment.PaymentMethod = &paymentMethod.String
-	}
-	if dueDate.Valid {
-		payment.DueDate = &dueDate.Time
-	}
-	if paidAt.Valid {
-		payment.PaidAt = &paidAt.Time
-	}
-
 	return &payment, nil
 }
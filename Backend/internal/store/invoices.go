@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvoicePaidByInstallments is returned when an invoice for an event is
+// marked paid by hand: it follows the event's installments instead.
+var ErrInvoicePaidByInstallments = errors.New("invoices for an event are paid through its installments")
+
+const invoiceColumns = `id, invoice_number, fiscal_year, sequence, ncf, client_rnc, event_id, client_id,
+	subtotal, tax_rate, tax_included, tax_amount, discount_amount, total, amount_paid, currency, status,
+	issue_date, due_date, sent_at, paid_date, voided_at, void_reason, notes, terms, created_by, created_at, updated_at`
+
+type invoiceScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvoice(row invoiceScanner, inv *models.Invoice) error {
+	return row.Scan(
+		&inv.ID, &inv.InvoiceNumber, &inv.FiscalYear, &inv.Sequence, &inv.NCF, &inv.ClientRNC, &inv.EventID, &inv.ClientID,
+		&inv.Subtotal, &inv.TaxRate, &inv.TaxIncluded, &inv.TaxAmount, &inv.DiscountAmount, &inv.Total, &inv.AmountPaid, &inv.Currency, &inv.Status,
+		&inv.IssueDate, &inv.DueDate, &inv.SentAt, &inv.PaidDate, &inv.VoidedAt, &inv.VoidReason, &inv.Notes, &inv.Terms, &inv.CreatedBy, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+}
+
+// CreateInvoice issues the invoice with the next number of its fiscal year.
+// Numbers have no gaps: the year's counter is bumped in the same
+// transaction, so an invoice that fails to save gives its number back. An
+// invoice for an event picks up what was already paid on the event. It is
+// ErrNotFound if the event doesn't exist and ErrConflict if the event
+// already has an invoice that isn't void, or the NCF was already used.
+func (s *FinancialStore) CreateInvoice(ctx context.Context, inv *models.Invoice) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if inv.IssueDate.IsZero() {
+		inv.IssueDate = time.Now()
+	}
+	if inv.Currency == "" {
+		inv.Currency = "DOP"
+	}
+	inv.Status = models.InvoiceStatusIssued
+	inv.FiscalYear = inv.IssueDate.Year()
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if inv.EventID != nil {
+			err := tx.QueryRowContext(ctx, `SELECT user_id FROM events WHERE id = $1 FOR UPDATE`, *inv.EventID).Scan(&inv.ClientID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrNotFound
+				}
+				return err
+			}
+			var invoiced bool
+			err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM invoices WHERE event_id = $1 AND status <> 'void')`, *inv.EventID).
+				Scan(&invoiced)
+			if err != nil {
+				return err
+			}
+			if invoiced {
+				return ErrConflict
+			}
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO invoice_sequences (fiscal_year, last_number) VALUES ($1, 1)
+			ON CONFLICT (fiscal_year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+			RETURNING last_number`, inv.FiscalYear).Scan(&inv.Sequence)
+		if err != nil {
+			return err
+		}
+		inv.InvoiceNumber = models.FormatInvoiceNumber(inv.FiscalYear, inv.Sequence)
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO invoices (invoice_number, fiscal_year, sequence, ncf, client_rnc, event_id, client_id,
+				subtotal, tax_rate, tax_included, tax_amount, discount_amount, total, currency, status,
+				issue_date, due_date, notes, terms, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			RETURNING id, created_at, updated_at`,
+			inv.InvoiceNumber, inv.FiscalYear, inv.Sequence, inv.NCF, inv.ClientRNC, inv.EventID, inv.ClientID,
+			inv.Subtotal, inv.TaxRate, inv.TaxIncluded, inv.TaxAmount, inv.DiscountAmount, inv.Total, inv.Currency, inv.Status,
+			inv.IssueDate, inv.DueDate, inv.Notes, inv.Terms, inv.CreatedBy,
+		).Scan(&inv.ID, &inv.CreatedAt, &inv.UpdatedAt)
+		if err != nil {
+			if err.Error() == `pq: duplicate key value violates unique constraint "idx_invoices_ncf"` {
+				return ErrConflict
+			}
+			return err
+		}
+
+		for i, line := range inv.Lines {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO invoice_lines (invoice_id, position, description, quantity, unit_price, total)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				inv.ID, i+1, line.Description, line.Quantity, line.UnitPrice, line.Total)
+			if err != nil {
+				return err
+			}
+		}
+
+		if inv.EventID == nil {
+			return nil
+		}
+		if err := syncEventInvoicesTx(ctx, tx, *inv.EventID); err != nil {
+			return err
+		}
+		return tx.QueryRowContext(ctx, `SELECT amount_paid, status, paid_date FROM invoices WHERE id = $1`, inv.ID).
+			Scan(&inv.AmountPaid, &inv.Status, &inv.PaidDate)
+	})
+	return err
+}
+
+func (s *FinancialStore) GetInvoices(ctx context.Context, clientID string, status string) ([]models.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE 1=1`
+
+	args := []interface{}{}
+	argIdx := 1
+
+	if clientID != "" {
+		query += fmt.Sprintf(" AND client_id = $%d", argIdx)
+		args = append(args, clientID)
+		argIdx++
+	}
+	if status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, status)
+	}
+
+	query += " ORDER BY fiscal_year DESC, sequence DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []models.Invoice
+	for rows.Next() {
+		var i models.Invoice
+		if err := scanInvoice(rows, &i); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, i)
+	}
+	return invoices, rows.Err()
+}
+
+// GetInvoice returns the invoice with its lines.
+func (s *FinancialStore) GetInvoice(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var inv models.Invoice
+	err := scanInvoice(s.db.QueryRowContext(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, id), &inv)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT description, quantity, unit_price, total FROM invoice_lines
+		WHERE invoice_id = $1 ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.InvoiceLine
+		if err := rows.Scan(&l.Description, &l.Quantity, &l.UnitPrice, &l.Total); err != nil {
+			return nil, err
+		}
+		inv.Lines = append(inv.Lines, l)
+	}
+	return &inv, rows.Err()
+}
+
+// UpdateInvoiceStatus moves the invoice to sent, paid or void. The change
+// must be allowed by the invoice lifecycle, and only invoices without an
+// event can be marked paid by hand.
+func (s *FinancialStore) UpdateInvoiceStatus(ctx context.Context, id uuid.UUID, status string, reason *string) (*models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var from string
+		var eventID uuid.NullUUID
+		err := tx.QueryRowContext(ctx, `SELECT status, event_id FROM invoices WHERE id = $1 FOR UPDATE`, id).Scan(&from, &eventID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if !models.CanTransitionInvoice(from, status) {
+			return &InvalidTransitionError{Entity: "invoice", From: from, To: status}
+		}
+
+		switch status {
+		case models.InvoiceStatusSent:
+			_, err = tx.ExecContext(ctx, `UPDATE invoices SET status = $1, sent_at = COALESCE(sent_at, NOW()), updated_at = NOW() WHERE id = $2`, status, id)
+		case models.InvoiceStatusPaid:
+			if eventID.Valid {
+				return ErrInvoicePaidByInstallments
+			}
+			_, err = tx.ExecContext(ctx, `UPDATE invoices SET status = $1, amount_paid = total, paid_date = COALESCE(paid_date, NOW()), updated_at = NOW() WHERE id = $2`, status, id)
+		case models.InvoiceStatusVoid:
+			_, err = tx.ExecContext(ctx, `UPDATE invoices SET status = $1, voided_at = NOW(), void_reason = $2, updated_at = NOW() WHERE id = $3`, status, reason, id)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetInvoice(ctx, id)
+}
+
+// syncEventInvoicesTx brings the open invoice of an event in line with its
+// installments: the amount paid is what the paid installments add up to,
+// and the invoice is paid once no installment is left owing. Surcharges
+// are a fee of the payment method, and damage charges are billed after the
+// event, so neither is part of the invoice.
+func syncEventInvoicesTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE invoices i
+		SET amount_paid = LEAST(i.total, p.paid),
+		    status = CASE
+		        WHEN p.paid > 0 AND p.owing = 0 THEN 'paid'
+		        WHEN p.paid > 0 THEN 'partially_paid'
+		        ELSE i.status END,
+		    paid_date = CASE WHEN p.paid > 0 AND p.owing = 0 THEN COALESCE(i.paid_date, NOW()) END,
+		    updated_at = NOW()
+		FROM (
+			SELECT COALESCE(SUM(amount) FILTER (WHERE payment_status = 'paid'), 0) AS paid,
+			       COUNT(*) FILTER (WHERE payment_status IN ('pending', 'pending_verification')) AS owing
+			FROM installment_payments
+			WHERE event_id = $1 AND purpose <> 'damage'
+		) p
+		WHERE i.event_id = $1 AND i.status IN ('issued', 'sent', 'partially_paid')`, eventID)
+	return err
+}
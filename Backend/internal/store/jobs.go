@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type JobsStore struct {
+	db *sql.DB
+}
+
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, run_at, unique_key,
+	locked_by, locked_at, last_error, created_at, updated_at, finished_at`
+
+func scanJob(row interface{ Scan(...any) error }, j *models.Job) error {
+	return row.Scan(
+		&j.ID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt, &j.UniqueKey,
+		&j.LockedBy, &j.LockedAt, &j.LastError, &j.CreatedAt, &j.UpdatedAt, &j.FinishedAt,
+	)
+}
+
+// Enqueue adds a job. When the job has a unique key that was already used it
+// is not added and Enqueue reports false.
+func (s *JobsStore) Enqueue(ctx context.Context, job *models.Job) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if len(job.Payload) == 0 {
+		job.Payload = []byte("{}")
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 5
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO jobs (kind, payload, max_attempts, run_at, unique_key)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (unique_key) WHERE unique_key IS NOT NULL DO NOTHING
+		RETURNING ` + jobColumns
+
+	// lib/pq sends []byte as bytea, so the payload goes in as text.
+	err := scanJob(s.db.QueryRowContext(ctx, query, job.Kind, string(job.Payload), job.MaxAttempts, job.RunAt, job.UniqueKey), job)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Claim locks the next job that is ready to run and marks it running for
+// workerID. Jobs left running past lease, because their worker died, are
+// claimed again, unless that was their last attempt, in which case they
+// are dead. Concurrent workers skip each other's rows instead of waiting
+// on them. It returns nil when there is nothing to do.
+func (s *JobsStore) Claim(ctx context.Context, workerID string, kinds []string, lease time.Duration) (*models.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var job *models.Job
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE jobs
+			SET status = 'dead', last_error = COALESCE(last_error, 'lease expired'),
+			    finished_at = NOW(), updated_at = NOW(), locked_by = NULL, locked_at = NULL
+			WHERE id IN (
+				SELECT id FROM jobs
+				WHERE kind = ANY($1)
+				  AND status = 'running' AND locked_at < NOW() - make_interval(secs => $2)
+				  AND attempts >= max_attempts
+				FOR UPDATE SKIP LOCKED
+			)`, pq.Array(kinds), lease.Seconds())
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE jobs
+			SET status = 'running', attempts = attempts + 1, locked_by = $1, locked_at = NOW(), updated_at = NOW()
+			WHERE id = (
+				SELECT id FROM jobs
+				WHERE kind = ANY($2)
+				  AND ((status = 'pending' AND run_at <= NOW())
+				    OR (status = 'running' AND locked_at < NOW() - make_interval(secs => $3) AND attempts < max_attempts))
+				ORDER BY run_at
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + jobColumns
+
+		var j models.Job
+		err = scanJob(tx.QueryRowContext(ctx, query, workerID, pq.Array(kinds), lease.Seconds()), &j)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		job = &j
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Complete marks a job claimed by workerID as done.
+func (s *JobsStore) Complete(ctx context.Context, id uuid.UUID, workerID string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'succeeded', finished_at = NOW(), updated_at = NOW(), locked_by = NULL, locked_at = NULL, last_error = NULL
+		WHERE id = $1 AND status = 'running' AND locked_by = $2`, id, workerID)
+	return err
+}
+
+// Fail records a failed attempt. The job runs again at retryAt, or becomes
+// dead when retryAt is nil.
+func (s *JobsStore) Fail(ctx context.Context, id uuid.UUID, workerID, errMsg string, retryAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if retryAt == nil {
+		_, err := s.db.ExecContext(ctx, `
+			UPDATE jobs
+			SET status = 'dead', last_error = $3, finished_at = NOW(), updated_at = NOW(), locked_by = NULL, locked_at = NULL
+			WHERE id = $1 AND status = 'running' AND locked_by = $2`, id, workerID, errMsg)
+		return err
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = 'pending', last_error = $3, run_at = $4, updated_at = NOW(), locked_by = NULL, locked_at = NULL
+		WHERE id = $1 AND status = 'running' AND locked_by = $2`, id, workerID, errMsg, *retryAt)
+	return err
+}
+
+// List returns jobs in a status, most recently updated first.
+func (s *JobsStore) List(ctx context.Context, status string, limit int) ([]models.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+jobColumns+` FROM jobs
+		WHERE status = $1
+		ORDER BY updated_at DESC
+		LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.Job
+	for rows.Next() {
+		var j models.Job
+		if err := scanJob(rows, &j); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// Retry puts a dead job back in the queue with a fresh set of attempts.
+func (s *JobsStore) Retry(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var job models.Job
+	err := scanJob(s.db.QueryRowContext(ctx, `
+		UPDATE jobs
+		SET status = 'pending', attempts = 0, run_at = NOW(), finished_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+		RETURNING `+jobColumns, id), &job)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// DeleteSucceededBefore prunes finished jobs. Dead jobs are kept for
+// inspection.
+func (s *JobsStore) DeleteSucceededBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM jobs WHERE status = 'succeeded' AND finished_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
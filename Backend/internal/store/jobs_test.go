@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Backend/internal/store/models"
+	"Backend/internal/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobsClaim(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	tdb := testutils.SetupTestDatabase(t)
+	defer tdb.Teardown(t)
+
+	jobs := &JobsStore{db: tdb.Db}
+	ctx := context.Background()
+	kinds := []string{"test"}
+
+	// expire pushes the job's lease into the past, as if its worker died.
+	expire := func(t *testing.T, job *models.Job) {
+		_, err := tdb.Db.ExecContext(ctx, `UPDATE jobs SET locked_at = NOW() - INTERVAL '1 hour' WHERE id = $1`, job.ID)
+		require.NoError(t, err)
+	}
+
+	t.Run("expired leases are claimed again", func(t *testing.T) {
+		job := &models.Job{Kind: "test", MaxAttempts: 2}
+		_, err := jobs.Enqueue(ctx, job)
+		require.NoError(t, err)
+
+		claimed, err := jobs.Claim(ctx, "a", kinds, time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		expire(t, claimed)
+
+		claimed, err = jobs.Claim(ctx, "b", kinds, time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, job.ID, claimed.ID)
+		assert.Equal(t, 2, claimed.Attempts)
+		require.NoError(t, jobs.Complete(ctx, claimed.ID, "b"))
+	})
+
+	t.Run("an expired last attempt is dead", func(t *testing.T) {
+		job := &models.Job{Kind: "test", MaxAttempts: 1}
+		_, err := jobs.Enqueue(ctx, job)
+		require.NoError(t, err)
+
+		claimed, err := jobs.Claim(ctx, "a", kinds, time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		expire(t, claimed)
+
+		claimed, err = jobs.Claim(ctx, "b", kinds, time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, claimed)
+
+		dead, err := jobs.List(ctx, "dead", 10)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, job.ID, dead[0].ID)
+		assert.Equal(t, 1, dead[0].Attempts)
+	})
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+// postLedgerTx inserts the financial record posted by a payment, unless the
+// payment already posted one: confirming or capturing a payment twice must
+// not count it twice. The record must carry its SourceType and SourceID.
+func postLedgerTx(ctx context.Context, tx *sql.Tx, rec *models.FinancialRecord) error {
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO financial_records (event_id, category_id, type, amount, currency, description, reference_number, payment_method, recorded_by, record_date, source_type, source_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (source_type, source_id) WHERE source_id IS NOT NULL DO NOTHING
+		RETURNING id, created_at, updated_at`,
+		rec.EventID, rec.CategoryID, rec.Type, rec.Amount, rec.Currency, rec.Description,
+		rec.ReferenceNumber, rec.PaymentMethod, rec.RecordedBy, rec.RecordDate, rec.SourceType, rec.SourceID,
+	).Scan(&rec.ID, &rec.CreatedAt, &rec.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// paymentIncomeCategory is the income category client payments are booked
+// under, falling back to the oldest income category if it was renamed.
+const paymentIncomeCategory = `
+	SELECT id FROM financial_categories
+	WHERE type = 'income' AND is_active = true
+	ORDER BY (name = 'Alquiler de equipos') DESC, created_at ASC
+	LIMIT 1`
+
+// recordPaymentIncomeTx books a settled payment, surcharge included, as an
+// income record linked to its event. recordedBy is nil when nobody
+// confirmed the payment by hand, as with card and PayPal payments.
+func recordPaymentIncomeTx(ctx context.Context, tx *sql.Tx, payment *models.InstallmentPayment, recordedBy *uuid.UUID) error {
+	var categoryID uuid.UUID
+	if err := tx.QueryRowContext(ctx, paymentIncomeCategory).Scan(&categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("no income category to book the payment under")
+		}
+		return err
+	}
+
+	var eventName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM events WHERE id = $1`, payment.EventID).Scan(&eventName); err != nil {
+		return err
+	}
+
+	description := "Pago de cuota - " + eventName
+	switch payment.Purpose {
+	case models.InstallmentPurposeDeposit:
+		description = "Depósito - " + eventName
+	case models.InstallmentPurposeFull:
+		description = "Pago completo - " + eventName
+	case models.InstallmentPurposeDamage:
+		description = models.DamageChargeLabel + " - " + eventName
+	}
+	if payment.Label != "" && payment.Purpose == models.InstallmentPurposeInstallment {
+		description = payment.Label + " - " + eventName
+	}
+
+	recordDate := time.Now()
+	if payment.PaidAt != nil {
+		recordDate = *payment.PaidAt
+	}
+	reference := payment.ID.String()
+	source := models.LedgerSourceInstallmentPayment
+	return postLedgerTx(ctx, tx, &models.FinancialRecord{
+		EventID:         &payment.EventID,
+		CategoryID:      categoryID,
+		Type:            "income",
+		Amount:          float64(payment.Amount + payment.Surcharge),
+		Currency:        "DOP",
+		Description:     description,
+		ReferenceNumber: &reference,
+		PaymentMethod:   payment.PaymentMethod,
+		RecordedBy:      recordedBy,
+		RecordDate:      recordDate,
+		SourceType:      &source,
+		SourceID:        &payment.ID,
+	})
+}
+
+// reversePaymentIncomeTx books a refunded payment as negative income that
+// cancels the record the payment posted. That record is detached from the
+// payment, so paying it again posts a new one, and the reversal points at
+// it instead. A payment that posted nothing has nothing to reverse.
+func reversePaymentIncomeTx(ctx context.Context, tx *sql.Tx, paymentID uuid.UUID) error {
+	var posted models.FinancialRecord
+	err := tx.QueryRowContext(ctx, `
+		UPDATE financial_records
+		SET source_type = NULL, source_id = NULL, updated_at = NOW()
+		WHERE source_type = $1 AND source_id = $2
+		RETURNING id, event_id, category_id, amount, COALESCE(currency, 'DOP'), description, reference_number, payment_method`,
+		models.LedgerSourceInstallmentPayment, paymentID,
+	).Scan(&posted.ID, &posted.EventID, &posted.CategoryID, &posted.Amount, &posted.Currency, &posted.Description, &posted.ReferenceNumber, &posted.PaymentMethod)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	source := models.LedgerSourceInstallmentRefund
+	return postLedgerTx(ctx, tx, &models.FinancialRecord{
+		EventID:         posted.EventID,
+		CategoryID:      posted.CategoryID,
+		Type:            "income",
+		Amount:          -posted.Amount,
+		Currency:        posted.Currency,
+		Description:     "Reembolso - " + posted.Description,
+		ReferenceNumber: posted.ReferenceNumber,
+		PaymentMethod:   posted.PaymentMethod,
+		RecordDate:      time.Now(),
+		SourceType:      &source,
+		SourceID:        &posted.ID,
+	})
+}
+
+// vendorExpenseCategory picks the expense category a vendor payment is
+// booked under: the one named like the vendor's category, else
+// 'Proveedores', else the oldest expense category.
+const vendorExpenseCategory = `
+	SELECT v.name, c.id
+	FROM expense_vendors v
+	LEFT JOIN LATERAL (
+		SELECT id FROM financial_categories
+		WHERE type = 'expense' AND is_active = true
+		ORDER BY (lower(name) = lower(v.category)) DESC, (name = 'Proveedores') DESC, created_at ASC
+		LIMIT 1
+	) c ON true
+	WHERE v.id = $1`
+
+// recordVendorExpenseTx books a vendor payment as an expense, linked to the
+// event it was spent on, if any.
+func recordVendorExpenseTx(ctx context.Context, tx *sql.Tx, payment *models.VendorPayment) error {
+	var vendorName string
+	var categoryID uuid.NullUUID
+	if err := tx.QueryRowContext(ctx, vendorExpenseCategory, payment.VendorID).Scan(&vendorName, &categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !categoryID.Valid {
+		return errors.New("no expense category to book the vendor payment under")
+	}
+
+	description := "Pago a proveedor - " + vendorName
+	if payment.Description != nil && *payment.Description != "" {
+		description += ": " + *payment.Description
+	}
+	method := payment.PaymentMethod
+	source := models.LedgerSourceVendorPayment
+	return postLedgerTx(ctx, tx, &models.FinancialRecord{
+		EventID:         payment.EventID,
+		CategoryID:      categoryID.UUID,
+		Type:            "expense",
+		Amount:          payment.Amount,
+		Currency:        payment.Currency,
+		Description:     description,
+		ReferenceNumber: payment.ReferenceNumber,
+		PaymentMethod:   &method,
+		RecordedBy:      payment.RecordedBy,
+		RecordDate:      payment.PaymentDate,
+		SourceType:      &source,
+		SourceID:        &payment.ID,
+	})
+}
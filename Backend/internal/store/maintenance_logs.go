@@ -19,8 +19,8 @@ type MaintenanceLogsStore struct {
 func (s *MaintenanceLogsStore) Create(ctx context.Context, log *models.ArticleMaintenanceLog) error {
 	query := `
 		INSERT INTO article_maintenance_logs (id, article_id, variant_id, maintenance_type, status,
-		                                     description, performed_by, performed_at, next_maintenance_due, cost, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		                                     description, performed_by, performed_at, next_maintenance_due, cost, created_by, event_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING created_at, updated_at`
 
 	if log.ID == uuid.Nil {
@@ -28,7 +28,7 @@ func (s *MaintenanceLogsStore) Create(ctx context.Context, log *models.ArticleMa
 	}
 	return s.db.QueryRowContext(ctx, query,
 		log.ID, log.ArticleID, log.VariantID, log.MaintenanceType, log.Status,
-		log.Description, log.PerformedBy, log.PerformedAt, log.NextMaintenanceDue, log.Cost, log.CreatedBy,
+		log.Description, log.PerformedBy, log.PerformedAt, log.NextMaintenanceDue, log.Cost, log.CreatedBy, log.EventID,
 	).Scan(&log.CreatedAt, &log.UpdatedAt)
 }
 
@@ -37,7 +37,7 @@ func (s *MaintenanceLogsStore) GetAll(ctx context.Context, status, maintType str
 		SELECT ml.id, ml.article_id, ml.variant_id, ml.maintenance_type, ml.status,
 		       COALESCE(ml.description, ''), COALESCE(ml.performed_by, ''), ml.performed_at,
 		       ml.next_maintenance_due, ml.cost, ml.created_at, ml.updated_at, ml.created_by,
-		       a.name_template
+		       a.name_template, ml.event_id, ml.stock_held
 		FROM article_maintenance_logs ml
 		JOIN articles a ON ml.article_id = a.id
 		WHERE 1=1`
@@ -69,10 +69,11 @@ func (s *MaintenanceLogsStore) GetAll(ctx context.Context, status, maintType str
 		var performedAt, nextDue sql.NullTime
 		var cost sql.NullFloat64
 		var articleName sql.NullString
+		var eventID uuid.NullUUID
 
 		if err := rows.Scan(
 			&log.ID, &log.ArticleID, &variantID, &log.MaintenanceType, &log.Status,
-			&desc, &performedBy, &performedAt, &nextDue, &cost, &log.CreatedAt, &log.UpdatedAt, &createdBy, &articleName,
+			&desc, &performedBy, &performedAt, &nextDue, &cost, &log.CreatedAt, &log.UpdatedAt, &createdBy, &articleName, &eventID, &log.StockHeld,
 		); err != nil {
 			return nil, err
 		}
@@ -95,6 +96,9 @@ func (s *MaintenanceLogsStore) GetAll(ctx context.Context, status, maintType str
 		if cost.Valid {
 			log.Cost = &cost.Float64
 		}
+		if eventID.Valid {
+			log.EventID = &eventID.UUID
+		}
 		logs = append(logs, log)
 	}
 	return logs, nil
@@ -104,7 +108,7 @@ func (s *MaintenanceLogsStore) GetByArticleID(ctx context.Context, articleID uui
 	query := `
 		SELECT id, article_id, variant_id, maintenance_type, status,
 		       COALESCE(description, ''), COALESCE(performed_by, ''), performed_at,
-		       next_maintenance_due, cost, created_at, updated_at, created_by
+		       next_maintenance_due, cost, created_at, updated_at, created_by, event_id, stock_held
 		FROM article_maintenance_logs
 		WHERE article_id = $1
 		ORDER BY created_at DESC`
@@ -122,10 +126,11 @@ func (s *MaintenanceLogsStore) GetByArticleID(ctx context.Context, articleID uui
 		var desc, performedBy sql.NullString
 		var performedAt, nextDue sql.NullTime
 		var cost sql.NullFloat64
+		var eventID uuid.NullUUID
 
 		if err := rows.Scan(
 			&log.ID, &log.ArticleID, &variantID, &log.MaintenanceType, &log.Status,
-			&desc, &performedBy, &performedAt, &nextDue, &cost, &log.CreatedAt, &log.UpdatedAt, &createdBy,
+			&desc, &performedBy, &performedAt, &nextDue, &cost, &log.CreatedAt, &log.UpdatedAt, &createdBy, &eventID, &log.StockHeld,
 		); err != nil {
 			return nil, err
 		}
@@ -148,6 +153,9 @@ func (s *MaintenanceLogsStore) GetByArticleID(ctx context.Context, articleID uui
 		if cost.Valid {
 			log.Cost = &cost.Float64
 		}
+		if eventID.Valid {
+			log.EventID = &eventID.UUID
+		}
 		logs = append(logs, log)
 	}
 	return logs, nil
@@ -200,23 +208,43 @@ func (s *MaintenanceLogsStore) GetOverdue(ctx context.Context) ([]models.Article
 	return logs, nil
 }
 
+// Update saves the log's progress. Completing a log that took units out of
+// stock, such as the repair of items damaged at an event, gives them back.
 func (s *MaintenanceLogsStore) Update(ctx context.Context, log *models.ArticleMaintenanceLog) error {
-	query := `
-		UPDATE article_maintenance_logs
-		SET status = $2, performed_by = $3, performed_at = $4,
-		    next_maintenance_due = $5, cost = $6, updated_at = NOW()
-		WHERE id = $1`
-	_, err := s.db.ExecContext(ctx, query,
-		log.ID, log.Status, log.PerformedBy, log.PerformedAt, log.NextMaintenanceDue, log.Cost,
-	)
-	return err
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE article_maintenance_logs
+			SET status = $2, performed_by = $3, performed_at = $4,
+			    next_maintenance_due = $5, cost = $6, updated_at = NOW()
+			WHERE id = $1`
+		_, err := tx.ExecContext(ctx, query,
+			log.ID, log.Status, log.PerformedBy, log.PerformedAt, log.NextMaintenanceDue, log.Cost,
+		)
+		if err != nil || log.Status != models.MaintenanceStatusCompleted {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			WITH released AS (
+				UPDATE article_maintenance_logs ml
+				SET stock_held = 0
+				FROM (SELECT id, stock_held FROM article_maintenance_logs WHERE id = $1 FOR UPDATE) prev
+				WHERE ml.id = prev.id AND prev.stock_held > 0
+				RETURNING ml.variant_id, prev.stock_held
+			)
+			UPDATE article_variants v
+			SET stock = COALESCE(v.stock, 0) + released.stock_held
+			FROM released
+			WHERE v.id = released.variant_id`, log.ID)
+		return err
+	})
 }
 
 func (s *MaintenanceLogsStore) GetByID(ctx context.Context, id uuid.UUID) (*models.ArticleMaintenanceLog, error) {
 	query := `
 		SELECT id, article_id, variant_id, maintenance_type, status,
 		       COALESCE(description, ''), COALESCE(performed_by, ''), performed_at,
-		       next_maintenance_due, cost, created_at, updated_at, created_by
+		       next_maintenance_due, cost, created_at, updated_at, created_by, event_id, stock_held
 		FROM article_maintenance_logs WHERE id = $1`
 
 	var log models.ArticleMaintenanceLog
@@ -224,10 +252,11 @@ func (s *MaintenanceLogsStore) GetByID(ctx context.Context, id uuid.UUID) (*mode
 	var desc, performedBy sql.NullString
 	var performedAt, nextDue sql.NullTime
 	var cost sql.NullFloat64
+	var eventID uuid.NullUUID
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&log.ID, &log.ArticleID, &variantID, &log.MaintenanceType, &log.Status,
-		&desc, &performedBy, &performedAt, &nextDue, &cost, &log.CreatedAt, &log.UpdatedAt, &createdBy,
+		&desc, &performedBy, &performedAt, &nextDue, &cost, &log.CreatedAt, &log.UpdatedAt, &createdBy, &eventID, &log.StockHeld,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -254,6 +283,9 @@ func (s *MaintenanceLogsStore) GetByID(ctx context.Context, id uuid.UUID) (*mode
 	if cost.Valid {
 		log.Cost = &cost.Float64
 	}
+	if eventID.Valid {
+		log.EventID = &eventID.UUID
+	}
 	return &log, nil
 }
 
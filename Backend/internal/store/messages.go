@@ -15,23 +15,35 @@ type MessagesStore struct {
 
 // Create inserts a new message into the database.
 func (s *MessagesStore) Create(ctx context.Context, msg *models.EventMessage) error {
+	return createEventMessage(ctx, s.db, msg)
+}
+
+// createEventMessage inserts msg with db, which may be a transaction.
+func createEventMessage(ctx context.Context, db interface {
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+}, msg *models.EventMessage) error {
+	if msg.Channel == "" {
+		msg.Channel = models.MessageChannelApp
+	}
+
 	query := `
-		INSERT INTO event_messages (event_id, sender_id, content)
-		VALUES ($1, $2, $3)
+		INSERT INTO event_messages (event_id, sender_id, content, channel)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at
 	`
 
-	return s.db.QueryRowContext(ctx, query,
+	return db.QueryRowContext(ctx, query,
 		msg.EventID,
 		msg.SenderID,
 		msg.Content,
+		msg.Channel,
 	).Scan(&msg.ID, &msg.CreatedAt)
 }
 
 // GetByEventID retrieves all messages for a specific event, ordered by date.
 func (s *MessagesStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]models.EventMessage, error) {
 	query := `
-		SELECT m.id, m.event_id, m.sender_id, m.content, m.created_at, u.username
+		SELECT m.id, m.event_id, m.sender_id, m.content, m.channel, m.created_at, u.user_name
 		FROM event_messages m
 		JOIN users u ON m.sender_id = u.id
 		WHERE m.event_id = $1
@@ -52,6 +64,7 @@ func (s *MessagesStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]
 			&msg.EventID,
 			&msg.SenderID,
 			&msg.Content,
+			&msg.Channel,
 			&msg.CreatedAt,
 			&msg.SenderName,
 		); err != nil {
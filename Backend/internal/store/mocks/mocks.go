@@ -106,6 +106,34 @@ func (m *UserStore) GetAllClientsForExport(ctx context.Context) ([]models.Client
 	return args.Get(0).([]models.ClientExport), args.Error(1)
 }
 
+func (m *UserStore) Block(ctx context.Context, userID, blockedBy uuid.UUID, until *time.Time, reason string) error {
+	args := m.Called(ctx, userID, blockedBy, until, reason)
+	return args.Error(0)
+}
+
+func (m *UserStore) Unblock(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *UserStore) RevokeTokens(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *UserStore) SetRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	args := m.Called(ctx, userID, roleID)
+	return args.Error(0)
+}
+
+func (m *UserStore) ListStaff(ctx context.Context) ([]models.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
 type ArticlesStore struct {
 	mock.Mock
 }
@@ -175,6 +203,45 @@ func (m *RoleStore) RetrieveByName(ctx context.Context, name string) (*models.Ro
 	return args.Get(0).(*models.Role), args.Error(1)
 }
 
+func (m *RoleStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *RoleStore) List(ctx context.Context) ([]models.Role, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *RoleStore) Permissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *RoleStore) Create(ctx context.Context, role *models.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *RoleStore) Update(ctx context.Context, role *models.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *RoleStore) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 type CategoryStore struct {
 	mock.Mock
 }
@@ -214,8 +281,8 @@ type RefreshTokenStore struct {
 	mock.Mock
 }
 
-func (m *RefreshTokenStore) Create(ctx context.Context, token *models.RefreshToken) error {
-	args := m.Called(ctx, token)
+func (m *RefreshTokenStore) Create(ctx context.Context, token *models.RefreshToken, session *models.Session) error {
+	args := m.Called(ctx, token, session)
 	return args.Error(0)
 }
 
@@ -237,6 +304,86 @@ func (m *RefreshTokenStore) DeleteAllForUser(ctx context.Context, id uuid.UUID)
 	return args.Error(0)
 }
 
+func (m *RefreshTokenStore) Rotate(ctx context.Context, presented string, next *models.RefreshToken, device *models.Session) error {
+	args := m.Called(ctx, presented, next, device)
+	return args.Error(0)
+}
+
+func (m *RefreshTokenStore) ListSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *RefreshTokenStore) SessionExists(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *RefreshTokenStore) DeleteSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+type TwoFactorStore struct {
+	mock.Mock
+}
+
+func (m *TwoFactorStore) Get(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TwoFactor), args.Error(1)
+}
+
+func (m *TwoFactorStore) Enroll(ctx context.Context, userID uuid.UUID, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *TwoFactorStore) Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryCodes []string) error {
+	args := m.Called(ctx, userID, step, recoveryCodes)
+	return args.Error(0)
+}
+
+func (m *TwoFactorStore) Disable(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *TwoFactorStore) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *TwoFactorStore) UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	args := m.Called(ctx, userID, code)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *TwoFactorStore) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []string) error {
+	args := m.Called(ctx, userID, codes)
+	return args.Error(0)
+}
+
+func (m *TwoFactorStore) CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge) error {
+	args := m.Called(ctx, challenge)
+	return args.Error(0)
+}
+
+func (m *TwoFactorStore) AttemptChallenge(ctx context.Context, token string, maxAttempts int) (uuid.UUID, error) {
+	args := m.Called(ctx, token, maxAttempts)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *TwoFactorStore) DeleteChallenge(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
 type EventStore struct {
 	mock.Mock
 }
@@ -280,6 +427,14 @@ func (m *EventStore) GetAll(ctx context.Context) ([]models.Event, error) {
 	return args.Get(0).([]models.Event), args.Error(1)
 }
 
+func (m *EventStore) GetForTriggers(ctx context.Context, from, until time.Time) ([]models.TriggerEvent, error) {
+	args := m.Called(ctx, from, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TriggerEvent), args.Error(1)
+}
+
 func (m *EventStore) Update(ctx context.Context, event *models.Event) error {
 	args := m.Called(ctx, event)
 	return args.Error(0)
@@ -295,6 +450,11 @@ func (m *EventStore) AddItem(ctx context.Context, item *models.EventItem) error
 	return args.Error(0)
 }
 
+func (m *EventStore) AddItemWithReservation(ctx context.Context, item *models.EventItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
 func (m *EventStore) UpdateItemQuantity(ctx context.Context, itemID uuid.UUID, quantity int) error {
 	args := m.Called(ctx, itemID, quantity)
 	return args.Error(0)
@@ -318,8 +478,8 @@ func (m *EventStore) GetDebrief(ctx context.Context, id uuid.UUID) (*models.Even
 	return args.Get(0).(*models.EventDebrief), args.Error(1)
 }
 
-func (m *EventStore) ApproveQuote(ctx context.Context, eventID, userID uuid.UUID) error {
-	args := m.Called(ctx, eventID, userID)
+func (m *EventStore) ApproveQuote(ctx context.Context, eventID, userID uuid.UUID, total int, schedule []models.ScheduledInstallment) error {
+	args := m.Called(ctx, eventID, userID, total, schedule)
 	return args.Error(0)
 }
 
@@ -505,246 +665,1046 @@ func (m *StatsStore) GetSummary(ctx context.Context) (*models.AdminStats, error)
 	return args.Get(0).(*models.AdminStats), args.Error(1)
 }
 
-type MessagesStore struct {
+type AnalyticsStore struct {
 	mock.Mock
 }
 
-func (m *MessagesStore) Create(ctx context.Context, msg *models.EventMessage) error {
-	args := m.Called(ctx, msg)
-	return args.Error(0)
+func (m *AnalyticsStore) EventVolume(ctx context.Context, filter models.AnalyticsFilter) ([]models.EventVolumePoint, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventVolumePoint), args.Error(1)
 }
 
-func (m *MessagesStore) GetByEventID(ctx context.Context, id uuid.UUID) ([]models.EventMessage, error) {
-	args := m.Called(ctx, id)
-	return args.Get(0).([]models.EventMessage), args.Error(1)
+func (m *AnalyticsStore) Revenue(ctx context.Context, filter models.AnalyticsFilter) ([]models.RevenuePoint, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RevenuePoint), args.Error(1)
 }
 
-type FavoritesStore struct {
-	mock.Mock
+func (m *AnalyticsStore) TopArticles(ctx context.Context, filter models.AnalyticsFilter, limit int) ([]models.TopArticle, error) {
+	args := m.Called(ctx, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TopArticle), args.Error(1)
 }
 
-func (m *FavoritesStore) List(ctx context.Context, userID uuid.UUID) ([]models.Article, error) {
-	args := m.Called(ctx, userID)
+func (m *AnalyticsStore) Conversion(ctx context.Context, filter models.AnalyticsFilter) (*models.ConversionStats, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.Article), args.Error(1)
+	return args.Get(0).(*models.ConversionStats), args.Error(1)
 }
 
-func (m *FavoritesStore) Add(ctx context.Context, userID, articleID uuid.UUID) error {
-	args := m.Called(ctx, userID, articleID)
-	return args.Error(0)
+func (m *AnalyticsStore) PendingPayments(ctx context.Context, filter models.AnalyticsFilter) ([]models.PendingPayment, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PendingPayment), args.Error(1)
 }
 
-func (m *FavoritesStore) Remove(ctx context.Context, userID, articleID uuid.UUID) error {
-	args := m.Called(ctx, userID, articleID)
-	return args.Error(0)
+func (m *AnalyticsStore) LeadFunnel(ctx context.Context, filter models.AnalyticsFilter) (*models.LeadFunnel, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LeadFunnel), args.Error(1)
 }
 
-func (m *FavoritesStore) IsFavorite(ctx context.Context, userID, articleID uuid.UUID) (bool, error) {
-	args := m.Called(ctx, userID, articleID)
-	return args.Bool(0), args.Error(1)
+func (m *AnalyticsStore) MaintenanceCosts(ctx context.Context, filter models.AnalyticsFilter) ([]models.MaintenanceCost, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.MaintenanceCost), args.Error(1)
 }
 
-type CompanyReviewsStore struct {
-	mock.Mock
+func (m *AnalyticsStore) EventProfits(ctx context.Context, filter models.AnalyticsFilter) ([]models.EventProfit, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventProfit), args.Error(1)
 }
 
-func (m *CompanyReviewsStore) Create(ctx context.Context, review *models.CompanyReview) error {
-	args := m.Called(ctx, review)
-	return args.Error(0)
+func (m *AnalyticsStore) EventProfit(ctx context.Context, eventID uuid.UUID) (*models.EventProfit, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EventProfit), args.Error(1)
 }
 
-func (m *CompanyReviewsStore) GetAll(ctx context.Context) ([]models.CompanyReview, error) {
+type DeliveryZonesStore struct {
+	mock.Mock
+}
+
+func (m *DeliveryZonesStore) GetAll(ctx context.Context) ([]models.DeliveryZone, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.CompanyReview), args.Error(1)
+	return args.Get(0).([]models.DeliveryZone), args.Error(1)
 }
 
-func (m *CompanyReviewsStore) GetSummary(ctx context.Context) (float64, int, error) {
-	args := m.Called(ctx)
-	return args.Get(0).(float64), args.Int(1), args.Error(2)
+func (m *DeliveryZonesStore) GetByID(ctx context.Context, id uuid.UUID) (*models.DeliveryZone, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeliveryZone), args.Error(1)
 }
 
-type EventReviewsStore struct {
-	mock.Mock
+func (m *DeliveryZonesStore) ReplaceAll(ctx context.Context, zones []models.DeliveryZonePayload) ([]models.DeliveryZone, error) {
+	args := m.Called(ctx, zones)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DeliveryZone), args.Error(1)
 }
 
-func (m *EventReviewsStore) Create(ctx context.Context, review *models.EventReview) error {
-	args := m.Called(ctx, review)
-	return args.Error(0)
+type PaymentPlansStore struct {
+	mock.Mock
 }
 
-func (m *EventReviewsStore) GetByEventID(ctx context.Context, id uuid.UUID) ([]models.EventReview, error) {
-	args := m.Called(ctx, id)
+func (m *PaymentPlansStore) List(ctx context.Context) ([]models.PaymentPlan, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.EventReview), args.Error(1)
+	return args.Get(0).([]models.PaymentPlan), args.Error(1)
 }
 
-func (m *EventReviewsStore) GetSummary(ctx context.Context, id uuid.UUID) (float64, int, error) {
+func (m *PaymentPlansStore) GetByID(ctx context.Context, id uuid.UUID) (*models.PaymentPlan, error) {
 	args := m.Called(ctx, id)
-	return args.Get(0).(float64), args.Int(1), args.Error(2)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentPlan), args.Error(1)
 }
 
-func (m *EventReviewsStore) AddPhoto(ctx context.Context, reviewID uuid.UUID, photoURL string, caption string, sortOrder int) error {
-	args := m.Called(ctx, reviewID, photoURL, caption, sortOrder)
-	return args.Error(0)
+func (m *PaymentPlansStore) ForEvent(ctx context.Context, eventID uuid.UUID) (*models.PaymentPlan, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentPlan), args.Error(1)
 }
 
-func (m *EventReviewsStore) GetPhotos(ctx context.Context, reviewID uuid.UUID) ([]models.ReviewPhoto, error) {
-	args := m.Called(ctx, reviewID)
+func (m *PaymentPlansStore) Create(ctx context.Context, payload *models.PaymentPlanPayload) (*models.PaymentPlan, error) {
+	args := m.Called(ctx, payload)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.ReviewPhoto), args.Error(1)
+	return args.Get(0).(*models.PaymentPlan), args.Error(1)
 }
 
-type NotificationLogsStore struct {
-	mock.Mock
+func (m *PaymentPlansStore) Update(ctx context.Context, id uuid.UUID, payload *models.PaymentPlanPayload) (*models.PaymentPlan, error) {
+	args := m.Called(ctx, id, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentPlan), args.Error(1)
 }
 
-func (m *NotificationLogsStore) LogNotification(ctx context.Context, eventID uuid.UUID, notificationType models.NotificationType) error {
-	args := m.Called(ctx, eventID, notificationType)
+func (m *PaymentPlansStore) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *NotificationLogsStore) HasNotificationBeenSent(ctx context.Context, eventID uuid.UUID, notificationType models.NotificationType) (bool, error) {
-	args := m.Called(ctx, eventID, notificationType)
-	return args.Bool(0), args.Error(1)
+func (m *PaymentPlansStore) AssignToEvent(ctx context.Context, eventID uuid.UUID, planID *uuid.UUID) error {
+	args := m.Called(ctx, eventID, planID)
+	return args.Error(0)
 }
 
-type PostsStore struct {
-	mock.Mock
+func (m *PaymentPlansStore) AssignToEventType(ctx context.Context, eventTypeID uuid.UUID, planID *uuid.UUID) error {
+	args := m.Called(ctx, eventTypeID, planID)
+	return args.Error(0)
 }
 
-func (m *PostsStore) Create(ctx context.Context, post *models.Post) error {
-	args := m.Called(ctx, post)
-	return args.Error(0)
+type PaymentMethodsStore struct {
+	mock.Mock
 }
 
-func (m *PostsStore) RetrieveById(ctx context.Context, id uuid.UUID) (*models.Post, error) {
-	args := m.Called(ctx, id)
+func (m *PaymentMethodsStore) List(ctx context.Context, enabledOnly bool) ([]models.PaymentMethod, error) {
+	args := m.Called(ctx, enabledOnly)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.Post), args.Error(1)
-}
-
-func (m *PostsStore) Update(ctx context.Context, post *models.Post) error {
-	args := m.Called(ctx, post)
-	return args.Error(0)
+	return args.Get(0).([]models.PaymentMethod), args.Error(1)
 }
 
-func (m *PostsStore) Delete(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
+func (m *PaymentMethodsStore) GetByCode(ctx context.Context, code string) (*models.PaymentMethod, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentMethod), args.Error(1)
 }
 
-func (m *PostsStore) GetUserFeed(ctx context.Context, userID uuid.UUID, fq models.PaginatedFeedQueryModel) ([]models.PostWithMetadata, error) {
-	args := m.Called(ctx, userID, fq)
+func (m *PaymentMethodsStore) ReplaceAll(ctx context.Context, methods []models.PaymentMethodPayload) ([]models.PaymentMethod, error) {
+	args := m.Called(ctx, methods)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.PostWithMetadata), args.Error(1)
+	return args.Get(0).([]models.PaymentMethod), args.Error(1)
 }
 
-type CommentsStore struct {
+type FinancialStore struct {
 	mock.Mock
 }
 
-func (m *CommentsStore) CreatePostComment(ctx context.Context, comment *models.Comment) error {
-	args := m.Called(ctx, comment)
+func (m *FinancialStore) CreateFinancialCategory(ctx context.Context, cat *models.FinancialCategory) error {
+	args := m.Called(ctx, cat)
 	return args.Error(0)
 }
 
-func (m *CommentsStore) RetrieveCommentsByPostId(ctx context.Context, postID uuid.UUID) ([]models.Comment, error) {
-	args := m.Called(ctx, postID)
+func (m *FinancialStore) GetAllFinancialCategories(ctx context.Context) ([]models.FinancialCategory, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.Comment), args.Error(1)
-}
-
-type EventPhotosStore struct {
-	mock.Mock
+	return args.Get(0).([]models.FinancialCategory), args.Error(1)
 }
 
-func (m *EventPhotosStore) Create(ctx context.Context, photo *models.EventPhoto) error {
-	args := m.Called(ctx, photo)
+func (m *FinancialStore) CreateFinancialRecord(ctx context.Context, rec *models.FinancialRecord) error {
+	args := m.Called(ctx, rec)
 	return args.Error(0)
 }
 
-func (m *EventPhotosStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]models.EventPhoto, error) {
-	args := m.Called(ctx, eventID)
+func (m *FinancialStore) GetFinancialRecords(ctx context.Context, startDate, endDate, recordType, categoryID string) ([]models.FinancialRecord, error) {
+	args := m.Called(ctx, startDate, endDate, recordType, categoryID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.EventPhoto), args.Error(1)
+	return args.Get(0).([]models.FinancialRecord), args.Error(1)
 }
 
-func (m *EventPhotosStore) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *FinancialStore) GetFinancialSummary(ctx context.Context, startDate, endDate string) (*models.FinancialSummary, error) {
+	args := m.Called(ctx, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FinancialSummary), args.Error(1)
+}
+
+func (m *FinancialStore) GetTotalsByPeriod(ctx context.Context, granularity string, from, to time.Time) ([]models.FinancialPeriodTotal, error) {
+	args := m.Called(ctx, granularity, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FinancialPeriodTotal), args.Error(1)
+}
+
+func (m *FinancialStore) GetIncomeByCategory(ctx context.Context, startDate, endDate string) (map[string]float64, error) {
+	args := m.Called(ctx, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *FinancialStore) GetExpensesByCategory(ctx context.Context, startDate, endDate string) (map[string]float64, error) {
+	args := m.Called(ctx, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *FinancialStore) ReconcileRecord(ctx context.Context, recordID uuid.UUID) error {
+	args := m.Called(ctx, recordID)
+	return args.Error(0)
+}
+
+func (m *FinancialStore) CreateInvoice(ctx context.Context, inv *models.Invoice) error {
+	args := m.Called(ctx, inv)
+	return args.Error(0)
+}
+
+func (m *FinancialStore) GetInvoices(ctx context.Context, clientID, status string) ([]models.Invoice, error) {
+	args := m.Called(ctx, clientID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Invoice), args.Error(1)
+}
+
+func (m *FinancialStore) GetInvoice(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invoice), args.Error(1)
+}
+
+func (m *FinancialStore) UpdateInvoiceStatus(ctx context.Context, id uuid.UUID, status string, reason *string) (*models.Invoice, error) {
+	args := m.Called(ctx, id, status, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invoice), args.Error(1)
+}
+
+func (m *FinancialStore) CreateExpenseVendor(ctx context.Context, vendor *models.ExpenseVendor) error {
+	args := m.Called(ctx, vendor)
+	return args.Error(0)
+}
+
+func (m *FinancialStore) GetExpenseVendors(ctx context.Context) ([]models.ExpenseVendor, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ExpenseVendor), args.Error(1)
+}
+
+func (m *FinancialStore) CreateVendorPayment(ctx context.Context, payment *models.VendorPayment) error {
+	args := m.Called(ctx, payment)
+	return args.Error(0)
+}
+
+func (m *FinancialStore) GetVendorPayments(ctx context.Context, vendorID uuid.UUID) ([]models.VendorPayment, error) {
+	args := m.Called(ctx, vendorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.VendorPayment), args.Error(1)
+}
+
+type PayPalStore struct {
+	mock.Mock
+}
+
+func (m *PayPalStore) CreateTransaction(ctx context.Context, tx *models.PayPalTransaction) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+func (m *PayPalStore) CompleteCapture(ctx context.Context, orderID, captureID string, response *string) (bool, error) {
+	args := m.Called(ctx, orderID, captureID, response)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PayPalStore) UpdateTransactionStatus(ctx context.Context, orderID, status string) error {
+	args := m.Called(ctx, orderID, status)
+	return args.Error(0)
+}
+
+func (m *PayPalStore) RecordInstallments(ctx context.Context, transactionID uuid.UUID, installmentIDs []uuid.UUID) error {
+	args := m.Called(ctx, transactionID, installmentIDs)
+	return args.Error(0)
+}
+
+func (m *PayPalStore) Refund(ctx context.Context, orderID string) (bool, error) {
+	args := m.Called(ctx, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PayPalStore) GetTransactionByOrderID(ctx context.Context, orderID string) (*models.PayPalTransaction, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PayPalTransaction), args.Error(1)
+}
+
+func (m *PayPalStore) GetTransactionsByEvent(ctx context.Context, eventID uuid.UUID) ([]models.PayPalTransaction, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PayPalTransaction), args.Error(1)
+}
+
+func (m *PayPalStore) IsWebhookEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	args := m.Called(ctx, eventID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PayPalStore) MarkWebhookEventProcessed(ctx context.Context, eventID, eventType string) error {
+	args := m.Called(ctx, eventID, eventType)
+	return args.Error(0)
+}
+
+type MessagesStore struct {
+	mock.Mock
+}
+
+func (m *MessagesStore) Create(ctx context.Context, msg *models.EventMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MessagesStore) GetByEventID(ctx context.Context, id uuid.UUID) ([]models.EventMessage, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]models.EventMessage), args.Error(1)
+}
+
+type FavoritesStore struct {
+	mock.Mock
+}
+
+func (m *FavoritesStore) List(ctx context.Context, userID uuid.UUID) ([]models.Article, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Article), args.Error(1)
+}
+
+func (m *FavoritesStore) Add(ctx context.Context, userID, articleID uuid.UUID) error {
+	args := m.Called(ctx, userID, articleID)
+	return args.Error(0)
+}
+
+func (m *FavoritesStore) Remove(ctx context.Context, userID, articleID uuid.UUID) error {
+	args := m.Called(ctx, userID, articleID)
+	return args.Error(0)
+}
+
+func (m *FavoritesStore) IsFavorite(ctx context.Context, userID, articleID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, articleID)
+	return args.Bool(0), args.Error(1)
+}
+
+type CompanyReviewsStore struct {
+	mock.Mock
+}
+
+func (m *CompanyReviewsStore) Create(ctx context.Context, review *models.CompanyReview) error {
+	args := m.Called(ctx, review)
+	return args.Error(0)
+}
+
+func (m *CompanyReviewsStore) GetAll(ctx context.Context) ([]models.CompanyReview, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CompanyReview), args.Error(1)
+}
+
+func (m *CompanyReviewsStore) GetSummary(ctx context.Context) (float64, int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(float64), args.Int(1), args.Error(2)
+}
+
+type EventReviewsStore struct {
+	mock.Mock
+}
+
+func (m *EventReviewsStore) Create(ctx context.Context, review *models.EventReview) error {
+	args := m.Called(ctx, review)
+	return args.Error(0)
+}
+
+func (m *EventReviewsStore) GetByEventID(ctx context.Context, id uuid.UUID) ([]models.EventReview, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventReview), args.Error(1)
+}
+
+func (m *EventReviewsStore) GetSummary(ctx context.Context, id uuid.UUID) (float64, int, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(float64), args.Int(1), args.Error(2)
+}
+
+func (m *EventReviewsStore) AddPhoto(ctx context.Context, reviewID uuid.UUID, photoURL string, caption string, sortOrder int) error {
+	args := m.Called(ctx, reviewID, photoURL, caption, sortOrder)
+	return args.Error(0)
+}
+
+func (m *EventReviewsStore) GetPhotos(ctx context.Context, reviewID uuid.UUID) ([]models.ReviewPhoto, error) {
+	args := m.Called(ctx, reviewID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ReviewPhoto), args.Error(1)
+}
+
+type NotificationLogsStore struct {
+	mock.Mock
+}
+
+func (m *NotificationLogsStore) LogNotification(ctx context.Context, eventID uuid.UUID, notificationType models.NotificationType) error {
+	args := m.Called(ctx, eventID, notificationType)
+	return args.Error(0)
+}
+
+func (m *NotificationLogsStore) HasNotificationBeenSent(ctx context.Context, eventID uuid.UUID, notificationType models.NotificationType) (bool, error) {
+	args := m.Called(ctx, eventID, notificationType)
+	return args.Bool(0), args.Error(1)
+}
+
+type PostsStore struct {
+	mock.Mock
+}
+
+func (m *PostsStore) Create(ctx context.Context, post *models.Post) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+func (m *PostsStore) RetrieveById(ctx context.Context, id uuid.UUID) (*models.Post, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *PostsStore) Update(ctx context.Context, post *models.Post) error {
+	args := m.Called(ctx, post)
+	return args.Error(0)
+}
+
+func (m *PostsStore) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *PostsStore) GetUserFeed(ctx context.Context, userID uuid.UUID, fq models.PaginatedFeedQueryModel) ([]models.PostWithMetadata, error) {
+	args := m.Called(ctx, userID, fq)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PostWithMetadata), args.Error(1)
+}
+
+type CommentsStore struct {
+	mock.Mock
+}
+
+func (m *CommentsStore) CreatePostComment(ctx context.Context, comment *models.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *CommentsStore) RetrieveCommentsByPostId(ctx context.Context, postID uuid.UUID) ([]models.Comment, error) {
+	args := m.Called(ctx, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Comment), args.Error(1)
+}
+
+type EventPhotosStore struct {
+	mock.Mock
+}
+
+func (m *EventPhotosStore) Create(ctx context.Context, photo *models.EventPhoto) error {
+	args := m.Called(ctx, photo)
+	return args.Error(0)
+}
+
+func (m *EventPhotosStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]models.EventPhoto, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventPhoto), args.Error(1)
+}
+
+func (m *EventPhotosStore) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type AuditLogsStore struct {
+	mock.Mock
+}
+
+func (m *AuditLogsStore) Log(ctx context.Context, log *models.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *AuditLogsStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]models.AuditLogWithUser, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AuditLogWithUser), args.Error(1)
+}
+
+type InstallmentsStore struct {
+	mock.Mock
+}
+
+func (m *InstallmentsStore) CreateInstallmentPayment(ctx context.Context, eventID uuid.UUID, amount int, dueDate *time.Time) (*models.InstallmentPayment, error) {
+	args := m.Called(ctx, eventID, amount, dueDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) GetInstallmentByEventID(ctx context.Context, eventID uuid.UUID) ([]models.InstallmentPayment, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) MarkPaid(ctx context.Context, paymentID uuid.UUID, paymentMethod string) (*models.InstallmentPayment, error) {
+	args := m.Called(ctx, paymentID, paymentMethod)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) CreateSchedule(ctx context.Context, eventID uuid.UUID, schedule []models.ScheduledInstallment) ([]models.InstallmentPayment, error) {
+	args := m.Called(ctx, eventID, schedule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) SubmitReceipt(ctx context.Context, paymentID uuid.UUID, paymentMethod, receiptURL string) (*models.InstallmentPayment, error) {
+	args := m.Called(ctx, paymentID, paymentMethod, receiptURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) RejectPayment(ctx context.Context, paymentID, adminID uuid.UUID, reason string) (*models.InstallmentPayment, error) {
+	args := m.Called(ctx, paymentID, adminID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) ListAwaitingVerification(ctx context.Context) ([]models.PaymentVerification, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PaymentVerification), args.Error(1)
+}
+
+func (m *InstallmentsStore) ConfirmPayment(ctx context.Context, paymentID, adminID uuid.UUID) (*models.InstallmentPayment, error) {
+	args := m.Called(ctx, paymentID, adminID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) GetPendingInstallments(ctx context.Context) ([]models.InstallmentPayment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InstallmentPayment), args.Error(1)
+}
+
+func (m *InstallmentsStore) GetByID(ctx context.Context, paymentID uuid.UUID) (*models.InstallmentPayment, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+}
+
+type EventReturnsStore struct {
+	mock.Mock
+}
+
+func (m *EventReturnsStore) Get(ctx context.Context, eventID uuid.UUID) (*models.EventReturn, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EventReturn), args.Error(1)
+}
+
+func (m *EventReturnsStore) Create(ctx context.Context, ret *models.EventReturn) error {
+	args := m.Called(ctx, ret)
+	return args.Error(0)
+}
+
+type NotificationTriggersStore struct {
+	mock.Mock
+}
+
+func (m *NotificationTriggersStore) GetAll(ctx context.Context) ([]models.NotificationTrigger, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationTrigger), args.Error(1)
+}
+
+func (m *NotificationTriggersStore) GetEnabled(ctx context.Context) ([]models.NotificationTrigger, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationTrigger), args.Error(1)
+}
+
+func (m *NotificationTriggersStore) GetByID(ctx context.Context, id uuid.UUID) (*models.NotificationTrigger, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationTrigger), args.Error(1)
+}
+
+func (m *NotificationTriggersStore) Create(ctx context.Context, trigger *models.NotificationTrigger) error {
+	args := m.Called(ctx, trigger)
+	return args.Error(0)
+}
+
+func (m *NotificationTriggersStore) Update(ctx context.Context, trigger *models.NotificationTrigger) error {
+	args := m.Called(ctx, trigger)
+	return args.Error(0)
+}
+
+func (m *NotificationTriggersStore) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-type AuditLogsStore struct {
+type JobsStore struct {
 	mock.Mock
 }
 
-func (m *AuditLogsStore) Log(ctx context.Context, log *models.AuditLog) error {
-	args := m.Called(ctx, log)
+func (m *JobsStore) Enqueue(ctx context.Context, job *models.Job) (bool, error) {
+	args := m.Called(ctx, job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *JobsStore) Claim(ctx context.Context, workerID string, kinds []string, lease time.Duration) (*models.Job, error) {
+	args := m.Called(ctx, workerID, kinds, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *JobsStore) Complete(ctx context.Context, id uuid.UUID, workerID string) error {
+	args := m.Called(ctx, id, workerID)
 	return args.Error(0)
 }
 
-func (m *AuditLogsStore) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]models.AuditLogWithUser, error) {
-	args := m.Called(ctx, eventID)
+func (m *JobsStore) Fail(ctx context.Context, id uuid.UUID, workerID, errMsg string, retryAt *time.Time) error {
+	args := m.Called(ctx, id, workerID, errMsg, retryAt)
+	return args.Error(0)
+}
+
+func (m *JobsStore) List(ctx context.Context, status string, limit int) ([]models.Job, error) {
+	args := m.Called(ctx, status, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.AuditLogWithUser), args.Error(1)
+	return args.Get(0).([]models.Job), args.Error(1)
 }
 
-type InstallmentsStore struct {
+func (m *JobsStore) Retry(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *JobsStore) DeleteSucceededBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type WhatsAppStore struct {
 	mock.Mock
 }
 
-func (m *InstallmentsStore) CreateInstallmentPayment(ctx context.Context, eventID uuid.UUID, amount int, dueDate *time.Time) (*models.InstallmentPayment, error) {
-	args := m.Called(ctx, eventID, amount, dueDate)
+func (m *WhatsAppStore) Contact(ctx context.Context, phone string) (*models.WhatsAppContact, error) {
+	args := m.Called(ctx, phone)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+	return args.Get(0).(*models.WhatsAppContact), args.Error(1)
 }
 
-func (m *InstallmentsStore) GetInstallmentByEventID(ctx context.Context, eventID uuid.UUID) ([]models.InstallmentPayment, error) {
-	args := m.Called(ctx, eventID)
+func (m *WhatsAppStore) SaveInbound(ctx context.Context, msg *models.WhatsAppMessage) (bool, error) {
+	args := m.Called(ctx, msg)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *WhatsAppStore) SaveOutbound(ctx context.Context, msg *models.WhatsAppMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *WhatsAppStore) UpdateStatus(ctx context.Context, waMessageID, status string) error {
+	args := m.Called(ctx, waMessageID, status)
+	return args.Error(0)
+}
+
+func (m *WhatsAppStore) ListConversations(ctx context.Context, limit int) ([]models.WhatsAppConversation, error) {
+	args := m.Called(ctx, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.InstallmentPayment), args.Error(1)
+	return args.Get(0).([]models.WhatsAppConversation), args.Error(1)
 }
 
-func (m *InstallmentsStore) MarkPaid(ctx context.Context, paymentID uuid.UUID, paymentMethod string) error {
-	args := m.Called(ctx, paymentID, paymentMethod)
+func (m *WhatsAppStore) GetThread(ctx context.Context, phone string, limit int) ([]models.WhatsAppMessage, error) {
+	args := m.Called(ctx, phone, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WhatsAppMessage), args.Error(1)
+}
+
+type ChatbotStore struct {
+	mock.Mock
+}
+
+func (m *ChatbotStore) ListFAQs(ctx context.Context, category string, activeOnly bool) ([]models.FAQ, error) {
+	args := m.Called(ctx, category, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FAQ), args.Error(1)
+}
+
+func (m *ChatbotStore) GetFAQByID(ctx context.Context, id uuid.UUID) (*models.FAQ, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FAQ), args.Error(1)
+}
+
+func (m *ChatbotStore) CreateFAQ(ctx context.Context, faq *models.FAQ) error {
+	args := m.Called(ctx, faq)
 	return args.Error(0)
 }
 
-func (m *InstallmentsStore) GetPendingInstallments(ctx context.Context) ([]models.InstallmentPayment, error) {
+func (m *ChatbotStore) UpdateFAQ(ctx context.Context, faq *models.FAQ) error {
+	args := m.Called(ctx, faq)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) DeleteFAQ(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) ListIntents(ctx context.Context, activeOnly bool) ([]models.ChatbotIntent, error) {
+	args := m.Called(ctx, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ChatbotIntent), args.Error(1)
+}
+
+func (m *ChatbotStore) GetIntentByID(ctx context.Context, id uuid.UUID) (*models.ChatbotIntent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatbotIntent), args.Error(1)
+}
+
+func (m *ChatbotStore) CreateIntent(ctx context.Context, intent *models.ChatbotIntent) error {
+	args := m.Called(ctx, intent)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) UpdateIntent(ctx context.Context, intent *models.ChatbotIntent) error {
+	args := m.Called(ctx, intent)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) DeleteIntent(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) ListSynonyms(ctx context.Context) ([]models.ChatbotSynonym, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.InstallmentPayment), args.Error(1)
+	return args.Get(0).([]models.ChatbotSynonym), args.Error(1)
 }
 
-func (m *InstallmentsStore) GetByID(ctx context.Context, paymentID uuid.UUID) (*models.InstallmentPayment, error) {
-	args := m.Called(ctx, paymentID)
+func (m *ChatbotStore) UpsertSynonym(ctx context.Context, synonym *models.ChatbotSynonym) error {
+	args := m.Called(ctx, synonym)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) DeleteSynonym(ctx context.Context, term string) error {
+	args := m.Called(ctx, term)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) GetOrCreateSession(ctx context.Context, key, phone, name string) (*models.ChatbotSession, error) {
+	args := m.Called(ctx, key, phone, name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.InstallmentPayment), args.Error(1)
+	return args.Get(0).(*models.ChatbotSession), args.Error(1)
+}
+
+func (m *ChatbotStore) SaveTurn(ctx context.Context, session *models.ChatbotSession, turn *models.ChatbotTurn) error {
+	args := m.Called(ctx, session, turn)
+	return args.Error(0)
+}
+
+func (m *ChatbotStore) ListTurns(ctx context.Context, sessionKey string, limit int) ([]models.ChatbotTurn, error) {
+	args := m.Called(ctx, sessionKey, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ChatbotTurn), args.Error(1)
+}
+
+func (m *ChatbotStore) RecordFeedback(ctx context.Context, turnID uuid.UUID, helpful bool) (*models.ChatbotTurn, error) {
+	args := m.Called(ctx, turnID, helpful)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatbotTurn), args.Error(1)
+}
+
+type EventTypesStore struct {
+	mock.Mock
+}
+
+func (m *EventTypesStore) GetAll(ctx context.Context) ([]models.EventType, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventType), args.Error(1)
+}
+
+func (m *EventTypesStore) GetByID(ctx context.Context, id uuid.UUID) (*models.EventType, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EventType), args.Error(1)
+}
+
+func (m *EventTypesStore) Create(ctx context.Context, eventType *models.EventType) error {
+	args := m.Called(ctx, eventType)
+	return args.Error(0)
+}
+
+func (m *EventTypesStore) Update(ctx context.Context, eventType *models.EventType) error {
+	args := m.Called(ctx, eventType)
+	return args.Error(0)
+}
+
+func (m *EventTypesStore) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *EventTypesStore) GetItemsByType(ctx context.Context, eventTypeID uuid.UUID) ([]models.EventTypeItem, error) {
+	args := m.Called(ctx, eventTypeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EventTypeItem), args.Error(1)
+}
+
+func (m *EventTypesStore) SetItems(ctx context.Context, eventTypeID uuid.UUID, items []models.EventTypeItem) error {
+	args := m.Called(ctx, eventTypeID, items)
+	return args.Error(0)
+}
+
+type LeadsStore struct {
+	mock.Mock
+}
+
+func (m *LeadsStore) CreateLead(ctx context.Context, lead *models.Lead) error {
+	args := m.Called(ctx, lead)
+	return args.Error(0)
+}
+
+func (m *LeadsStore) GetLeadByID(ctx context.Context, id uuid.UUID) (*models.Lead, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Lead), args.Error(1)
+}
+
+func (m *LeadsStore) GetLeads(ctx context.Context, status, assignedTo string, limit, offset int) ([]models.Lead, int, error) {
+	args := m.Called(ctx, status, assignedTo, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Lead), args.Int(1), args.Error(2)
+}
+
+func (m *LeadsStore) UpdateLeadStatus(ctx context.Context, id uuid.UUID, status string) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *LeadsStore) AddLeadFollowup(ctx context.Context, followup *models.LeadFollowup) error {
+	args := m.Called(ctx, followup)
+	return args.Error(0)
+}
+
+func (m *LeadsStore) GetLeadFollowups(ctx context.Context, leadID uuid.UUID) ([]models.LeadFollowup, error) {
+	args := m.Called(ctx, leadID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeadFollowup), args.Error(1)
+}
+
+func (m *LeadsStore) CompleteFollowup(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *LeadsStore) LogLeadActivity(ctx context.Context, leadID uuid.UUID, activityType, description string) error {
+	args := m.Called(ctx, leadID, activityType, description)
+	return args.Error(0)
+}
+
+func (m *LeadsStore) GetLeadActivities(ctx context.Context, leadID uuid.UUID) ([]models.LeadActivity, error) {
+	args := m.Called(ctx, leadID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeadActivity), args.Error(1)
+}
+
+func (m *LeadsStore) GetOverdueFollowups(ctx context.Context) ([]models.LeadFollowup, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeadFollowup), args.Error(1)
+}
+
+func (m *LeadsStore) GetLeadsStats(ctx context.Context) (*models.LeadStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LeadStats), args.Error(1)
 }
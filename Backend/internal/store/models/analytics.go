@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Analytics granularities, named after the date_trunc field they use.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// AnalyticsFilter narrows the dashboard to a date range and, optionally, one
+// event type. A zero From or To leaves that end of the range open.
+type AnalyticsFilter struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	EventTypeID *uuid.UUID
+}
+
+// EventVolumePoint counts the events held in one period, by event date.
+// Revenue is the quoted total of the events that were booked.
+type EventVolumePoint struct {
+	Period    time.Time `json:"period"`
+	Events    int       `json:"events"`
+	Booked    int       `json:"booked"`
+	Cancelled int       `json:"cancelled"`
+	Revenue   float64   `json:"revenue"`
+}
+
+// RevenuePoint is the money collected in one period, by payment date.
+// Every payment settles installments, PayPal captures included, so revenue
+// is the paid installments, split by whether PayPal collected them.
+type RevenuePoint struct {
+	Period       time.Time `json:"period"`
+	Installments float64   `json:"installments"`
+	PayPal       float64   `json:"paypal"`
+	Total        float64   `json:"total"`
+}
+
+type TopArticle struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	Name      string    `json:"name"`
+	Rentals   int       `json:"rentals"` // units rented
+	Events    int       `json:"events"`
+	Revenue   float64   `json:"revenue"`
+}
+
+// ConversionStats measures how many quotes sent in the range were approved.
+type ConversionStats struct {
+	QuotesSent int     `json:"quotes_sent"`
+	Approved   int     `json:"approved"`
+	Rejected   int     `json:"rejected"`
+	Pending    int     `json:"pending"`
+	Rate       float64 `json:"rate"`
+}
+
+type PendingPayment struct {
+	InstallmentID uuid.UUID  `json:"installment_id"`
+	EventID       uuid.UUID  `json:"event_id"`
+	EventName     string     `json:"event_name"`
+	Client        string     `json:"client"`
+	Amount        int        `json:"amount"`
+	DueDate       *time.Time `json:"due_date"`
+	Overdue       bool       `json:"overdue"`
+}
+
+// LeadFunnel counts leads created in the range by how far down the sales
+// pipeline they got. A lead counts in its current stage and every stage
+// before it; lost leads only count in Total, since the stage they were lost
+// at isn't recorded.
+type LeadFunnel struct {
+	Total  int           `json:"total"`
+	Lost   int           `json:"lost"`
+	Stages []FunnelStage `json:"stages"`
+}
+
+type FunnelStage struct {
+	Stage string `json:"stage"`
+	Count int    `json:"count"`
+}
+
+// LeadStages are the lead statuses in pipeline order.
+var LeadStages = []string{"new", "contacted", "qualified", "proposal", "negotiating", "won"}
+
+// MaintenanceCost is what completed maintenance of one type cost in the
+// range.
+type MaintenanceCost struct {
+	Type  string  `json:"type"`
+	Count int     `json:"count"`
+	Total float64 `json:"total"`
+}
+
+// Kinds of EventCostLine.
+const (
+	EventCostVendor       = "vendor"
+	EventCostMaintenance  = "maintenance"
+	EventCostDepreciation = "depreciation"
+)
+
+// EventProfit is the profit and loss of one event. Revenue is the quoted
+// total, which already includes DeliveryFees; Collected is what the client
+// has actually paid, surcharges included. Costs are only listed line by
+// line for a single event.
+type EventProfit struct {
+	EventID          uuid.UUID       `json:"event_id"`
+	EventName        string          `json:"event_name"`
+	EventDate        *time.Time      `json:"event_date"`
+	Status           string          `json:"status"`
+	Client           string          `json:"client"`
+	Revenue          float64         `json:"revenue"`
+	DeliveryFees     float64         `json:"delivery_fees"`
+	Collected        float64         `json:"collected"`
+	VendorCosts      float64         `json:"vendor_costs"`
+	MaintenanceCosts float64         `json:"maintenance_costs"`
+	Depreciation     float64         `json:"depreciation"`
+	TotalCosts       float64         `json:"total_costs"`
+	Profit           float64         `json:"profit"`
+	Margin           float64         `json:"margin"`
+	Costs            []EventCostLine `json:"costs,omitempty"`
+}
+
+type EventCostLine struct {
+	Kind        string     `json:"kind"`
+	Description string     `json:"description"`
+	Amount      float64    `json:"amount"`
+	Date        *time.Time `json:"date,omitempty"`
+}
+
+// ComputeTotals derives the totals, profit and margin from the revenue and
+// cost components. An event without revenue has no margin.
+func (p *EventProfit) ComputeTotals() {
+	p.TotalCosts = p.VendorCosts + p.MaintenanceCosts + p.Depreciation
+	p.Profit = p.Revenue - p.TotalCosts
+	p.Margin = 0
+	if p.Revenue > 0 {
+		p.Margin = p.Profit / p.Revenue
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "testing"
+
+func TestEventProfitComputeTotals(t *testing.T) {
+	p := EventProfit{Revenue: 20000, VendorCosts: 3000, MaintenanceCosts: 1500, Depreciation: 500}
+	p.ComputeTotals()
+	if p.TotalCosts != 5000 || p.Profit != 15000 || p.Margin != 0.75 {
+		t.Errorf("unexpected totals %+v", p)
+	}
+
+	loss := EventProfit{VendorCosts: 1000}
+	loss.ComputeTotals()
+	if loss.Profit != -1000 || loss.Margin != 0 {
+		t.Errorf("an event without revenue should have no margin, got %+v", loss)
+	}
+}
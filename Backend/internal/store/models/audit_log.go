@@ -19,6 +19,11 @@ const (
 	AuditActionEventPhotoAdd   AuditAction = "photo_added"
 	AuditActionEventReject     AuditAction = "quote_rejected"
 	AuditActionQuoteApprove    AuditAction = "quote_approved"
+	AuditActionUserBlock       AuditAction = "user_blocked"
+	AuditActionUserUnblock     AuditAction = "user_unblocked"
+	AuditActionUserLogout      AuditAction = "user_logged_out"
+	AuditActionRoleAssign      AuditAction = "role_assigned"
+	AuditActionRoleChange      AuditAction = "role_changed"
 )
 
 // AuditLog represents an entry in the audit trail.
@@ -31,6 +36,7 @@ type AuditLog struct {
 	EntityID   *uuid.UUID  `json:"entity_id,omitempty"`
 	OldValue   *string     `json:"old_value,omitempty"`
 	NewValue   *string     `json:"new_value,omitempty"`
+	Reason     *string     `json:"reason,omitempty"`
 	CreatedAt  time.Time   `json:"created_at"`
 }
 
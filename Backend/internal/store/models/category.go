@@ -7,4 +7,8 @@ type Category struct {
 	ImageURL    *string `json:"image_url,omitempty"`
 	Icon        *string `json:"icon,omitempty"`
 	ParentID    *string `json:"parent_id,omitempty"`
+	// SetupBufferHours and CleaningBufferHours extend every rental of an
+	// item in this category before pickup and after return.
+	SetupBufferHours    int `json:"setup_buffer_hours"`
+	CleaningBufferHours int `json:"cleaning_buffer_hours"`
 }
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FAQ is a stored question the chatbot answers when a message mentions its
+// keyword (or a synonym of it).
+type FAQ struct {
+	ID             uuid.UUID `json:"id"`
+	Keyword        string    `json:"keyword"`
+	Question       string    `json:"question"`
+	Answer         string    `json:"answer"`
+	Category       string    `json:"category"`
+	Priority       int       `json:"priority"`
+	IsActive       bool      `json:"is_active"`
+	Language       string    `json:"language"`
+	HelpfulCount   int       `json:"helpful_count"`
+	UnhelpfulCount int       `json:"unhelpful_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CreateFAQPayload struct {
+	Keyword  string `json:"keyword" validate:"required,max=100"`
+	Question string `json:"question" validate:"required,max=500"`
+	Answer   string `json:"answer" validate:"required,max=2000"`
+	Category string `json:"category" validate:"max=50"`
+	Priority int    `json:"priority"`
+	IsActive *bool  `json:"is_active"`
+	Language string `json:"language" validate:"omitempty,len=2"`
+}
+
+type UpdateFAQPayload struct {
+	Keyword  *string `json:"keyword" validate:"omitempty,max=100"`
+	Question *string `json:"question" validate:"omitempty,max=500"`
+	Answer   *string `json:"answer" validate:"omitempty,max=2000"`
+	Category *string `json:"category" validate:"omitempty,max=50"`
+	Priority *int    `json:"priority"`
+	IsActive *bool   `json:"is_active"`
+	Language *string `json:"language" validate:"omitempty,len=2"`
+}
+
+// ChatbotActionCollectLead makes an intent start asking for the event
+// details and end by creating a lead.
+const ChatbotActionCollectLead = "collect_lead"
+
+// ChatbotIntent is something a client may want, recognised by any of its
+// phrases appearing in a message.
+type ChatbotIntent struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Phrases        []string  `json:"phrases"`
+	Response       string    `json:"response"`
+	Action         string    `json:"action"`
+	Priority       int       `json:"priority"`
+	IsActive       bool      `json:"is_active"`
+	HelpfulCount   int       `json:"helpful_count"`
+	UnhelpfulCount int       `json:"unhelpful_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CreateChatbotIntentPayload struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=500"`
+	Phrases     []string `json:"phrases" validate:"required,min=1,dive,required,max=100"`
+	Response    string   `json:"response" validate:"required,max=2000"`
+	Action      string   `json:"action" validate:"omitempty,oneof=collect_lead"`
+	Priority    int      `json:"priority"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+type UpdateChatbotIntentPayload struct {
+	Name        *string   `json:"name" validate:"omitempty,max=50"`
+	Description *string   `json:"description" validate:"omitempty,max=500"`
+	Phrases     *[]string `json:"phrases" validate:"omitempty,min=1,dive,required,max=100"`
+	Response    *string   `json:"response" validate:"omitempty,max=2000"`
+	Action      *string   `json:"action" validate:"omitempty,oneof='' collect_lead"`
+	Priority    *int      `json:"priority"`
+	IsActive    *bool     `json:"is_active"`
+}
+
+// ChatbotSynonym rewrites a word or phrase to its canonical form before
+// intents and FAQs are matched, e.g. "matrimonio" to "boda".
+type ChatbotSynonym struct {
+	Term      string `json:"term" validate:"required,max=100"`
+	Canonical string `json:"canonical" validate:"required,max=100"`
+}
+
+// Chatbot session states.
+const (
+	ChatbotStateIdle       = "idle"
+	ChatbotStateCollecting = "collecting"
+)
+
+// Slots the chatbot fills before creating a lead.
+const (
+	SlotEventType   = "event_type"
+	SlotEventDate   = "event_date"
+	SlotGuestCount  = "guest_count"
+	SlotClientName  = "client_name"
+	SlotClientPhone = "client_phone"
+)
+
+type ChatbotSlots struct {
+	EventType  string     `json:"event_type,omitempty"`
+	EventDate  *time.Time `json:"event_date,omitempty"`
+	GuestCount int        `json:"guest_count,omitempty"`
+}
+
+// ChatbotSession is the state of one conversation with the chatbot.
+type ChatbotSession struct {
+	ID          uuid.UUID    `json:"id"`
+	SessionKey  string       `json:"session_id"`
+	ClientPhone string       `json:"client_phone,omitempty"`
+	ClientName  string       `json:"client_name,omitempty"`
+	State       string       `json:"state"`
+	PendingSlot string       `json:"pending_slot,omitempty"`
+	Slots       ChatbotSlots `json:"slots"`
+	LeadID      *uuid.UUID   `json:"lead_id,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ChatbotTurn is one message and the answer the chatbot gave.
+type ChatbotTurn struct {
+	ID          uuid.UUID  `json:"id"`
+	SessionKey  string     `json:"session_id"`
+	ClientPhone string     `json:"client_phone,omitempty"`
+	ClientName  string     `json:"client_name,omitempty"`
+	Message     string     `json:"message"`
+	Response    string     `json:"response"`
+	FAQID       *uuid.UUID `json:"faq_id,omitempty"`
+	IntentID    *uuid.UUID `json:"intent_id,omitempty"`
+	WasHelpful  *bool      `json:"was_helpful"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type ChatbotMessagePayload struct {
+	Message    string `json:"message" validate:"required,max=1000"`
+	SessionID  string `json:"session_id" validate:"omitempty,max=100"`
+	Phone      string `json:"phone" validate:"omitempty,max=30"`
+	ClientName string `json:"client_name" validate:"omitempty,max=100"`
+}
+
+type ChatbotFeedbackPayload struct {
+	WasHelpful *bool `json:"was_helpful" validate:"required"`
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// DeliveryZone is a ring around the warehouse. It covers addresses from
+// BaseRadiusKm up to MaxRadiusKm away and charges TravelFee to deliver there.
 type DeliveryZone struct {
 	ID           uuid.UUID `json:"id"`
 	Name         string    `json:"name"`
@@ -13,15 +15,33 @@ type DeliveryZone struct {
 	MaxRadiusKm  float64   `json:"max_radius_km"`
 	TravelFee    float64   `json:"travel_fee"`
 	IsActive     bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DeliveryZonePayload is one zone in the admin's zone list. Zones without an
+// ID are created.
+type DeliveryZonePayload struct {
+	ID           *uuid.UUID `json:"id"`
+	Name         string     `json:"name" validate:"required,max=100"`
+	BaseRadiusKm float64    `json:"base_radius_km" validate:"min=0"`
+	MaxRadiusKm  float64    `json:"max_radius_km" validate:"gtfield=BaseRadiusKm,max=999"`
+	TravelFee    float64    `json:"travel_fee" validate:"min=0"`
+	IsActive     *bool      `json:"is_active"`
 }
 
 type DeliveryFeeRequest struct {
-	Address string `json:"address" validate:"required"`
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
 }
 
+// DeliveryFeeResponse is the fee to deliver to a point. Outside every zone
+// InRange is false and the fee is that of the farthest zone, as a floor for
+// the delivery the team arranges with the client.
 type DeliveryFeeResponse struct {
-	Fee     int    `json:"fee"`
-	Zone    string `json:"zone"`
-	Message string `json:"message"`
-}
\ No newline at end of file
+	Fee        float64 `json:"fee"`
+	Zone       string  `json:"zone"`
+	DistanceKm float64 `json:"distance_km"`
+	InRange    bool    `json:"in_range"`
+	Message    string  `json:"message"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailTemplate is one saved version of an email template edited from the
+// admin panel. Key matches the embedded template file name without its
+// .tmpl extension; only one version per key is active at a time.
+type EmailTemplate struct {
+	ID        uuid.UUID  `json:"id"`
+	Key       string     `json:"key"`
+	Version   int        `json:"version"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Active    bool       `json:"active"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -21,6 +21,40 @@ const (
 	EventStatusRejected  = "rejected"
 )
 
+// eventTransitions is the event lifecycle: for each status, the statuses it
+// may move to next. Completed and cancelled events are final, and a paid
+// event goes back to confirmed when a payment is refunded.
+var eventTransitions = map[string][]string{
+	EventStatusDraft:     {EventStatusRequested, EventStatusCancelled},
+	EventStatusPlanning:  {EventStatusDraft, EventStatusRequested, EventStatusCancelled},
+	EventStatusRequested: {EventStatusAdjusted, EventStatusConfirmed, EventStatusRejected, EventStatusCancelled},
+	EventStatusAdjusted:  {EventStatusConfirmed, EventStatusRejected, EventStatusCancelled},
+	EventStatusConfirmed: {EventStatusPaid, EventStatusCancelled},
+	EventStatusPaid:      {EventStatusConfirmed, EventStatusCompleted, EventStatusCancelled},
+	EventStatusRejected:  {EventStatusRequested, EventStatusCancelled},
+	EventStatusCompleted: {},
+	EventStatusCancelled: {},
+}
+
+// CanTransitionEvent reports whether an event may move from one status to
+// another. Staying in the same status is always allowed.
+func CanTransitionEvent(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range eventTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextEventStatuses returns the statuses an event may move to from status.
+func NextEventStatuses(status string) []string {
+	return append([]string{}, eventTransitions[status]...)
+}
+
 type Event struct {
 	ID                 uuid.UUID  `json:"id"`
 	UserID             uuid.UUID  `json:"user_id"`
@@ -45,8 +79,69 @@ type Event struct {
 	RemainingAmount    int        `json:"remainingAmount"`
 	InstallmentDueDate *time.Time `json:"installmentDueDate,omitempty"`
 	TotalQuote         int        `json:"totalQuote"`
-	CreatedAt          string     `json:"created_at"`
-	UpdatedAt          string     `json:"updated_at"`
+	EventTypeID        *uuid.UUID `json:"event_type_id,omitempty"`
+	Latitude           *float64   `json:"latitude,omitempty"`
+	Longitude          *float64   `json:"longitude,omitempty"`
+	DeliveryFee        float64    `json:"delivery_fee"`
+	// PickupAt and ReturnAt are the event's own rental window. Without them
+	// the items go out the day before the event and come back the day after.
+	PickupAt  *time.Time `json:"pickup_at,omitempty"`
+	ReturnAt  *time.Time `json:"return_at,omitempty"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+
+	// StatusChange says who is moving the event to Status and why. The
+	// store records it when it saves a change of status.
+	StatusChange *EventStatusChange `json:"-"`
+}
+
+// EventStatusChange is recorded in the audit log, in the same transaction
+// as the change of status it describes.
+type EventStatusChange struct {
+	ActorID uuid.UUID
+	Reason  *string
+	// Action defaults to AuditActionEventStatus. Flows with an audit action
+	// of their own, like adjusting the quote, record that one instead.
+	Action AuditAction
+}
+
+// QuoteTotal is what the client is quoted for the event given the subtotal
+// of its items: the items plus the admin's additional costs and delivery.
+func (e *Event) QuoteTotal(subtotal float64) float64 {
+	return subtotal + e.AdditionalCosts + e.DeliveryFee
+}
+
+// ApplySchedule brings the event's payment fields in line with its
+// installments: the deposit is the first installment, the remaining amount
+// what is still owed and the due date that of the next one owed.
+func (e *Event) ApplySchedule(installments []InstallmentPayment) {
+	var first *InstallmentPayment
+	remaining := 0
+	var next *time.Time
+	for i := range installments {
+		inst := &installments[i]
+		if inst.Purpose != InstallmentPurposeInstallment {
+			continue
+		}
+		if first == nil {
+			first = inst
+		}
+		if inst.Outstanding() {
+			remaining += inst.Amount
+			if next == nil {
+				next = inst.DueDate
+			}
+		}
+	}
+	if first == nil {
+		return
+	}
+
+	e.DepositAmount = first.Amount
+	e.DepositPaid = first.PaymentStatus == InstallmentStatusPaid
+	e.DepositPaidAt = first.PaidAt
+	e.RemainingAmount = remaining
+	e.InstallmentDueDate = next
 }
 
 // CreateEventPayload still requires name and date because the explicit
@@ -58,17 +153,30 @@ type CreateEventPayload struct {
 	Location   string  `json:"location" validate:"max=255"`
 	GuestCount int     `json:"guest_count" validate:"min=0"`
 	Budget     float64 `json:"budget" validate:"min=0"`
+	// EventTypeID tags the event with one of the catalog's event types.
+	EventTypeID *uuid.UUID `json:"event_type_id"`
+	// Latitude and Longitude locate the venue for the delivery fee. They are
+	// given together or not at all.
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
 }
 
 type UpdateEventPayload struct {
-	Name            *string  `json:"name" validate:"omitempty,max=255"`
-	Date            *string  `json:"date" validate:"omitempty"`
-	Location        *string  `json:"location" validate:"omitempty,max=255"`
-	GuestCount      *int     `json:"guest_count" validate:"omitempty,min=0"`
-	Budget          *float64 `json:"budget" validate:"omitempty,min=0"`
-	AdditionalCosts *float64 `json:"additional_costs" validate:"omitempty,min=0"`
-	AdminNotes      *string  `json:"admin_notes" validate:"omitempty"`
-	Status          *string  `json:"status" validate:"omitempty,oneof=draft planning requested adjusted confirmed paid completed cancelled rejected"`
-	PaymentStatus   *string  `json:"payment_status" validate:"omitempty"`
-	PaymentMethod   *string  `json:"payment_method" validate:"omitempty"`
+	Name            *string    `json:"name" validate:"omitempty,max=255"`
+	Date            *string    `json:"date" validate:"omitempty"`
+	Location        *string    `json:"location" validate:"omitempty,max=255"`
+	GuestCount      *int       `json:"guest_count" validate:"omitempty,min=0"`
+	Budget          *float64   `json:"budget" validate:"omitempty,min=0"`
+	AdditionalCosts *float64   `json:"additional_costs" validate:"omitempty,min=0"`
+	EventTypeID     *uuid.UUID `json:"event_type_id"`
+	Latitude        *float64   `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude       *float64   `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
+	AdminNotes      *string    `json:"admin_notes" validate:"omitempty"`
+	Status          *string    `json:"status" validate:"omitempty,oneof=draft planning requested adjusted confirmed paid completed cancelled rejected"`
+	StatusReason    *string    `json:"status_reason" validate:"omitempty,max=500"`
+	PaymentStatus   *string    `json:"payment_status" validate:"omitempty"`
+	PaymentMethod   *string    `json:"payment_method" validate:"omitempty"`
+	// PickupAt and ReturnAt set the rental window. They are given together.
+	PickupAt *time.Time `json:"pickup_at" validate:"required_with=ReturnAt"`
+	ReturnAt *time.Time `json:"return_at" validate:"required_with=PickupAt"`
 }
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conditions an item can come back in.
+const (
+	ReturnConditionOK      = "ok"
+	ReturnConditionDamaged = "damaged"
+	ReturnConditionLost    = "lost"
+)
+
+// InstallmentPurposeDamage is an extra installment billing the client for
+// items damaged or lost at their event.
+const InstallmentPurposeDamage = "damage"
+
+// DamageChargeLabel labels damage installments and their ledger entries.
+const DamageChargeLabel = "Cargo por daños"
+
+// DamageChargeDue is how long the client has to pay a damage charge.
+const DamageChargeDue = 7 * 24 * time.Hour
+
+// EventReturn is the inspection of an event's items when they come back.
+// Until one is recorded, the checklist is built from the event's items,
+// all returned in good condition, and Inspected is false.
+type EventReturn struct {
+	ID             uuid.UUID         `json:"id"`
+	EventID        uuid.UUID         `json:"event_id"`
+	Inspected      bool              `json:"inspected"`
+	InspectedBy    *uuid.UUID        `json:"inspected_by,omitempty"`
+	Notes          string            `json:"notes"`
+	DamageCharge   int               `json:"damage_charge"`
+	DamageChargeID *uuid.UUID        `json:"damage_charge_id,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Lines          []EventReturnLine `json:"lines"`
+}
+
+// EventReturnLine is how one event item came back. Units neither damaged
+// nor lost were returned in good condition. Charge is what the client is
+// billed for the line, by default the replacement cost of every unit
+// damaged or lost.
+type EventReturnLine struct {
+	ID               uuid.UUID  `json:"id"`
+	EventItemID      *uuid.UUID `json:"event_item_id,omitempty"`
+	ArticleID        uuid.UUID  `json:"article_id"`
+	VariantID        *uuid.UUID `json:"variant_id,omitempty"`
+	Name             string     `json:"name"`
+	Quantity         int        `json:"quantity"`
+	Damaged          int        `json:"damaged"`
+	Lost             int        `json:"lost"`
+	ReplacementCost  float64    `json:"replacement_cost"`
+	Charge           float64    `json:"charge"`
+	Notes            string     `json:"notes,omitempty"`
+	MaintenanceLogID *uuid.UUID `json:"maintenance_log_id,omitempty"`
+}
+
+// Returned is how many units came back in good condition.
+func (l *EventReturnLine) Returned() int {
+	return l.Quantity - l.Damaged - l.Lost
+}
+
+// ReturnInspectionPayload marks event items as damaged or lost. Items not
+// listed came back in good condition. An item may be listed more than once
+// to split its units between conditions; a zero quantity covers every
+// unit of the item not yet accounted for.
+type ReturnInspectionPayload struct {
+	Notes string                 `json:"notes" validate:"max=2000"`
+	Lines []ReturnInspectionLine `json:"lines" validate:"dive"`
+}
+
+type ReturnInspectionLine struct {
+	EventItemID uuid.UUID `json:"event_item_id" validate:"required"`
+	Condition   string    `json:"condition" validate:"required,oneof=ok damaged lost"`
+	Quantity    int       `json:"quantity" validate:"min=0"`
+	// Charge replaces the replacement cost billed for these units.
+	Charge *float64 `json:"charge" validate:"omitempty,min=0"`
+	Notes  string   `json:"notes" validate:"max=500"`
+}
+
+var ErrUnknownReturnItem = errors.New("item is not part of the event")
+
+// NewEventReturn builds the return checklist of an event from its items,
+// with every unit returned in good condition.
+func NewEventReturn(eventID uuid.UUID, items []EventItem) *EventReturn {
+	ret := &EventReturn{EventID: eventID, Lines: make([]EventReturnLine, 0, len(items))}
+	for _, item := range items {
+		line := EventReturnLine{
+			EventItemID: &item.ID,
+			ArticleID:   item.ArticleID,
+			VariantID:   item.VariantID,
+			Quantity:    item.Quantity,
+		}
+		if item.Article != nil {
+			line.Name = item.Article.NameTemplate
+		}
+		if item.Variant != nil {
+			if item.Variant.Name != "" {
+				line.Name += " (" + item.Variant.Name + ")"
+			}
+			if item.Variant.ReplacementCost != nil {
+				line.ReplacementCost = *item.Variant.ReplacementCost
+			}
+		}
+		ret.Lines = append(ret.Lines, line)
+	}
+	return ret
+}
+
+// Apply marks the checklist's lines as the payload says and works out the
+// damage charge, in whole pesos. Variants without a replacement cost are
+// charged nothing unless a charge is given.
+func (ret *EventReturn) Apply(payload ReturnInspectionPayload) error {
+	byItem := make(map[uuid.UUID]*EventReturnLine, len(ret.Lines))
+	charges := make(map[uuid.UUID]float64)
+	for i := range ret.Lines {
+		line := &ret.Lines[i]
+		line.Damaged, line.Lost, line.Charge, line.Notes = 0, 0, 0, ""
+		if line.EventItemID != nil {
+			byItem[*line.EventItemID] = line
+		}
+	}
+	ret.Notes = payload.Notes
+
+	for _, mark := range payload.Lines {
+		line, ok := byItem[mark.EventItemID]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownReturnItem, mark.EventItemID)
+		}
+		left := line.Returned()
+		qty := mark.Quantity
+		if qty == 0 {
+			qty = left
+		}
+		if qty > left {
+			return fmt.Errorf("%s: %d units marked but only %d left to inspect", line.Name, qty, left)
+		}
+
+		switch mark.Condition {
+		case ReturnConditionDamaged:
+			line.Damaged += qty
+		case ReturnConditionLost:
+			line.Lost += qty
+		case ReturnConditionOK:
+			if mark.Charge != nil {
+				return fmt.Errorf("%s: units returned in good condition can't be charged", line.Name)
+			}
+		}
+		if mark.Condition != ReturnConditionOK {
+			charge := float64(qty) * line.ReplacementCost
+			if mark.Charge != nil {
+				charge = *mark.Charge
+			}
+			charges[mark.EventItemID] += charge
+		}
+		if mark.Notes != "" {
+			if line.Notes != "" {
+				line.Notes += "; "
+			}
+			line.Notes += mark.Notes
+		}
+	}
+
+	var total float64
+	for i := range ret.Lines {
+		line := &ret.Lines[i]
+		if line.EventItemID == nil {
+			continue
+		}
+		line.Charge = math.Round(charges[*line.EventItemID]*100) / 100
+		total += line.Charge
+	}
+	ret.DamageCharge = int(math.Round(total))
+	return nil
+}
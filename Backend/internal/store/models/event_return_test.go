@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEventReturnApply(t *testing.T) {
+	cost := 1500.0
+	chairs := EventItem{ID: uuid.New(), ArticleID: uuid.New(), Quantity: 10,
+		Article: &Article{NameTemplate: "Silla"}, Variant: &ArticleVariant{Name: "Blanca", ReplacementCost: &cost}}
+	tables := EventItem{ID: uuid.New(), ArticleID: uuid.New(), Quantity: 2, Article: &Article{NameTemplate: "Mesa"}}
+
+	newReturn := func() *EventReturn {
+		return NewEventReturn(uuid.New(), []EventItem{chairs, tables})
+	}
+
+	t.Run("charges replacement cost unless overridden", func(t *testing.T) {
+		ret := newReturn()
+		discount := 200.0
+		err := ret.Apply(ReturnInspectionPayload{Lines: []ReturnInspectionLine{
+			{EventItemID: chairs.ID, Condition: ReturnConditionDamaged, Quantity: 2},
+			{EventItemID: chairs.ID, Condition: ReturnConditionLost, Quantity: 1},
+			{EventItemID: tables.ID, Condition: ReturnConditionDamaged, Charge: &discount},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, m := ret.Lines[0], ret.Lines[1]
+		if c.Name != "Silla (Blanca)" || c.Damaged != 2 || c.Lost != 1 || c.Returned() != 7 || c.Charge != 4500 {
+			t.Errorf("unexpected chairs line %+v", c)
+		}
+		if m.Damaged != 2 || m.Charge != 200 {
+			t.Errorf("a zero quantity should cover the whole line, got %+v", m)
+		}
+		if ret.DamageCharge != 4700 {
+			t.Errorf("expected a 4700 damage charge, got %d", ret.DamageCharge)
+		}
+	})
+
+	t.Run("variants without a replacement cost are free", func(t *testing.T) {
+		ret := newReturn()
+		if err := ret.Apply(ReturnInspectionPayload{Lines: []ReturnInspectionLine{
+			{EventItemID: tables.ID, Condition: ReturnConditionLost, Quantity: 1},
+		}}); err != nil {
+			t.Fatal(err)
+		}
+		if ret.Lines[1].Lost != 1 || ret.DamageCharge != 0 {
+			t.Errorf("unexpected return %+v", ret)
+		}
+	})
+
+	t.Run("rejects more units than the line has", func(t *testing.T) {
+		err := newReturn().Apply(ReturnInspectionPayload{Lines: []ReturnInspectionLine{
+			{EventItemID: tables.ID, Condition: ReturnConditionDamaged, Quantity: 2},
+			{EventItemID: tables.ID, Condition: ReturnConditionLost, Quantity: 1},
+		}})
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("rejects items of another event", func(t *testing.T) {
+		err := newReturn().Apply(ReturnInspectionPayload{Lines: []ReturnInspectionLine{
+			{EventItemID: uuid.New(), Condition: ReturnConditionLost},
+		}})
+		if !errors.Is(err, ErrUnknownReturnItem) {
+			t.Errorf("expected ErrUnknownReturnItem, got %v", err)
+		}
+	})
+}
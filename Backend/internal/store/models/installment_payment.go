@@ -6,23 +6,90 @@ import (
 	"github.com/google/uuid"
 )
 
+// Installment payment statuses. A payment made with a method that needs
+// manual confirmation, or with an uploaded receipt, waits in
+// pending_verification until an admin confirms the money arrived. A rejected
+// installment goes back to pending; a rejected deposit or full payment is
+// kept as rejected.
+const (
+	InstallmentStatusPending             = "pending"
+	InstallmentStatusPendingVerification = "pending_verification"
+	InstallmentStatusPaid                = "paid"
+	InstallmentStatusRejected            = "rejected"
+)
+
+// What an installment payment is for. Installments are the scheduled
+// remainder of an event; deposits and full payments are recorded when the
+// client pays them by hand.
+const (
+	InstallmentPurposeDeposit     = "deposit"
+	InstallmentPurposeFull        = "full"
+	InstallmentPurposeInstallment = "installment"
+)
+
+// InstallmentPayment is an installment of the event's payment plan, or a
+// deposit or full payment recorded before plans existed. RejectionReason is
+// why an admin last rejected it, shown to the client. Overdue is worked out
+// when the payment is read.
 type InstallmentPayment struct {
-	ID            uuid.UUID  `json:"id"`
-	EventID       uuid.UUID  `json:"event_id"`
-	Amount        int        `json:"amount"`
-	PaymentMethod *string    `json:"payment_method"`
-	PaymentStatus string     `json:"payment_status"`
-	DueDate       *time.Time `json:"due_date,omitempty"`
-	PaidAt        *time.Time `json:"paid_at,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
+	ID              uuid.UUID  `json:"id"`
+	EventID         uuid.UUID  `json:"event_id"`
+	Purpose         string     `json:"purpose"`
+	Sequence        *int       `json:"sequence,omitempty"`
+	Label           string     `json:"label,omitempty"`
+	Amount          int        `json:"amount"`
+	Surcharge       int        `json:"surcharge"`
+	PaymentMethod   *string    `json:"payment_method"`
+	PaymentStatus   string     `json:"payment_status"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+	SubmittedAt     *time.Time `json:"submitted_at,omitempty"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	VerifiedBy      *uuid.UUID `json:"verified_by,omitempty"`
+	ReceiptURL      *string    `json:"receipt_url,omitempty"`
+	RejectionReason *string    `json:"rejection_reason,omitempty"`
+	RejectedAt      *time.Time `json:"rejected_at,omitempty"`
+	Overdue         bool       `json:"overdue"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Outstanding reports whether the payment is still owed: not paid, and not
+// a rejected deposit or full payment.
+func (p *InstallmentPayment) Outstanding() bool {
+	return p.PaymentStatus == InstallmentStatusPending || p.PaymentStatus == InstallmentStatusPendingVerification
+}
+
+// IsOverdue reports whether the payment is still pending after its due
+// date. A payment waiting for verification isn't overdue: the client
+// already paid.
+func (p *InstallmentPayment) IsOverdue(now time.Time) bool {
+	return p.PaymentStatus == InstallmentStatusPending && p.DueDate != nil && p.DueDate.Before(now)
+}
+
+// PaymentVerification is a payment in the admin queue, with the event and
+// client it belongs to.
+type PaymentVerification struct {
+	InstallmentPayment
+	EventName   string `json:"event_name"`
+	ClientName  string `json:"client_name"`
+	ClientEmail string `json:"client_email"`
+}
+
+type RejectPaymentPayload struct {
+	Reason string `json:"reason" validate:"required,max=500"`
 }
 
+// PaymentSchedule summarises what the client paid and owes. The deposit is
+// the first installment of the plan.
 type PaymentSchedule struct {
-	DepositPaid        bool       `json:"depositPaid"`
-	DepositAmount      int        `json:"depositAmount"`
-	DepositPaidAt      *time.Time `json:"depositPaidAt,omitempty"`
-	RemainingAmount    int        `json:"remainingAmount"`
-	InstallmentDueDate *time.Time `json:"installmentDueDate,omitempty"`
-	TotalQuote         int        `json:"totalQuote"`
+	PlanName           string               `json:"planName,omitempty"`
+	DepositPaid        bool                 `json:"depositPaid"`
+	DepositAmount      int                  `json:"depositAmount"`
+	DepositPaidAt      *time.Time           `json:"depositPaidAt,omitempty"`
+	RemainingAmount    int                  `json:"remainingAmount"`
+	InstallmentDueDate *time.Time           `json:"installmentDueDate,omitempty"`
+	TotalQuote         int                  `json:"totalQuote"`
+	Installments       []InstallmentPayment `json:"installments"`
 	PendingPayments    []InstallmentPayment `json:"pendingPayments,omitempty"`
+	OverdueAmount      int                  `json:"overdueAmount"`
 }
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invoice statuses. An invoice is issued when created, and moves to
+// partially_paid and paid as the installments of its event are paid.
+const (
+	InvoiceStatusIssued        = "issued"
+	InvoiceStatusSent          = "sent"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusVoid          = "void"
+)
+
+// invoiceTransitions is the invoice lifecycle. Paid and void invoices are
+// final: a paid invoice is corrected with a credit note, not voided.
+var invoiceTransitions = map[string][]string{
+	InvoiceStatusIssued:        {InvoiceStatusSent, InvoiceStatusPartiallyPaid, InvoiceStatusPaid, InvoiceStatusVoid},
+	InvoiceStatusSent:          {InvoiceStatusPartiallyPaid, InvoiceStatusPaid, InvoiceStatusVoid},
+	InvoiceStatusPartiallyPaid: {InvoiceStatusPaid, InvoiceStatusVoid},
+	InvoiceStatusPaid:          {},
+	InvoiceStatusVoid:          {},
+}
+
+// CanTransitionInvoice reports whether an invoice may move from one status
+// to another. Staying in the same status is always allowed.
+func CanTransitionInvoice(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range invoiceTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextInvoiceStatuses returns the statuses an invoice may move to from
+// status.
+func NextInvoiceStatuses(status string) []string {
+	return append([]string{}, invoiceTransitions[status]...)
+}
+
+// ITBISRate is the Dominican sales tax (Impuesto sobre Transferencias de
+// Bienes Industrializados y Servicios) charged on invoices.
+const ITBISRate = 0.18
+
+type Invoice struct {
+	ID             uuid.UUID     `json:"id"`
+	InvoiceNumber  string        `json:"invoice_number"`
+	FiscalYear     int           `json:"fiscal_year"`
+	Sequence       int           `json:"sequence"`
+	NCF            *string       `json:"ncf,omitempty"`
+	ClientRNC      *string       `json:"client_rnc,omitempty"`
+	EventID        *uuid.UUID    `json:"event_id,omitempty"`
+	ClientID       uuid.UUID     `json:"client_id"`
+	Subtotal       float64       `json:"subtotal"`
+	TaxRate        float64       `json:"tax_rate"`
+	TaxIncluded    bool          `json:"tax_included"`
+	TaxAmount      float64       `json:"tax_amount"`
+	DiscountAmount float64       `json:"discount_amount"`
+	Total          float64       `json:"total"`
+	AmountPaid     float64       `json:"amount_paid"`
+	Currency       string        `json:"currency"`
+	Status         string        `json:"status"`
+	IssueDate      time.Time     `json:"issue_date"`
+	DueDate        *time.Time    `json:"due_date,omitempty"`
+	SentAt         *time.Time    `json:"sent_at,omitempty"`
+	PaidDate       *time.Time    `json:"paid_date,omitempty"`
+	VoidedAt       *time.Time    `json:"voided_at,omitempty"`
+	VoidReason     *string       `json:"void_reason,omitempty"`
+	Notes          *string       `json:"notes,omitempty"`
+	Terms          *string       `json:"terms,omitempty"`
+	CreatedBy      *uuid.UUID    `json:"created_by,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Lines          []InvoiceLine `json:"lines,omitempty"`
+}
+
+type InvoiceLine struct {
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Total       float64 `json:"total"`
+}
+
+// FormatInvoiceNumber is the number printed on an invoice: the fiscal year
+// and its sequence within the year.
+func FormatInvoiceNumber(year, sequence int) string {
+	return fmt.Sprintf("FAC-%d-%06d", year, sequence)
+}
+
+// ComputeTotals fills in the line totals and the invoice amounts from the
+// lines and the discount. When TaxIncluded the line prices already include
+// ITBIS, which is broken out of the total; otherwise it is added on top.
+// Subtotal is the taxable base in both cases.
+func (inv *Invoice) ComputeTotals() {
+	inv.TaxRate = ITBISRate
+	var gross float64
+	for i := range inv.Lines {
+		inv.Lines[i].Total = roundCents(inv.Lines[i].UnitPrice * float64(inv.Lines[i].Quantity))
+		gross += inv.Lines[i].Total
+	}
+	net := roundCents(gross - inv.DiscountAmount)
+
+	if inv.TaxIncluded {
+		inv.Total = net
+		inv.Subtotal = roundCents(net / (1 + inv.TaxRate))
+		inv.TaxAmount = roundCents(inv.Total - inv.Subtotal)
+		return
+	}
+	inv.Subtotal = net
+	inv.TaxAmount = roundCents(net * inv.TaxRate)
+	inv.Total = roundCents(inv.Subtotal + inv.TaxAmount)
+}
+
+// Gross is the sum of the line totals, before the discount.
+func (inv *Invoice) Gross() float64 {
+	var gross float64
+	for _, l := range inv.Lines {
+		gross += l.Total
+	}
+	return roundCents(gross)
+}
+
+// Balance is what is still owed on the invoice.
+func (inv *Invoice) Balance() float64 {
+	return math.Max(0, roundCents(inv.Total-inv.AmountPaid))
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+var (
+	// An NCF is a series letter, a two digit receipt type and a sequence:
+	// eight digits for printed receipts (B), ten for electronic ones (E).
+	ncfPattern = regexp.MustCompile(`^(B(01|02|14|15|16)\d{8}|E(31|32|33|34|41|43|44|45|46|47)\d{10})$`)
+	// An RNC has 9 digits; individuals use their 11 digit cédula instead.
+	rncPattern = regexp.MustCompile(`^(\d{9}|\d{11})$`)
+
+	ErrInvalidNCF  = errors.New("ncf is not a valid fiscal receipt number")
+	ErrInvalidRNC  = errors.New("client_rnc must be a 9 digit RNC or an 11 digit cédula")
+	ErrNCFNeedsRNC = errors.New("a tax credit receipt (B01, E31) needs the client's RNC")
+)
+
+// NCFType is the receipt type of an NCF, such as B01 for tax credit or B02
+// for final consumer receipts.
+func NCFType(ncf string) string {
+	if len(ncf) < 3 {
+		return ""
+	}
+	return ncf[:3]
+}
+
+// CheckFiscalFields validates the invoice's NCF and client RNC. Both are
+// optional, but a tax credit receipt is only valid with the client's RNC.
+func (inv *Invoice) CheckFiscalFields() error {
+	if inv.ClientRNC != nil && !rncPattern.MatchString(*inv.ClientRNC) {
+		return ErrInvalidRNC
+	}
+	if inv.NCF == nil {
+		return nil
+	}
+	if !ncfPattern.MatchString(*inv.NCF) {
+		return ErrInvalidNCF
+	}
+	if t := NCFType(*inv.NCF); (t == "B01" || t == "E31") && inv.ClientRNC == nil {
+		return ErrNCFNeedsRNC
+	}
+	return nil
+}
+
+type InvoiceLinePayload struct {
+	Description string  `json:"description" validate:"required,max=255"`
+	Quantity    int     `json:"quantity" validate:"required,min=1"`
+	UnitPrice   float64 `json:"unit_price" validate:"min=0"`
+}
+
+// CreateInvoicePayload creates an invoice. An invoice for an event takes
+// its client and lines from the event, with ITBIS included in the quoted
+// prices; any other invoice lists its own lines.
+type CreateInvoicePayload struct {
+	EventID        *uuid.UUID           `json:"event_id"`
+	ClientID       *uuid.UUID           `json:"client_id" validate:"required_without=EventID"`
+	Lines          []InvoiceLinePayload `json:"lines" validate:"required_without=EventID,dive"`
+	TaxIncluded    bool                 `json:"tax_included"`
+	DiscountAmount float64              `json:"discount_amount" validate:"min=0"`
+	NCF            *string              `json:"ncf"`
+	ClientRNC      *string              `json:"client_rnc"`
+	DueDate        *time.Time           `json:"due_date"`
+	Notes          *string              `json:"notes" validate:"omitempty,max=2000"`
+	Terms          *string              `json:"terms" validate:"omitempty,max=2000"`
+}
+
+// UpdateInvoiceStatusPayload moves an invoice along its lifecycle by hand.
+// Invoices for an event are paid through its installments; only other
+// invoices can be marked paid here.
+type UpdateInvoiceStatusPayload struct {
+	Status string  `json:"status" validate:"required,oneof=sent paid void"`
+	Reason *string `json:"reason" validate:"required_if=Status void,omitempty,max=500"`
+}
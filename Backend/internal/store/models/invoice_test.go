@@ -0,0 +1,71 @@
+package models
+
+import "testing"
+
+func TestInvoiceComputeTotals(t *testing.T) {
+	t.Run("adds ITBIS on top", func(t *testing.T) {
+		inv := Invoice{DiscountAmount: 100, Lines: []InvoiceLine{
+			{Description: "Silla", Quantity: 10, UnitPrice: 50},
+			{Description: "Montaje", Quantity: 1, UnitPrice: 600},
+		}}
+		inv.ComputeTotals()
+
+		if inv.Lines[0].Total != 500 || inv.Gross() != 1100 {
+			t.Errorf("unexpected lines %+v", inv.Lines)
+		}
+		if inv.Subtotal != 1000 || inv.TaxAmount != 180 || inv.Total != 1180 {
+			t.Errorf("got subtotal %v tax %v total %v", inv.Subtotal, inv.TaxAmount, inv.Total)
+		}
+	})
+
+	t.Run("breaks ITBIS out of included prices", func(t *testing.T) {
+		inv := Invoice{TaxIncluded: true, Lines: []InvoiceLine{{Description: "Decoración", Quantity: 1, UnitPrice: 10000}}}
+		inv.ComputeTotals()
+
+		if inv.Total != 10000 || inv.Subtotal != 8474.58 || inv.TaxAmount != 1525.42 {
+			t.Errorf("got subtotal %v tax %v total %v", inv.Subtotal, inv.TaxAmount, inv.Total)
+		}
+	})
+}
+
+func TestInvoiceFiscalFields(t *testing.T) {
+	str := func(s string) *string { return &s }
+	for _, tc := range []struct {
+		name string
+		ncf  *string
+		rnc  *string
+		want error
+	}{
+		{"no fiscal fields", nil, nil, nil},
+		{"consumer receipt", str("B0200000001"), nil, nil},
+		{"tax credit with RNC", str("B0100000001"), str("101234567"), nil},
+		{"electronic tax credit with cédula", str("E310000000001"), str("00112345678"), nil},
+		{"tax credit without RNC", str("B0100000001"), nil, ErrNCFNeedsRNC},
+		{"short sequence", str("B021234"), nil, ErrInvalidNCF},
+		{"unknown type", str("B0900000001"), nil, ErrInvalidNCF},
+		{"bad RNC", nil, str("12-345"), ErrInvalidRNC},
+	} {
+		inv := Invoice{NCF: tc.ncf, ClientRNC: tc.rnc}
+		if err := inv.CheckFiscalFields(); err != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestCanTransitionInvoice(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		want     bool
+	}{
+		{InvoiceStatusIssued, InvoiceStatusSent, true},
+		{InvoiceStatusSent, InvoiceStatusPartiallyPaid, true},
+		{InvoiceStatusPartiallyPaid, InvoiceStatusVoid, true},
+		{InvoiceStatusPartiallyPaid, InvoiceStatusSent, false},
+		{InvoiceStatusPaid, InvoiceStatusVoid, false},
+		{InvoiceStatusVoid, InvoiceStatusIssued, false},
+	} {
+		if got := CanTransitionInvoice(tc.from, tc.to); got != tc.want {
+			t.Errorf("%s -> %s: got %v", tc.from, tc.to, got)
+		}
+	}
+}
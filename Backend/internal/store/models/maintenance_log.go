@@ -28,6 +28,8 @@ type ArticleMaintenanceLog struct {
 	ID                 uuid.UUID        `json:"id"`
 	ArticleID          uuid.UUID        `json:"article_id"`
 	VariantID          *uuid.UUID       `json:"variant_id,omitempty"`
+	EventID            *uuid.UUID       `json:"event_id,omitempty"` // event whose damage caused it
+	StockHeld          int              `json:"stock_held"`          // units out of stock until completed
 	MaintenanceType    MaintenanceType  `json:"maintenance_type"`
 	Status             MaintenanceStatus `json:"status"`
 	Description        string           `json:"description,omitempty"`
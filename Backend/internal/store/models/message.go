@@ -6,12 +6,19 @@ import (
 	"github.com/google/uuid"
 )
 
+// Channels an event message can arrive through.
+const (
+	MessageChannelApp      = "app"
+	MessageChannelWhatsApp = "whatsapp"
+)
+
 // EventMessage represents a chat message within an event context.
 type EventMessage struct {
 	ID        uuid.UUID `json:"id"`
 	EventID   uuid.UUID `json:"event_id"`
 	SenderID  uuid.UUID `json:"sender_id"`
 	Content   string    `json:"content"`
+	Channel   string    `json:"channel"`
 	CreatedAt time.Time `json:"created_at"`
 
 	// Optional: Include sender name for the UI
@@ -6,18 +6,32 @@ import (
 	"github.com/google/uuid"
 )
 
+// PayPal transaction statuses and what a payment settles.
+const (
+	PayPalStatusPending   = "pending"
+	PayPalStatusCompleted = "completed"
+	PayPalStatusFailed    = "failed"
+	PayPalStatusRefunded  = "refunded"
+
+	PayPalPurposeDeposit     = "deposit"
+	PayPalPurposeFull        = "full"
+	PayPalPurposeInstallment = "installment"
+)
+
 type PayPalTransaction struct {
-	ID              uuid.UUID `json:"id"`
-	PayPalOrderID   string    `json:"paypal_order_id"`
-	PayPalCaptureID *string   `json:"paypal_capture_id,omitempty"`
-	EventID         uuid.UUID `json:"event_id"`
-	UserID          uuid.UUID `json:"user_id"`
-	Amount          float64   `json:"amount"`
-	Currency        string    `json:"currency"`
-	Status          string    `json:"status"`
-	PayPalResponse  *string   `json:"paypal_response,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	PayPalOrderID   string     `json:"paypal_order_id"`
+	PayPalCaptureID *string    `json:"paypal_capture_id,omitempty"`
+	EventID         uuid.UUID  `json:"event_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Amount          float64    `json:"amount"`
+	Currency        string     `json:"currency"`
+	Status          string     `json:"status"`
+	Purpose         string     `json:"purpose"`
+	InstallmentID   *uuid.UUID `json:"installment_id,omitempty"`
+	PayPalResponse  *string    `json:"paypal_response,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type ArticleInsurance struct {
@@ -75,6 +89,16 @@ type FinancialCategory struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// Ledger sources: the kinds of payment that post their own financial record.
+// A refund's record points at the record it reverses.
+const (
+	LedgerSourceInstallmentPayment = "installment_payment"
+	LedgerSourceVendorPayment      = "vendor_payment"
+	LedgerSourceInstallmentRefund  = "installment_refund"
+)
+
+// FinancialRecord is an income or expense entry. Records posted by a payment
+// carry its SourceType and SourceID; records entered by hand have neither.
 type FinancialRecord struct {
 	ID              uuid.UUID          `json:"id"`
 	EventID         *uuid.UUID         `json:"event_id,omitempty"`
@@ -90,33 +114,13 @@ type FinancialRecord struct {
 	IsReconciled    bool               `json:"is_reconciled"`
 	ReconciledAt    *time.Time         `json:"reconciled_at,omitempty"`
 	Metadata        *map[string]any    `json:"metadata,omitempty"`
+	SourceType      *string            `json:"source_type,omitempty"`
+	SourceID        *uuid.UUID         `json:"source_id,omitempty"`
 	CreatedAt       time.Time          `json:"created_at"`
 	UpdatedAt       time.Time          `json:"updated_at"`
 	Category        *FinancialCategory `json:"category,omitempty"`
 }
 
-type Invoice struct {
-	ID             uuid.UUID  `json:"id"`
-	InvoiceNumber  string     `json:"invoice_number"`
-	EventID        *uuid.UUID `json:"event_id,omitempty"`
-	ClientID       uuid.UUID  `json:"client_id"`
-	Subtotal       float64    `json:"subtotal"`
-	TaxAmount      float64    `json:"tax_amount"`
-	DiscountAmount float64    `json:"discount_amount"`
-	Total          float64    `json:"total"`
-	AmountPaid     float64    `json:"amount_paid"`
-	Currency       string     `json:"currency"`
-	Status         string     `json:"status"`
-	IssueDate      time.Time  `json:"issue_date"`
-	DueDate        *time.Time `json:"due_date,omitempty"`
-	PaidDate       *time.Time `json:"paid_date,omitempty"`
-	Notes          *string    `json:"notes,omitempty"`
-	Terms          *string    `json:"terms,omitempty"`
-	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-}
-
 type ExpenseVendor struct {
 	ID          uuid.UUID `json:"id"`
 	Name        string    `json:"name"`
@@ -134,6 +138,7 @@ type ExpenseVendor struct {
 type VendorPayment struct {
 	ID              uuid.UUID  `json:"id"`
 	VendorID        uuid.UUID  `json:"vendor_id"`
+	EventID         *uuid.UUID `json:"event_id,omitempty"`
 	Amount          float64    `json:"amount"`
 	Currency        string     `json:"currency"`
 	PaymentDate     time.Time  `json:"payment_date"`
@@ -165,6 +170,13 @@ type FinancialSummary struct {
 	PeriodEnd       string  `json:"period_end"`
 }
 
+// FinancialPeriodTotal is the income and expenses recorded in one period.
+type FinancialPeriodTotal struct {
+	Period   time.Time `json:"period"`
+	Income   float64   `json:"income"`
+	Expenses float64   `json:"expenses"`
+}
+
 type InsuranceWithArticle struct {
 	Insurance   ArticleInsurance `json:"insurance"`
 	ArticleName string           `json:"article_name"`
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What makes a notification trigger fire.
+const (
+	TriggerDaysBeforeEvent    = "days_before_event"
+	TriggerDaysAfterEvent     = "days_after_event"
+	TriggerQuoteAdjusted      = "quote_adjusted"
+	TriggerInstallmentDue     = "installment_due"
+	TriggerInstallmentOverdue = "installment_overdue"
+)
+
+// Channels a trigger can deliver through.
+const (
+	ChannelEmail    = "email"
+	ChannelWhatsApp = "whatsapp"
+	ChannelPush     = "push"
+)
+
+// NotificationTrigger is an admin-configured rule the scheduler evaluates.
+// DaysOffset is how many days before the event (or before the installment
+// due date, or after the event or the missed due date) it fires;
+// quote_adjusted ignores it.
+// TemplateID names an email template key for email, an approved WhatsApp
+// Business template for whatsapp, and a push message for push.
+type NotificationTrigger struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	EventType  string    `json:"event_type"`
+	Channel    string    `json:"channel"`
+	TemplateID string    `json:"template_id"`
+	DaysOffset int       `json:"days_offset"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// LogType is the NotificationLogs type recording that the trigger fired for
+// an event. subjectID narrows it further when one event can fire the same
+// trigger more than once, e.g. once per installment.
+func (t *NotificationTrigger) LogType(subjectID *uuid.UUID) NotificationType {
+	if subjectID == nil {
+		return NotificationType("trigger:" + t.ID.String())
+	}
+	return NotificationType(fmt.Sprintf("trigger:%s:%s", t.ID, subjectID))
+}
+
+// TriggerEvent is an event the trigger scheduler may notify about.
+// LastAdjustmentID is the audit row of its latest quote adjustment, set for
+// adjusted events so each adjustment is notified once. It is nil for events
+// adjusted before adjustments were audited.
+type TriggerEvent struct {
+	Event
+	LastAdjustmentID *uuid.UUID
+}
+
+type CreateNotificationTriggerPayload struct {
+	Name       string `json:"name" validate:"required,max=255"`
+	EventType  string `json:"event_type" validate:"required,oneof=days_before_event days_after_event quote_adjusted installment_due installment_overdue"`
+	Channel    string `json:"channel" validate:"required,oneof=email whatsapp push"`
+	TemplateID string `json:"template_id" validate:"required,max=255"`
+	DaysOffset int    `json:"days_offset" validate:"min=0,max=365"`
+	Enabled    *bool  `json:"enabled"`
+}
+
+type UpdateNotificationTriggerPayload struct {
+	Name       *string `json:"name" validate:"omitempty,max=255"`
+	EventType  *string `json:"event_type" validate:"omitempty,oneof=days_before_event days_after_event quote_adjusted installment_due installment_overdue"`
+	Channel    *string `json:"channel" validate:"omitempty,oneof=email whatsapp push"`
+	TemplateID *string `json:"template_id" validate:"omitempty,max=255"`
+	DaysOffset *int    `json:"days_offset" validate:"omitempty,min=0,max=365"`
+	Enabled    *bool   `json:"enabled"`
+}
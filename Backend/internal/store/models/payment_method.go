@@ -0,0 +1,42 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentMethod is a way clients can pay, configured by the admins. Code is
+// what payments record as their payment_method.
+type PaymentMethod struct {
+	ID                   uuid.UUID `json:"id"`
+	Code                 string    `json:"code"`
+	Name                 string    `json:"name"`
+	Enabled              bool      `json:"enabled"`
+	Instructions         string    `json:"instructions"`
+	SurchargePercent     float64   `json:"surcharge_percent"`
+	RequiresConfirmation bool      `json:"requires_confirmation"`
+	SortOrder            int       `json:"sort_order"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// Surcharge is what paying amount with this method costs on top, rounded to
+// the peso.
+func (m *PaymentMethod) Surcharge(amount int) int {
+	return int(math.Round(float64(amount) * m.SurchargePercent / 100))
+}
+
+// PaymentMethodPayload is one method in the admin's method list. Methods
+// without an ID are created.
+type PaymentMethodPayload struct {
+	ID                   *uuid.UUID `json:"id"`
+	Code                 string     `json:"code" validate:"required,max=50,lowercase"`
+	Name                 string     `json:"name" validate:"required,max=100"`
+	Enabled              bool       `json:"enabled"`
+	Instructions         string     `json:"instructions" validate:"max=2000"`
+	SurchargePercent     float64    `json:"surcharge_percent" validate:"min=0,max=100"`
+	RequiresConfirmation bool       `json:"requires_confirmation"`
+	SortOrder            int        `json:"sort_order"`
+}
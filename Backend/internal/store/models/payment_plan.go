@@ -0,0 +1,116 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentPlan splits an event's quote into installments. An event uses its
+// own plan if it has one, else its event type's, else the default plan.
+type PaymentPlan struct {
+	ID           uuid.UUID                `json:"id"`
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description"`
+	IsDefault    bool                     `json:"is_default"`
+	Installments []PaymentPlanInstallment `json:"installments"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+// PaymentPlanInstallment is one share of the quote. It is due the given
+// number of days before the event, or as soon as the quote is approved when
+// DaysBeforeEvent is nil.
+type PaymentPlanInstallment struct {
+	Sequence        int     `json:"sequence"`
+	Label           string  `json:"label"`
+	Percent         float64 `json:"percent"`
+	DaysBeforeEvent *int    `json:"days_before_event"`
+}
+
+// FallbackPaymentPlan is used when no plan is configured at all: half on
+// approval and the rest a week before the event.
+var FallbackPaymentPlan = PaymentPlan{
+	Name: "Reserva 50%",
+	Installments: []PaymentPlanInstallment{
+		{Sequence: 1, Label: "Reserva", Percent: 50},
+		{Sequence: 2, Label: "Saldo", Percent: 50, DaysBeforeEvent: intPtr(7)},
+	},
+}
+
+func intPtr(n int) *int { return &n }
+
+// ScheduledInstallment is a plan installment worked out for one event.
+type ScheduledInstallment struct {
+	Sequence int
+	Label    string
+	Amount   int
+	DueDate  time.Time
+}
+
+// Schedule works out the installments of the plan for a quote total
+// approved at approvedAt. Amounts are rounded to whole pesos with the last
+// installment taking the difference, so they always add up to the total.
+// Installments that would fall due before the approval, or before the one
+// preceding them, are due with it instead.
+func (p *PaymentPlan) Schedule(total int, approvedAt time.Time, eventDate *time.Time) []ScheduledInstallment {
+	schedule := make([]ScheduledInstallment, 0, len(p.Installments))
+	due := approvedAt
+	allocated := 0
+	for i, inst := range p.Installments {
+		amount := int(math.Round(float64(total) * inst.Percent / 100))
+		if i == len(p.Installments)-1 {
+			amount = total - allocated
+		}
+		allocated += amount
+
+		if inst.DaysBeforeEvent != nil && eventDate != nil {
+			if d := eventDate.AddDate(0, 0, -*inst.DaysBeforeEvent); d.After(due) {
+				due = d
+			}
+		}
+		schedule = append(schedule, ScheduledInstallment{
+			Sequence: inst.Sequence,
+			Label:    inst.Label,
+			Amount:   amount,
+			DueDate:  due,
+		})
+	}
+	return schedule
+}
+
+type PaymentPlanPayload struct {
+	Name         string                          `json:"name" validate:"required,max=100"`
+	Description  string                          `json:"description" validate:"max=500"`
+	IsDefault    bool                            `json:"is_default"`
+	Installments []PaymentPlanInstallmentPayload `json:"installments" validate:"required,min=1,max=12,dive"`
+}
+
+type PaymentPlanInstallmentPayload struct {
+	Label           string  `json:"label" validate:"max=100"`
+	Percent         float64 `json:"percent" validate:"gt=0,lte=100"`
+	DaysBeforeEvent *int    `json:"days_before_event" validate:"omitempty,min=0,max=730"`
+}
+
+var ErrPlanPercentages = errors.New("installment percentages must add up to 100")
+
+// Check reports plans whose installments don't cover exactly the whole
+// quote.
+func (p *PaymentPlanPayload) Check() error {
+	var sum float64
+	for _, inst := range p.Installments {
+		sum += inst.Percent
+	}
+	if math.Abs(sum-100) > 0.001 {
+		return ErrPlanPercentages
+	}
+	return nil
+}
+
+// AssignPaymentPlanPayload sets or, with a null plan_id, clears the plan of
+// an event or event type.
+type AssignPaymentPlanPayload struct {
+	PlanID *uuid.UUID `json:"plan_id"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaymentPlanSchedule(t *testing.T) {
+	days := func(n int) *int { return &n }
+	plan := PaymentPlan{Installments: []PaymentPlanInstallment{
+		{Sequence: 1, Label: "Reserva", Percent: 30},
+		{Sequence: 2, Label: "Segundo pago", Percent: 40, DaysBeforeEvent: days(30)},
+		{Sequence: 3, Label: "Saldo", Percent: 30, DaysBeforeEvent: days(7)},
+	}}
+	approved := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("splits the total and dates installments from the event", func(t *testing.T) {
+		event := time.Date(2026, 6, 20, 18, 0, 0, 0, time.UTC)
+		got := plan.Schedule(10001, approved, &event)
+
+		wantAmounts := []int{3000, 4000, 3001}
+		wantDue := []time.Time{approved, event.AddDate(0, 0, -30), event.AddDate(0, 0, -7)}
+		for i, inst := range got {
+			if inst.Amount != wantAmounts[i] || !inst.DueDate.Equal(wantDue[i]) {
+				t.Errorf("installment %d: got %d due %v, want %d due %v", i+1, inst.Amount, inst.DueDate, wantAmounts[i], wantDue[i])
+			}
+		}
+	})
+
+	t.Run("never dates an installment before the approval", func(t *testing.T) {
+		event := approved.AddDate(0, 0, 10)
+		got := plan.Schedule(9000, approved, &event)
+
+		if !got[1].DueDate.Equal(approved) || !got[2].DueDate.Equal(event.AddDate(0, 0, -7)) {
+			t.Errorf("unexpected due dates %v, %v", got[1].DueDate, got[2].DueDate)
+		}
+	})
+
+	t.Run("without an event date everything is due on approval", func(t *testing.T) {
+		for _, inst := range plan.Schedule(9000, approved, nil) {
+			if !inst.DueDate.Equal(approved) {
+				t.Errorf("installment %d due %v", inst.Sequence, inst.DueDate)
+			}
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Permissions grant access to an area of the admin app. Roles are granted
+// any set of them in the database; the admin role holds them all.
+const (
+	PermDashboardRead   = "dashboard:read"
+	PermEventsRead      = "events:read"
+	PermEventsWrite     = "events:write"
+	PermClientsRead     = "clients:read"
+	PermClientsWrite    = "clients:write"
+	PermUsersBlock      = "users:block"
+	PermLeadsRead       = "leads:read"
+	PermLeadsWrite      = "leads:write"
+	PermInventoryRead   = "inventory:read"
+	PermInventoryWrite  = "inventory:write"
+	PermInventoryAdjust = "inventory:adjust"
+	PermFinancialRead   = "financial:read"
+	PermFinancialWrite  = "financial:write"
+	PermMessagesWrite   = "messages:write"
+	PermContentModerate = "content:moderate"
+	PermSettingsWrite   = "settings:write"
+	PermAuditRead       = "audit:read"
+	PermRolesManage     = "roles:manage"
+)
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Permissions is every permission a role can be granted. A permission
+// added here must also be granted to the admin role in a migration.
+var Permissions = []Permission{
+	{PermDashboardRead, "Ver el panel, estadísticas y reportes"},
+	{PermEventsRead, "Ver eventos y cotizaciones"},
+	{PermEventsWrite, "Crear y modificar eventos, cotizaciones y planes de pago"},
+	{PermClientsRead, "Ver clientes"},
+	{PermClientsWrite, "Crear y modificar clientes"},
+	{PermUsersBlock, "Bloquear usuarios y cerrar sus sesiones"},
+	{PermLeadsRead, "Ver prospectos y seguimientos"},
+	{PermLeadsWrite, "Gestionar prospectos y seguimientos"},
+	{PermInventoryRead, "Ver artículos, categorías y paquetes"},
+	{PermInventoryWrite, "Modificar artículos, categorías y paquetes"},
+	{PermInventoryAdjust, "Registrar mantenimiento y devoluciones de artículos"},
+	{PermFinancialRead, "Ver finanzas, facturas y pagos"},
+	{PermFinancialWrite, "Registrar pagos, facturas y gastos"},
+	{PermMessagesWrite, "Atender WhatsApp y configurar el chatbot"},
+	{PermContentModerate, "Moderar publicaciones y reseñas"},
+	{PermSettingsWrite, "Cambiar la configuración, plantillas y notificaciones"},
+	{PermAuditRead, "Ver el registro de auditoría"},
+	{PermRolesManage, "Gestionar roles y asignar personal"},
+}
+
+// PermissionNames are the names of every permission.
+func PermissionNames() []string {
+	names := make([]string, len(Permissions))
+	for i, p := range Permissions {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// ValidatePermissions checks that every name is a known permission.
+func ValidatePermissions(names []string) error {
+	for _, name := range names {
+		if !slices.ContainsFunc(Permissions, func(p Permission) bool { return p.Name == name }) {
+			return fmt.Errorf("unknown permission %q", name)
+		}
+	}
+	return nil
+}
@@ -1,14 +1,38 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/google/uuid"
 	"time"
 )
 
-// RefreshToken represents a refresh token in the system
+// RefreshToken represents a refresh token in the system. Token is only
+// known when the token is issued or presented; the database keeps its hash.
 type RefreshToken struct {
 	BaseModel
-	UserID    uuid.UUID `json:"user_id"`
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
+	UserID    uuid.UUID  `json:"user_id"`
+	SessionID uuid.UUID  `json:"session_id"`
+	Token     string     `json:"token"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// Session is a device a user is signed in on: a family of refresh tokens,
+// each replacing the one before it.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"-"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// Current is set for the session the request was made from.
+	Current bool `json:"current"`
+}
+
+// HashRefreshToken is how a refresh token is stored.
+func HashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
@@ -2,9 +2,36 @@ package models
 
 import "github.com/google/uuid"
 
+// Names of the roles the application relies on.
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Role groups the permissions of its users. System roles can't be renamed
+// or deleted, and the admin role always holds every permission.
 type Role struct {
 	ID          uuid.UUID `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
-	Level       int       `json:"level"`
+	IsSystem    bool      `json:"is_system,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
+	// Users is how many users have the role.
+	Users int `json:"users,omitempty"`
+}
+
+type CreateRolePayload struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRolePayload struct {
+	Name        *string  `json:"name" validate:"omitempty,max=50"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type AssignRolePayload struct {
+	RoleID uuid.UUID `json:"role_id" validate:"required"`
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactor is a user's TOTP authenticator. It protects the account once
+// EnabledAt is set; before that the user is still enrolling.
+type TwoFactor struct {
+	UserID    uuid.UUID
+	Secret    string
+	EnabledAt *time.Time
+	// LastStep is the time step of the last code accepted.
+	LastStep          int64
+	RecoveryCodesLeft int
+}
+
+func (t *TwoFactor) Enabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// TwoFactorStatus is what a user sees of their two-factor settings.
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment is handed to the user to add the secret to their
+// authenticator app, by scanning ProvisioningURI as a QR code or typing
+// the secret.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorChallenge is the second step of signing in for users with
+// two-factor enabled. Token is only known when the challenge is issued;
+// the database keeps its hash.
+type TwoFactorChallenge struct {
+	UserID    uuid.UUID
+	Token     string
+	ExpiresAt time.Time
+}
+
+// EnableTwoFactorPayload finishes enrollment with a code from the newly
+// enrolled authenticator.
+type EnableTwoFactorPayload struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorCodePayload proves the user holds their second factor, with a
+// code from their authenticator or one of their recovery codes.
+type TwoFactorCodePayload struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorLoginPayload completes signing in with the challenge token
+// returned for the password.
+type TwoFactorLoginPayload struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	TwoFactorCodePayload
+}
+
+// HashRecoveryCode is how a recovery code is stored. Codes are compared
+// ignoring case, spaces and dashes, however the user typed them.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+// HashChallengeToken is how a two-factor challenge token is stored.
+func HashChallengeToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -21,6 +23,27 @@ type User struct {
 	RoleID      uuid.UUID `json:"role_id"`
 	Role        Role      `json:"role"`
 	FCMToken    string    `json:"fcm_token,omitempty"`
+
+	BlockedAt     *time.Time `json:"blocked_at,omitempty"`
+	BlockedUntil  *time.Time `json:"blocked_until,omitempty"`
+	BlockedReason string     `json:"blocked_reason,omitempty"`
+	// TokenVersion is the version access tokens must carry to be valid.
+	TokenVersion int `json:"token_version"`
+	// TwoFactorEnabled is set once the user has enrolled an authenticator,
+	// and signing in takes a code from it.
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+}
+
+// Blocked reports whether the user is blocked at the given time. A
+// suspension stops blocking once BlockedUntil has passed.
+func (u *User) Blocked(now time.Time) bool {
+	return u.BlockedAt != nil && (u.BlockedUntil == nil || now.Before(*u.BlockedUntil))
+}
+
+// BlockUserPayload blocks a user, until Until if given.
+type BlockUserPayload struct {
+	Reason string     `json:"reason" validate:"required,max=500"`
+	Until  *time.Time `json:"until"`
 }
 
 type Password struct {
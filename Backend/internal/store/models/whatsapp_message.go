@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WhatsAppInbound  = "inbound"
+	WhatsAppOutbound = "outbound"
+)
+
+// WhatsAppMessage is a message exchanged with a client over WhatsApp. The
+// sender's phone is matched to a user and/or lead when it is received, and
+// messages from a user with an event are threaded into that event's chat.
+type WhatsAppMessage struct {
+	ID             uuid.UUID  `json:"id"`
+	WAMessageID    *string    `json:"wa_message_id,omitempty"`
+	Direction      string     `json:"direction"`
+	Phone          string     `json:"phone"`
+	ContactName    string     `json:"contact_name,omitempty"`
+	MessageType    string     `json:"message_type"`
+	Body           string     `json:"body"`
+	Status         string     `json:"status"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	LeadID         *uuid.UUID `json:"lead_id,omitempty"`
+	EventID        *uuid.UUID `json:"event_id,omitempty"`
+	EventMessageID *uuid.UUID `json:"event_message_id,omitempty"`
+	SentBy         *uuid.UUID `json:"sent_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// EventMessage is the copy written to event_messages, if any.
+	EventMessage *EventMessage `json:"-"`
+}
+
+// WhatsAppContact is who a phone number belongs to.
+type WhatsAppContact struct {
+	UserID  *uuid.UUID
+	LeadID  *uuid.UUID
+	EventID *uuid.UUID // the event the conversation is threaded into
+}
+
+// WhatsAppConversation summarises the latest message exchanged with a phone.
+type WhatsAppConversation struct {
+	Phone         string     `json:"phone"`
+	ContactName   string     `json:"contact_name,omitempty"`
+	UserID        *uuid.UUID `json:"user_id,omitempty"`
+	LeadID        *uuid.UUID `json:"lead_id,omitempty"`
+	EventID       *uuid.UUID `json:"event_id,omitempty"`
+	LastMessage   string     `json:"last_message"`
+	LastDirection string     `json:"last_direction"`
+	LastMessageAt time.Time  `json:"last_message_at"`
+	Unanswered    int        `json:"unanswered"`
+}
+
+type WhatsAppReplyPayload struct {
+	Body string `json:"body" validate:"required,max=4096"`
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+type NotificationTriggersStore struct {
+	db *sql.DB
+}
+
+const notificationTriggerColumns = `id, name, event_type, channel, template_id, days_offset, enabled, created_at, updated_at`
+
+func scanNotificationTrigger(row interface{ Scan(...any) error }, t *models.NotificationTrigger) error {
+	return row.Scan(&t.ID, &t.Name, &t.EventType, &t.Channel, &t.TemplateID, &t.DaysOffset, &t.Enabled, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (s *NotificationTriggersStore) list(ctx context.Context, query string) ([]models.NotificationTrigger, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []models.NotificationTrigger
+	for rows.Next() {
+		var t models.NotificationTrigger
+		if err := scanNotificationTrigger(rows, &t); err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers, rows.Err()
+}
+
+// GetAll returns every trigger, enabled or not.
+func (s *NotificationTriggersStore) GetAll(ctx context.Context) ([]models.NotificationTrigger, error) {
+	return s.list(ctx, `SELECT `+notificationTriggerColumns+` FROM notification_triggers ORDER BY created_at, name`)
+}
+
+// GetEnabled returns the triggers the scheduler should evaluate.
+func (s *NotificationTriggersStore) GetEnabled(ctx context.Context) ([]models.NotificationTrigger, error) {
+	return s.list(ctx, `SELECT `+notificationTriggerColumns+` FROM notification_triggers WHERE enabled ORDER BY created_at, name`)
+}
+
+func (s *NotificationTriggersStore) GetByID(ctx context.Context, id uuid.UUID) (*models.NotificationTrigger, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var t models.NotificationTrigger
+	row := s.db.QueryRowContext(ctx, `SELECT `+notificationTriggerColumns+` FROM notification_triggers WHERE id = $1`, id)
+	if err := scanNotificationTrigger(row, &t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *NotificationTriggersStore) Create(ctx context.Context, t *models.NotificationTrigger) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO notification_triggers (name, event_type, channel, template_id, days_offset, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	return s.db.QueryRowContext(ctx, query,
+		t.Name, t.EventType, t.Channel, t.TemplateID, t.DaysOffset, t.Enabled,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (s *NotificationTriggersStore) Update(ctx context.Context, t *models.NotificationTrigger) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE notification_triggers
+		SET name = $1, event_type = $2, channel = $3, template_id = $4, days_offset = $5, enabled = $6, updated_at = NOW()
+		WHERE id = $7
+		RETURNING updated_at`
+
+	err := s.db.QueryRowContext(ctx, query,
+		t.Name, t.EventType, t.Channel, t.TemplateID, t.DaysOffset, t.Enabled, t.ID,
+	).Scan(&t.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *NotificationTriggersStore) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM notification_triggers WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
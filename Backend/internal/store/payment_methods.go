@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrPaymentMethodUnavailable is returned when a payment names a method
+// that isn't configured, or, where the client chooses it, isn't enabled.
+var ErrPaymentMethodUnavailable = errors.New("payment method is not available")
+
+type PaymentMethodsStore struct {
+	db *sql.DB
+}
+
+const paymentMethodColumns = `id, code, name, enabled, instructions, surcharge_percent, requires_confirmation, sort_order, created_at, updated_at`
+
+type paymentMethodScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPaymentMethod(row paymentMethodScanner, m *models.PaymentMethod) error {
+	return row.Scan(
+		&m.ID,
+		&m.Code,
+		&m.Name,
+		&m.Enabled,
+		&m.Instructions,
+		&m.SurchargePercent,
+		&m.RequiresConfirmation,
+		&m.SortOrder,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+}
+
+// List returns the payment methods in display order, only the enabled ones
+// when enabledOnly is set.
+func (s *PaymentMethodsStore) List(ctx context.Context, enabledOnly bool) ([]models.PaymentMethod, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return listPaymentMethods(ctx, s.db, enabledOnly)
+}
+
+type paymentMethodQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func listPaymentMethods(ctx context.Context, q paymentMethodQuerier, enabledOnly bool) ([]models.PaymentMethod, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+paymentMethodColumns+`
+		FROM payment_methods
+		WHERE enabled OR NOT $1
+		ORDER BY sort_order ASC, name ASC`, enabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	methods := []models.PaymentMethod{}
+	for rows.Next() {
+		var m models.PaymentMethod
+		if err := scanPaymentMethod(rows, &m); err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+	}
+	return methods, rows.Err()
+}
+
+// GetByCode returns the method payments record as code, enabled or not.
+func (s *PaymentMethodsStore) GetByCode(ctx context.Context, code string) (*models.PaymentMethod, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var m models.PaymentMethod
+	err := scanPaymentMethod(s.db.QueryRowContext(ctx, `
+		SELECT `+paymentMethodColumns+`
+		FROM payment_methods
+		WHERE code = $1`, code), &m)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ReplaceAll saves the admin's method list: listed methods with an ID are
+// updated and the rest are created. Methods left out are disabled rather
+// than deleted, since past payments still name them. An ID that doesn't
+// exist is ErrNotFound and a code already in use is ErrConflict.
+func (s *PaymentMethodsStore) ReplaceAll(ctx context.Context, methods []models.PaymentMethodPayload) ([]models.PaymentMethod, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var result []models.PaymentMethod
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		keep := make([]uuid.UUID, 0, len(methods))
+		for _, m := range methods {
+			if m.ID == nil {
+				var id uuid.UUID
+				err := tx.QueryRowContext(ctx, `
+					INSERT INTO payment_methods (code, name, enabled, instructions, surcharge_percent, requires_confirmation, sort_order)
+					VALUES ($1, $2, $3, $4, $5, $6, $7)
+					RETURNING id`,
+					m.Code, m.Name, m.Enabled, m.Instructions, m.SurchargePercent, m.RequiresConfirmation, m.SortOrder,
+				).Scan(&id)
+				if err != nil {
+					return paymentMethodCodeTaken(err)
+				}
+				keep = append(keep, id)
+				continue
+			}
+
+			res, err := tx.ExecContext(ctx, `
+				UPDATE payment_methods
+				SET code = $1, name = $2, enabled = $3, instructions = $4, surcharge_percent = $5,
+				    requires_confirmation = $6, sort_order = $7, updated_at = NOW()
+				WHERE id = $8`,
+				m.Code, m.Name, m.Enabled, m.Instructions, m.SurchargePercent, m.RequiresConfirmation, m.SortOrder, *m.ID,
+			)
+			if err != nil {
+				return paymentMethodCodeTaken(err)
+			}
+			if n, err := res.RowsAffected(); err != nil {
+				return err
+			} else if n == 0 {
+				return ErrNotFound
+			}
+			keep = append(keep, *m.ID)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE payment_methods SET enabled = false, updated_at = NOW()
+			WHERE enabled AND NOT (id = ANY($1))`, pq.Array(keep)); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = listPaymentMethods(ctx, tx, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func paymentMethodCodeTaken(err error) error {
+	if err.Error() == `pq: duplicate key value violates unique constraint "payment_methods_code_key"` {
+		return ErrConflict
+	}
+	return err
+}
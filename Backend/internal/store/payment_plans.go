@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+type PaymentPlansStore struct {
+	db *sql.DB
+}
+
+type paymentPlanQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// loadPaymentPlans returns the plans matching the WHERE clause, with their
+// installments in order.
+func loadPaymentPlans(ctx context.Context, q paymentPlanQuerier, where string, args ...any) ([]models.PaymentPlan, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT p.id, p.name, p.description, p.is_default, p.created_at, p.updated_at,
+		       i.sequence, i.label, i.percent, i.days_before_event
+		FROM payment_plans p
+		LEFT JOIN payment_plan_installments i ON i.plan_id = p.id
+		`+where+`
+		ORDER BY p.is_default DESC, p.name ASC, p.id, i.sequence ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []models.PaymentPlan{}
+	for rows.Next() {
+		var p models.PaymentPlan
+		var sequence sql.NullInt64
+		var label sql.NullString
+		var percent sql.NullFloat64
+		var days sql.NullInt64
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.IsDefault, &p.CreatedAt, &p.UpdatedAt,
+			&sequence, &label, &percent, &days); err != nil {
+			return nil, err
+		}
+		if n := len(plans); n == 0 || plans[n-1].ID != p.ID {
+			p.Installments = []models.PaymentPlanInstallment{}
+			plans = append(plans, p)
+		}
+		if !sequence.Valid {
+			continue
+		}
+		inst := models.PaymentPlanInstallment{Sequence: int(sequence.Int64), Label: label.String, Percent: percent.Float64}
+		if days.Valid {
+			d := int(days.Int64)
+			inst.DaysBeforeEvent = &d
+		}
+		last := &plans[len(plans)-1]
+		last.Installments = append(last.Installments, inst)
+	}
+	return plans, rows.Err()
+}
+
+func (s *PaymentPlansStore) List(ctx context.Context) ([]models.PaymentPlan, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return loadPaymentPlans(ctx, s.db, "")
+}
+
+func (s *PaymentPlansStore) GetByID(ctx context.Context, id uuid.UUID) (*models.PaymentPlan, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return getPaymentPlan(ctx, s.db, id)
+}
+
+func getPaymentPlan(ctx context.Context, q paymentPlanQuerier, id uuid.UUID) (*models.PaymentPlan, error) {
+	plans, err := loadPaymentPlans(ctx, q, "WHERE p.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, ErrNotFound
+	}
+	return &plans[0], nil
+}
+
+// ForEvent returns the plan the event is billed with: its own, else its
+// event type's, else the default one. It is ErrNotFound if there is none.
+func (s *PaymentPlansStore) ForEvent(ctx context.Context, eventID uuid.UUID) (*models.PaymentPlan, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var planID uuid.NullUUID
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(e.payment_plan_id, t.payment_plan_id, (SELECT id FROM payment_plans WHERE is_default))
+		FROM events e
+		LEFT JOIN event_types t ON t.id = e.event_type_id
+		WHERE e.id = $1`, eventID).Scan(&planID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !planID.Valid {
+		return nil, ErrNotFound
+	}
+	return getPaymentPlan(ctx, s.db, planID.UUID)
+}
+
+func (s *PaymentPlansStore) Create(ctx context.Context, payload *models.PaymentPlanPayload) (*models.PaymentPlan, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var plan *models.PaymentPlan
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if payload.IsDefault {
+			if _, err := tx.ExecContext(ctx, `UPDATE payment_plans SET is_default = false WHERE is_default`); err != nil {
+				return err
+			}
+		}
+		var id uuid.UUID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO payment_plans (name, description, is_default)
+			VALUES ($1, $2, $3)
+			RETURNING id`, payload.Name, payload.Description, payload.IsDefault).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if err := insertPlanInstallmentsTx(ctx, tx, id, payload.Installments); err != nil {
+			return err
+		}
+		plan, err = getPaymentPlan(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Update replaces the plan and its installments. Schedules already
+// generated from it are left as they are.
+func (s *PaymentPlansStore) Update(ctx context.Context, id uuid.UUID, payload *models.PaymentPlanPayload) (*models.PaymentPlan, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var plan *models.PaymentPlan
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if payload.IsDefault {
+			if _, err := tx.ExecContext(ctx, `UPDATE payment_plans SET is_default = false WHERE is_default AND id <> $1`, id); err != nil {
+				return err
+			}
+		}
+		res, err := tx.ExecContext(ctx, `
+			UPDATE payment_plans
+			SET name = $1, description = $2, is_default = $3, updated_at = NOW()
+			WHERE id = $4`, payload.Name, payload.Description, payload.IsDefault, id)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM payment_plan_installments WHERE plan_id = $1`, id); err != nil {
+			return err
+		}
+		if err := insertPlanInstallmentsTx(ctx, tx, id, payload.Installments); err != nil {
+			return err
+		}
+		plan, err = getPaymentPlan(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func insertPlanInstallmentsTx(ctx context.Context, tx *sql.Tx, planID uuid.UUID, installments []models.PaymentPlanInstallmentPayload) error {
+	for i, inst := range installments {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO payment_plan_installments (plan_id, sequence, label, percent, days_before_event)
+			VALUES ($1, $2, $3, $4, $5)`,
+			planID, i+1, inst.Label, inst.Percent, inst.DaysBeforeEvent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes a plan; events and event types using it fall back to the
+// default. The default plan itself can't be deleted, which is ErrConflict.
+func (s *PaymentPlansStore) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var isDefault bool
+	err := s.db.QueryRowContext(ctx, `DELETE FROM payment_plans WHERE id = $1 AND NOT is_default RETURNING is_default`, id).
+		Scan(&isDefault)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := s.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return err
+}
+
+// AssignToEvent sets the plan of one event, overriding its event type's.
+// A nil plan clears the override. It is ErrNotFound if the event or the
+// plan doesn't exist.
+func (s *PaymentPlansStore) AssignToEvent(ctx context.Context, eventID uuid.UUID, planID *uuid.UUID) error {
+	return s.assign(ctx, `UPDATE events SET payment_plan_id = $1, updated_at = NOW() WHERE id = $2`, eventID, planID)
+}
+
+// AssignToEventType sets the plan of every event of the type without a
+// plan of its own. A nil plan goes back to the default.
+func (s *PaymentPlansStore) AssignToEventType(ctx context.Context, eventTypeID uuid.UUID, planID *uuid.UUID) error {
+	return s.assign(ctx, `UPDATE event_types SET payment_plan_id = $1, updated_at = NOW() WHERE id = $2`, eventTypeID, planID)
+}
+
+func (s *PaymentPlansStore) assign(ctx context.Context, query string, id uuid.UUID, planID *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if planID != nil {
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM payment_plans WHERE id = $1)`, *planID).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				return ErrNotFound
+			}
+		}
+		res, err := tx.ExecContext(ctx, query, planID, id)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
@@ -9,29 +9,45 @@ import (
 	"time"
 )
 
+// ErrRefreshTokenReused is returned when a refresh token that was already
+// rotated is presented again. Its session is revoked.
+var ErrRefreshTokenReused = errors.New("refresh token was already used")
+
 type RefreshTokensStore struct {
 	db *sql.DB
 }
 
-func (s *RefreshTokensStore) Create(ctx context.Context, token *models.RefreshToken) error {
-	query := `INSERT INTO refresh_tokens (user_id, token, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`
-
+// Create starts a session on the device and issues its first token.
+func (s *RefreshTokensStore) Create(ctx context.Context, token *models.RefreshToken, session *models.Session) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err := s.db.QueryRowContext(
-		ctx, query, token.UserID, token.Token, token.ExpiresAt,
-	).Scan(&token.ID, &token.Created, &token.Updated)
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO user_sessions (user_id, user_agent, ip)
+			VALUES ($1, $2, $3)
+			RETURNING id, created_at, last_used_at`,
+			token.UserID, session.UserAgent, session.IP,
+		).Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt)
+		if err != nil {
+			return err
+		}
+		session.UserID = token.UserID
+		token.SessionID = session.ID
+		return createRefreshTokenTx(ctx, tx, token)
+	})
+}
 
-	if err != nil {
-		return err
-	}
+func createRefreshTokenTx(ctx context.Context, tx *sql.Tx, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, session_id, token, expires_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`
 
-	return nil
+	return tx.QueryRowContext(
+		ctx, query, token.UserID, token.SessionID, models.HashRefreshToken(token.Token), token.ExpiresAt,
+	).Scan(&token.ID, &token.Created, &token.Updated)
 }
 
 func (s *RefreshTokensStore) GetByToken(ctx context.Context, tokenStr string) (*models.RefreshToken, error) {
-	query := `SELECT id, user_id, token, expires_at, created_at, updated_at FROM refresh_tokens WHERE token = $1`
+	query := `SELECT id, user_id, session_id, expires_at, used_at, created_at, updated_at FROM refresh_tokens WHERE token = $1`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -39,8 +55,8 @@ func (s *RefreshTokensStore) GetByToken(ctx context.Context, tokenStr string) (*
 	var token models.RefreshToken
 	var created, updated time.Time
 
-	err := s.db.QueryRowContext(ctx, query, tokenStr).Scan(
-		&token.ID, &token.UserID, &token.Token, &token.ExpiresAt, &created, &updated,
+	err := s.db.QueryRowContext(ctx, query, models.HashRefreshToken(tokenStr)).Scan(
+		&token.ID, &token.UserID, &token.SessionID, &token.ExpiresAt, &token.UsedAt, &created, &updated,
 	)
 
 	if err != nil {
@@ -52,31 +68,98 @@ func (s *RefreshTokensStore) GetByToken(ctx context.Context, tokenStr string) (*
 		}
 	}
 
+	token.Token = tokenStr
 	token.Created = &created
 	token.Updated = &updated
 
-	// Check if token is expired
 	if token.ExpiresAt.Before(time.Now()) {
-		// Delete the expired token
-		_ = s.Delete(ctx, tokenStr)
 		return nil, errors.New("refresh token expired")
 	}
 
 	return &token, nil
 }
 
+// Rotate exchanges the presented token for next, in the same session, and
+// records the device the session was last used from. It is ErrNotFound if
+// the token is unknown or expired. If the token was already rotated, the
+// session is revoked and ErrRefreshTokenReused is returned, with next
+// naming the user and session for the record.
+func (s *RefreshTokensStore) Rotate(ctx context.Context, presented string, next *models.RefreshToken, device *models.Session) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	reused := false
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var current models.RefreshToken
+		err := tx.QueryRowContext(ctx, `
+			SELECT id, user_id, session_id, expires_at, used_at
+			FROM refresh_tokens
+			WHERE token = $1
+			FOR UPDATE`, models.HashRefreshToken(presented),
+		).Scan(&current.ID, &current.UserID, &current.SessionID, &current.ExpiresAt, &current.UsedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		if current.UsedAt != nil {
+			reused = true
+			next.UserID = current.UserID
+			next.SessionID = current.SessionID
+			_, err := tx.ExecContext(ctx, `DELETE FROM user_sessions WHERE id = $1`, current.SessionID)
+			return err
+		}
+		if current.ExpiresAt.Before(time.Now()) {
+			return ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = NOW(), updated_at = NOW() WHERE id = $1`, current.ID); err != nil {
+			return err
+		}
+		// Rotated tokens are only kept to catch reuse until they expire.
+		if _, err := tx.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE session_id = $1 AND expires_at < NOW()`, current.SessionID); err != nil {
+			return err
+		}
+		err = tx.QueryRowContext(ctx, `
+			UPDATE user_sessions
+			SET user_agent = $2, ip = $3, last_used_at = NOW()
+			WHERE id = $1
+			RETURNING user_id, created_at, last_used_at`,
+			current.SessionID, device.UserAgent, device.IP,
+		).Scan(&device.UserID, &device.CreatedAt, &device.LastUsedAt)
+		if err != nil {
+			return err
+		}
+		device.ID = current.SessionID
+
+		next.UserID = current.UserID
+		next.SessionID = current.SessionID
+		return createRefreshTokenTx(ctx, tx, next)
+	})
+	if err != nil {
+		return err
+	}
+	if reused {
+		return ErrRefreshTokenReused
+	}
+	return nil
+}
+
+// Delete signs out of the session the token belongs to.
 func (s *RefreshTokensStore) Delete(ctx context.Context, tokenStr string) error {
-	query := `DELETE FROM refresh_tokens WHERE token = $1`
+	query := `DELETE FROM user_sessions WHERE id = (SELECT session_id FROM refresh_tokens WHERE token = $1)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := s.db.ExecContext(ctx, query, tokenStr)
+	_, err := s.db.ExecContext(ctx, query, models.HashRefreshToken(tokenStr))
 	return err
 }
 
 func (s *RefreshTokensStore) DeleteAllForUser(ctx context.Context, userID uuid.UUID) error {
-	query := `DELETE FROM refresh_tokens WHERE user_id = $1`
+	query := `DELETE FROM user_sessions WHERE user_id = $1`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -84,3 +167,60 @@ func (s *RefreshTokensStore) DeleteAllForUser(ctx context.Context, userID uuid.U
 	_, err := s.db.ExecContext(ctx, query, userID)
 	return err
 }
+
+// ListSessions returns the user's sessions, most recently used first.
+// Sessions whose tokens have all expired are left out.
+func (s *RefreshTokensStore) ListSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, s.user_id, s.user_agent, s.ip, s.created_at, s.last_used_at
+		FROM user_sessions s
+		WHERE s.user_id = $1
+		  AND EXISTS (
+		      SELECT 1 FROM refresh_tokens t
+		      WHERE t.session_id = s.id AND t.used_at IS NULL AND t.expires_at > NOW()
+		  )
+		ORDER BY s.last_used_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// SessionExists reports whether the session is still signed in.
+func (s *RefreshTokensStore) SessionExists(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM user_sessions WHERE id = $1)`, sessionID).Scan(&exists)
+	return exists, err
+}
+
+// DeleteSession signs the user out of one of their sessions. It is
+// ErrNotFound if the session isn't theirs.
+func (s *RefreshTokensStore) DeleteSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM user_sessions WHERE id = $1 AND user_id = $2`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
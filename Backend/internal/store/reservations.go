@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+// Reservation statuses stored in inventory_availability.status.
+const (
+	ReservationHeld      = "held"
+	ReservationReserved  = "reserved"
+	ReservationConfirmed = "confirmed"
+	ReservationDelivered = "delivered"
+	ReservationReturned  = "returned"
+)
+
+// HoldTTL is how long a requested event keeps its items on soft hold
+// before the expiry worker gives the stock back.
+var HoldTTL = 72 * time.Hour
+
+type reservationAction int
+
+const (
+	reservationKeep reservationAction = iota
+	reservationHold
+	reservationConfirm
+	reservationRelease
+)
+
+// reservationActionFor maps an event status to what its inventory rows
+// should look like. Completed events keep their rows untouched: the items
+// come back through the return flow, not through a status change.
+func reservationActionFor(status string) reservationAction {
+	switch status {
+	case models.EventStatusRequested, models.EventStatusAdjusted:
+		return reservationHold
+	case models.EventStatusConfirmed, models.EventStatusPaid:
+		return reservationConfirm
+	case models.EventStatusDraft, models.EventStatusPlanning,
+		models.EventStatusRejected, models.EventStatusCancelled:
+		return reservationRelease
+	default:
+		return reservationKeep
+	}
+}
+
+// syncEventReservationsTx brings the event's inventory rows in line with
+// its current status and items. Held and confirmed events are re-reserved
+// from scratch: their own rows are released first and every item is
+// reserved again, so the call is idempotent and picks up quantity, date and
+// rental window changes.
+//
+// A hold keeps the deadline it was given when it started, so editing the
+// event doesn't extend it. Once the deadline has passed the stock is no
+// longer the event's: an edit after that checks the stock again and starts
+// a new hold, or fails if the stock was taken in the meantime.
+func syncEventReservationsTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID) error {
+	var status string
+	var date *time.Time
+	err := tx.QueryRowContext(ctx, `SELECT status, date FROM events WHERE id = $1 FOR UPDATE`, eventID).
+		Scan(&status, &date)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	action := reservationActionFor(status)
+	switch action {
+	case reservationKeep:
+		return nil
+	case reservationRelease:
+		return releaseEventTx(ctx, tx, eventID)
+	}
+
+	// An undated event has nothing to hold stock against yet.
+	if date == nil {
+		return releaseEventTx(ctx, tx, eventID)
+	}
+
+	w, err := eventRentalWindowTx(ctx, tx, eventID, *date)
+	if err != nil {
+		return err
+	}
+
+	heldUntil, err := eventHoldDeadlineTx(ctx, tx, eventID)
+	if err != nil {
+		return err
+	}
+	if heldUntil == nil || !heldUntil.After(time.Now()) {
+		next := time.Now().Add(HoldTTL)
+		heldUntil = &next
+	}
+
+	if err := releaseEventTx(ctx, tx, eventID); err != nil {
+		return err
+	}
+
+	lines, err := eventReservationLinesTx(ctx, tx, eventID)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if action == reservationHold {
+		return reserveTx(ctx, tx, eventID, w, lines, ReservationHeld, heldUntil)
+	}
+	return reserveTx(ctx, tx, eventID, w, lines, ReservationConfirmed, nil)
+}
+
+// eventHoldDeadlineTx returns when the event's current hold runs out, or
+// nil if it holds nothing.
+func eventHoldDeadlineTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID) (*time.Time, error) {
+	var heldUntil *time.Time
+	query := `
+		SELECT MIN(held_until)
+		FROM inventory_availability
+		WHERE event_id = $1 AND status = 'held'`
+	if err := tx.QueryRowContext(ctx, query, eventID).Scan(&heldUntil); err != nil {
+		return nil, err
+	}
+	return heldUntil, nil
+}
+
+// releaseEventTx gives back every unit the event is still holding.
+func releaseEventTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID) error {
+	query := `
+		UPDATE inventory_availability
+		SET status = 'returned', held_until = NULL, updated_at = NOW()
+		WHERE event_id = $1 AND status NOT IN ('returned')`
+	_, err := tx.ExecContext(ctx, query, eventID)
+	return err
+}
+
+// checkEventStockTx makes sure the stock can cover the items of a dated
+// draft or planning event without holding any of it, so a line that could
+// never be booked is refused when it is added rather than when the event
+// is requested. Events in any other status are left to
+// syncEventReservationsTx.
+func checkEventStockTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID) error {
+	var status string
+	var date *time.Time
+	err := tx.QueryRowContext(ctx, `SELECT status, date FROM events WHERE id = $1 FOR UPDATE`, eventID).
+		Scan(&status, &date)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if date == nil || (status != models.EventStatusDraft && status != models.EventStatusPlanning) {
+		return nil
+	}
+
+	w, err := eventRentalWindowTx(ctx, tx, eventID, *date)
+	if err != nil {
+		return err
+	}
+	lines, err := eventReservationLinesTx(ctx, tx, eventID)
+	if err != nil {
+		return err
+	}
+	_, err = checkStockTx(ctx, tx, w, lines)
+	return err
+}
+
+// eventRentalWindowTx returns the pickup and return times set on the event.
+// Without them the last window stored for the event date is reused, so one
+// set through the availability endpoints is kept, falling back to the
+// default one.
+func eventRentalWindowTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID, date time.Time) (RentalWindow, error) {
+	w := DefaultRentalWindow(date)
+
+	var pickupAt, returnAt *time.Time
+	err := tx.QueryRowContext(ctx, `SELECT pickup_at, return_at FROM events WHERE id = $1`, eventID).
+		Scan(&pickupAt, &returnAt)
+	if err != nil {
+		return RentalWindow{}, err
+	}
+	if pickupAt != nil && returnAt != nil {
+		w.PickupAt, w.ReturnAt = *pickupAt, *returnAt
+		return w, nil
+	}
+
+	windowQuery := `
+		SELECT pickup_at, return_at
+		FROM inventory_availability
+		WHERE event_id = $1 AND event_date = $2
+		ORDER BY updated_at DESC
+		LIMIT 1`
+	err = tx.QueryRowContext(ctx, windowQuery, eventID, w.EventDate).Scan(&w.PickupAt, &w.ReturnAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return RentalWindow{}, err
+	}
+	return w, nil
+}
+
+// eventReservationLinesTx sums the event's items per variant. Lines added
+// without a variant reserve the article's first active one.
+func eventReservationLinesTx(ctx context.Context, tx *sql.Tx, eventID uuid.UUID) ([]ReservationLine, error) {
+	itemsQuery := `
+		SELECT variant_id, SUM(quantity)
+		FROM (
+			SELECT COALESCE(ei.variant_id, (
+				SELECT v.id FROM article_variants v
+				WHERE v.article_id = ei.article_id AND v.is_active = TRUE
+				ORDER BY v.created_at ASC
+				LIMIT 1
+			)) AS variant_id, ei.quantity
+			FROM event_items ei
+			WHERE ei.event_id = $1
+		) items
+		WHERE variant_id IS NOT NULL
+		GROUP BY variant_id`
+	rows, err := tx.QueryContext(ctx, itemsQuery, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []ReservationLine
+	for rows.Next() {
+		var line ReservationLine
+		if err := rows.Scan(&line.ArticleID, &line.Quantity); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"Backend/internal/store/models"
+	"Backend/internal/testutils"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventReservations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	tdb := testutils.SetupTestDatabase(t)
+	defer tdb.Teardown(t)
+
+	events := &EventStore{db: tdb.Db}
+	availability := &AvailabilityStore{db: tdb.Db}
+	ctx := context.Background()
+
+	user := &models.User{
+		UserName:  gofakeit.Username(),
+		FirstName: gofakeit.FirstName(),
+		LastName:  gofakeit.LastName(),
+		Email:     gofakeit.Email(),
+	}
+	user.Password.Set("password123")
+	user.Role.Name = "user"
+	require.NoError(t, (&UsersStore{db: tdb.Db}).Create(ctx, nil, user))
+
+	article := &models.Article{
+		NameTemplate: "Chair",
+		Type:         models.ArticleTypeRental,
+		IsActive:     true,
+		Variants:     []models.ArticleVariant{{Sku: gofakeit.UUID(), Name: "White", IsActive: true, Stock: 5}},
+	}
+	require.NoError(t, (&ArticlesStore{db: tdb.Db}).Create(ctx, article))
+	variantID := article.Variants[0].ID
+
+	date := time.Date(2030, time.June, 14, 0, 0, 0, 0, time.UTC)
+
+	// newEvent creates a dated draft with quantity units of the chair.
+	newEvent := func(t *testing.T, quantity int) *models.Event {
+		event := &models.Event{UserID: user.ID, Name: "Party", Date: &date, PaymentStatus: "pending"}
+		require.NoError(t, events.Create(ctx, event))
+		item := &models.EventItem{EventID: event.ID, ArticleID: article.ID, VariantID: &variantID, Quantity: quantity}
+		require.NoError(t, events.AddItemWithReservation(ctx, item))
+		return event
+	}
+	setStatus := func(t *testing.T, event *models.Event, status string) {
+		event.Status = status
+		require.NoError(t, events.Update(ctx, event))
+	}
+	// rows returns the status and quantity of the event's reservation rows.
+	rows := func(t *testing.T, eventID uuid.UUID) map[string]int {
+		r, err := tdb.Db.QueryContext(ctx, `SELECT status, quantity_used FROM inventory_availability WHERE event_id = $1`, eventID)
+		require.NoError(t, err)
+		defer r.Close()
+		got := map[string]int{}
+		for r.Next() {
+			var status string
+			var quantity int
+			require.NoError(t, r.Scan(&status, &quantity))
+			got[status] += quantity
+		}
+		require.NoError(t, r.Err())
+		return got
+	}
+
+	t.Run("drafts hold nothing but can't exceed stock", func(t *testing.T) {
+		event := newEvent(t, 2)
+		assert.Empty(t, rows(t, event.ID))
+
+		item := &models.EventItem{EventID: event.ID, ArticleID: article.ID, VariantID: &variantID, Quantity: 4}
+		err := events.AddItemWithReservation(ctx, item)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+	})
+
+	t.Run("requesting holds and approving the adjusted quote confirms", func(t *testing.T) {
+		event := newEvent(t, 2)
+		setStatus(t, event, models.EventStatusRequested)
+		assert.Equal(t, map[string]int{ReservationHeld: 2}, rows(t, event.ID))
+
+		assert.ErrorIs(t, events.ApproveQuote(ctx, event.ID, user.ID, 10000, nil), ErrInvalidTransition, "only adjusted quotes can be approved")
+		setStatus(t, event, models.EventStatusAdjusted)
+
+		schedule := models.FallbackPaymentPlan.Schedule(10000, time.Now(), &date)
+		require.NoError(t, events.ApproveQuote(ctx, event.ID, user.ID, 10000, schedule))
+		assert.Equal(t, map[string]int{ReservationConfirmed: 2}, rows(t, event.ID))
+
+		var approvals int
+		err := tdb.Db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM audit_logs
+			WHERE event_id = $1 AND action = $2 AND old_value = $3 AND new_value = $4`,
+			event.ID, models.AuditActionQuoteApprove, models.EventStatusAdjusted, models.EventStatusConfirmed,
+		).Scan(&approvals)
+		require.NoError(t, err)
+		assert.Equal(t, 1, approvals)
+
+		event, err = events.GetByID(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 10000, event.TotalQuote)
+		assert.Equal(t, schedule[0].Amount, event.DepositAmount)
+		setStatus(t, event, models.EventStatusPaid)
+		assert.Equal(t, map[string]int{ReservationConfirmed: 2}, rows(t, event.ID))
+
+		setStatus(t, event, models.EventStatusCancelled)
+		assert.Equal(t, map[string]int{ReservationReturned: 2}, rows(t, event.ID))
+	})
+
+	t.Run("rejecting releases the hold", func(t *testing.T) {
+		event := newEvent(t, 2)
+		setStatus(t, event, models.EventStatusRequested)
+		setStatus(t, event, models.EventStatusAdjusted)
+
+		require.NoError(t, events.RejectQuote(ctx, event.ID, user.ID))
+		assert.Equal(t, map[string]int{ReservationReturned: 2}, rows(t, event.ID))
+	})
+
+	t.Run("deleting frees the stock", func(t *testing.T) {
+		event := newEvent(t, 5)
+		other := newEvent(t, 1)
+		setStatus(t, event, models.EventStatusRequested)
+
+		other.Status = models.EventStatusRequested
+		assert.ErrorIs(t, events.Update(ctx, other), ErrInsufficientStock)
+
+		require.NoError(t, events.Delete(ctx, event.ID))
+		assert.Empty(t, rows(t, event.ID))
+		setStatus(t, other, models.EventStatusRequested)
+		assert.Equal(t, map[string]int{ReservationHeld: 1}, rows(t, other.ID))
+		setStatus(t, other, models.EventStatusCancelled)
+	})
+
+	t.Run("the event's own rental window is reserved and kept", func(t *testing.T) {
+		event := newEvent(t, 1)
+		pickupAt := date.Add(-6 * time.Hour)
+		returnAt := date.Add(30 * time.Hour)
+		event.PickupAt, event.ReturnAt = &pickupAt, &returnAt
+		setStatus(t, event, models.EventStatusRequested)
+
+		window := func() (time.Time, time.Time) {
+			var from, until time.Time
+			err := tdb.Db.QueryRowContext(ctx, `SELECT pickup_at, return_at FROM inventory_availability WHERE event_id = $1`, event.ID).
+				Scan(&from, &until)
+			require.NoError(t, err)
+			return from, until
+		}
+		from, until := window()
+		assert.True(t, from.Equal(pickupAt) && until.Equal(returnAt), "expected %v-%v, got %v-%v", pickupAt, returnAt, from, until)
+
+		require.NoError(t, availability.ReserveInventory(ctx, variantID, event.ID, DefaultRentalWindow(date), 1))
+		from, until = window()
+		assert.True(t, from.Equal(pickupAt) && until.Equal(returnAt), "expected the window to be kept, got %v-%v", from, until)
+
+		item := &models.EventItem{EventID: event.ID, ArticleID: article.ID, VariantID: &variantID, Quantity: 1}
+		require.NoError(t, events.AddItemWithReservation(ctx, item))
+		from, until = window()
+		assert.True(t, from.Equal(pickupAt) && until.Equal(returnAt), "expected the window to be kept, got %v-%v", from, until)
+
+		setStatus(t, event, models.EventStatusCancelled)
+	})
+
+	t.Run("expired holds are freed", func(t *testing.T) {
+		event := newEvent(t, 2)
+		setStatus(t, event, models.EventStatusRequested)
+
+		expired, err := availability.ExpireHolds(ctx, time.Now())
+		require.NoError(t, err)
+		assert.NotContains(t, expired, event.ID)
+
+		expired, err = availability.ExpireHolds(ctx, time.Now().Add(HoldTTL+time.Hour))
+		require.NoError(t, err)
+		assert.Contains(t, expired, event.ID)
+		assert.Equal(t, map[string]int{ReservationReturned: 2}, rows(t, event.ID))
+	})
+
+	// heldUntil returns when the event's hold runs out.
+	heldUntil := func(t *testing.T, eventID uuid.UUID) time.Time {
+		var until time.Time
+		err := tdb.Db.QueryRowContext(ctx, `SELECT MIN(held_until) FROM inventory_availability WHERE event_id = $1 AND status = 'held'`, eventID).
+			Scan(&until)
+		require.NoError(t, err)
+		return until
+	}
+
+	t.Run("edits keep the hold's deadline", func(t *testing.T) {
+		event := newEvent(t, 2)
+		setStatus(t, event, models.EventStatusRequested)
+		deadline := heldUntil(t, event.ID)
+
+		item := &models.EventItem{EventID: event.ID, ArticleID: article.ID, VariantID: &variantID, Quantity: 1}
+		require.NoError(t, events.AddItemWithReservation(ctx, item))
+		assert.Equal(t, map[string]int{ReservationHeld: 3}, rows(t, event.ID))
+		assert.True(t, heldUntil(t, event.ID).Equal(deadline), "expected the deadline to be kept")
+
+		setStatus(t, event, models.EventStatusCancelled)
+	})
+
+	t.Run("edits after the hold expired check stock and start a new hold", func(t *testing.T) {
+		event := newEvent(t, 2)
+		setStatus(t, event, models.EventStatusRequested)
+		deadline := heldUntil(t, event.ID)
+
+		_, err := availability.ExpireHolds(ctx, time.Now().Add(HoldTTL+time.Hour))
+		require.NoError(t, err)
+		other := newEvent(t, 2)
+		setStatus(t, other, models.EventStatusRequested)
+
+		item := &models.EventItem{EventID: event.ID, ArticleID: article.ID, VariantID: &variantID, Quantity: 2}
+		assert.ErrorIs(t, events.AddItemWithReservation(ctx, item), ErrInsufficientStock)
+		assert.Equal(t, map[string]int{ReservationReturned: 2}, rows(t, event.ID))
+
+		setStatus(t, other, models.EventStatusCancelled)
+		require.NoError(t, events.AddItemWithReservation(ctx, item))
+		assert.Equal(t, map[string]int{ReservationHeld: 4}, rows(t, event.ID))
+		assert.True(t, heldUntil(t, event.ID).After(deadline), "expected a new hold")
+
+		setStatus(t, event, models.EventStatusCancelled)
+	})
+}
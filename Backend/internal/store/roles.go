@@ -4,21 +4,200 @@ import (
 	"Backend/internal/store/models"
 	"context"
 	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// ErrSystemRole is returned when changing what can't change about a system
+// role.
+var ErrSystemRole = errors.New("system roles can't be renamed or deleted, and the admin role's permissions can't change")
+
 type RolesStore struct {
 	db *sql.DB
 }
 
+const roleColumns = `r.id, r.name, COALESCE(r.description, ''), r.is_system,
+	COALESCE((SELECT array_agg(rp.permission ORDER BY rp.permission) FROM role_permissions rp WHERE rp.role_id = r.id), '{}'),
+	(SELECT COUNT(*) FROM users u WHERE u.role_id = r.id)`
+
+func scanRole(row interface{ Scan(...any) error }) (*models.Role, error) {
+	var role models.Role
+	var permissions pq.StringArray
+	if err := row.Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &permissions, &role.Users); err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+	return &role, nil
+}
+
 func (s *RolesStore) RetrieveByName(ctx context.Context, name string) (*models.Role, error) {
-	query := `SELECT id, name, description, level FROM roles WHERE name = $1`
+	query := `SELECT ` + roleColumns + ` FROM roles r WHERE r.name = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
-	role := &models.Role{}
-	err := s.db.QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, &role.Description, &role.Level)
+	role, err := scanRole(s.db.QueryRowContext(ctx, query, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return role, err
+}
 
+func (s *RolesStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	role, err := scanRole(s.db.QueryRowContext(ctx, `SELECT `+roleColumns+` FROM roles r WHERE r.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return role, err
+}
+
+// List returns every role with its permissions and how many users have it.
+func (s *RolesStore) List(ctx context.Context) ([]models.Role, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+roleColumns+` FROM roles r ORDER BY r.is_system DESC, r.name`)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	roles := []models.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+// Permissions returns the permissions granted to the role.
+func (s *RolesStore) Permissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
 
-	return role, nil
+	var permissions pq.StringArray
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(array_agg(permission), '{}') FROM role_permissions WHERE role_id = $1`, roleID,
+	).Scan(&permissions)
+	return permissions, err
+}
+
+// Create adds a role with its permissions. It is ErrConflict if the name
+// is taken.
+func (s *RolesStore) Create(ctx context.Context, role *models.Role) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO roles (name, description) VALUES ($1, $2)
+			ON CONFLICT (name) DO NOTHING
+			RETURNING id`, role.Name, role.Description,
+		).Scan(&role.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrConflict
+		}
+		if err != nil {
+			return err
+		}
+		return setRolePermissionsTx(ctx, tx, role.ID, role.Permissions)
+	})
+}
+
+// Update saves the role's name, description and permissions. It is
+// ErrSystemRole if a system role would be renamed or the admin role's
+// permissions changed, and ErrConflict if the new name is taken.
+func (s *RolesStore) Update(ctx context.Context, role *models.Role) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		current, err := scanRole(tx.QueryRowContext(ctx, `SELECT `+roleColumns+` FROM roles r WHERE r.id = $1 FOR UPDATE`, role.ID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.IsSystem && role.Name != current.Name {
+			return ErrSystemRole
+		}
+		if current.Name == models.RoleAdmin && !samePermissions(current.Permissions, role.Permissions) {
+			return ErrSystemRole
+		}
+
+		var taken bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1 AND id <> $2)`, role.Name, role.ID).Scan(&taken)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrConflict
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE roles SET name = $2, description = $3 WHERE id = $1`, role.ID, role.Name, role.Description); err != nil {
+			return err
+		}
+		role.IsSystem = current.IsSystem
+		role.Users = current.Users
+		return setRolePermissionsTx(ctx, tx, role.ID, role.Permissions)
+	})
+}
+
+// Delete removes a role. It is ErrSystemRole for system roles and
+// ErrConflict while users still have it.
+func (s *RolesStore) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		current, err := scanRole(tx.QueryRowContext(ctx, `SELECT `+roleColumns+` FROM roles r WHERE r.id = $1 FOR UPDATE`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.IsSystem {
+			return ErrSystemRole
+		}
+		if current.Users > 0 {
+			return ErrConflict
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, id)
+		return err
+	})
+}
+
+func setRolePermissionsTx(ctx context.Context, tx *sql.Tx, roleID uuid.UUID, permissions []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO role_permissions (role_id, permission)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING`, roleID, pq.Array(permissions))
+	return err
+}
+
+func samePermissions(a, b []string) bool {
+	set := make(map[string]bool, len(a))
+	for _, p := range a {
+		set[p] = true
+	}
+	for _, p := range b {
+		if !set[p] {
+			return false
+		}
+		delete(set, p)
+	}
+	return len(set) == 0
 }
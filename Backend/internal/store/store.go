@@ -15,6 +15,8 @@ var (
 	ErrNotFound          = errors.New("resource not found")
 	QueryTimeoutDuration = 5 * time.Second
 	ErrConflict          = errors.New("resource conflict")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidTransition = errors.New("invalid status transition")
 )
 
 type Storage struct {
@@ -60,19 +62,46 @@ type Storage struct {
 		DeletePasswordResetTokenByToken(context.Context, string) error
 		UpdatePassword(context.Context, uuid.UUID, []byte) error
 		GetAllClientsForExport(context.Context) ([]models.ClientExport, error)
+		Block(ctx context.Context, userID, blockedBy uuid.UUID, until *time.Time, reason string) error
+		Unblock(context.Context, uuid.UUID) error
+		RevokeTokens(context.Context, uuid.UUID) error
+		SetRole(ctx context.Context, userID, roleID uuid.UUID) error
+		ListStaff(context.Context) ([]models.User, error)
 	}
 	Roles interface {
 		RetrieveByName(context.Context, string) (*models.Role, error)
+		GetByID(context.Context, uuid.UUID) (*models.Role, error)
+		List(context.Context) ([]models.Role, error)
+		Permissions(ctx context.Context, roleID uuid.UUID) ([]string, error)
+		Create(context.Context, *models.Role) error
+		Update(context.Context, *models.Role) error
+		Delete(context.Context, uuid.UUID) error
 	}
 	Comments interface {
 		CreatePostComment(context.Context, *models.Comment) error
 		RetrieveCommentsByPostId(context.Context, uuid.UUID) ([]models.Comment, error)
 	}
 	RefreshTokens interface {
-		Create(context.Context, *models.RefreshToken) error
+		Create(context.Context, *models.RefreshToken, *models.Session) error
 		GetByToken(context.Context, string) (*models.RefreshToken, error)
+		Rotate(context.Context, string, *models.RefreshToken, *models.Session) error
 		Delete(context.Context, string) error
 		DeleteAllForUser(context.Context, uuid.UUID) error
+		ListSessions(context.Context, uuid.UUID) ([]models.Session, error)
+		SessionExists(context.Context, uuid.UUID) (bool, error)
+		DeleteSession(context.Context, uuid.UUID, uuid.UUID) error
+	}
+	TwoFactor interface {
+		Get(context.Context, uuid.UUID) (*models.TwoFactor, error)
+		Enroll(ctx context.Context, userID uuid.UUID, secret string) error
+		Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryCodes []string) error
+		Disable(context.Context, uuid.UUID) error
+		UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+		UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (bool, error)
+		ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []string) error
+		CreateChallenge(context.Context, *models.TwoFactorChallenge) error
+		AttemptChallenge(ctx context.Context, token string, maxAttempts int) (uuid.UUID, error)
+		DeleteChallenge(context.Context, string) error
 	}
 	Events interface {
 		Create(context.Context, *models.Event) error
@@ -87,12 +116,19 @@ type Storage struct {
 		Update(context.Context, *models.Event) error
 		Delete(context.Context, uuid.UUID) error
 		AddItem(context.Context, *models.EventItem) error
+		// AddItemWithReservation adds the line and reserves its stock for
+		// the event's rental window in a single transaction. It fails with
+		// an *InsufficientStockError when the variant cannot cover it.
+		AddItemWithReservation(context.Context, *models.EventItem) error
 		UpdateItemQuantity(context.Context, uuid.UUID, int) error
 		RemoveItem(context.Context, uuid.UUID, uuid.UUID) error
 		GetItems(context.Context, uuid.UUID) ([]models.EventItem, error)
 		GetDebrief(context.Context, uuid.UUID) (*models.EventDebrief, error)
 		GetAll(context.Context) ([]models.Event, error)
-		ApproveQuote(context.Context, uuid.UUID, uuid.UUID) error
+		GetForTriggers(ctx context.Context, from, until time.Time) ([]models.TriggerEvent, error)
+		// ApproveQuote confirms the event and schedules its payments for
+		// the approved total in the same transaction.
+		ApproveQuote(context.Context, uuid.UUID, uuid.UUID, int, []models.ScheduledInstallment) error
 		RejectQuote(context.Context, uuid.UUID, uuid.UUID) error
 	}
 	Guests interface {
@@ -133,6 +169,17 @@ type Storage struct {
 	Stats interface {
 		GetSummary(context.Context) (*models.AdminStats, error)
 	}
+	Analytics interface {
+		EventVolume(context.Context, models.AnalyticsFilter) ([]models.EventVolumePoint, error)
+		Revenue(context.Context, models.AnalyticsFilter) ([]models.RevenuePoint, error)
+		TopArticles(context.Context, models.AnalyticsFilter, int) ([]models.TopArticle, error)
+		Conversion(context.Context, models.AnalyticsFilter) (*models.ConversionStats, error)
+		PendingPayments(context.Context, models.AnalyticsFilter) ([]models.PendingPayment, error)
+		LeadFunnel(context.Context, models.AnalyticsFilter) (*models.LeadFunnel, error)
+		MaintenanceCosts(context.Context, models.AnalyticsFilter) ([]models.MaintenanceCost, error)
+		EventProfits(context.Context, models.AnalyticsFilter) ([]models.EventProfit, error)
+		EventProfit(context.Context, uuid.UUID) (*models.EventProfit, error)
+	}
 	Reviews interface {
 		Create(context.Context, *models.Review) error
 		GetByArticleID(context.Context, uuid.UUID) ([]models.Review, error)
@@ -172,7 +219,7 @@ type Storage struct {
 	DeliveryZones interface {
 		GetAll(context.Context) ([]models.DeliveryZone, error)
 		GetByID(context.Context, uuid.UUID) (*models.DeliveryZone, error)
-		CalculateFee(context.Context, string) (*models.DeliveryFeeResponse, error)
+		ReplaceAll(context.Context, []models.DeliveryZonePayload) ([]models.DeliveryZone, error)
 	}
 	Bundles interface {
 		GetAll(context.Context) ([]models.Bundle, error)
@@ -200,10 +247,30 @@ type Storage struct {
 	Installments interface {
 		CreateInstallmentPayment(ctx context.Context, eventID uuid.UUID, amount int, dueDate *time.Time) (*models.InstallmentPayment, error)
 		GetInstallmentByEventID(ctx context.Context, eventID uuid.UUID) ([]models.InstallmentPayment, error)
-		MarkPaid(ctx context.Context, paymentID uuid.UUID, paymentMethod string) error
+		MarkPaid(ctx context.Context, paymentID uuid.UUID, paymentMethod string) (*models.InstallmentPayment, error)
+		CreateSchedule(ctx context.Context, eventID uuid.UUID, schedule []models.ScheduledInstallment) ([]models.InstallmentPayment, error)
+		SubmitReceipt(ctx context.Context, paymentID uuid.UUID, paymentMethod, receiptURL string) (*models.InstallmentPayment, error)
+		ConfirmPayment(ctx context.Context, paymentID, adminID uuid.UUID) (*models.InstallmentPayment, error)
+		RejectPayment(ctx context.Context, paymentID, adminID uuid.UUID, reason string) (*models.InstallmentPayment, error)
+		ListAwaitingVerification(ctx context.Context) ([]models.PaymentVerification, error)
 		GetPendingInstallments(ctx context.Context) ([]models.InstallmentPayment, error)
 		GetByID(ctx context.Context, paymentID uuid.UUID) (*models.InstallmentPayment, error)
 	}
+	PaymentMethods interface {
+		List(context.Context, bool) ([]models.PaymentMethod, error)
+		GetByCode(context.Context, string) (*models.PaymentMethod, error)
+		ReplaceAll(context.Context, []models.PaymentMethodPayload) ([]models.PaymentMethod, error)
+	}
+	PaymentPlans interface {
+		List(context.Context) ([]models.PaymentPlan, error)
+		GetByID(context.Context, uuid.UUID) (*models.PaymentPlan, error)
+		ForEvent(context.Context, uuid.UUID) (*models.PaymentPlan, error)
+		Create(context.Context, *models.PaymentPlanPayload) (*models.PaymentPlan, error)
+		Update(context.Context, uuid.UUID, *models.PaymentPlanPayload) (*models.PaymentPlan, error)
+		Delete(context.Context, uuid.UUID) error
+		AssignToEvent(ctx context.Context, eventID uuid.UUID, planID *uuid.UUID) error
+		AssignToEventType(ctx context.Context, eventTypeID uuid.UUID, planID *uuid.UUID) error
+	}
 	EventTypes interface {
 		GetAll(context.Context) ([]models.EventType, error)
 		GetByID(context.Context, uuid.UUID) (*models.EventType, error)
@@ -220,6 +287,10 @@ type Storage struct {
 		GetOverdue(context.Context) ([]models.ArticleMaintenanceLog, error)
 		Update(context.Context, *models.ArticleMaintenanceLog) error
 	}
+	EventReturns interface {
+		Get(context.Context, uuid.UUID) (*models.EventReturn, error)
+		Create(context.Context, *models.EventReturn) error
+	}
 	RecurringEvents interface {
 		GetAll(context.Context) ([]models.RecurringEvent, error)
 		GetByUserID(context.Context, uuid.UUID) ([]models.RecurringEvent, error)
@@ -236,11 +307,14 @@ type Storage struct {
 		CreateFinancialRecord(context.Context, *models.FinancialRecord) error
 		GetFinancialRecords(context.Context, string, string, string, string) ([]models.FinancialRecord, error)
 		GetFinancialSummary(context.Context, string, string) (*models.FinancialSummary, error)
+		GetTotalsByPeriod(context.Context, string, time.Time, time.Time) ([]models.FinancialPeriodTotal, error)
 		GetIncomeByCategory(context.Context, string, string) (map[string]float64, error)
 		GetExpensesByCategory(context.Context, string, string) (map[string]float64, error)
 		ReconcileRecord(context.Context, uuid.UUID) error
 		CreateInvoice(context.Context, *models.Invoice) error
 		GetInvoices(context.Context, string, string) ([]models.Invoice, error)
+		GetInvoice(context.Context, uuid.UUID) (*models.Invoice, error)
+		UpdateInvoiceStatus(ctx context.Context, id uuid.UUID, status string, reason *string) (*models.Invoice, error)
 		CreateExpenseVendor(context.Context, *models.ExpenseVendor) error
 		GetExpenseVendors(context.Context) ([]models.ExpenseVendor, error)
 		CreateVendorPayment(context.Context, *models.VendorPayment) error
@@ -258,15 +332,75 @@ type Storage struct {
 	}
 	PayPal interface {
 		CreateTransaction(context.Context, *models.PayPalTransaction) error
-		UpdateTransactionCapture(context.Context, string, string) error
+		// CompleteCapture marks the order captured and reports whether this
+		// call did it, so the payment is applied exactly once.
+		CompleteCapture(context.Context, string, string, *string) (bool, error)
+		UpdateTransactionStatus(context.Context, string, string) error
+		RecordInstallments(ctx context.Context, transactionID uuid.UUID, installmentIDs []uuid.UUID) error
+		// Refund marks the order refunded and reports whether it reopened
+		// installments the order had paid.
+		Refund(context.Context, string) (bool, error)
 		GetTransactionByOrderID(context.Context, string) (*models.PayPalTransaction, error)
 		GetTransactionsByEvent(context.Context, uuid.UUID) ([]models.PayPalTransaction, error)
+		IsWebhookEventProcessed(context.Context, string) (bool, error)
+		MarkWebhookEventProcessed(context.Context, string, string) error
 	}
 	Audit interface {
 		LogClientAction(context.Context, *models.ClientAuditLog) error
 		GetClientAuditLog(context.Context, uuid.UUID, int) ([]models.ClientAuditLog, error)
 		GetAllAuditLogs(context.Context, *uuid.UUID, string, string, string, string, int, int) ([]models.ClientAuditLog, int, error)
 	}
+	EmailTemplates interface {
+		ListActive(context.Context) ([]models.EmailTemplate, error)
+		GetActive(context.Context, string) (*models.EmailTemplate, error)
+		ListVersions(context.Context, string) ([]models.EmailTemplate, error)
+		CreateVersion(context.Context, *models.EmailTemplate) error
+		Activate(context.Context, string, int) (*models.EmailTemplate, error)
+	}
+	NotificationTriggers interface {
+		GetAll(context.Context) ([]models.NotificationTrigger, error)
+		GetEnabled(context.Context) ([]models.NotificationTrigger, error)
+		GetByID(context.Context, uuid.UUID) (*models.NotificationTrigger, error)
+		Create(context.Context, *models.NotificationTrigger) error
+		Update(context.Context, *models.NotificationTrigger) error
+		Delete(context.Context, uuid.UUID) error
+	}
+	Jobs interface {
+		Enqueue(context.Context, *models.Job) (bool, error)
+		Claim(context.Context, string, []string, time.Duration) (*models.Job, error)
+		Complete(context.Context, uuid.UUID, string) error
+		Fail(context.Context, uuid.UUID, string, string, *time.Time) error
+		List(context.Context, string, int) ([]models.Job, error)
+		Retry(context.Context, uuid.UUID) (*models.Job, error)
+		DeleteSucceededBefore(context.Context, time.Time) (int64, error)
+	}
+	WhatsApp interface {
+		Contact(context.Context, string) (*models.WhatsAppContact, error)
+		SaveInbound(context.Context, *models.WhatsAppMessage) (bool, error)
+		SaveOutbound(context.Context, *models.WhatsAppMessage) error
+		UpdateStatus(context.Context, string, string) error
+		ListConversations(context.Context, int) ([]models.WhatsAppConversation, error)
+		GetThread(context.Context, string, int) ([]models.WhatsAppMessage, error)
+	}
+	Chatbot interface {
+		ListFAQs(context.Context, string, bool) ([]models.FAQ, error)
+		GetFAQByID(context.Context, uuid.UUID) (*models.FAQ, error)
+		CreateFAQ(context.Context, *models.FAQ) error
+		UpdateFAQ(context.Context, *models.FAQ) error
+		DeleteFAQ(context.Context, uuid.UUID) error
+		ListIntents(context.Context, bool) ([]models.ChatbotIntent, error)
+		GetIntentByID(context.Context, uuid.UUID) (*models.ChatbotIntent, error)
+		CreateIntent(context.Context, *models.ChatbotIntent) error
+		UpdateIntent(context.Context, *models.ChatbotIntent) error
+		DeleteIntent(context.Context, uuid.UUID) error
+		ListSynonyms(context.Context) ([]models.ChatbotSynonym, error)
+		UpsertSynonym(context.Context, *models.ChatbotSynonym) error
+		DeleteSynonym(context.Context, string) error
+		GetOrCreateSession(context.Context, string, string, string) (*models.ChatbotSession, error)
+		SaveTurn(context.Context, *models.ChatbotSession, *models.ChatbotTurn) error
+		ListTurns(context.Context, string, int) ([]models.ChatbotTurn, error)
+		RecordFeedback(context.Context, uuid.UUID, bool) (*models.ChatbotTurn, error)
+	}
 	Notifications interface {
 		GetUserNotifications(context.Context, uuid.UUID, int) ([]models.Notification, error)
 		Create(context.Context, *models.Notification) error
@@ -289,51 +423,62 @@ type Storage struct {
 		GetArticleAvailabilityRange(context.Context, uuid.UUID, time.Time, time.Time) ([]CalendarDay, error)
 		GetAllArticlesAvailability(context.Context, time.Time) ([]ArticleAvailability, error)
 		GetCalendarView(context.Context, time.Time, time.Time) ([]CalendarDay, error)
-		ReserveInventory(context.Context, uuid.UUID, uuid.UUID, time.Time, int) error
+		ReserveInventory(context.Context, uuid.UUID, uuid.UUID, RentalWindow, int) error
 		ConfirmInventory(context.Context, uuid.UUID, uuid.UUID, time.Time) error
 		ReleaseInventory(context.Context, uuid.UUID, uuid.UUID, time.Time) error
+		ExpireHolds(context.Context, time.Time) ([]uuid.UUID, error)
 	}
 }
 
 func NewStorage(db *sql.DB) Storage {
 	return Storage{
-		Articles:         &ArticlesStore{db: db},
-		Categories:       &CategoriesStore{db: db},
-		Posts:            &PostsStore{db: db},
-		Users:            &UsersStore{db: db},
-		Comments:         &CommentsStore{db: db},
-		Roles:            &RolesStore{db: db},
-		RefreshTokens:    &RefreshTokensStore{db: db},
-		Events:           &EventStore{db: db},
-		Guests:           &GuestStore{db: db},
-		EventTasks:       &EventTaskStore{db: db},
-		Suppliers:        &SupplierStore{db: db},
-		Timeline:         &timelineStore{db: db},
-		Messages:         &MessagesStore{db: db},
-		Stats:            &StatsStore{db: db},
-		Reviews:          &ReviewsStore{db: db},
-		EventReviews:     &EventReviewsStore{db: db},
-		CompanyReviews:   &CompanyReviewsStore{db: db},
-		NotificationLogs: &NotificationLogsStore{db: db},
-		Favorites:        &FavoritesStore{db: db},
-		EventPhotos:      &EventPhotosStore{db: db},
-		AuditLogs:        &AuditLogsStore{db: db},
-		DeliveryZones:    &DeliveryZonesStore{db: db},
-		Bundles:          &BundlesStore{db: db},
-		Inspiration:      &InspirationStore{db: db},
-		EventColors:      &EventColorsStore{db: db},
-		Installments:     &InstallmentStore{db: db},
-		Variants:         &VariantsStore{db: db},
-		EventTypes:       &EventTypesStore{db: db},
-		MaintenanceLogs:  &MaintenanceLogsStore{db: db},
-		RecurringEvents:  &RecurringEventsStore{db: db},
-		Financial:        NewFinancialStore(db),
-		Insurance:        NewInsuranceStore(db),
-		PayPal:           NewPayPalStore(db),
-		Audit:            NewClientAuditStore(db),
-		Notifications:    NewNotificationsStore(db),
-		Leads:            &LeadsStore{db: db},
-		Availability:     &AvailabilityStore{db: db},
+		Articles:             &ArticlesStore{db: db},
+		Categories:           &CategoriesStore{db: db},
+		Posts:                &PostsStore{db: db},
+		Users:                &UsersStore{db: db},
+		Comments:             &CommentsStore{db: db},
+		Roles:                &RolesStore{db: db},
+		RefreshTokens:        &RefreshTokensStore{db: db},
+		TwoFactor:            &TwoFactorStore{db: db},
+		Events:               &EventStore{db: db},
+		Guests:               &GuestStore{db: db},
+		EventTasks:           &EventTaskStore{db: db},
+		Suppliers:            &SupplierStore{db: db},
+		Timeline:             &timelineStore{db: db},
+		Messages:             &MessagesStore{db: db},
+		Stats:                &StatsStore{db: db},
+		Analytics:            &AnalyticsStore{db: db},
+		Reviews:              &ReviewsStore{db: db},
+		EventReviews:         &EventReviewsStore{db: db},
+		CompanyReviews:       &CompanyReviewsStore{db: db},
+		NotificationLogs:     &NotificationLogsStore{db: db},
+		Favorites:            &FavoritesStore{db: db},
+		EventPhotos:          &EventPhotosStore{db: db},
+		AuditLogs:            &AuditLogsStore{db: db},
+		DeliveryZones:        &DeliveryZonesStore{db: db},
+		PaymentMethods:       &PaymentMethodsStore{db: db},
+		PaymentPlans:         &PaymentPlansStore{db: db},
+		Bundles:              &BundlesStore{db: db},
+		Inspiration:          &InspirationStore{db: db},
+		EventColors:          &EventColorsStore{db: db},
+		Installments:         &InstallmentStore{db: db},
+		Variants:             &VariantsStore{db: db},
+		EventTypes:           &EventTypesStore{db: db},
+		MaintenanceLogs:      &MaintenanceLogsStore{db: db},
+		EventReturns:         &EventReturnsStore{db: db},
+		RecurringEvents:      &RecurringEventsStore{db: db},
+		Financial:            NewFinancialStore(db),
+		Insurance:            NewInsuranceStore(db),
+		PayPal:               NewPayPalStore(db),
+		Audit:                NewClientAuditStore(db),
+		Notifications:        NewNotificationsStore(db),
+		EmailTemplates:       &EmailTemplatesStore{db: db},
+		NotificationTriggers: &NotificationTriggersStore{db: db},
+		Jobs:                 &JobsStore{db: db},
+		WhatsApp:             &WhatsAppStore{db: db},
+		Chatbot:              &ChatbotStore{db: db},
+		Leads:                &LeadsStore{db: db},
+		Availability:         &AvailabilityStore{db: db},
 	}
 }
 
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// twoFactorEnabledColumn selects whether the user in the users table has
+// two-factor enabled.
+const twoFactorEnabledColumn = `EXISTS (SELECT 1 FROM user_two_factor tf WHERE tf.user_id = users.id AND tf.enabled_at IS NOT NULL)`
+
+type TwoFactorStore struct {
+	db *sql.DB
+}
+
+// Get returns the user's authenticator, enabled or still enrolling. It is
+// ErrNotFound if they have none.
+func (s *TwoFactorStore) Get(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	tf := models.TwoFactor{UserID: userID}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT secret, enabled_at, last_step,
+		       (SELECT COUNT(*) FROM user_recovery_codes c WHERE c.user_id = tf.user_id AND c.used_at IS NULL)
+		FROM user_two_factor tf
+		WHERE user_id = $1`, userID,
+	).Scan(&tf.Secret, &tf.EnabledAt, &tf.LastStep, &tf.RecoveryCodesLeft)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// Enroll starts enrolling an authenticator with the secret, replacing one
+// whose enrollment wasn't finished. It is ErrConflict if two-factor is
+// already enabled.
+func (s *TwoFactorStore) Enroll(ctx context.Context, userID uuid.UUID, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_two_factor (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_step = 0, created_at = NOW()
+		WHERE user_two_factor.enabled_at IS NULL`, userID, secret)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Enable finishes enrollment once the user has entered a code from the
+// authenticator, from the given time step, and sets their recovery codes.
+// It is ErrNotFound if there is no enrollment in progress.
+func (s *TwoFactorStore) Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryCodes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE user_two_factor SET enabled_at = NOW(), last_step = $2
+			WHERE user_id = $1 AND enabled_at IS NULL`, userID, step)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		return replaceRecoveryCodesTx(ctx, tx, userID, recoveryCodes)
+	})
+}
+
+// Disable removes the user's authenticator and recovery codes.
+func (s *TwoFactorStore) Disable(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM user_two_factor WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// UseStep records that a code from the time step was accepted. It reports
+// false if a code from that step or a later one was already used, so the
+// code must be refused.
+func (s *TwoFactorStore) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE user_two_factor SET last_step = $2
+		WHERE user_id = $1 AND last_step < $2`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// UseRecoveryCode spends one of the user's recovery codes. It reports
+// false if the code isn't theirs or was already used.
+func (s *TwoFactorStore) UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE user_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code = $2 AND used_at IS NULL`, userID, models.HashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ReplaceRecoveryCodes invalidates the user's recovery codes and gives
+// them new ones.
+func (s *TwoFactorStore) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return replaceRecoveryCodesTx(ctx, tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodesTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = models.HashRecoveryCode(code)
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO user_recovery_codes (user_id, code)
+		SELECT $1, unnest($2::text[])`, userID, pq.Array(hashes))
+	return err
+}
+
+// CreateChallenge issues a challenge to finish signing the user in with,
+// clearing their expired ones.
+func (s *TwoFactorStore) CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM two_factor_challenges WHERE user_id = $1 AND expires_at < NOW()`, challenge.UserID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO two_factor_challenges (token, user_id, expires_at) VALUES ($1, $2, $3)`,
+			models.HashChallengeToken(challenge.Token), challenge.UserID, challenge.ExpiresAt)
+		return err
+	})
+}
+
+// AttemptChallenge counts an attempt at answering the challenge and returns
+// the user it was issued to. It is ErrNotFound if the challenge is unknown,
+// expired or out of attempts.
+func (s *TwoFactorStore) AttemptChallenge(ctx context.Context, token string, maxAttempts int) (uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var userID uuid.UUID
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE two_factor_challenges SET attempts = attempts + 1
+		WHERE token = $1 AND expires_at > NOW() AND attempts < $2
+		RETURNING user_id`, models.HashChallengeToken(token), maxAttempts,
+	).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ErrNotFound
+	}
+	return userID, err
+}
+
+// DeleteChallenge removes a challenge once it has been answered.
+func (s *TwoFactorStore) DeleteChallenge(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `DELETE FROM two_factor_challenges WHERE token = $1`, models.HashChallengeToken(token))
+	return err
+}
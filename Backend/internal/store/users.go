@@ -51,11 +51,16 @@ func (s *UsersStore) Create(ctx context.Context, tx *sql.Tx, user *models.User)
 }
 
 func (s *UsersStore) RetrieveById(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	query := `SELECT users.id, user_name, first_name, last_name, email, password, created_at, roles.* FROM users JOIN roles ON (users.role_id = roles.id) WHERE users.id = $1`
+	query := `SELECT users.id, user_name, first_name, last_name, email, password, created_at,
+		blocked_at, blocked_until, blocked_reason, token_version, ` + twoFactorEnabledColumn + `,
+		roles.id, roles.name, COALESCE(roles.description, ''), roles.is_system
+		FROM users JOIN roles ON (users.role_id = roles.id) WHERE users.id = $1`
 
 	var user models.User
 
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Email, &user.Password.Hash, &user.CreatedAt, &user.Role.ID, &user.Role.Name, &user.Role.Level, &user.Role.Description)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Email, &user.Password.Hash, &user.CreatedAt,
+		&user.BlockedAt, &user.BlockedUntil, &user.BlockedReason, &user.TokenVersion, &user.TwoFactorEnabled,
+		&user.Role.ID, &user.Role.Name, &user.Role.Description, &user.Role.IsSystem)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -202,14 +207,17 @@ func (s *UsersStore) deleteUserInvitations(ctx context.Context, tx *sql.Tx, id u
 }
 
 func (s *UsersStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT id, user_name, first_name, last_name, email, password, created_at, activated FROM users WHERE email = $1`
+	query := `SELECT id, user_name, first_name, last_name, email, password, created_at, activated,
+		blocked_at, blocked_until, blocked_reason, token_version, ` + twoFactorEnabledColumn + `
+		FROM users WHERE email = $1`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	user := &models.User{}
 
-	err := s.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Email, &user.Password.Hash, &user.CreatedAt, &user.IsActive)
+	err := s.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName, &user.Email, &user.Password.Hash, &user.CreatedAt, &user.IsActive,
+		&user.BlockedAt, &user.BlockedUntil, &user.BlockedReason, &user.TokenVersion, &user.TwoFactorEnabled)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -222,6 +230,115 @@ func (s *UsersStore) GetByEmail(ctx context.Context, email string) (*models.User
 	return user, nil
 }
 
+// Block blocks the user, until the given time if set, and revokes their
+// sessions. Blocking a blocked user replaces the block.
+func (s *UsersStore) Block(ctx context.Context, userID, blockedBy uuid.UUID, until *time.Time, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE users
+			SET blocked_at = NOW(), blocked_until = $2, blocked_reason = $3, blocked_by = $4,
+			    token_version = token_version + 1
+			WHERE id = $1`, userID, until, reason, blockedBy)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM user_sessions WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// Unblock lifts the user's block. Sessions revoked by the block stay
+// revoked.
+func (s *UsersStore) Unblock(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users
+		SET blocked_at = NULL, blocked_until = NULL, blocked_reason = '', blocked_by = NULL
+		WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeTokens signs the user out everywhere: their refresh tokens are
+// deleted and the access tokens already issued stop being accepted.
+func (s *UsersStore) RevokeTokens(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE users SET token_version = token_version + 1 WHERE id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM user_sessions WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// SetRole assigns the user a role. It is ErrNotFound if either doesn't
+// exist.
+func (s *UsersStore) SetRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users SET role_id = r.id
+		FROM roles r
+		WHERE users.id = $1 AND r.id = $2`, userID, roleID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListStaff returns the users whose role grants any permission, by name.
+func (s *UsersStore) ListStaff(ctx context.Context) ([]models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.id, u.user_name, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''), u.email, u.created_at,
+		       u.blocked_at, u.blocked_until, r.id, r.name, COALESCE(r.description, ''), r.is_system
+		FROM users u
+		JOIN roles r ON r.id = u.role_id
+		WHERE EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id)
+		ORDER BY u.first_name, u.last_name, u.email`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staff := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.UserName, &u.FirstName, &u.LastName, &u.Email, &u.CreatedAt,
+			&u.BlockedAt, &u.BlockedUntil, &u.Role.ID, &u.Role.Name, &u.Role.Description, &u.Role.IsSystem); err != nil {
+			return nil, err
+		}
+		staff = append(staff, u)
+	}
+	return staff, rows.Err()
+}
+
 // UpdateFCMToken updates the FCM token for a specific user.
 func (s *UsersStore) UpdateFCMToken(ctx context.Context, userID uuid.UUID, token string) error {
 	query := `UPDATE users SET fcm_token = $1 WHERE id = $2`
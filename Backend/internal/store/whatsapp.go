@@ -0,0 +1,278 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+)
+
+type WhatsAppStore struct {
+	db *sql.DB
+}
+
+// minPhoneDigits is the shortest number worth matching; anything shorter
+// would match unrelated contacts.
+const minPhoneDigits = 7
+
+const whatsAppMessageColumns = `id, wa_message_id, direction, phone, COALESCE(contact_name, ''), message_type, body, status,
+	user_id, lead_id, event_id, event_message_id, sent_by, created_at, updated_at`
+
+func scanWhatsAppMessage(row interface{ Scan(...any) error }, m *models.WhatsAppMessage) error {
+	return row.Scan(
+		&m.ID, &m.WAMessageID, &m.Direction, &m.Phone, &m.ContactName, &m.MessageType, &m.Body, &m.Status,
+		&m.UserID, &m.LeadID, &m.EventID, &m.EventMessageID, &m.SentBy, &m.CreatedAt, &m.UpdatedAt,
+	)
+}
+
+// Contact finds the user and lead a phone number (digits only) belongs to,
+// and the event a conversation with them is threaded into: the user's next
+// upcoming event, or their most recent one if none is upcoming. Cancelled
+// and rejected events are never picked.
+func (s *WhatsAppStore) Contact(ctx context.Context, phone string) (*models.WhatsAppContact, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var contact models.WhatsAppContact
+	if len(phone) < minPhoneDigits {
+		return &contact, nil
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM users
+		WHERE phonenumber IS NOT NULL AND `+phoneMatches("phonenumber")+`
+		ORDER BY created_at DESC
+		LIMIT 1`, phone).Scan(&contact.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT id FROM leads
+		WHERE client_phone IS NOT NULL AND `+phoneMatches("client_phone")+`
+		ORDER BY created_at DESC
+		LIMIT 1`, phone).Scan(&contact.LeadID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if contact.UserID == nil {
+		return &contact, nil
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT id FROM events
+		WHERE user_id = $1 AND status NOT IN ($2, $3)
+		ORDER BY (date >= NOW()) DESC NULLS LAST,
+		         CASE WHEN date >= NOW() THEN date END ASC,
+		         created_at DESC
+		LIMIT 1`, *contact.UserID, models.EventStatusCancelled, models.EventStatusRejected).Scan(&contact.EventID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// phoneMatches compares the last 10 digits of a stored phone with $1, so
+// numbers saved with or without the +1 country code still match.
+func phoneMatches(column string) string {
+	return `right(regexp_replace(` + column + `, '\D', '', 'g'), 10) = right($1, 10)`
+}
+
+// SaveInbound stores a received message. It reports false when the message
+// was already stored, since Meta redelivers webhooks it considers failed.
+// A message from a user with an event is copied into the event chat, and a
+// message from a lead counts as contact on the lead.
+func (s *WhatsAppStore) SaveInbound(ctx context.Context, msg *models.WhatsAppMessage) (bool, error) {
+	msg.Direction = models.WhatsAppInbound
+	if msg.Status == "" {
+		msg.Status = "received"
+	}
+
+	created := false
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ok, err := s.insert(ctx, tx, msg)
+		if err != nil || !ok {
+			return err
+		}
+		created = true
+
+		if msg.UserID != nil {
+			if err := s.threadIntoEvent(ctx, tx, msg, *msg.UserID); err != nil {
+				return err
+			}
+		}
+
+		if msg.LeadID != nil {
+			if _, err := tx.ExecContext(ctx, `UPDATE leads SET last_contact_at = NOW(), updated_at = NOW() WHERE id = $1`, *msg.LeadID); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO lead_activities (lead_id, activity_type, description) VALUES ($1, 'whatsapp_received', $2)`,
+				*msg.LeadID, msg.Body); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return created, err
+}
+
+// SaveOutbound stores a message staff sent, copying it into the event chat
+// as sent by them when the conversation is threaded into an event.
+func (s *WhatsAppStore) SaveOutbound(ctx context.Context, msg *models.WhatsAppMessage) error {
+	msg.Direction = models.WhatsAppOutbound
+	if msg.Status == "" {
+		msg.Status = "sent"
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := s.insert(ctx, tx, msg); err != nil {
+			return err
+		}
+		if msg.SentBy != nil {
+			return s.threadIntoEvent(ctx, tx, msg, *msg.SentBy)
+		}
+		return nil
+	})
+}
+
+func (s *WhatsAppStore) insert(ctx context.Context, tx *sql.Tx, msg *models.WhatsAppMessage) (bool, error) {
+	if msg.MessageType == "" {
+		msg.MessageType = "text"
+	}
+
+	query := `
+		INSERT INTO whatsapp_messages (wa_message_id, direction, phone, contact_name, message_type, body, status,
+			user_id, lead_id, event_id, sent_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (wa_message_id) WHERE wa_message_id IS NOT NULL DO NOTHING
+		RETURNING id, created_at, updated_at`
+
+	err := tx.QueryRowContext(ctx, query,
+		msg.WAMessageID, msg.Direction, msg.Phone, msg.ContactName, msg.MessageType, msg.Body, msg.Status,
+		msg.UserID, msg.LeadID, msg.EventID, msg.SentBy,
+	).Scan(&msg.ID, &msg.CreatedAt, &msg.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *WhatsAppStore) threadIntoEvent(ctx context.Context, tx *sql.Tx, msg *models.WhatsAppMessage, senderID uuid.UUID) error {
+	if msg.EventID == nil {
+		return nil
+	}
+
+	eventMsg := &models.EventMessage{
+		EventID:  *msg.EventID,
+		SenderID: senderID,
+		Content:  msg.Body,
+		Channel:  models.MessageChannelWhatsApp,
+	}
+	if err := createEventMessage(ctx, tx, eventMsg); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE whatsapp_messages SET event_message_id = $1 WHERE id = $2`, eventMsg.ID, msg.ID); err != nil {
+		return err
+	}
+	msg.EventMessageID = &eventMsg.ID
+	msg.EventMessage = eventMsg
+	return nil
+}
+
+// UpdateStatus records a delivery status for a message we sent. Meta may
+// deliver statuses out of order, so a status never moves backwards.
+func (s *WhatsAppStore) UpdateStatus(ctx context.Context, waMessageID, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE whatsapp_messages
+		SET status = $2, updated_at = NOW()
+		WHERE wa_message_id = $1 AND direction = 'outbound'
+		  AND array_position(ARRAY['sent', 'delivered', 'read', 'failed'], status)
+		    < array_position(ARRAY['sent', 'delivered', 'read', 'failed'], $2::text)`,
+		waMessageID, status)
+	return err
+}
+
+// ListConversations returns one row per phone with its latest message, most
+// recent first. Unanswered counts the inbound messages since staff last
+// replied.
+func (s *WhatsAppStore) ListConversations(ctx context.Context, limit int) ([]models.WhatsAppConversation, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH latest AS (
+			SELECT DISTINCT ON (phone) phone, user_id, lead_id, event_id, body, direction, created_at
+			FROM whatsapp_messages
+			ORDER BY phone, created_at DESC
+		), names AS (
+			SELECT DISTINCT ON (phone) phone, contact_name
+			FROM whatsapp_messages
+			WHERE contact_name IS NOT NULL
+			ORDER BY phone, created_at DESC
+		)
+		SELECT l.phone, COALESCE(n.contact_name, ''), l.user_id, l.lead_id, l.event_id, l.body, l.direction, l.created_at,
+			(SELECT COUNT(*) FROM whatsapp_messages i
+			 WHERE i.phone = l.phone AND i.direction = 'inbound'
+			   AND i.created_at > COALESCE(
+			       (SELECT MAX(o.created_at) FROM whatsapp_messages o WHERE o.phone = l.phone AND o.direction = 'outbound'),
+			       '-infinity'))
+		FROM latest l
+		LEFT JOIN names n ON n.phone = l.phone
+		ORDER BY l.created_at DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []models.WhatsAppConversation
+	for rows.Next() {
+		var c models.WhatsAppConversation
+		if err := rows.Scan(&c.Phone, &c.ContactName, &c.UserID, &c.LeadID, &c.EventID,
+			&c.LastMessage, &c.LastDirection, &c.LastMessageAt, &c.Unanswered); err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, c)
+	}
+	return conversations, rows.Err()
+}
+
+// GetThread returns the latest messages exchanged with a phone, oldest first.
+func (s *WhatsAppStore) GetThread(ctx context.Context, phone string, limit int) ([]models.WhatsAppMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT * FROM (
+			SELECT `+whatsAppMessageColumns+` FROM whatsapp_messages
+			WHERE phone = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		) t ORDER BY created_at ASC`, phone, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.WhatsAppMessage
+	for rows.Next() {
+		var m models.WhatsAppMessage
+		if err := scanWhatsAppMessage(rows, &m); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,215 @@
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the webhook body, keyed with the
+// app secret, as "sha256=<hex>".
+const SignatureHeader = "X-Hub-Signature-256"
+
+// VerifySubscription answers Meta's webhook handshake: the subscription is
+// accepted when the mode is "subscribe" and the token is the one configured
+// for this app.
+func (c *Client) VerifySubscription(mode, token string) bool {
+	if mode != "subscribe" || c.config.VerifyToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(c.config.VerifyToken)) == 1
+}
+
+// VerifySignature reports whether body was signed with the app secret.
+// Without a configured secret every payload is rejected.
+func (c *Client) VerifySignature(body []byte, signature string) bool {
+	return VerifySignature(c.config.AppSecret, body, signature)
+}
+
+// VerifySignature checks a SignatureHeader value against body.
+func VerifySignature(appSecret string, body []byte, signature string) bool {
+	if appSecret == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	sum, err := hex.DecodeString(got)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
+
+// WebhookPayload is the body of a Cloud API webhook delivery.
+type WebhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		ID      string `json:"id"`
+		Changes []struct {
+			Field string       `json:"field"`
+			Value webhookValue `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type webhookValue struct {
+	Contacts []struct {
+		WaID    string `json:"wa_id"`
+		Profile struct {
+			Name string `json:"name"`
+		} `json:"profile"`
+	} `json:"contacts"`
+	Messages []webhookMessage `json:"messages"`
+	Statuses []struct {
+		ID          string `json:"id"`
+		Status      string `json:"status"`
+		Timestamp   string `json:"timestamp"`
+		RecipientID string `json:"recipient_id"`
+	} `json:"statuses"`
+}
+
+type webhookMessage struct {
+	ID        string `json:"id"`
+	From      string `json:"from"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Text      struct {
+		Body string `json:"body"`
+	} `json:"text"`
+	Button struct {
+		Text string `json:"text"`
+	} `json:"button"`
+	Interactive struct {
+		ButtonReply struct {
+			Title string `json:"title"`
+		} `json:"button_reply"`
+		ListReply struct {
+			Title string `json:"title"`
+		} `json:"list_reply"`
+	} `json:"interactive"`
+	Image    mediaMessage `json:"image"`
+	Video    mediaMessage `json:"video"`
+	Document mediaMessage `json:"document"`
+}
+
+type mediaMessage struct {
+	Caption string `json:"caption"`
+}
+
+// InboundMessage is a message a client sent to the business number.
+type InboundMessage struct {
+	ID        string // wamid, unique per message
+	From      string // sender phone, digits only
+	Name      string // sender's WhatsApp profile name
+	Type      string
+	Body      string // text, caption or a placeholder for media without one
+	Timestamp time.Time
+}
+
+// StatusUpdate reports the delivery state of a message we sent.
+type StatusUpdate struct {
+	MessageID string
+	Status    string // sent, delivered, read or failed
+	Timestamp time.Time
+}
+
+// ParseWebhook extracts the inbound messages and status updates from a
+// webhook delivery.
+func ParseWebhook(body []byte) ([]InboundMessage, []StatusUpdate, error) {
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, nil, err
+	}
+
+	var messages []InboundMessage
+	var statuses []StatusUpdate
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			v := change.Value
+
+			names := make(map[string]string, len(v.Contacts))
+			for _, c := range v.Contacts {
+				names[c.WaID] = c.Profile.Name
+			}
+
+			for _, m := range v.Messages {
+				messages = append(messages, InboundMessage{
+					ID:        m.ID,
+					From:      NormalizePhone(m.From),
+					Name:      names[m.From],
+					Type:      m.Type,
+					Body:      m.body(),
+					Timestamp: parseTimestamp(m.Timestamp),
+				})
+			}
+			for _, s := range v.Statuses {
+				statuses = append(statuses, StatusUpdate{
+					MessageID: s.ID,
+					Status:    s.Status,
+					Timestamp: parseTimestamp(s.Timestamp),
+				})
+			}
+		}
+	}
+	return messages, statuses, nil
+}
+
+func (m webhookMessage) body() string {
+	switch m.Type {
+	case "text":
+		return m.Text.Body
+	case "button":
+		return m.Button.Text
+	case "interactive":
+		if m.Interactive.ButtonReply.Title != "" {
+			return m.Interactive.ButtonReply.Title
+		}
+		return m.Interactive.ListReply.Title
+	case "image":
+		return captionOr(m.Image.Caption, "[imagen]")
+	case "video":
+		return captionOr(m.Video.Caption, "[video]")
+	case "document":
+		return captionOr(m.Document.Caption, "[documento]")
+	}
+	return "[" + m.Type + "]"
+}
+
+func captionOr(caption, placeholder string) string {
+	if caption != "" {
+		return caption
+	}
+	return placeholder
+}
+
+func parseTimestamp(s string) time.Time {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Now()
+	}
+	return time.Unix(sec, 0)
+}
+
+// NormalizePhone strips everything but digits, which is the form WhatsApp
+// uses for phone numbers.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package whatsapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"object":"whatsapp_business_account"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: "secret", signature: valid, want: true},
+		{name: "wrong secret", secret: "other", signature: valid, want: false},
+		{name: "missing prefix", secret: "secret", signature: valid[len("sha256="):], want: false},
+		{name: "not hex", secret: "secret", signature: "sha256=zz", want: false},
+		{name: "no secret configured", secret: "", signature: valid, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, body, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	body := []byte(`{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{
+		"contacts":[{"wa_id":"18095551234","profile":{"name":"Ana"}}],
+		"messages":[
+			{"id":"wamid.1","from":"18095551234","timestamp":"1748772000","type":"text","text":{"body":"Hola"}},
+			{"id":"wamid.2","from":"18095551234","timestamp":"1748772060","type":"image","image":{"id":"media-1"}},
+			{"id":"wamid.3","from":"18095551234","timestamp":"1748772120","type":"interactive","interactive":{"button_reply":{"title":"Sí"}}}
+		],
+		"statuses":[{"id":"wamid.out","status":"read","timestamp":"1748772000"}]}}]}]}`)
+
+	messages, statuses, err := ParseWebhook(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+
+	want := []string{"Hola", "[imagen]", "Sí"}
+	for i, m := range messages {
+		if m.Body != want[i] {
+			t.Errorf("message %d body = %q, want %q", i, m.Body, want[i])
+		}
+		if m.Name != "Ana" || m.From != "18095551234" {
+			t.Errorf("message %d sender = %q/%q", i, m.Name, m.From)
+		}
+	}
+	if messages[0].Timestamp.Unix() != 1748772000 {
+		t.Errorf("unexpected timestamp %v", messages[0].Timestamp)
+	}
+
+	if len(statuses) != 1 || statuses[0].MessageID != "wamid.out" || statuses[0].Status != "read" {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	if got := NormalizePhone("+1 (809) 555-1234"); got != "18095551234" {
+		t.Errorf("NormalizePhone() = %q", got)
+	}
+}
@@ -15,6 +15,8 @@ type Config struct {
 	PhoneNumberID string // The phone number ID from Meta developer portal
 	AccessToken   string // Permanent access token from Meta
 	FromName      string // Business name shown to recipients
+	VerifyToken   string // Token Meta echoes back when subscribing the webhook
+	AppSecret     string // App secret used to sign webhook payloads
 }
 
 // Message represents a WhatsApp message to send.
@@ -36,7 +38,7 @@ type Client struct {
 func NewClient(cfg Config) *Client {
 	if cfg.AccessToken == "" || cfg.PhoneNumberID == "" {
 		log.Println("[WhatsApp] Not configured - using mock mode")
-		return &Client{enabled: false}
+		return &Client{config: cfg, enabled: false}
 	}
 
 	return &Client{
@@ -50,18 +52,25 @@ func NewClient(cfg Config) *Client {
 
 // SendTextMessage sends a simple text WhatsApp message.
 func (c *Client) SendTextMessage(ctx context.Context, msg Message) error {
+	_, err := c.SendText(ctx, msg.To, msg.Body)
+	return err
+}
+
+// SendText sends a text message and returns the WhatsApp message ID, which
+// later status webhooks refer to. The ID is empty in mock mode.
+func (c *Client) SendText(ctx context.Context, to, body string) (string, error) {
 	if !c.enabled {
-		log.Printf("[WhatsApp MOCK] Sending to %s: %s", msg.To, msg.Body)
-		return nil
+		log.Printf("[WhatsApp MOCK] Sending to %s: %s", to, body)
+		return "", nil
 	}
 
 	payload := map[string]interface{}{
 		"messaging_product": "whatsapp",
-		"to":               msg.To,
+		"to":               to,
 		"type":             "text",
 		"text": map[string]string{
 			"preview_url": "false",
-			"body":        msg.Body,
+			"body":        body,
 		},
 	}
 
@@ -87,7 +96,8 @@ func (c *Client) SendTemplateMessage(ctx context.Context, msg Message) error {
 		},
 	}
 
-	return c.send(ctx, payload)
+	_, err := c.send(ctx, payload)
+	return err
 }
 
 // SendEventStatusNotification sends a formatted event status update.
@@ -147,7 +157,7 @@ func (c *Client) SendPaymentReminder(ctx context.Context, to, eventName string,
 	return c.SendTextMessage(ctx, Message{To: to, Body: body})
 }
 
-func (c *Client) send(ctx context.Context, payload map[string]interface{}) error {
+func (c *Client) send(ctx context.Context, payload map[string]interface{}) (string, error) {
 	url := fmt.Sprintf(
 		"https://graph.facebook.com/v21.0/%s/messages",
 		c.config.PhoneNumberID,
@@ -155,12 +165,12 @@ func (c *Client) send(ctx context.Context, payload map[string]interface{}) error
 
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshal payload: %w", err)
+		return "", fmt.Errorf("marshal payload: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return "", fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.config.AccessToken)
@@ -168,7 +178,7 @@ func (c *Client) send(ctx context.Context, payload map[string]interface{}) error
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("send request: %w", err)
+		return "", fmt.Errorf("send request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -176,14 +186,21 @@ func (c *Client) send(ctx context.Context, payload map[string]interface{}) error
 		var errResp map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
 		log.Printf("[WhatsApp] API error: %v", errResp)
-		return fmt.Errorf("WhatsApp API error: status %d", resp.StatusCode)
+		return "", fmt.Errorf("WhatsApp API error: status %d", resp.StatusCode)
 	}
 
-	var result map[string]interface{}
+	var result struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("decode response: %w", err)
+		return "", fmt.Errorf("decode response: %w", err)
 	}
 
-	log.Printf("[WhatsApp] Message sent successfully: %v", result["messages"])
-	return nil
+	log.Printf("[WhatsApp] Message sent successfully: %v", result.Messages)
+	if len(result.Messages) == 0 {
+		return "", nil
+	}
+	return result.Messages[0].ID, nil
 }
@@ -3,10 +3,10 @@ package worker
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"Backend/internal/notifications"
 	"Backend/internal/store"
+	"Backend/internal/store/models"
 
 	"go.uber.org/zap"
 )
@@ -25,34 +25,24 @@ func NewDelayChecker(store store.Storage, logger *zap.SugaredLogger, notificatio
 	}
 }
 
-func (c *DelayChecker) Start(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
+// DelayCheckJob alerts about critical timeline items running late.
+const DelayCheckJob = "timeline.check_delays"
 
-	c.logger.Info("Delay checker worker started")
-
-	for {
-		select {
-		case <-ctx.Done():
-			c.logger.Info("Delay checker worker stopping")
-			return
-		case <-ticker.C:
-			c.checkDelayedItems(ctx)
-		}
-	}
+// Run is the DelayCheckJob handler.
+func (c *DelayChecker) Run(ctx context.Context, _ *models.Job) error {
+	return c.checkDelayedItems(ctx)
 }
 
-func (c *DelayChecker) checkDelayedItems(ctx context.Context) {
+func (c *DelayChecker) checkDelayedItems(ctx context.Context) error {
 	c.logger.Debug("Checking for overdue critical timeline items...")
 
 	items, err := c.store.Timeline.GetOverdueCriticalItems(ctx)
 	if err != nil {
-		c.logger.Errorf("Error fetching overdue critical items: %v", err)
-		return
+		return fmt.Errorf("fetching overdue critical items: %w", err)
 	}
 
 	if len(items) == 0 {
-		return
+		return nil
 	}
 
 	// Fetch organizer tokens once per check cycle
@@ -92,4 +82,5 @@ func (c *DelayChecker) checkDelayedItems(ctx context.Context) {
 			}
 		}
 	}
+	return nil
 }
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+
+	"go.uber.org/zap"
+)
+
+// HoldExpirer returns stock held by requested events whose hold period ran
+// out without the quote being confirmed.
+type HoldExpirer struct {
+	store  store.Storage
+	logger *zap.SugaredLogger
+}
+
+func NewHoldExpirer(store store.Storage, logger *zap.SugaredLogger) *HoldExpirer {
+	return &HoldExpirer{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// HoldExpireJob releases inventory holds that ran out.
+const HoldExpireJob = "inventory.expire_holds"
+
+// Run is the HoldExpireJob handler.
+func (h *HoldExpirer) Run(ctx context.Context, _ *models.Job) error {
+	return h.expireHolds(ctx)
+}
+
+func (h *HoldExpirer) expireHolds(ctx context.Context) error {
+	eventIDs, err := h.store.Availability.ExpireHolds(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("expiring inventory holds: %w", err)
+	}
+
+	for _, eventID := range eventIDs {
+		h.logger.Infow("Inventory hold expired", "event_id", eventID)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+
+	"go.uber.org/zap"
+)
+
+// Handler runs one job. Returning an error retries the job with exponential
+// backoff until it runs out of attempts and is marked dead.
+type Handler func(ctx context.Context, job *models.Job) error
+
+const (
+	// PruneJobsJob deletes succeeded jobs once they are a week old.
+	PruneJobsJob = "jobs.prune"
+
+	scheduledMaxAttempts = 3
+	backoffBase          = 30 * time.Second
+	backoffMax           = time.Hour
+	succeededRetention   = 7 * 24 * time.Hour
+	recordTimeout        = 10 * time.Second
+)
+
+type QueueConfig struct {
+	WorkerID     string        // identifies this process in jobs.locked_by
+	Concurrency  int           // jobs run at the same time by this process
+	PollInterval time.Duration // wait between claims when the queue is empty
+	Lease        time.Duration // how long a job may run before another worker reclaims it
+}
+
+type schedule struct {
+	kind  string
+	every time.Duration
+}
+
+// Queue runs jobs from the Postgres-backed jobs table. Every replica can run
+// a Queue: claims use SKIP LOCKED so a job is only picked up once, and
+// scheduled jobs are enqueued under a per-slot unique key so only one
+// replica's insert wins.
+type Queue struct {
+	store     store.Storage
+	logger    *zap.SugaredLogger
+	config    QueueConfig
+	handlers  map[string]Handler
+	kinds     []string
+	schedules []schedule
+	now       func() time.Time
+}
+
+func NewQueue(store store.Storage, logger *zap.SugaredLogger, config QueueConfig) *Queue {
+	if config.WorkerID == "" {
+		host, _ := os.Hostname()
+		config.WorkerID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+	if config.Lease <= 0 {
+		config.Lease = 10 * time.Minute
+	}
+
+	q := &Queue{
+		store:    store,
+		logger:   logger,
+		config:   config,
+		handlers: make(map[string]Handler),
+		now:      time.Now,
+	}
+	q.Register(PruneJobsJob, q.pruneJobs)
+	q.Schedule(PruneJobsJob, 24*time.Hour)
+	return q
+}
+
+// Register sets the handler for a kind of job.
+func (q *Queue) Register(kind string, h Handler) {
+	if _, ok := q.handlers[kind]; !ok {
+		q.kinds = append(q.kinds, kind)
+	}
+	q.handlers[kind] = h
+}
+
+// Schedule enqueues a job of kind once per interval, aligned to the clock.
+func (q *Queue) Schedule(kind string, every time.Duration) {
+	q.schedules = append(q.schedules, schedule{kind: kind, every: every})
+}
+
+// Enqueue adds a one-off job. payload is marshalled to JSON.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any, runAt time.Time) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal job payload: %w", err)
+	}
+	_, err = q.store.Jobs.Enqueue(ctx, &models.Job{Kind: kind, Payload: raw, RunAt: runAt})
+	return err
+}
+
+// Run processes jobs until ctx is cancelled, then waits for the jobs already
+// running to finish before returning.
+func (q *Queue) Run(ctx context.Context) {
+	q.logger.Infow("Job queue started", "worker_id", q.config.WorkerID, "concurrency", q.config.Concurrency, "kinds", q.kinds)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.runScheduler(ctx)
+	}()
+
+	for i := 0; i < q.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.runWorker(ctx)
+		}()
+	}
+
+	wg.Wait()
+	q.logger.Info("Job queue stopped")
+}
+
+func (q *Queue) runScheduler(ctx context.Context) {
+	if len(q.schedules) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		q.enqueueDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueDue enqueues the current slot of every schedule. The unique key
+// makes repeated calls, from this or any other replica, no-ops.
+func (q *Queue) enqueueDue(ctx context.Context) {
+	now := q.now()
+	for _, s := range q.schedules {
+		slot := now.Truncate(s.every)
+		key := fmt.Sprintf("%s@%d", s.kind, slot.Unix())
+		job := &models.Job{Kind: s.kind, RunAt: slot, MaxAttempts: scheduledMaxAttempts, UniqueKey: &key}
+		if _, err := q.store.Jobs.Enqueue(ctx, job); err != nil && ctx.Err() == nil {
+			q.logger.Errorw("error scheduling job", "kind", s.kind, "error", err)
+		}
+	}
+}
+
+func (q *Queue) runWorker(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := q.store.Jobs.Claim(ctx, q.config.WorkerID, q.kinds, q.config.Lease)
+		if err != nil && ctx.Err() == nil {
+			q.logger.Errorw("error claiming job", "error", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(q.config.PollInterval):
+			}
+			continue
+		}
+
+		q.process(ctx, job)
+	}
+}
+
+// process runs a claimed job and records the outcome. The job keeps running
+// when ctx is cancelled so a shutdown lets it finish; the lease still bounds
+// how long it may take. The outcome is recorded on a context of its own so a
+// job that used up its lease still gets marked.
+func (q *Queue) process(ctx context.Context, job *models.Job) {
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.config.Lease)
+	start := q.now()
+	err := q.handle(jobCtx, job)
+	cancel()
+
+	recordCtx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+
+	if err == nil {
+		if err := q.store.Jobs.Complete(recordCtx, job.ID, q.config.WorkerID); err != nil {
+			q.logger.Errorw("error completing job", "job_id", job.ID, "kind", job.Kind, "error", err)
+		}
+		q.logger.Debugw("Job succeeded", "job_id", job.ID, "kind", job.Kind, "duration", q.now().Sub(start))
+		return
+	}
+
+	var retryAt *time.Time
+	if job.Attempts < job.MaxAttempts {
+		at := q.now().Add(Backoff(job.Attempts))
+		retryAt = &at
+	}
+
+	if err := q.store.Jobs.Fail(recordCtx, job.ID, q.config.WorkerID, err.Error(), retryAt); err != nil {
+		q.logger.Errorw("error recording job failure", "job_id", job.ID, "kind", job.Kind, "error", err)
+	}
+	if retryAt == nil {
+		q.logger.Errorw("Job is dead", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+		return
+	}
+	q.logger.Warnw("Job failed, retrying", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "retry_at", retryAt, "error", err)
+}
+
+func (q *Queue) handle(ctx context.Context, job *models.Job) (err error) {
+	h, ok := q.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return h(ctx, job)
+}
+
+// Backoff is how long to wait before retrying a job that failed its
+// attempt-th run: 30s doubling each attempt, capped at an hour, with up to
+// 10% jitter so jobs that failed together don't retry together.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := backoffMax
+	if attempt <= 20 {
+		d = min(backoffBase<<(attempt-1), backoffMax)
+	}
+	return d + time.Duration(rand.Int64N(int64(d/10)+1))
+}
+
+func (q *Queue) pruneJobs(ctx context.Context, _ *models.Job) error {
+	n, err := q.store.Jobs.DeleteSucceededBefore(ctx, q.now().Add(-succeededRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		q.logger.Infow("Pruned finished jobs", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"Backend/internal/store"
+	"Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestQueue_process(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	newQueue := func(jobs *mocks.JobsStore) *Queue {
+		q := NewQueue(store.Storage{Jobs: jobs}, logger, QueueConfig{WorkerID: "worker-1"})
+		q.now = func() time.Time { return now }
+		return q
+	}
+
+	t.Run("completes a job whose handler succeeds", func(t *testing.T) {
+		jobs := &mocks.JobsStore{}
+		job := &models.Job{ID: uuid.New(), Kind: "test.ok", Attempts: 1, MaxAttempts: 3}
+		jobs.On("Complete", mock.Anything, job.ID, "worker-1").Return(nil)
+
+		q := newQueue(jobs)
+		q.Register("test.ok", func(ctx context.Context, _ *models.Job) error { return nil })
+		q.process(context.Background(), job)
+
+		jobs.AssertExpectations(t)
+		jobs.AssertNotCalled(t, "Fail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("schedules a retry while attempts remain", func(t *testing.T) {
+		jobs := &mocks.JobsStore{}
+		job := &models.Job{ID: uuid.New(), Kind: "test.fail", Attempts: 1, MaxAttempts: 3}
+		jobs.On("Fail", mock.Anything, job.ID, "worker-1", "boom", mock.MatchedBy(func(at *time.Time) bool {
+			return at != nil && !at.Before(now.Add(backoffBase)) && at.Before(now.Add(2*backoffBase))
+		})).Return(nil)
+
+		q := newQueue(jobs)
+		q.Register("test.fail", func(ctx context.Context, _ *models.Job) error { return errors.New("boom") })
+		q.process(context.Background(), job)
+
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("marks the job dead on its last attempt", func(t *testing.T) {
+		jobs := &mocks.JobsStore{}
+		job := &models.Job{ID: uuid.New(), Kind: "test.fail", Attempts: 3, MaxAttempts: 3}
+		jobs.On("Fail", mock.Anything, job.ID, "worker-1", "boom", (*time.Time)(nil)).Return(nil)
+
+		q := newQueue(jobs)
+		q.Register("test.fail", func(ctx context.Context, _ *models.Job) error { return errors.New("boom") })
+		q.process(context.Background(), job)
+
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("turns a panic into a failed attempt", func(t *testing.T) {
+		jobs := &mocks.JobsStore{}
+		job := &models.Job{ID: uuid.New(), Kind: "test.panic", Attempts: 1, MaxAttempts: 3}
+		jobs.On("Fail", mock.Anything, job.ID, "worker-1", "job panicked: oops", mock.Anything).Return(nil)
+
+		q := newQueue(jobs)
+		q.Register("test.panic", func(ctx context.Context, _ *models.Job) error { panic("oops") })
+		q.process(context.Background(), job)
+
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("keeps running a job after shutdown starts", func(t *testing.T) {
+		jobs := &mocks.JobsStore{}
+		job := &models.Job{ID: uuid.New(), Kind: "test.ctx", Attempts: 1, MaxAttempts: 3}
+		jobs.On("Complete", mock.Anything, job.ID, "worker-1").Return(nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		q := newQueue(jobs)
+		q.Register("test.ctx", func(ctx context.Context, _ *models.Job) error { return ctx.Err() })
+		q.process(ctx, job)
+
+		jobs.AssertExpectations(t)
+	})
+
+	t.Run("records the failure of a job that outlived its lease", func(t *testing.T) {
+		jobs := &mocks.JobsStore{}
+		job := &models.Job{ID: uuid.New(), Kind: "test.slow", Attempts: 1, MaxAttempts: 3}
+		jobs.On("Fail", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil }),
+			job.ID, "worker-1", context.DeadlineExceeded.Error(), mock.Anything).Return(nil)
+
+		q := NewQueue(store.Storage{Jobs: jobs}, logger, QueueConfig{WorkerID: "worker-1", Lease: time.Millisecond})
+		q.Register("test.slow", func(ctx context.Context, _ *models.Job) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		q.process(context.Background(), job)
+
+		jobs.AssertExpectations(t)
+	})
+}
+
+func TestQueue_enqueueDue(t *testing.T) {
+	jobs := &mocks.JobsStore{}
+	now := time.Date(2025, 6, 1, 10, 7, 30, 0, time.UTC)
+	slot := time.Date(2025, 6, 1, 10, 5, 0, 0, time.UTC)
+
+	q := NewQueue(store.Storage{Jobs: jobs}, zap.NewNop().Sugar(), QueueConfig{})
+	q.schedules = nil
+	q.now = func() time.Time { return now }
+	q.Schedule(DelayCheckJob, 5*time.Minute)
+
+	jobs.On("Enqueue", mock.Anything, mock.MatchedBy(func(j *models.Job) bool {
+		return j.Kind == DelayCheckJob && j.RunAt.Equal(slot) && j.UniqueKey != nil &&
+			*j.UniqueKey == DelayCheckJob+"@1748772300" && j.MaxAttempts == scheduledMaxAttempts
+	})).Return(true, nil).Twice()
+
+	// A second pass in the same slot reuses the key, so the store ignores it.
+	q.enqueueDue(context.Background())
+	q.enqueueDue(context.Background())
+
+	jobs.AssertExpectations(t)
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		min     time.Duration
+	}{
+		{attempt: 0, min: 30 * time.Second},
+		{attempt: 1, min: 30 * time.Second},
+		{attempt: 2, min: time.Minute},
+		{attempt: 5, min: 8 * time.Minute},
+		{attempt: 8, min: time.Hour},
+		{attempt: 100, min: time.Hour},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			got := Backoff(tt.attempt)
+			if got < tt.min || got > tt.min+tt.min/10 {
+				t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.min+tt.min/10)
+			}
+		}
+	}
+}
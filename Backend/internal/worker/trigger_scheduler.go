@@ -0,0 +1,339 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"Backend/internal/mailer"
+	"Backend/internal/notifications"
+	"Backend/internal/store"
+	"Backend/internal/store/models"
+	"Backend/internal/whatsapp"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// triggerCatchUp is how long after its due time a trigger still fires, so a
+// scheduler that was down for a while catches up without sending reminders
+// that are no longer relevant.
+const triggerCatchUp = 24 * time.Hour
+
+// PushMessage is the title and body of a push notification. Both are
+// text/template strings rendered with the same data as the email templates.
+type PushMessage struct {
+	Title string
+	Body  string
+}
+
+// PushMessages are the messages a push trigger can send, keyed by template ID.
+var PushMessages = map[string]PushMessage{
+	"event_reminder_7d":   {Title: "🎉 ¡Tu evento es en 7 días!", Body: "{{.EventName}} - Revisa tu checklist y prepárate"},
+	"event_reminder_24h":  {Title: "{{.EventName}}", Body: "¡Tu evento es mañana! Recordatorio de Rosa Fiesta."},
+	"post_event_review":   {Title: "{{.EventName}}", Body: "¡Gracias por confiar en nosotros! ¿Podrías dejarnos una reseña?"},
+	"quote_adjusted":      {Title: "Cotización actualizada", Body: "Revisamos la cotización de {{.EventName}}. Entra para aprobarla o pedir cambios."},
+	"installment_due":     {Title: "Recordatorio de pago", Body: "Tu cuota de RD$ {{.AmountDue}} para {{.EventName}} vence el {{.DueDate}}."},
+	"installment_overdue": {Title: "Pago vencido", Body: "Tu cuota de RD$ {{.AmountDue}} para {{.EventName}} venció el {{.DueDate}}. Realiza el pago para mantener tu reserva."},
+}
+
+var errNoRecipient = errors.New("user has no address for this channel")
+
+// TriggerScheduler evaluates the notification triggers configured from the
+// admin panel and sends each one at most once per event (or per installment,
+// or per quote adjustment) through the trigger's channel.
+type TriggerScheduler struct {
+	store         store.Storage
+	logger        *zap.SugaredLogger
+	mailer        mailer.Client
+	whatsapp      *whatsapp.Client
+	notifications *notifications.NotificationService
+	frontendURL   string
+	now           func() time.Time
+}
+
+func NewTriggerScheduler(
+	store store.Storage,
+	logger *zap.SugaredLogger,
+	mailer mailer.Client,
+	whatsapp *whatsapp.Client,
+	notifications *notifications.NotificationService,
+	frontendURL string,
+) *TriggerScheduler {
+	return &TriggerScheduler{
+		store:         store,
+		logger:        logger,
+		mailer:        mailer,
+		whatsapp:      whatsapp,
+		notifications: notifications,
+		frontendURL:   frontendURL,
+		now:           time.Now,
+	}
+}
+
+// TriggerEvaluateJob evaluates every enabled notification trigger.
+const TriggerEvaluateJob = "notifications.evaluate_triggers"
+
+// Run is the TriggerEvaluateJob handler.
+func (s *TriggerScheduler) Run(ctx context.Context, _ *models.Job) error {
+	return s.evaluate(ctx)
+}
+
+// triggerRun caches what one evaluation pass loads, so several triggers
+// share the same queries.
+type triggerRun struct {
+	events       []models.TriggerEvent
+	eventsByID   map[uuid.UUID]*models.Event
+	installments []models.InstallmentPayment
+	users        map[uuid.UUID]*models.User
+}
+
+func (s *TriggerScheduler) evaluate(ctx context.Context) error {
+	triggers, err := s.store.NotificationTriggers.GetEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching notification triggers: %w", err)
+	}
+	if len(triggers) == 0 {
+		return nil
+	}
+
+	// Only events some trigger could fire for today are loaded: the
+	// furthest a trigger looks is its offset plus the catch-up window.
+	now := s.now()
+	horizon := triggerCatchUp
+	for _, trigger := range triggers {
+		horizon = max(horizon, time.Duration(trigger.DaysOffset)*24*time.Hour+triggerCatchUp)
+	}
+	events, err := s.store.Events.GetForTriggers(ctx, now.Add(-horizon), now.Add(horizon))
+	if err != nil {
+		return fmt.Errorf("fetching events for notification triggers: %w", err)
+	}
+
+	run := &triggerRun{
+		events:     events,
+		eventsByID: make(map[uuid.UUID]*models.Event, len(events)),
+		users:      make(map[uuid.UUID]*models.User),
+	}
+	for i := range events {
+		run.eventsByID[events[i].ID] = &events[i].Event
+	}
+
+	for _, trigger := range triggers {
+		isInstallment := trigger.EventType == models.TriggerInstallmentDue || trigger.EventType == models.TriggerInstallmentOverdue
+		if isInstallment && run.installments == nil {
+			run.installments, err = s.store.Installments.GetPendingInstallments(ctx)
+			if err != nil {
+				return fmt.Errorf("fetching installments for notification triggers: %w", err)
+			}
+		}
+		s.evaluateTrigger(ctx, run, &trigger)
+	}
+	return nil
+}
+
+func (s *TriggerScheduler) evaluateTrigger(ctx context.Context, run *triggerRun, trigger *models.NotificationTrigger) {
+	now := s.now()
+	offset := time.Duration(trigger.DaysOffset) * 24 * time.Hour
+
+	switch trigger.EventType {
+	case models.TriggerDaysBeforeEvent:
+		for i := range run.events {
+			event := &run.events[i].Event
+			if event.Date == nil || !isBookedStatus(event.Status) {
+				continue
+			}
+			// Never remind about an event that already started.
+			due := event.Date.Add(-offset)
+			if inWindow(now, due, minTime(due.Add(triggerCatchUp), *event.Date)) {
+				s.fire(ctx, run, trigger, event, nil, nil)
+			}
+		}
+	case models.TriggerDaysAfterEvent:
+		for i := range run.events {
+			event := &run.events[i].Event
+			if event.Date == nil || !(isBookedStatus(event.Status) || event.Status == models.EventStatusCompleted) {
+				continue
+			}
+			due := event.Date.Add(offset)
+			if inWindow(now, due, due.Add(triggerCatchUp)) {
+				s.fire(ctx, run, trigger, event, nil, nil)
+			}
+		}
+	case models.TriggerQuoteAdjusted:
+		for i := range run.events {
+			event := &run.events[i]
+			if event.Status != models.EventStatusAdjusted {
+				continue
+			}
+			s.fire(ctx, run, trigger, &event.Event, event.LastAdjustmentID, nil)
+		}
+	case models.TriggerInstallmentDue:
+		for _, inst := range run.installments {
+			event, ok := run.eventsByID[inst.EventID]
+			if !ok || inst.DueDate == nil {
+				continue
+			}
+			due := inst.DueDate.Add(-offset)
+			if inWindow(now, due, due.Add(triggerCatchUp)) {
+				data := map[string]any{
+					"AmountDue": inst.Amount,
+					"DueDate":   inst.DueDate.Format("02 Jan 2006"),
+				}
+				s.fire(ctx, run, trigger, event, &inst.ID, data)
+			}
+		}
+	case models.TriggerInstallmentOverdue:
+		for _, inst := range run.installments {
+			event, ok := run.eventsByID[inst.EventID]
+			if !ok || inst.DueDate == nil {
+				continue
+			}
+			due := inst.DueDate.Add(offset)
+			if inst.IsOverdue(now) && inWindow(now, due, due.Add(triggerCatchUp)) {
+				data := map[string]any{
+					"AmountDue": inst.Amount,
+					"DueDate":   inst.DueDate.Format("02 Jan 2006"),
+				}
+				s.fire(ctx, run, trigger, event, &inst.ID, data)
+			}
+		}
+	default:
+		s.logger.Warnw("unknown notification trigger type", "trigger_id", trigger.ID, "event_type", trigger.EventType)
+	}
+}
+
+// fire sends the trigger for an event unless NotificationLogs shows it was
+// already sent. Failed sends are not logged, so the next pass retries them
+// while the trigger is still in its window.
+func (s *TriggerScheduler) fire(ctx context.Context, run *triggerRun, trigger *models.NotificationTrigger, event *models.Event, subjectID *uuid.UUID, extra map[string]any) {
+	logType := trigger.LogType(subjectID)
+
+	sent, err := s.store.NotificationLogs.HasNotificationBeenSent(ctx, event.ID, logType)
+	if err != nil {
+		s.logger.Errorf("error checking notification log: %v", err)
+		return
+	}
+	if sent {
+		return
+	}
+
+	user, ok := run.users[event.UserID]
+	if !ok {
+		user, err = s.store.Users.RetrieveById(ctx, event.UserID)
+		if err != nil {
+			s.logger.Errorw("error fetching event owner for notification", "event_id", event.ID, "error", err)
+			return
+		}
+		run.users[event.UserID] = user
+	}
+
+	data := s.templateData(event, user)
+	for k, v := range extra {
+		data[k] = v
+	}
+
+	if err := s.dispatch(ctx, trigger, user, data); err != nil {
+		if errors.Is(err, errNoRecipient) {
+			return
+		}
+		s.logger.Errorw("error sending triggered notification",
+			"trigger_id", trigger.ID, "channel", trigger.Channel, "event_id", event.ID, "error", err)
+		return
+	}
+
+	if err := s.store.NotificationLogs.LogNotification(ctx, event.ID, logType); err != nil {
+		s.logger.Errorf("error logging notification: %v", err)
+		return
+	}
+	s.logger.Infow("Sent triggered notification", "trigger", trigger.Name, "channel", trigger.Channel, "event_id", event.ID)
+}
+
+func (s *TriggerScheduler) dispatch(ctx context.Context, trigger *models.NotificationTrigger, user *models.User, data map[string]any) error {
+	switch trigger.Channel {
+	case models.ChannelEmail:
+		if user.Email == "" {
+			return errNoRecipient
+		}
+		_, err := s.mailer.Send(trigger.TemplateID+".tmpl", user.FirstName, user.Email, data, false)
+		return err
+	case models.ChannelWhatsApp:
+		if s.whatsapp == nil {
+			return errors.New("whatsapp is not configured")
+		}
+		if user.PhoneNumber == "" {
+			return errNoRecipient
+		}
+		return s.whatsapp.SendTemplateMessage(ctx, whatsapp.Message{To: user.PhoneNumber, Template: trigger.TemplateID})
+	case models.ChannelPush:
+		if s.notifications == nil {
+			return errors.New("push notifications are not configured")
+		}
+		if user.FCMToken == "" {
+			return errNoRecipient
+		}
+		msg, ok := PushMessages[trigger.TemplateID]
+		if !ok {
+			return fmt.Errorf("unknown push message %q", trigger.TemplateID)
+		}
+		title, err := renderText(msg.Title, data)
+		if err != nil {
+			return err
+		}
+		body, err := renderText(msg.Body, data)
+		if err != nil {
+			return err
+		}
+		return s.notifications.SendPush(ctx, user.FCMToken, title, body)
+	}
+	return fmt.Errorf("unknown channel %q", trigger.Channel)
+}
+
+func (s *TriggerScheduler) templateData(event *models.Event, user *models.User) map[string]any {
+	data := map[string]any{
+		"UserName":      user.FirstName,
+		"Username":      user.UserName,
+		"EventName":     event.Name,
+		"EventDate":     "",
+		"EventTime":     "",
+		"EventLocation": event.Location,
+		"ReviewURL":     s.frontendURL + "/reviews",
+		"ChecklistURL":  fmt.Sprintf("%s/event/%s/checklist", s.frontendURL, event.ID),
+	}
+	if event.Date != nil {
+		data["EventDate"] = event.Date.Format("02 Jan 2006")
+		data["EventTime"] = event.Date.Format("15:04")
+	}
+	return data
+}
+
+func renderText(text string, data map[string]any) (string, error) {
+	tmpl, err := template.New("push").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// isBookedStatus reports whether an event is going ahead, i.e. reminders
+// about it make sense.
+func isBookedStatus(status string) bool {
+	return status == models.EventStatusConfirmed || status == models.EventStatusPaid
+}
+
+func inWindow(now, from, until time.Time) bool {
+	return !now.Before(from) && now.Before(until)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mailerMocks "Backend/internal/mailer/mocks"
+	"Backend/internal/notifications"
+	"Backend/internal/store"
+	"Backend/internal/store/mocks"
+	"Backend/internal/store/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestTriggerScheduler_evaluate(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	notificationService, err := notifications.NewNotificationService()
+	if err != nil {
+		t.Fatalf("failed to create notification service: %v", err)
+	}
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	user := &models.User{ID: uuid.New(), FirstName: "Ana", Email: "ana@example.com", FCMToken: "fcm-token"}
+
+	reminder := models.NotificationTrigger{
+		ID: uuid.New(), Name: "Recordatorio 7 dias", EventType: models.TriggerDaysBeforeEvent,
+		Channel: models.ChannelEmail, TemplateID: "auto_reminder_7d", DaysOffset: 7, Enabled: true,
+	}
+	installmentDue := models.NotificationTrigger{
+		ID: uuid.New(), Name: "Cuota por vencer", EventType: models.TriggerInstallmentDue,
+		Channel: models.ChannelPush, TemplateID: "installment_due", DaysOffset: 3, Enabled: true,
+	}
+
+	newScheduler := func(s store.Storage, m *mailerMocks.Mailer) *TriggerScheduler {
+		scheduler := NewTriggerScheduler(s, logger, m, nil, notificationService, "https://rosafiesta.test")
+		scheduler.now = func() time.Time { return now }
+		return scheduler
+	}
+
+	t.Run("sends a due reminder once and logs it", func(t *testing.T) {
+		eventDate := now.Add(7*24*time.Hour - time.Hour)
+		event := models.Event{ID: uuid.New(), UserID: user.ID, Name: "Boda", Date: &eventDate, Status: models.EventStatusConfirmed}
+
+		triggers := &mocks.NotificationTriggersStore{}
+		events := &mocks.EventStore{}
+		users := &mocks.UserStore{}
+		logs := &mocks.NotificationLogsStore{}
+		m := &mailerMocks.Mailer{}
+
+		triggers.On("GetEnabled", mock.Anything).Return([]models.NotificationTrigger{reminder}, nil)
+		// The 7-day reminder looks 7 days plus the catch-up day either way.
+		events.On("GetForTriggers", mock.Anything, now.Add(-8*24*time.Hour), now.Add(8*24*time.Hour)).
+			Return([]models.TriggerEvent{{Event: event}}, nil)
+		logs.On("HasNotificationBeenSent", mock.Anything, event.ID, reminder.LogType(nil)).Return(false, nil)
+		users.On("RetrieveById", mock.Anything, user.ID).Return(user, nil)
+		m.On("Send", "auto_reminder_7d.tmpl", "Ana", "ana@example.com", mock.Anything, false).Return(200, nil)
+		logs.On("LogNotification", mock.Anything, event.ID, reminder.LogType(nil)).Return(nil)
+
+		s := store.Storage{NotificationTriggers: triggers, Events: events, Users: users, NotificationLogs: logs}
+		newScheduler(s, m).evaluate(context.Background())
+
+		m.AssertExpectations(t)
+		logs.AssertExpectations(t)
+	})
+
+	t.Run("skips reminders already sent", func(t *testing.T) {
+		eventDate := now.Add(7*24*time.Hour - time.Hour)
+		event := models.Event{ID: uuid.New(), UserID: user.ID, Name: "Boda", Date: &eventDate, Status: models.EventStatusPaid}
+
+		triggers := &mocks.NotificationTriggersStore{}
+		events := &mocks.EventStore{}
+		logs := &mocks.NotificationLogsStore{}
+		m := &mailerMocks.Mailer{}
+
+		triggers.On("GetEnabled", mock.Anything).Return([]models.NotificationTrigger{reminder}, nil)
+		events.On("GetForTriggers", mock.Anything, mock.Anything, mock.Anything).Return([]models.TriggerEvent{{Event: event}}, nil)
+		logs.On("HasNotificationBeenSent", mock.Anything, event.ID, reminder.LogType(nil)).Return(true, nil)
+
+		s := store.Storage{NotificationTriggers: triggers, Events: events, NotificationLogs: logs}
+		newScheduler(s, m).evaluate(context.Background())
+
+		m.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		logs.AssertNotCalled(t, "LogNotification", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ignores events outside the window or not booked", func(t *testing.T) {
+		farDate := now.Add(10 * 24 * time.Hour)
+		dueDate := now.Add(7*24*time.Hour - time.Hour)
+		events := []models.TriggerEvent{
+			{Event: models.Event{ID: uuid.New(), UserID: user.ID, Date: &farDate, Status: models.EventStatusConfirmed}},
+			{Event: models.Event{ID: uuid.New(), UserID: user.ID, Date: &dueDate, Status: models.EventStatusRequested}},
+			{Event: models.Event{ID: uuid.New(), UserID: user.ID, Status: models.EventStatusConfirmed}},
+		}
+
+		triggers := &mocks.NotificationTriggersStore{}
+		eventStore := &mocks.EventStore{}
+		logs := &mocks.NotificationLogsStore{}
+		m := &mailerMocks.Mailer{}
+
+		triggers.On("GetEnabled", mock.Anything).Return([]models.NotificationTrigger{reminder}, nil)
+		eventStore.On("GetForTriggers", mock.Anything, mock.Anything, mock.Anything).Return(events, nil)
+
+		s := store.Storage{NotificationTriggers: triggers, Events: eventStore, NotificationLogs: logs}
+		newScheduler(s, m).evaluate(context.Background())
+
+		logs.AssertNotCalled(t, "HasNotificationBeenSent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("logs installment reminders per installment", func(t *testing.T) {
+		eventDate := now.Add(30 * 24 * time.Hour)
+		event := models.Event{ID: uuid.New(), UserID: user.ID, Name: "Quinceañera", Date: &eventDate, Status: models.EventStatusConfirmed}
+		dueDate := now.Add(3*24*time.Hour - time.Hour)
+		inst := models.InstallmentPayment{ID: uuid.New(), EventID: event.ID, Amount: 25000, PaymentStatus: "pending", DueDate: &dueDate}
+
+		triggers := &mocks.NotificationTriggersStore{}
+		events := &mocks.EventStore{}
+		users := &mocks.UserStore{}
+		logs := &mocks.NotificationLogsStore{}
+		installments := &mocks.InstallmentsStore{}
+
+		triggers.On("GetEnabled", mock.Anything).Return([]models.NotificationTrigger{installmentDue}, nil)
+		events.On("GetForTriggers", mock.Anything, mock.Anything, mock.Anything).Return([]models.TriggerEvent{{Event: event}}, nil)
+		installments.On("GetPendingInstallments", mock.Anything).Return([]models.InstallmentPayment{inst}, nil)
+		logs.On("HasNotificationBeenSent", mock.Anything, event.ID, installmentDue.LogType(&inst.ID)).Return(false, nil)
+		users.On("RetrieveById", mock.Anything, user.ID).Return(user, nil)
+		logs.On("LogNotification", mock.Anything, event.ID, installmentDue.LogType(&inst.ID)).Return(nil)
+
+		s := store.Storage{NotificationTriggers: triggers, Events: events, Users: users, NotificationLogs: logs, Installments: installments}
+		newScheduler(s, &mailerMocks.Mailer{}).evaluate(context.Background())
+
+		logs.AssertExpectations(t)
+	})
+
+	t.Run("warns about installments still unpaid after the due date", func(t *testing.T) {
+		overdue := models.NotificationTrigger{
+			ID: uuid.New(), Name: "Cuota vencida", EventType: models.TriggerInstallmentOverdue,
+			Channel: models.ChannelPush, TemplateID: "installment_overdue", DaysOffset: 2, Enabled: true,
+		}
+		eventDate := now.Add(20 * 24 * time.Hour)
+		event := models.Event{ID: uuid.New(), UserID: user.ID, Name: "Boda", Date: &eventDate, Status: models.EventStatusConfirmed}
+		missed := now.Add(-2*24*time.Hour - time.Hour)
+		notYet := now.Add(-24 * time.Hour)
+		late := models.InstallmentPayment{ID: uuid.New(), EventID: event.ID, Amount: 12000, PaymentStatus: models.InstallmentStatusPending, DueDate: &missed}
+		recent := models.InstallmentPayment{ID: uuid.New(), EventID: event.ID, Amount: 8000, PaymentStatus: models.InstallmentStatusPending, DueDate: &notYet}
+
+		triggers := &mocks.NotificationTriggersStore{}
+		events := &mocks.EventStore{}
+		users := &mocks.UserStore{}
+		logs := &mocks.NotificationLogsStore{}
+		installments := &mocks.InstallmentsStore{}
+
+		triggers.On("GetEnabled", mock.Anything).Return([]models.NotificationTrigger{overdue}, nil)
+		events.On("GetForTriggers", mock.Anything, mock.Anything, mock.Anything).Return([]models.TriggerEvent{{Event: event}}, nil)
+		installments.On("GetPendingInstallments", mock.Anything).Return([]models.InstallmentPayment{late, recent}, nil)
+		logs.On("HasNotificationBeenSent", mock.Anything, event.ID, overdue.LogType(&late.ID)).Return(false, nil)
+		users.On("RetrieveById", mock.Anything, user.ID).Return(user, nil)
+		logs.On("LogNotification", mock.Anything, event.ID, overdue.LogType(&late.ID)).Return(nil)
+
+		s := store.Storage{NotificationTriggers: triggers, Events: events, Users: users, NotificationLogs: logs, Installments: installments}
+		newScheduler(s, &mailerMocks.Mailer{}).evaluate(context.Background())
+
+		logs.AssertExpectations(t)
+		logs.AssertNotCalled(t, "HasNotificationBeenSent", mock.Anything, event.ID, overdue.LogType(&recent.ID))
+	})
+
+	t.Run("notifies each quote adjustment once", func(t *testing.T) {
+		adjusted := models.NotificationTrigger{
+			ID: uuid.New(), Name: "Cotización ajustada", EventType: models.TriggerQuoteAdjusted,
+			Channel: models.ChannelPush, TemplateID: "quote_adjusted", Enabled: true,
+		}
+		adjustmentID := uuid.New()
+		event := models.TriggerEvent{
+			Event:            models.Event{ID: uuid.New(), UserID: user.ID, Name: "Boda", Status: models.EventStatusAdjusted},
+			LastAdjustmentID: &adjustmentID,
+		}
+
+		triggers := &mocks.NotificationTriggersStore{}
+		events := &mocks.EventStore{}
+		users := &mocks.UserStore{}
+		logs := &mocks.NotificationLogsStore{}
+
+		triggers.On("GetEnabled", mock.Anything).Return([]models.NotificationTrigger{adjusted}, nil)
+		events.On("GetForTriggers", mock.Anything, mock.Anything, mock.Anything).Return([]models.TriggerEvent{event}, nil)
+		logs.On("HasNotificationBeenSent", mock.Anything, event.ID, adjusted.LogType(&adjustmentID)).Return(false, nil)
+		users.On("RetrieveById", mock.Anything, user.ID).Return(user, nil)
+		logs.On("LogNotification", mock.Anything, event.ID, adjusted.LogType(&adjustmentID)).Return(nil)
+
+		s := store.Storage{NotificationTriggers: triggers, Events: events, Users: users, NotificationLogs: logs}
+		newScheduler(s, &mailerMocks.Mailer{}).evaluate(context.Background())
+
+		logs.AssertExpectations(t)
+	})
+}
+
+func TestRenderPushMessages(t *testing.T) {
+	data := map[string]any{"EventName": "Boda", "AmountDue": 25000, "DueDate": "04 Jun 2025"}
+	for id, msg := range PushMessages {
+		if _, err := renderText(msg.Title, data); err != nil {
+			t.Errorf("%s title: %v", id, err)
+		}
+		if _, err := renderText(msg.Body, data); err != nil {
+			t.Errorf("%s body: %v", id, err)
+		}
+	}
+}